@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// KeyPreset picks the solo keyboard layout.
+type KeyPreset int
+
+const (
+	// StandardKeys is the usual two-handed layout.
+	StandardKeys KeyPreset = iota
+	// LeftHandKeys clusters every action around WASD.
+	LeftHandKeys
+	// RightHandKeys clusters every action around the arrows.
+	RightHandKeys
+	// CustomKeys uses Settings.Keys, set on the Key Bindings page.
+	CustomKeys
+)
+
+// keyPreset is a solo layout and the panel's help lines for it.
+type keyPreset struct {
+	name     string
+	keys     KeyMap
+	help     []string
+	undoHelp string
+}
+
+var keyPresets = [...]keyPreset{
+	StandardKeys: {
+		name: "Standard",
+		keys: KeyMap{
+			BindLeft:      []ebiten.Key{ebiten.KeyLeft, ebiten.KeyA},
+			BindRight:     []ebiten.Key{ebiten.KeyRight, ebiten.KeyD},
+			BindSoftDrop:  []ebiten.Key{ebiten.KeyDown, ebiten.KeyS},
+			BindRotateCW:  []ebiten.Key{ebiten.KeyX, ebiten.KeyUp, ebiten.KeyW},
+			BindRotateCCW: []ebiten.Key{ebiten.KeyZ},
+			BindHardDrop:  []ebiten.Key{ebiten.KeySpace},
+			BindHold:      []ebiten.Key{ebiten.KeyC, ebiten.KeyShiftLeft, ebiten.KeyShiftRight},
+			BindUndo:      []ebiten.Key{ebiten.KeyBackspace, ebiten.KeyU},
+			BindPause:     []ebiten.Key{ebiten.KeyP, ebiten.KeyEscape},
+		},
+		help:     []string{"←/→ Move", "↓ Soft Drop", "Z/X/↑ Rotate", "Space Hard Drop", "C/Shift Hold"},
+		undoHelp: "Bksp/U Undo",
+	},
+	LeftHandKeys: {
+		name: "Left Hand",
+		keys: KeyMap{
+			BindLeft:      []ebiten.Key{ebiten.KeyA},
+			BindRight:     []ebiten.Key{ebiten.KeyD},
+			BindSoftDrop:  []ebiten.Key{ebiten.KeyS},
+			BindRotateCW:  []ebiten.Key{ebiten.KeyW},
+			BindRotateCCW: []ebiten.Key{ebiten.KeyQ},
+			BindHardDrop:  []ebiten.Key{ebiten.KeySpace},
+			BindHold:      []ebiten.Key{ebiten.KeyE, ebiten.KeyShiftLeft},
+			BindUndo:      []ebiten.Key{ebiten.KeyTab},
+			BindPause:     []ebiten.Key{ebiten.KeyP, ebiten.KeyEscape},
+		},
+		help:     []string{"A/D Move", "S Soft Drop", "W/Q Rotate", "Space Hard Drop", "E/LShift Hold"},
+		undoHelp: "Tab Undo",
+	},
+	RightHandKeys: {
+		name: "Right Hand",
+		keys: KeyMap{
+			BindLeft:      []ebiten.Key{ebiten.KeyLeft},
+			BindRight:     []ebiten.Key{ebiten.KeyRight},
+			BindSoftDrop:  []ebiten.Key{ebiten.KeyDown},
+			BindRotateCW:  []ebiten.Key{ebiten.KeyUp},
+			BindRotateCCW: []ebiten.Key{ebiten.KeySlash},
+			BindHardDrop:  []ebiten.Key{ebiten.KeyEnter},
+			BindHold:      []ebiten.Key{ebiten.KeyShiftRight, ebiten.KeyPeriod},
+			BindUndo:      []ebiten.Key{ebiten.KeyBackspace},
+			BindPause:     []ebiten.Key{ebiten.KeyP, ebiten.KeyEscape},
+		},
+		help:     []string{"←/→ Move", "↓ Soft Drop", "↑// Rotate", "Enter Hard Drop", "RShift/. Hold"},
+		undoHelp: "Bksp Undo",
+	},
+}
+
+// keyPreset is the layout st picks, with Custom built from st.Keys.
+func (st Settings) keyPreset() keyPreset {
+	if st.KeyPreset == CustomKeys {
+		return customPreset(st.Keys)
+	}
+	return keyPresets[st.KeyPreset]
+}
+
+func (g *Game) keyPreset() keyPreset {
+	return g.settings.keyPreset()
+}
+
+// TouchLayout picks where the on-screen buttons go.
+type TouchLayout int
+
+const (
+	// TouchStandard spreads four buttons across the bottom.
+	TouchStandard TouchLayout = iota
+	// TouchOneThumb packs them into a 2x2 block under the right thumb.
+	TouchOneThumb
+	// TouchDrag has no buttons: the piece follows a finger dragged over
+	// the screen; see dragPad.
+	TouchDrag
+	// TouchCustom puts the buttons where the player dragged them, kept in
+	// Settings.TouchButtons.
+	TouchCustom
+)
+
+func (t TouchLayout) String() string {
+	switch t {
+	case TouchOneThumb:
+		return "One Thumb"
+	case TouchDrag:
+		return "Drag"
+	case TouchCustom:
+		return "Custom"
+	}
+	return "Buttons"
+}
+
+// Touch buttons, indexing touchButtons.
+const (
+	btnLeft = iota
+	btnRight
+	btnRotate
+	btnDrop
+)
+
+var touchLabels = [...]string{"Left", "Right", "Rotate", "Drop"}
+
+// touchRects are the buttons as laid out on a w by h screen: where the
+// player put them, or the layout's, mirrored for the left hand.
+func (g *Game) touchRects(w, h float32) [4]rect {
+	s := g.settings
+	if s.TouchLayout == TouchCustom && len(s.TouchButtons) == len(touchLabels) {
+		var r [4]rect
+		for i, b := range s.TouchButtons {
+			r[i] = rect{float32(b.X) * w, float32(b.Y) * h, float32(b.W) * w, float32(b.H) * h}
+		}
+		return r
+	}
+	r := touchButtons(s.TouchLayout, w, h, g.touchBarHeight())
+	if s.TouchLeftHanded {
+		for i := range r {
+			r[i].x = w - r[i].x - r[i].w
+		}
+	}
+	return r
+}
+
+// touchButtons lays out the buttons in the bar of height ctrlH at the
+// bottom of a w by h screen.
+func touchButtons(t TouchLayout, w, h, ctrlH float32) [4]rect {
+	y := h - ctrlH
+	if t == TouchOneThumb {
+		bw, bh := w/4, ctrlH/2
+		return [4]rect{
+			btnLeft:   {w / 2, y + bh, bw, bh},
+			btnRight:  {w/2 + bw, y + bh, bw, bh},
+			btnRotate: {w / 2, y, bw, bh},
+			btnDrop:   {w/2 + bw, y, bw, bh},
+		}
+	}
+	bw := w / 4
+	var r [4]rect
+	for i := range r {
+		r[i] = rect{float32(i) * bw, y, bw, ctrlH}
+	}
+	return r
+}
+
+var accessibilityPage = &menuPage{title: "Accessibility", items: []settingItem{
+	{
+		label: "Keyboard",
+		value: func(g *Game) string { return g.keyPreset().name },
+		adjust: func(g *Game, dir int) {
+			n := len(keyPresets)
+			if g.settings.Keys != nil {
+				n++ // Custom, once there is one
+			}
+			g.settings.KeyPreset = KeyPreset(wrap(int(g.settings.KeyPreset)+dir, n))
+		},
+	},
+	subPage("Key Bindings", keyBindingsPage),
+	{
+		label:  "Mouse Control",
+		value:  func(g *Game) string { return onOff(g.settings.MouseControl) },
+		adjust: func(g *Game, dir int) { g.settings.MouseControl = !g.settings.MouseControl },
+	},
+	{
+		label: "Touch Layout",
+		value: func(g *Game) string { return g.settings.TouchLayout.String() },
+		adjust: func(g *Game, dir int) {
+			g.settings.TouchLayout = TouchLayout(wrap(int(g.settings.TouchLayout)+dir, int(TouchCustom)+1))
+		},
+	},
+	{
+		label:  "Left-Handed Touch",
+		value:  func(g *Game) string { return onOff(g.settings.TouchLeftHanded) },
+		adjust: func(g *Game, dir int) { g.settings.TouchLeftHanded = !g.settings.TouchLeftHanded },
+	},
+	{
+		label: "Button Opacity",
+		value: func(g *Game) string { return fmt.Sprintf("%d%%", int(g.settings.TouchOpacity*100+0.5)) },
+		adjust: func(g *Game, dir int) {
+			o := g.settings.TouchOpacity + float64(dir)*touchOpacityStep
+			g.settings.TouchOpacity = max(minTouchOpacity, min(1, o))
+		},
+	},
+	{
+		label:  "Edit Touch Buttons",
+		value:  func(g *Game) string { return "" },
+		adjust: func(g *Game, dir int) { g.editTouchButtons() },
+	},
+	{
+		label: "Piece Marks",
+		value: func(g *Game) string { return g.settings.PieceMarks.String() },
+		adjust: func(g *Game, dir int) {
+			g.settings.PieceMarks = PieceMarks(wrap(int(g.settings.PieceMarks)+dir, int(MarksLetters)+1))
+		},
+	},
+	{
+		label: "Game Speed",
+		value: func(g *Game) string {
+			if g.settings.speed() < 1 {
+				return speedLabel(g.settings.speed()) + " (assisted)"
+			}
+			return speedLabel(1)
+		},
+		adjust: func(g *Game, dir int) {
+			cur := 0
+			for i, s := range gameSpeeds {
+				if s == g.settings.speed() {
+					cur = i
+				}
+			}
+			g.settings.GameSpeed = gameSpeeds[wrap(cur+dir, len(gameSpeeds))]
+		},
+	},
+}}
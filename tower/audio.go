@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"tetris/engine"
+	"tetris/sound"
+)
+
+// volumeStep is how far one press moves a volume setting.
+const volumeStep = 0.1
+
+// soundPlayer plays effects and music for the windowed game; nil, and so
+// silent, for headless runs.
+var soundPlayer *sound.Player
+
+func applySound(s Settings) {
+	soundPlayer.SetVolumes(s.SFXVolume, s.MusicVolume, s.Mute)
+}
+
+// sfx sounds a game's locks, clears, level ups and end.
+type sfx struct {
+	engine.NopObserver
+	g *Game
+	// rival is set for the versus rival, whose end is heard through the
+	// game it ends.
+	rival bool
+}
+
+func (s sfx) OnLock(_, cleared int) {
+	switch {
+	case cleared == 4:
+		s.g.play(sound.Tetris)
+	case cleared > 0:
+		s.g.play(sound.LineClear)
+	default:
+		s.g.play(sound.Lock)
+	}
+}
+
+func (s sfx) OnLevelUp(int) { s.g.play(sound.LevelUp) }
+
+func (s sfx) OnGameOver(string) {
+	if !s.rival {
+		s.g.play(sound.GameOver)
+	}
+}
+
+// play sounds e, except while a replay is being stepped through.
+func (g *Game) play(e sound.Effect) {
+	if !g.offline && g.bench == nil {
+		soundPlayer.Play(e)
+	}
+}
+
+// updateMusic runs the background track during play, at the level's tempo.
+func (g *Game) updateMusic() {
+	soundPlayer.SetMusic(g.base() == statePlaying && g.bench == nil)
+	soundPlayer.SetLevel(g.Level())
+}
+
+func volumeItem(label string, v func(s *Settings) *float64) settingItem {
+	return settingItem{
+		label: label,
+		value: func(g *Game) string { return fmt.Sprintf("%d%%", int(math.Round(*v(&g.settings)*100))) },
+		adjust: func(g *Game, dir int) {
+			p := v(&g.settings)
+			*p = max(0, min(1, math.Round((*p+float64(dir)*volumeStep)*10)/10))
+			applySound(g.settings)
+		},
+	}
+}
+
+var soundPage = &menuPage{title: "Sound", items: []settingItem{
+	volumeItem("Effects Volume", func(s *Settings) *float64 { return &s.SFXVolume }),
+	volumeItem("Music Volume", func(s *Settings) *float64 { return &s.MusicVolume }),
+	{
+		label: "Mute",
+		value: func(g *Game) string { return onOff(g.settings.Mute) },
+		adjust: func(g *Game, dir int) {
+			g.settings.Mute = !g.settings.Mute
+			applySound(g.settings)
+		},
+	},
+}}
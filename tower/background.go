@@ -0,0 +1,146 @@
+package main
+
+import (
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// backgroundDir is scanned for user background images.
+const backgroundDir = "backgrounds"
+
+// listBackgrounds returns the image files in backgroundDir, sorted. A
+// missing folder just means no user backgrounds.
+func listBackgrounds() []string {
+	entries, err := os.ReadDir(backgroundDir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".png", ".jpg", ".jpeg":
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// backgroundPath resolves a background name: bare file names live in
+// backgroundDir, anything with a directory (as mod themes use) is a path.
+func backgroundPath(name string) string {
+	if strings.ContainsAny(name, `/\`) {
+		return name
+	}
+	return filepath.Join(backgroundDir, name)
+}
+
+// userBackground is a loaded background, already scaled to cover the screen
+// and blurred. Darkening is applied at draw time.
+type userBackground struct {
+	name string
+	blur int
+	w, h int
+	img  *ebiten.Image // nil if loading failed
+}
+
+var loadedBackground userBackground
+
+func (g *Game) backgroundName() string {
+	if g.settings.Background != "" {
+		return g.settings.Background
+	}
+	return g.theme().Background
+}
+
+// cycleBackground steps through the theme default and every file in
+// backgroundDir.
+func (g *Game) cycleBackground(dir int) {
+	names := append([]string{""}, listBackgrounds()...)
+	i := 0
+	for j, n := range names {
+		if n == g.settings.Background {
+			i = j
+		}
+	}
+	g.settings.Background = names[wrap(i+dir, len(names))]
+}
+
+// drawUserBackground draws the selected background image and reports whether
+// there was one.
+func (g *Game) drawUserBackground(screen *ebiten.Image) bool {
+	name := g.backgroundName()
+	if name == "" {
+		return false
+	}
+	th := g.theme()
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	bg := &loadedBackground
+	if bg.name != name || bg.blur != th.BackgroundBlur || bg.w != w || bg.h != h {
+		*bg = userBackground{name: name, blur: th.BackgroundBlur, w: w, h: h}
+		src, err := loadImage(backgroundPath(name))
+		if err != nil {
+			slog.Warn("background load failed", "file", name, "err", err)
+		} else {
+			bg.img = coverAndBlur(src, w, h, th.BackgroundBlur)
+		}
+	}
+	if bg.img == nil {
+		return false
+	}
+	op := &ebiten.DrawImageOptions{}
+	k := float32(1 - th.BackgroundDim)
+	op.ColorScale.Scale(k, k, k, 1)
+	screen.DrawImage(bg.img, op)
+	return true
+}
+
+func loadImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// coverAndBlur scales src to fill w×h (cropping the overflow) and blurs it
+// by bouncing through a smaller image blur times.
+func coverAndBlur(src image.Image, w, h, blur int) *ebiten.Image {
+	s := ebiten.NewImageFromImage(src)
+	sw, sh := float64(s.Bounds().Dx()), float64(s.Bounds().Dy())
+	k := float64(w) / sw
+	if kh := float64(h) / sh; kh > k {
+		k = kh
+	}
+	out := ebiten.NewImage(w, h)
+	op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	op.GeoM.Scale(k, k)
+	op.GeoM.Translate((float64(w)-sw*k)/2, (float64(h)-sh*k)/2)
+	out.DrawImage(s, op)
+	s.Deallocate()
+
+	if blur <= 0 {
+		return out
+	}
+	div := 1 << blur
+	small := ebiten.NewImage(max(1, w/div), max(1, h/div))
+	defer small.Deallocate()
+	down := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	down.GeoM.Scale(1/float64(div), 1/float64(div))
+	small.DrawImage(out, down)
+	out.Clear()
+	up := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	up.GeoM.Scale(float64(w)/float64(small.Bounds().Dx()), float64(h)/float64(small.Bounds().Dy()))
+	out.DrawImage(small, up)
+	return out
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"tetris/engine"
+)
+
+const benchDuration = 30 * time.Second
+
+// benchmark drives a scripted, maximally busy scene and times each drawn
+// frame. Vsync is off while it runs so the numbers reflect rendering cost
+// rather than the display's refresh rate.
+type benchmark struct {
+	start, last time.Time
+	frames      []time.Duration
+	ticks       int
+	saved       Settings
+	result      string // set once finished
+}
+
+// startBenchmark replaces the current game with the benchmark scene.
+func (g *Game) startBenchmark() {
+	for g.menuOpen() {
+		g.closeMenu()
+	}
+	saved := g.settings
+	g.Reset()
+	g.settings.Quality = QualityHigh
+	g.settings.Tweens = true
+	g.settings.ReduceMotion = false
+	// Fill all but the top rows, leaving a hole per row so nothing clears.
+	board := engine.NewBoard(g.Width(), g.Height())
+	for y := 3; y < g.Height(); y++ {
+		hole := rand.Intn(g.Width())
+		for x := 0; x < g.Width(); x++ {
+			if x != hole {
+				board[y][x] = 1 + rand.Intn(len(engine.Shapes))
+			}
+		}
+	}
+	g.SetBoard(board)
+	ebiten.SetVsyncEnabled(false)
+	g.bench = &benchmark{start: time.Now(), saved: saved}
+	slog.Info("benchmark started", "duration", benchDuration)
+}
+
+// updateBenchmark runs in place of normal game logic while a benchmark is
+// active, keeping every effect busy.
+func (g *Game) updateBenchmark() {
+	b := g.bench
+	if b.result != "" {
+		if inpututil.IsKeyJustPressed(ebiten.KeySpace) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) ||
+			inpututil.IsKeyJustPressed(ebiten.KeyEscape) || len(justTouchIDs(nil)) > 0 {
+			g.settings = b.saved
+			g.bench = nil
+			g.Reset()
+		}
+		return
+	}
+	if time.Since(b.start) >= benchDuration {
+		g.finishBenchmark()
+		return
+	}
+	b.ticks++
+	switch {
+	case b.ticks%90 == 0:
+		g.fx.startPunch()
+	case b.ticks%24 == 0:
+		board := g.Board()
+		rows := make([]engine.ClearedRow, 4)
+		for i := range rows {
+			rows[i].Y = g.Height() - 4 + i
+			rows[i].Cells = board[g.Height()-4+i]
+		}
+		g.fx.startDissolve(rows)
+	}
+	if b.ticks%8 == 0 {
+		dx := 1 - 2*(b.ticks/8%2)
+		g.fx.tweens[0].push(float32(dx), 1)
+	}
+}
+
+// benchFrame records the time since the previous drawn frame.
+func (b *benchmark) benchFrame() {
+	now := time.Now()
+	if !b.last.IsZero() && b.result == "" {
+		b.frames = append(b.frames, now.Sub(b.last))
+	}
+	b.last = now
+}
+
+func (g *Game) finishBenchmark() {
+	ebiten.SetVsyncEnabled(true)
+	b := g.bench
+	avg, low := frameStats(b.frames)
+	b.result = fmt.Sprintf("avg %.2f ms (%.0f fps), 1%% low %.2f ms (%.0f fps)",
+		ms(avg), 1000/ms(avg), ms(low), 1000/ms(low))
+	slog.Info("benchmark finished", "frames", len(b.frames), "avg", avg, "low1pct", low)
+}
+
+// frameStats returns the mean frame time and the "1% low": the mean of the
+// slowest 1% of frames.
+func frameStats(frames []time.Duration) (avg, low time.Duration) {
+	if len(frames) == 0 {
+		return 0, 0
+	}
+	s := append([]time.Duration(nil), frames...)
+	sort.Slice(s, func(i, j int) bool { return s[i] > s[j] })
+	var sum time.Duration
+	for _, d := range s {
+		sum += d
+	}
+	n := max(1, len(s)/100)
+	var worst time.Duration
+	for _, d := range s[:n] {
+		worst += d
+	}
+	return sum / time.Duration(len(s)), worst / time.Duration(n)
+}
+
+func ms(d time.Duration) float64 {
+	return max(float64(d)/float64(time.Millisecond), 0.001)
+}
+
+func (g *Game) drawBenchmark(screen *ebiten.Image) {
+	pal := g.palette()
+	b := g.bench
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	if b.result == "" {
+		left := benchDuration - time.Since(b.start)
+		g.drawText(screen, trf("Benchmark: %ds left", int(left.Seconds())+1), 8, 16, pal.Text)
+		return
+	}
+	vector.DrawFilledRect(screen, 0, 0, float32(w), float32(h), alpha(pal.Shade, 190), false)
+	k := float32(g.settings.UIScale)
+	lines := []string{tr("Benchmark"), b.result, trf("%d frames", len(b.frames)), tr("Tap or Space/Enter to return")}
+	for i, s := range lines {
+		g.drawCentered(screen, s, float32(w)/2, float32(h)/2+float32(i-2)*menuRowH*k, pal.Text)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// drawBossHealth draws the current phase's health bar at (x, y).
+func (g *Game) drawBossHealth(screen *ebiten.Image, x, y float32) {
+	pal := g.palette()
+	frac, ok := g.BossHealth()
+	if !ok {
+		return
+	}
+	k := float32(g.settings.UIScale)
+	w, h := 120*k, 6*k
+	vector.DrawFilledRect(screen, x, y, w, h, shade(pal.Alert, 0.3), false)
+	vector.DrawFilledRect(screen, x, y, w*float32(frac), h, pal.Alert, false)
+}
+
+// drawIncoming draws the queued garbage as a red meter beside the board's
+// left edge, one cell per row: bright for rows ready to rise, dim on top
+// for those still in their delay.
+func (g *Game) drawIncoming(screen *ebiten.Image, originX, originY, tile, boardPxH float32) {
+	pal := g.palette()
+	n := g.IncomingRows()
+	if n == 0 {
+		return
+	}
+	h := minF(float32(n)*tile, boardPxH)
+	ready := minF(float32(g.ReadyRows())*tile, h)
+	vector.DrawFilledRect(screen, originX-8, originY+boardPxH-h, 4, h-ready, shade(pal.Alert, 0.5), false)
+	vector.DrawFilledRect(screen, originX-8, originY+boardPxH-ready, 4, ready, pal.Alert, false)
+}
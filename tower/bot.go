@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"tetris/bot"
+)
+
+// OverBudget selects what happens when a bot misses its deadline or errors.
+type OverBudget int
+
+const (
+	// FallbackMove hard drops the piece where it currently is.
+	FallbackMove OverBudget = iota
+	// Forfeit ends the bot's game.
+	Forfeit
+)
+
+const defaultBotBudget = 100 * time.Millisecond
+
+// maxSteerFrames is how long the driver steers toward a placement before
+// giving up and dropping the piece where it is. A tuck soft drops most of
+// the board's height first.
+const maxSteerFrames = 60
+
+type botResult struct {
+	move bot.Move
+	err  error
+}
+
+// botDriver runs a Bot against a Game, one decision per piece, without
+// blocking the frame: the decision runs in the background and is polled
+// each frame. It plays the chosen move with the same per-frame input a
+// player would give, so bot runs record and replay like any other.
+type botDriver struct {
+	bot        bot.Bot
+	budget     time.Duration
+	overBudget OverBudget
+	now        func() time.Time // the clock deadlines are kept by
+
+	piece    int // g.pieces value the pending decision is for
+	pending  chan botResult
+	cancel   context.CancelFunc
+	deadline time.Time
+	target   *bot.Move // the decided placement being steered to
+	steered  int       // frames spent steering toward target
+}
+
+func newBotDriver(b bot.Bot, budget time.Duration, ob OverBudget) *botDriver {
+	if budget <= 0 {
+		budget = defaultBotBudget
+	}
+	return &botDriver{bot: b, budget: budget, overBudget: ob, now: time.Now, piece: -1}
+}
+
+// input is the driver's input for this frame. It starts a decision when a
+// new piece appears, then takes one step a frame along the way to the
+// decided placement and hard drops once it's straight below. A missed
+// deadline or a placement it can't reach applies the over-budget policy.
+func (d *botDriver) input(g *Game) frameInput {
+	if g.GameOver() {
+		d.stop()
+		return frameInput{}
+	}
+	p := g.Player(0)
+	if p.Spawning {
+		return frameInput{}
+	}
+	if d.piece != g.Pieces() {
+		d.stop()
+		d.target, d.steered = nil, 0
+		d.start(g)
+	}
+	if d.pending != nil {
+		select {
+		case r := <-d.pending:
+			d.stop()
+			if r.err != nil {
+				return d.penalize(g)
+			}
+			d.target = &r.move
+		default:
+			if d.now().After(d.deadline) {
+				d.stop()
+				return d.penalize(g)
+			}
+		}
+	}
+	if d.target == nil {
+		return frameInput{}
+	}
+	if d.steered++; d.steered > maxSteerFrames {
+		return d.penalize(g)
+	}
+	in, ok := bot.Steer(g.Game, *d.target)
+	if !ok {
+		return d.penalize(g)
+	}
+	if in.HardDrop {
+		d.target = nil
+	}
+	return frameInput{shift: in.Shift, rotCW: in.RotCW, rotCCW: in.RotCCW, softDrop: in.SoftDrop, hardDrop: in.HardDrop}
+}
+
+func (d *botDriver) start(g *Game) {
+	ctx, cancel := context.WithTimeout(context.Background(), d.budget)
+	ch := make(chan botResult, 1)
+	d.piece = g.Pieces()
+	d.pending = ch
+	d.cancel = cancel
+	d.deadline = d.now().Add(d.budget)
+	s := bot.StateOf(g.Game)
+	go func() {
+		start := time.Now()
+		m, err := d.bot.Decide(ctx, s)
+		botDecisionSeconds.Observe(time.Since(start).Seconds())
+		ch <- botResult{m, err}
+	}()
+}
+
+// stop cancels any in-flight decision.
+func (d *botDriver) stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.pending = nil
+	d.cancel = nil
+}
+
+func (d *botDriver) penalize(g *Game) frameInput {
+	slog.Debug("bot over budget", "piece", d.piece, "budget", d.budget, "policy", d.overBudget)
+	d.target = nil
+	if d.overBudget == Forfeit {
+		g.End("bot forfeit")
+		return frameInput{}
+	}
+	return frameInput{hardDrop: true}
+}
@@ -0,0 +1,84 @@
+// Package bot is what a bot sees of a game and what it answers, with the
+// built-in bots. It doesn't import Ebitengine, so headless tools such as
+// cmd/sim can run them.
+package bot
+
+import (
+	"context"
+
+	"tetris/engine"
+)
+
+// Move is a bot's chosen placement for the current piece: the rotation,
+// column and row it locks at, as one of the State's Placements.
+type Move struct {
+	Rot int
+	X   int
+	Y   int
+}
+
+// MoveTo is the move that locks p where it is.
+func MoveTo(p engine.Piece) Move {
+	return Move{Rot: p.Rot, X: p.X, Y: p.Y}
+}
+
+// State is the read-only view of the game handed to a bot. It is a copy,
+// so a bot may keep working on it while the game keeps running.
+type State struct {
+	Board engine.Board
+	Width int
+	Cur   engine.Piece
+	Next  int
+	// NoRotation is set when the mode won't turn pieces, so only Cur.Rot
+	// can be reached.
+	NoRotation bool
+	// Pieces is the game's piece set, nil for the standard one.
+	Pieces *engine.PieceSet
+}
+
+// StateOf is g's first player's view.
+func StateOf(g *engine.Game) State {
+	s := g.Snapshot()
+	return State{
+		Board: s.Board, Width: s.Width, Cur: s.Players[0].Piece, Next: s.Next,
+		NoRotation: g.Mode().Rules.NoRotation, Pieces: g.PieceSet(),
+	}
+}
+
+// Placements are the spots the current piece can lock in, the engine's
+// own search run on the copied board.
+func (s *State) Placements() []engine.Placement {
+	return s.Board.Placements(s.Pieces, s.Cur, s.NoRotation)
+}
+
+// Bot picks a placement for the current piece. Decide must return promptly
+// once ctx is done; results arriving after the deadline are discarded.
+type Bot interface {
+	Decide(ctx context.Context, s State) (Move, error)
+}
+
+// Steer is the input that takes g's active piece one step along its way
+// to m, or the hard drop once the rest of the way is straight down. ok is
+// false if m can't be reached from where the piece is now.
+func Steer(g *engine.Game, m Move) (in engine.Input, ok bool) {
+	cur := g.Player(0).Piece
+	route, ok := g.Route(engine.Piece{Kind: cur.Kind, Rot: m.Rot, X: m.X, Y: m.Y})
+	if !ok {
+		return engine.Input{}, false
+	}
+	for _, p := range route {
+		if p.Rot != cur.Rot || p.X != cur.X {
+			next := route[0]
+			switch {
+			case next.Rot == (cur.Rot+1)%4:
+				return engine.Input{RotCW: true}, true
+			case next.Rot != cur.Rot:
+				return engine.Input{RotCCW: true}, true
+			case next.X != cur.X:
+				return engine.Input{Shift: next.X - cur.X}, true
+			}
+			return engine.Input{SoftDrop: true}, true
+		}
+	}
+	return engine.Input{HardDrop: true}, true
+}
@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"context"
+	"math"
+
+	"tetris/engine"
+)
+
+// Weights score a board for Heuristic: each is multiplied by its measure
+// and the products summed.
+type Weights struct {
+	Height    float64 // summed column heights
+	Lines     float64 // lines the placement clears
+	Holes     float64 // empty cells with a block above
+	Bumpiness float64 // summed height steps between neighbouring columns
+}
+
+// DefaultWeights are the built-in CPU player's.
+var DefaultWeights = Weights{
+	Height:    -0.510066,
+	Lines:     0.760666,
+	Holes:     -0.35663,
+	Bumpiness: -0.184483,
+}
+
+// Heuristic places each piece where the board left behind scores best on
+// column height, holes, bumpiness and lines cleared. The zero value uses
+// DefaultWeights.
+type Heuristic struct {
+	Weights Weights
+}
+
+func (h Heuristic) Decide(ctx context.Context, s State) (Move, error) {
+	w := h.Weights
+	if w == (Weights{}) {
+		w = DefaultWeights
+	}
+	best, bestScore := MoveTo(s.Cur), math.Inf(-1)
+	for _, p := range s.Placements() {
+		if err := ctx.Err(); err != nil {
+			return best, err
+		}
+		if score := w.evaluate(p.Board, p.Lines); score > bestScore {
+			best, bestScore = MoveTo(p.Piece), score
+		}
+	}
+	return best, nil
+}
+
+// evaluate scores board b, left behind by a lock that cleared lines.
+func (w Weights) evaluate(b engine.Board, lines int) float64 {
+	height := 0
+	for _, h := range b.ColumnHeights() {
+		height += h
+	}
+	return w.Height*float64(height) + w.Lines*float64(lines) +
+		w.Holes*float64(b.Holes()) + w.Bumpiness*float64(b.Bumpiness())
+}
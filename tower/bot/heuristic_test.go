@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"tetris/engine"
+)
+
+func emptyState() State {
+	return State{Board: engine.NewBoard(engine.BoardW, engine.BoardH), Width: engine.BoardW}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name  string
+		board func(s *State)
+		p     engine.Piece
+		want  float64
+	}{
+		{
+			name: "flat I on the floor",
+			p:    engine.Piece{Kind: 0, Rot: 0, X: 0, Y: 18},
+			want: DefaultWeights.Height*4 + DefaultWeights.Bumpiness*1,
+		},
+		{
+			name:  "hole under an overhang",
+			board: func(s *State) { s.Board[18][0] = 1 },
+			p:     engine.Piece{Kind: 0, Rot: 1, X: 7, Y: 16},
+			want:  DefaultWeights.Height*6 + DefaultWeights.Holes*1 + DefaultWeights.Bumpiness*6,
+		},
+		{
+			name: "line clear empties the board",
+			board: func(s *State) {
+				for x := 4; x < engine.BoardW; x++ {
+					s.Board[19][x] = 1
+				}
+			},
+			p:    engine.Piece{Kind: 0, Rot: 0, X: 0, Y: 18},
+			want: DefaultWeights.Lines * 1,
+		},
+	}
+	for _, tt := range tests {
+		s := emptyState()
+		if tt.board != nil {
+			tt.board(&s)
+		}
+		b, lines := s.Board.Lock(tt.p.Cells(), tt.p.Kind+1)
+		if got := DefaultWeights.evaluate(b, lines); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: evaluate = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHeuristicBotTakesTheWell(t *testing.T) {
+	s := emptyState()
+	for y := 16; y < engine.BoardH; y++ {
+		for x := range engine.BoardW - 1 {
+			s.Board[y][x] = 1
+		}
+	}
+	s.Cur = engine.Piece{Kind: 0, X: 3}
+	m, err := Heuristic{}.Decide(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := engine.Piece{Kind: 0, Rot: m.Rot, X: m.X, Y: m.Y}
+	for _, c := range p.Cells() {
+		if c.X != engine.BoardW-1 || c.Y < 16 {
+			t.Fatalf("move %+v locks the I at %v, want it down the right-hand well", m, p.Cells())
+		}
+	}
+}
+
+func TestHeuristicBotTucks(t *testing.T) {
+	// Sliding under the overhang fills the cells it would cover.
+	s := emptyState()
+	for x := range engine.BoardW {
+		if x >= 7 {
+			s.Board[engine.BoardH-1][x] = 1
+		}
+		if x < 3 {
+			s.Board[engine.BoardH-2][x] = 1
+		}
+	}
+	s.Cur = engine.Piece{Kind: 0, X: 3}
+	m, err := Heuristic{}.Decide(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != (Move{Rot: 0, X: 0, Y: engine.BoardH - 2}) {
+		t.Errorf("move %+v, want the I tucked under the overhang", m)
+	}
+}
+
+func TestHeuristicBotNoRotation(t *testing.T) {
+	s := emptyState()
+	s.Cur = engine.Piece{Kind: 2, Rot: 2, X: 3}
+	s.NoRotation = true
+	m, err := Heuristic{}.Decide(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Rot != 2 {
+		t.Errorf("Rot = %d with rotation off, want the spawn rotation 2", m.Rot)
+	}
+}
+
+func TestHeuristicBotStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := (Heuristic{}).Decide(ctx, emptyState()); err == nil {
+		t.Error("Decide on a done context returned no error")
+	}
+}
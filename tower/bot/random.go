@@ -0,0 +1,21 @@
+package bot
+
+import (
+	"context"
+	"math/rand"
+)
+
+// Random places each piece at one of its placements picked by Rand, a
+// baseline to measure other bots against. Rand isn't safe for concurrent
+// use, so each Random should drive one game.
+type Random struct {
+	Rand *rand.Rand
+}
+
+func (r Random) Decide(ctx context.Context, s State) (Move, error) {
+	ps := s.Placements()
+	if len(ps) == 0 {
+		return MoveTo(s.Cur), nil
+	}
+	return MoveTo(ps[r.Rand.Intn(len(ps))].Piece), ctx.Err()
+}
@@ -0,0 +1,30 @@
+package bot
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"tetris/engine"
+)
+
+func TestRandomPlacesWithinReach(t *testing.T) {
+	s := emptyState()
+	s.Cur = engine.Piece{Kind: 2, X: 3}
+	r := Random{Rand: rand.New(rand.NewSource(1))}
+	seen := map[Move]bool{}
+	for range 50 {
+		m, err := r.Decide(context.Background(), s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.ContainsFunc(s.Placements(), func(p engine.Placement) bool { return MoveTo(p.Piece) == m }) {
+			t.Fatalf("move %+v isn't a placement", m)
+		}
+		seen[m] = true
+	}
+	if len(seen) < 10 {
+		t.Errorf("%d different moves in 50, want them spread around", len(seen))
+	}
+}
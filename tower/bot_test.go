@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tetris/bot"
+)
+
+// gatedBot holds its first decision until release is closed, ignoring
+// the deadline, and answers later ones at once with late.
+type gatedBot struct {
+	release     chan struct{}
+	first, late bot.Move
+	calls       atomic.Int32
+}
+
+func (b *gatedBot) Decide(ctx context.Context, s bot.State) (bot.Move, error) {
+	if b.calls.Add(1) == 1 {
+		<-b.release
+		return dropped(s, b.first), nil
+	}
+	return dropped(s, b.late), nil
+}
+
+// overBudget runs a driver against a bot that doesn't answer its first
+// decision, moving the driver's clock past the budget once the decision
+// has started, until the driver gives its first non-empty input.
+func overBudget(t *testing.T, ob OverBudget) (*Game, *botDriver, *gatedBot, frameInput) {
+	t.Helper()
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.offline = true
+	b := &gatedBot{release: make(chan struct{}), late: bot.Move{X: 6}}
+	d := newBotDriver(b, time.Second, ob)
+	clock := time.Unix(0, 0)
+	d.now = func() time.Time { return clock }
+	for range 100 {
+		in := d.input(g)
+		if in != (frameInput{}) || g.GameOver() {
+			return g, d, b, in
+		}
+		if d.pending != nil {
+			clock = clock.Add(2 * time.Second)
+		}
+		g.stepPlayers(in)
+	}
+	t.Fatal("the driver never acted on the missed deadline")
+	return nil, nil, nil, frameInput{}
+}
+
+func TestOverBudgetFallbackDrops(t *testing.T) {
+	g, d, b, in := overBudget(t, FallbackMove)
+	defer close(b.release)
+	if !in.hardDrop {
+		t.Errorf("input = %+v, want a hard drop", in)
+	}
+	if g.GameOver() || d.pending != nil || d.target != nil {
+		t.Errorf("game over %v, pending %v, target %v; want the decision dropped and play going on", g.GameOver(), d.pending != nil, d.target)
+	}
+}
+
+func TestOverBudgetForfeitEndsTheGame(t *testing.T) {
+	g, _, b, _ := overBudget(t, Forfeit)
+	defer close(b.release)
+	if !g.GameOver() {
+		t.Error("a forfeit left the game running")
+	}
+}
+
+func TestLateResultIsDiscarded(t *testing.T) {
+	g, d, b, in := overBudget(t, FallbackMove)
+	first := g.Pieces()
+	close(b.release) // the first decision comes in now, too late
+	g.stepPlayers(in)
+	for g.Pieces() == first || g.Player(0).Spawning {
+		g.stepPlayers(d.input(g))
+	}
+	ins := steer(t, g, d)
+	if x := g.Player(0).Piece.X; x != 6 {
+		t.Errorf("second piece dropped at x %d after %d frames, want 6 from its own decision", x, len(ins))
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"image"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	// clipStep is the ticks between a clip's frames, 20 a second.
+	clipStep = 3
+	// clipFrames is how many frames a clip keeps: its last ten seconds.
+	clipFrames = 10 * 60 / clipStep
+	// clipScale shrinks clip frames, so ten seconds fit in memory.
+	clipScale = 2
+	// captureNoteFrames is how long "Saved ..." stays up.
+	captureNoteFrames = 2 * 60
+)
+
+// capture is F12's screenshot and the clip recorder.
+type capture struct {
+	shot       bool          // F12 was pressed; the next Draw saves the screen
+	clip       *clipRecorder // while Settings.RecordClips is on
+	note       string        // where the last capture went
+	noteFrames int
+}
+
+// captureDone carries notes back from clips encoding in the background.
+var captureDone = make(chan string, 4)
+
+// clipRecorder keeps the last clipFrames frames of a run, shrunk and
+// reduced to the web-safe palette as they're captured, in a ring.
+type clipRecorder struct {
+	frames []*image.Paletted
+	next   int // the oldest frame, once the ring is full
+	ticks  int // since the last frame
+	small  *ebiten.Image
+	pix    []byte
+}
+
+// push adds f, dropping the oldest frame once clipFrames are kept.
+func (c *clipRecorder) push(f *image.Paletted) {
+	if len(c.frames) < clipFrames {
+		c.frames = append(c.frames, f)
+		return
+	}
+	c.frames[c.next] = f
+	c.next = (c.next + 1) % clipFrames
+}
+
+// ordered is the frames oldest first.
+func (c *clipRecorder) ordered() []*image.Paletted {
+	return append(append([]*image.Paletted(nil), c.frames[c.next:]...), c.frames[:c.next]...)
+}
+
+// grab shrinks screen into a new frame.
+func (c *clipRecorder) grab(screen *ebiten.Image) {
+	w, h := screen.Bounds().Dx()/clipScale, screen.Bounds().Dy()/clipScale
+	if c.small == nil || c.small.Bounds().Dx() != w || c.small.Bounds().Dy() != h {
+		// The window changed size; a clip's frames all share one.
+		*c = clipRecorder{small: ebiten.NewImage(w, h), pix: make([]byte, 4*w*h)}
+	}
+	op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	op.GeoM.Scale(1.0/clipScale, 1.0/clipScale)
+	c.small.Clear()
+	c.small.DrawImage(screen, op)
+	c.small.ReadPixels(c.pix)
+	c.push(webSafe(c.pix, w, h))
+}
+
+// webSafe maps RGBA pixels to the nearest of palette.WebSafe's 6x6x6
+// cube, directly rather than by searching the palette.
+func webSafe(pix []byte, w, h int) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, w, h), palette.WebSafe)
+	for i := range img.Pix {
+		r, g, b := int(pix[4*i]), int(pix[4*i+1]), int(pix[4*i+2])
+		img.Pix[i] = uint8((r+25)/51*36 + (g+25)/51*6 + (b+25)/51)
+	}
+	return img
+}
+
+// encodeClip writes frames as a looping GIF.
+func encodeClip(w io.Writer, frames []*image.Paletted) error {
+	anim := &gif.GIF{Image: frames, Delay: make([]int, len(frames))}
+	for i := range anim.Delay {
+		anim.Delay[i] = clipStep * 100 / 60
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// updateCapture takes F12 for a screenshot, whatever screen is up, and
+// times the clip recorder's frames during play.
+func (g *Game) updateCapture() {
+	c := &g.capture
+	if inpututil.IsKeyJustPressed(ebiten.KeyF12) {
+		c.shot = true
+	}
+	select {
+	case c.note = <-captureDone:
+		c.noteFrames = captureNoteFrames
+	default:
+		if c.noteFrames > 0 {
+			c.noteFrames--
+		}
+	}
+	switch {
+	case !g.settings.RecordClips:
+		c.clip = nil
+	case g.state == statePlaying && g.countdown == 0:
+		if c.clip == nil {
+			c.clip = &clipRecorder{}
+		}
+		c.clip.ticks++
+	}
+}
+
+// drawCapture saves the screen if F12 asked for it and grabs a clip frame
+// when one is due. It runs once the scene is drawn, before any overlay
+// that shouldn't be in the picture.
+func (g *Game) drawCapture(screen *ebiten.Image) {
+	c := &g.capture
+	if c.shot {
+		c.shot = false
+		path, err := saveScreenshot(screen)
+		c.note, c.noteFrames = captureNote(path, err, "Screenshot"), captureNoteFrames
+	}
+	if c.clip != nil && c.clip.ticks >= clipStep {
+		c.clip.ticks = 0
+		c.clip.grab(screen)
+	}
+	if c.noteFrames > 0 {
+		g.drawText(screen, c.note, 8, 14, g.palette().Info)
+	}
+}
+
+// captureNote says where a capture went, or that it failed.
+func captureNote(path string, err error, what string) string {
+	if err != nil {
+		slog.Error("capture save failed", "what", what, "err", err)
+		return trf("%s failed to save", tr(what))
+	}
+	slog.Info("capture saved", "what", what, "path", path)
+	return trf("Saved %s", filepath.Base(path))
+}
+
+// saveClip writes the run's last seconds as a GIF in the background, so
+// the game over screen doesn't wait on the encoder.
+func (g *Game) saveClip() {
+	c := g.capture.clip
+	g.capture.clip = nil
+	if c == nil || len(c.frames) == 0 {
+		return
+	}
+	frames, mode := c.ordered(), g.Mode().Name
+	go func() {
+		path, err := createCapture("clips", autoReplayName(mode)+".gif", func(w io.Writer) error {
+			return encodeClip(w, frames)
+		})
+		captureDone <- captureNote(path, err, "Clip")
+	}()
+}
+
+// saveScreenshot writes screen as a PNG and returns its path.
+func saveScreenshot(screen *ebiten.Image) (string, error) {
+	b := screen.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	screen.ReadPixels(img.Pix)
+	name := time.Now().Format("2006-01-02_150405.000") + ".png"
+	return createCapture("screenshots", name, func(w io.Writer) error { return png.Encode(w, img) })
+}
+
+// createCapture writes a file with write to dir in the config directory,
+// as saveResultCard does, and returns its path.
+func createCapture(dir, name string, write func(io.Writer) error) (string, error) {
+	base, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(base, dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"testing"
+)
+
+func TestWebSafeMatchesPalette(t *testing.T) {
+	for _, c := range []color.RGBA{{0, 0, 0, 255}, {255, 255, 255, 255}, {250, 30, 120, 255}, {26, 25, 76, 255}} {
+		got := webSafe([]byte{c.R, c.G, c.B, c.A}, 1, 1).Pix[0]
+		if want := uint8(color.Palette(palette.WebSafe).Index(c)); got != want {
+			t.Errorf("%v maps to %v, want the nearest, %v", c, palette.WebSafe[got], palette.WebSafe[want])
+		}
+	}
+}
+
+func TestClipKeepsTheLastFrames(t *testing.T) {
+	var c clipRecorder
+	for i := range clipFrames + 5 {
+		f := image.NewPaletted(image.Rect(0, 0, 2, 2), palette.WebSafe)
+		f.Pix[0] = uint8(i)
+		c.push(f)
+	}
+	frames := c.ordered()
+	if len(frames) != clipFrames || frames[0].Pix[0] != 5 || frames[len(frames)-1].Pix[0] != clipFrames+4 {
+		t.Fatalf("kept %d frames from %d to %d, want the last %d", len(frames), frames[0].Pix[0], frames[len(frames)-1].Pix[0], clipFrames)
+	}
+
+	var buf bytes.Buffer
+	if err := encodeClip(&buf, frames); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != clipFrames || anim.Delay[0] != 5 {
+		t.Errorf("the GIF has %d frames %d/100 s apart, want %d at 20 a second", len(anim.Image), anim.Delay[0], clipFrames)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	cardW, cardH = 640, 360
+	cardTile     = 14
+)
+
+var cardFaces = sync.OnceValues(func() (map[string]font.Face, error) {
+	faces := map[string]font.Face{}
+	for _, f := range []struct {
+		name string
+		ttf  []byte
+		size float64
+	}{
+		{"title", gobold.TTF, 28},
+		{"score", gobold.TTF, 56},
+		{"label", goregular.TTF, 14},
+		{"value", gobold.TTF, 22},
+	} {
+		parsed, err := opentype.Parse(f.ttf)
+		if err != nil {
+			return nil, err
+		}
+		face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: f.size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, err
+		}
+		faces[f.name] = face
+	}
+	return faces, nil
+})
+
+// resultCard draws the finished run for sharing: mode, score, stats, the
+// date and final board, and the profile name if one is set.
+func (g *Game) resultCard() (*image.RGBA, error) {
+	faces, err := cardFaces()
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, cardW, cardH))
+	top, bottom := color.RGBA{24, 28, 56, 255}, color.RGBA{70, 30, 90, 255}
+	for y := range cardH {
+		t := float64(y) / cardH
+		c := color.RGBA{lerp8(top.R, bottom.R, t), lerp8(top.G, bottom.G, t), lerp8(top.B, bottom.B, t), 255}
+		draw.Draw(img, image.Rect(0, y, cardW, y+1), image.NewUniform(c), image.Point{}, draw.Src)
+	}
+	draw.Draw(img, image.Rect(0, 0, 8, cardH), image.NewUniform(color.RGBA{255, 120, 80, 255}), image.Point{}, draw.Src)
+
+	text := func(face string, s string, x, y int, c color.Color) {
+		d := font.Drawer{Dst: img, Src: image.NewUniform(c), Face: faces[face], Dot: fixed.P(x, y)}
+		d.DrawString(s)
+	}
+	dim := color.RGBA{190, 190, 220, 255}
+	snap := g.Snapshot()
+	mode := g.Mode().Name
+	if f := g.scoreFlags(); len(f) > 0 {
+		mode += " [" + strings.Join(f, ", ") + "]"
+	}
+	text("title", mode, 32, 52, color.White)
+	text("label", "SCORE", 32, 96, dim)
+	text("score", fmt.Sprint(g.TotalScore()), 32, 152, color.White)
+
+	secs := snap.Frames / 60
+	pps := 0.0
+	if secs > 0 {
+		pps = float64(snap.Pieces) / float64(snap.Frames) * 60
+	}
+	stats := [][2]string{
+		{"LINES", fmt.Sprint(snap.Lines)},
+		{"LEVEL", fmt.Sprint(snap.Level)},
+		{"TIME", fmt.Sprintf("%d:%02d", secs/60, secs%60)},
+		{"PIECES/S", fmt.Sprintf("%.2f", pps)},
+	}
+	for i, st := range stats {
+		x, y := 32+(i%2)*170, 200+(i/2)*60
+		text("label", st[0], x, y, dim)
+		text("value", st[1], x, y+26, color.White)
+	}
+	footer := time.Now().Format("2006-01-02")
+	if profile.Name != "" {
+		footer = profile.Name + "  ·  " + footer
+	}
+	text("label", footer, 32, cardH-24, dim)
+
+	// Tall boards shrink to fit the card.
+	rows := snap.Board.Height()
+	tile := min(cardTile, (cardH-48)/rows)
+	bx := cardW - 32 - snap.Width*tile
+	by := (cardH - rows*tile) / 2
+	draw.Draw(img, image.Rect(bx-2, by-2, bx+snap.Width*tile+2, by+rows*tile+2), image.NewUniform(color.RGBA{0, 0, 0, 120}), image.Point{}, draw.Over)
+	for y := range rows {
+		for x := range snap.Width {
+			if v := snap.Board[y][x]; v != 0 {
+				r := image.Rect(bx+x*tile+1, by+y*tile+1, bx+(x+1)*tile, by+(y+1)*tile)
+				draw.Draw(img, r, image.NewUniform(g.palette().Pieces[v-1]), image.Point{}, draw.Src)
+			}
+		}
+	}
+	return img, nil
+}
+
+func lerp8(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t)
+}
+
+// exportResultCard saves the card and notes where for the game over screen.
+func (g *Game) exportResultCard() {
+	path, err := g.saveResultCard()
+	if err != nil {
+		slog.Error("result card save failed", "err", err)
+		g.cardNote = "Result card failed to save"
+		return
+	}
+	slog.Info("result card saved", "path", path)
+	g.cardNote = "Saved " + filepath.Base(path)
+}
+
+// saveResultCard writes the card to cards/ in the config directory and
+// returns its path.
+func (g *Game) saveResultCard() (string, error) {
+	img, err := g.resultCard()
+	if err != nil {
+		return "", err
+	}
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "cards")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, autoReplayName(g.Mode().Name)+".png")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
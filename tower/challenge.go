@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"image/color"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"tetris/engine"
+	"tetris/qr"
+)
+
+// challengeScheme prefixes challenge links: a mode, modifiers and seed
+// that start the same game anywhere.
+const challengeScheme = "tower://challenge"
+
+const qrModulePx = 3
+
+func (g *Game) challengeURL() string {
+	v := url.Values{"mode": {g.Mode().Name}, "seed": {strconv.FormatInt(g.seed, 10)}}
+	if f := g.modifiers.flags(); len(f) > 0 {
+		v.Set("mods", strings.Join(f, ","))
+	}
+	return challengeScheme + "?" + v.Encode()
+}
+
+// parseChallenge reads a link made by challengeURL.
+func parseChallenge(s string) (engine.Mode, Modifiers, int64, error) {
+	rest, ok := strings.CutPrefix(s, challengeScheme+"?")
+	if !ok {
+		return engine.Mode{}, Modifiers{}, 0, errors.New("not a challenge link")
+	}
+	v, err := url.ParseQuery(rest)
+	if err != nil {
+		return engine.Mode{}, Modifiers{}, 0, err
+	}
+	seed, err := strconv.ParseInt(v.Get("seed"), 10, 64)
+	if err != nil {
+		return engine.Mode{}, Modifiers{}, 0, err
+	}
+	var mods Modifiers
+	if f := v.Get("mods"); f != "" {
+		mods = modifiersFromFlags(strings.Split(f, ","))
+	}
+	return modeByName(v.Get("mode")), mods, seed, nil
+}
+
+// challengeQR renders the challenge link as a QR code with its quiet zone,
+// made once per finished run.
+func (g *Game) challengeQR() *ebiten.Image {
+	if g.qr != nil {
+		return g.qr
+	}
+	code, err := qr.Encode([]byte(g.challengeURL()))
+	if err != nil {
+		return nil
+	}
+	n := code.Size + 8
+	img := ebiten.NewImage(n*qrModulePx, n*qrModulePx)
+	img.Fill(color.White)
+	dark := ebiten.NewImage(qrModulePx, qrModulePx)
+	dark.Fill(color.Black)
+	for y := range code.Size {
+		for x := range code.Size {
+			if code.Black(x, y) {
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(float64((x+4)*qrModulePx), float64((y+4)*qrModulePx))
+				img.DrawImage(dark, op)
+			}
+		}
+	}
+	g.qr = img
+	return img
+}
+
+// drawChallengeQR centres the QR code above the game over text.
+func (g *Game) drawChallengeQR(screen *ebiten.Image, bottom float32) {
+	img := g.challengeQR()
+	if img == nil {
+		return
+	}
+	w := screen.Bounds().Dx()
+	s := img.Bounds().Dx()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(w-s)/2, float64(bottom)-float64(s))
+	screen.DrawImage(img, op)
+	g.drawCentered(screen, tr("Scan to play this seed"), float32(w)/2, bottom-float32(s)-6, color.White)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"tetris/engine"
+)
+
+func TestCheeseHeight(t *testing.T) {
+	m := modeByName("Cheese")
+	mods := Modifiers{CheeseRows: 4}
+	if got := modifiersFromFlags(mods.flags()); got != mods {
+		t.Errorf("round trip = %+v, want %+v", got, mods)
+	}
+	if got := mods.apply(m).Cheese; got != 4 {
+		t.Errorf("Cheese height %d, want 4", got)
+	}
+	if got := (Modifiers{}).apply(m).Cheese; got != m.Cheese {
+		t.Errorf("default Cheese height %d, want the mode's %d", got, m.Cheese)
+	}
+	if got := (Modifiers{CheeseRows: 99}).apply(m).Cheese; got != maxCheeseRows {
+		t.Errorf("Cheese height %d, want it capped at %d", got, maxCheeseRows)
+	}
+	if got := mods.apply(modeByName("Marathon")).Cheese; got != 0 {
+		t.Errorf("a height turned Marathon into Cheese with %d rows", got)
+	}
+
+	g := newGameSeeded(1, m, mods)
+	rows := 0
+	board := g.Board()
+	for y := range engine.BoardH {
+		for x := range g.Width() {
+			if board[y][x] == engine.GarbageCell {
+				rows++
+				break
+			}
+		}
+	}
+	if rows != 4 {
+		t.Errorf("the game started with %d garbage rows, want 4", rows)
+	}
+}
@@ -0,0 +1,80 @@
+// Command render-replay turns a recorded input log into a video.
+//
+// It runs the game with -render-replay, which simulates the log through the
+// normal renderer and streams raw frames, and pipes them into ffmpeg:
+//
+//	render-replay -game ./tetris -o run.mp4 run.json
+//
+// The container and codec follow the output extension: .webm uses VP9,
+// anything else H.264.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Frame size and rate of the game's renderer.
+const (
+	frameW = 480
+	frameH = 640
+	fps    = 60
+)
+
+func main() {
+	game := flag.String("game", "tetris", "path to the game binary")
+	out := flag.String("o", "replay.mp4", "output video (.mp4 or .webm)")
+	ffmpeg := flag.String("ffmpeg", "ffmpeg", "path to ffmpeg")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: render-replay [flags] replay.json")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*game, *ffmpeg, flag.Arg(0), *out); err != nil {
+		fmt.Fprintln(os.Stderr, "render-replay:", err)
+		os.Exit(1)
+	}
+}
+
+func run(game, ffmpeg, replay, out string) error {
+	codec := []string{"-c:v", "libx264", "-pix_fmt", "yuv420p", "-movflags", "+faststart"}
+	if strings.EqualFold(filepath.Ext(out), ".webm") {
+		codec = []string{"-c:v", "libvpx-vp9", "-pix_fmt", "yuv420p", "-b:v", "0", "-crf", "32"}
+	}
+	args := append([]string{
+		"-y", "-loglevel", "error",
+		"-f", "rawvideo", "-pix_fmt", "rgba",
+		"-s", fmt.Sprintf("%dx%d", frameW, frameH), "-r", fmt.Sprint(fps),
+		"-i", "-",
+	}, codec...)
+	enc := exec.Command(ffmpeg, append(args, out)...)
+	enc.Stdout, enc.Stderr = os.Stdout, os.Stderr
+
+	src := exec.Command(game, "-render-replay", replay)
+	src.Stderr = os.Stderr
+	pipe, err := src.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	enc.Stdin = pipe
+	if err := enc.Start(); err != nil {
+		return fmt.Errorf("starting ffmpeg: %w", err)
+	}
+	if err := src.Run(); err != nil {
+		enc.Process.Kill()
+		enc.Wait()
+		return fmt.Errorf("rendering: %w", err)
+	}
+	if err := enc.Wait(); err != nil {
+		return fmt.Errorf("encoding: %w", err)
+	}
+	return nil
+}
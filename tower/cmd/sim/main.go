@@ -0,0 +1,187 @@
+// Command sim plays Marathon games with a bot and no window, and prints how
+// they went. It is for tuning bot weights and catching rules regressions
+// without a CI:
+//
+//	sim -games 100 -policy heuristic
+//	sim -policy heuristic -weights -0.5,0.76,-0.36,-0.18
+//	sim -randomizer classic
+//
+// Game i is dealt from seed+i, so a run repeats exactly.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"tetris/bot"
+	"tetris/engine"
+)
+
+// maxSteerFrames matches the game's CPU driver: a placement not reached
+// by then is dropped where the piece is.
+const maxSteerFrames = 60
+
+// marathon is the mode played, dealt by the -randomizer flag's randomizer.
+var marathon = engine.Mode{Name: "Marathon", Width: engine.BoardW}
+
+// result is one finished (or cut off) game.
+type result struct {
+	seed      int64
+	score     int
+	lines     int
+	pps       float64
+	toppedOut bool
+}
+
+func main() {
+	games := flag.Int("games", 10, "games to play")
+	policy := flag.String("policy", "heuristic", "bot to play: random or heuristic")
+	weights := flag.String("weights", "", "heuristic weights as height,lines,holes,bumpiness; empty for the CPU player's")
+	seed := flag.Int64("seed", 1, "seed of the first game")
+	minutes := flag.Float64("minutes", 10, "cut each game off after this much play")
+	verbose := flag.Bool("v", false, "print every game, not just the totals")
+	randomizer := flag.String("randomizer", "7-bag", "randomizer dealing the pieces: 7-bag, classic or tgm")
+	flag.Parse()
+	// The engine logs every game over; only the totals matter here.
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	newBot, err := policyFor(*policy, *weights)
+	if err == nil {
+		marathon.Rules.Randomizer, err = randomizerFor(*randomizer)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sim:", err)
+		os.Exit(2)
+	}
+	var results []result
+	for i := range *games {
+		r := play(*seed+int64(i), newBot(*seed+int64(i)), int(*minutes*60*60))
+		if *verbose {
+			end := ""
+			if r.toppedOut {
+				end = "  topped out"
+			}
+			fmt.Printf("seed %-6d score %-8d lines %-5d pps %.2f%s\n", r.seed, r.score, r.lines, r.pps, end)
+		}
+		results = append(results, r)
+	}
+	summarize(results)
+}
+
+// policyFor makes a bot for each game, by name.
+func policyFor(name, weights string) (func(seed int64) bot.Bot, error) {
+	switch name {
+	case "random":
+		return func(seed int64) bot.Bot { return bot.Random{Rand: rand.New(rand.NewSource(seed))} }, nil
+	case "heuristic":
+		h := bot.Heuristic{}
+		if weights != "" {
+			w, err := parseWeights(weights)
+			if err != nil {
+				return nil, err
+			}
+			h.Weights = w
+		}
+		return func(int64) bot.Bot { return h }, nil
+	}
+	return nil, fmt.Errorf("unknown policy %q, want random or heuristic", name)
+}
+
+// randomizerFor finds a randomizer by name, in any case.
+func randomizerFor(name string) (engine.RandomizerKind, error) {
+	for _, k := range engine.Randomizers {
+		if strings.EqualFold(k.String(), name) {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown randomizer %q, want 7-bag, classic or tgm", name)
+}
+
+func parseWeights(s string) (bot.Weights, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return bot.Weights{}, fmt.Errorf("weights %q: want four, height,lines,holes,bumpiness", s)
+	}
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return bot.Weights{}, fmt.Errorf("weights: %w", err)
+		}
+		v[i] = f
+	}
+	return bot.Weights{Height: v[0], Lines: v[1], Holes: v[2], Bumpiness: v[3]}, nil
+}
+
+// play runs one game with b deciding each piece, steering to its move a
+// step a frame as a player would, for at most frames frames.
+func play(seed int64, b bot.Bot, frames int) result {
+	g := engine.New(seed, marathon)
+	piece := -1
+	var target *bot.Move
+	steered := 0
+	for range frames {
+		if g.GameOver() {
+			break
+		}
+		p := g.Player(0)
+		if p.Spawning {
+			g.Step(engine.Input{})
+			continue
+		}
+		if piece != g.Pieces() {
+			piece, steered = g.Pieces(), 0
+			m, err := b.Decide(context.Background(), bot.StateOf(g))
+			target = &m
+			if err != nil {
+				target = nil
+			}
+		}
+		g.Step(steer(g, target, &steered))
+	}
+	s := g.Stats()
+	return result{seed: seed, score: g.Score(), lines: g.Lines(), pps: s.PPS(), toppedOut: g.GameOver()}
+}
+
+// steer is the input that brings the piece one step closer to target, and
+// the hard drop once it is straight below or steering has gone on too
+// long.
+func steer(g *engine.Game, target *bot.Move, steered *int) engine.Input {
+	if *steered++; target == nil || *steered > maxSteerFrames {
+		return engine.Input{HardDrop: true}
+	}
+	in, ok := bot.Steer(g, *target)
+	if !ok {
+		return engine.Input{HardDrop: true}
+	}
+	return in
+}
+
+func summarize(results []result) {
+	if len(results) == 0 {
+		return
+	}
+	var score, lines, pps float64
+	minScore, maxScore := results[0].score, results[0].score
+	toppedOut := 0
+	for _, r := range results {
+		score += float64(r.score)
+		lines += float64(r.lines)
+		pps += r.pps
+		minScore, maxScore = min(minScore, r.score), max(maxScore, r.score)
+		if r.toppedOut {
+			toppedOut++
+		}
+	}
+	n := float64(len(results))
+	fmt.Printf("games %d, topped out %d\n", len(results), toppedOut)
+	fmt.Printf("score mean %.0f (min %d, max %d)\n", score/n, minScore, maxScore)
+	fmt.Printf("lines mean %.1f\n", lines/n)
+	fmt.Printf("pps   mean %.2f\n", pps/n)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+
+	"tetris/engine"
+)
+
+func TestPlay(t *testing.T) {
+	h, err := policyFor("heuristic", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := play(1, h(1), 60*60)
+	if r.toppedOut || r.lines < 20 || r.pps <= 0 {
+		t.Errorf("a minute of heuristic play: %+v, want 20+ lines without topping out", r)
+	}
+	if again := play(1, h(1), 60*60); again != r {
+		t.Errorf("the same seed played %+v, then %+v", r, again)
+	}
+
+	rnd, _ := policyFor("random", "")
+	if r := play(1, rnd(1), 60*60); !r.toppedOut {
+		t.Errorf("random play lasted the minute: %+v", r)
+	}
+}
+
+func TestPolicyFlags(t *testing.T) {
+	if _, err := policyFor("greedy", ""); err == nil {
+		t.Error("an unknown policy was accepted")
+	}
+	for _, bad := range []string{"1,2,3", "1,2,x,4"} {
+		if _, err := policyFor("heuristic", bad); err == nil {
+			t.Errorf("weights %q accepted", bad)
+		}
+	}
+	if k, err := randomizerFor("TGM"); err != nil || k != engine.RandomizerTGM {
+		t.Errorf("randomizer TGM is %v, %v", k, err)
+	}
+	if _, err := randomizerFor("bag8"); err == nil {
+		t.Error("an unknown randomizer was accepted")
+	}
+	w, err := parseWeights("-1, 2, -3, 0.5")
+	if err != nil || w.Height != -1 || w.Lines != 2 || w.Holes != -3 || w.Bumpiness != 0.5 {
+		t.Errorf("parsed %+v, %v", w, err)
+	}
+}
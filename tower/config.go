@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+)
+
+func settingsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "settings.json"), nil
+}
+
+// settingsMigrations upgrade older settings files; see loadSave.
+var settingsMigrations = []saveMigration{
+	// 1: Theme (an index into the built-in themes) became ThemeName.
+	func(doc map[string]any) error {
+		if i, ok := doc["Theme"].(float64); ok && int(i) >= 0 && int(i) < len(builtinThemes) {
+			doc["ThemeName"] = builtinThemes[int(i)].Name
+		}
+		delete(doc, "Theme")
+		return nil
+	},
+	// 2: the Modern theme was renamed Dark.
+	func(doc map[string]any) error {
+		if doc["ThemeName"] == "Modern" {
+			doc["ThemeName"] = "Dark"
+		}
+		return nil
+	},
+}
+
+// settingsReadOnly is set when the settings file can't be safely rewritten,
+// because it came from a newer build or could not be parsed.
+var settingsReadOnly bool
+
+// loadSettings reads the saved settings over the defaults, so options added
+// since the file was written keep their default values.
+func loadSettings() Settings {
+	s := DefaultSettings()
+	path, err := settingsPath()
+	if err != nil {
+		return s
+	}
+	if err := loadSave(path, &s, settingsMigrations); err != nil {
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case errors.As(err, new(errSaveTooNew)):
+			slog.Warn("settings from a newer version, not saving changes", "err", err)
+			settingsReadOnly = true
+		default:
+			slog.Warn("settings file unreadable, using defaults and leaving it untouched", "path", path, "err", err)
+			settingsReadOnly = true
+		}
+		return DefaultSettings()
+	}
+	if s.Gestures == nil {
+		s.Gestures = defaultGestureMap()
+	}
+	if s.KeyPreset == CustomKeys && s.Keys == nil {
+		s.KeyPreset = StandardKeys
+	}
+	slog.Debug("settings loaded", "path", path)
+	return s
+}
+
+func saveSettings(s Settings) error {
+	if settingsReadOnly {
+		slog.Debug("settings not saved: file is read-only this session")
+		return nil
+	}
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	s.Version = len(settingsMigrations)
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeSave(path, b); err != nil {
+		return err
+	}
+	slog.Debug("settings saved", "path", path)
+	return nil
+}
+
+func logPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "logs", "tower.log"), nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"tetris/bot"
+	"tetris/engine"
+)
+
+// newCPU is the built-in bot, driven at the default budget.
+func newCPU() *botDriver {
+	return newBotDriver(bot.Heuristic{}, 0, FallbackMove)
+}
+
+// demoable reports whether the CPU can play all of m: co-op's two pieces
+// share a board, which the CPU doesn't play.
+func demoable(m engine.Mode) bool {
+	return !m.Coop
+}
+
+// startDemo begins a game of m with the CPU playing it, both boards in
+// versus.
+func (g *Game) startDemo(m engine.Mode) {
+	if !demoable(m) {
+		return
+	}
+	g.startMode(m, Modifiers{})
+	g.cpu, g.cpuPlayed = newCPU(), true
+	if m.Versus {
+		g.rivalCPU = newCPU()
+	}
+	g.sources = g.playerSources()
+}
+
+// startVersusCPU begins a versus game against the CPU.
+func (g *Game) startVersusCPU() {
+	g.startMode(modeByName("Versus"), Modifiers{})
+	g.rivalCPU = newCPU()
+	g.sources = g.playerSources()
+}
+
+// toggleCPU hands the run to the CPU from the current piece on, or takes
+// it back. In versus the first player's board is taken back first, and
+// otherwise it's the rival the CPU takes over or leaves to a second
+// player. Co-op has two pieces on one board to play, so it stays with the
+// players.
+func (g *Game) toggleCPU() {
+	switch {
+	case g.cpu != nil:
+		g.cpu.stop()
+		g.cpu = nil
+	case g.Mode().Versus && g.rivalCPU != nil:
+		g.rivalCPU.stop()
+		g.rivalCPU = nil
+	case g.Mode().Versus:
+		g.rivalCPU = newCPU()
+	case !g.Mode().Coop:
+		g.cpu = newCPU()
+		g.cpuPlayed = true
+	}
+	g.sources = g.playerSources()
+}
+
+// updateCPUToggle switches CPU control with F3.
+func (g *Game) updateCPUToggle() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		g.toggleCPU()
+	}
+}
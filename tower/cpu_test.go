@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tetris/bot"
+)
+
+// fixedBot always asks for the same rotation and column, hard dropped.
+type fixedBot struct{ m bot.Move }
+
+func (b fixedBot) Decide(ctx context.Context, s bot.State) (bot.Move, error) {
+	return dropped(s, b.m), nil
+}
+
+// dropped is m at the row a hard drop from its rotation and column lands
+// on, or m itself if it isn't a placement.
+func dropped(s bot.State, m bot.Move) bot.Move {
+	for _, p := range s.Placements() {
+		if p.Piece.Rot == m.Rot && p.Piece.X == m.X {
+			return bot.MoveTo(p.Piece)
+		}
+	}
+	return m
+}
+
+// steer runs d against g until it hard drops, returning the inputs it gave.
+// d's clock stands still, so every decision is waited for however long it
+// takes.
+func steer(t *testing.T, g *Game, d *botDriver) []frameInput {
+	t.Helper()
+	d.now = func() time.Time { return time.Unix(0, 0) }
+	var ins []frameInput
+	for range 300 {
+		in := d.input(g)
+		ins = append(ins, in)
+		if in.hardDrop {
+			return ins
+		}
+		for d.pending != nil && len(d.pending) == 0 {
+			time.Sleep(time.Millisecond) // let the decision come in
+		}
+		g.stepPlayers(in)
+	}
+	t.Fatal("the driver never dropped the piece")
+	return nil
+}
+
+func TestBotDriverSteers(t *testing.T) {
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.offline = true
+	d := newBotDriver(fixedBot{bot.Move{Rot: 3, X: 0}}, time.Second, FallbackMove)
+	ins := steer(t, g, d)
+	if p := g.Player(0).Piece; p.Rot != 3 || p.X != 0 {
+		t.Fatalf("dropped at rot %d x %d, want rot 3 x 0", p.Rot, p.X)
+	}
+	var ccw, cw, left int
+	for _, in := range ins {
+		switch {
+		case in.rotCCW:
+			ccw++
+		case in.rotCW:
+			cw++
+		case in.shift < 0:
+			left++
+		case in.shift > 0:
+			t.Errorf("shifted right toward column 0")
+		}
+	}
+	if ccw != 1 || cw != 0 {
+		t.Errorf("rotated %d CCW and %d CW, want the one CCW turn", ccw, cw)
+	}
+	if left == 0 {
+		t.Error("never shifted left")
+	}
+}
+
+func TestBotDriverGivesUpOnAnUnreachableMove(t *testing.T) {
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.offline = true
+	d := newBotDriver(fixedBot{bot.Move{X: -10}}, time.Second, FallbackMove)
+	if ins := steer(t, g, d); len(ins) > maxSteerFrames+10 {
+		t.Errorf("took %d frames to give up, want about %d", len(ins), maxSteerFrames)
+	}
+}
+
+func TestCPUClearsLines(t *testing.T) {
+	g := newGameSeeded(7, modes[0], Modifiers{})
+	g.offline = true
+	d := newCPU()
+	for range 60 * 60 {
+		for d.pending != nil && len(d.pending) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		g.stepPlayers(d.input(g))
+		if g.GameOver() {
+			break
+		}
+	}
+	if g.GameOver() || g.Lines() < 20 {
+		t.Errorf("after a minute: game over %v with %d lines, want still playing with 20+", g.GameOver(), g.Lines())
+	}
+}
+
+func TestVersusCPU(t *testing.T) {
+	tempConfig(t)
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.startVersusCPU()
+	g.offline = true
+	for range 10 * 60 {
+		for d := g.rivalCPU; d.pending != nil && len(d.pending) == 0; {
+			time.Sleep(time.Millisecond)
+		}
+		g.stepPlayers(g.pollInputs()...)
+	}
+	if n := g.rival.Pieces(); n < 10 {
+		t.Errorf("the CPU placed %d pieces in ten seconds", n)
+	}
+	if g.cpuPlayed {
+		t.Error("the player's own board counts as CPU played")
+	}
+
+	g.toggleCPU()
+	if g.rivalCPU != nil || len(g.sources) != 2 || len(g.sources[1]) != 1 {
+		t.Errorf("F3 left the rival with %v", g.sources[1])
+	}
+	if _, ok := g.sources[1][0].(keyboardSource); !ok {
+		t.Error("the second board didn't go back to the keyboard")
+	}
+}
+
+func TestNoDemoInCoop(t *testing.T) {
+	tempConfig(t)
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.startDemo(modeByName("Co-op"))
+	if g.cpu != nil || g.Mode().Coop {
+		t.Error("the CPU took on co-op")
+	}
+}
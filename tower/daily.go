@@ -0,0 +1,69 @@
+package main
+
+import (
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"tetris/engine"
+)
+
+const (
+	// dailyName is the Daily Challenge mode, an Ultra everyone plays from
+	// the same seed on the same (UTC) day.
+	dailyName = "Daily Challenge"
+	// dailyKeepDays is how long a day's leaderboard is kept.
+	dailyKeepDays = 30
+)
+
+// fixedSeed, from -seed, deals every run from one seed; 0 picks a new one
+// each run.
+var fixedSeed int64
+
+// dailySeed is the day's seed, the same for every player.
+func dailySeed(day time.Time) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(day.UTC().Format(time.DateOnly)))
+	return int64(h.Sum64() >> 1)
+}
+
+// dailyBoard names the day's leaderboard.
+func dailyBoard(day time.Time) string {
+	return dailyName + " " + day.UTC().Format(time.DateOnly)
+}
+
+// seedFor picks a new game of m's seed: the day's for the Daily Challenge,
+// then -seed's, then a fresh one.
+func seedFor(m engine.Mode, now time.Time) int64 {
+	switch {
+	case m.Name == dailyName:
+		return dailySeed(now)
+	case fixedSeed != 0:
+		return fixedSeed
+	}
+	return now.UnixNano()
+}
+
+// scoreBoard is the leaderboard the game's score goes on: its mode's, or
+// the day's for a Daily Challenge. A daily run from another day's seed, as
+// a challenge link can start, has none.
+func (g *Game) scoreBoard() string {
+	name := g.Mode().Name
+	if name != dailyName {
+		return name
+	}
+	if g.seed != dailySeed(g.startedAt) {
+		return ""
+	}
+	return dailyBoard(g.startedAt)
+}
+
+// pruneDaily drops the day leaderboards older than dailyKeepDays.
+func (h *highScores) pruneDaily(now time.Time) {
+	cutoff := now.UTC().AddDate(0, 0, -dailyKeepDays).Format(time.DateOnly)
+	for board := range h.Boards {
+		if day, ok := strings.CutPrefix(board, dailyName+" "); ok && day < cutoff {
+			delete(h.Boards, board)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailySeed(t *testing.T) {
+	day := time.Date(2026, 3, 14, 1, 0, 0, 0, time.UTC)
+	later := time.Date(2026, 3, 14, 23, 0, 0, 0, time.UTC)
+	daily := modeByName(dailyName)
+	if seedFor(daily, day) != seedFor(daily, later) {
+		t.Error("two runs on one day got different seeds")
+	}
+	if dailySeed(day) == dailySeed(day.AddDate(0, 0, 1)) {
+		t.Error("the next day has the same seed")
+	}
+	if seedFor(modes[0], day) == seedFor(modes[0], later) {
+		t.Error("Marathon reused a seed")
+	}
+
+	saved := fixedSeed
+	t.Cleanup(func() { fixedSeed = saved })
+	fixedSeed = 42
+	if seedFor(modes[0], day) != 42 || seedFor(daily, day) != dailySeed(day) {
+		t.Error("-seed wasn't used, or replaced the day's seed")
+	}
+}
+
+func TestDailyBoard(t *testing.T) {
+	tempConfig(t)
+	saved := scores
+	t.Cleanup(func() { scores = saved })
+	scores = highScores{Boards: map[string][]scoreEntry{}}
+
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.startMode(modeByName(dailyName), Modifiers{})
+	if want := dailyBoard(time.Now()); g.scoreBoard() != want {
+		t.Errorf("board %q, want %q", g.scoreBoard(), want)
+	}
+	g.seed++
+	if g.scoreBoard() != "" {
+		t.Errorf("a run from another seed went on %q", g.scoreBoard())
+	}
+
+	old := dailyBoard(time.Now().AddDate(0, 0, -dailyKeepDays-1))
+	recent := dailyBoard(time.Now().AddDate(0, 0, -1))
+	scores.Boards[old] = []scoreEntry{{Score: 1}}
+	scores.Boards[recent] = []scoreEntry{{Score: 1}}
+	scores.Boards["Marathon"] = []scoreEntry{{Score: 1}}
+	scores.pruneDaily(time.Now())
+	if _, ok := scores.Boards[old]; ok {
+		t.Error("a month-old day's board was kept")
+	}
+	if len(scores.Boards) != 2 {
+		t.Errorf("boards left %v, want yesterday's and Marathon's", scores.Boards)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"tetris/dashboard"
+)
+
+// dashboardServer is non-nil while -stats-server is serving.
+var dashboardServer *dashboard.Server
+
+// startDashboard serves the stats dashboard on addr until the returned
+// stop is called.
+func startDashboard(addr string) (stop func()) {
+	dashboardServer = &dashboard.Server{}
+	publishHistory()
+	mux := http.NewServeMux()
+	mux.Handle("/", dashboardServer.Handler())
+	mux.Handle("GET /metrics", gameMetrics.Handler())
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("stats server failed", "addr", addr, "err", err)
+		}
+	}()
+	slog.Info("stats server listening", "addr", addr)
+	return func() { srv.Close() }
+}
+
+// publishLive hands the dashboard this frame's state.
+func (g *Game) publishLive() {
+	if dashboardServer == nil {
+		return
+	}
+	status := g.base().String()
+	activeRuns.Set(float64(b2i(g.activeRun())))
+	dashboardServer.SetLive(dashboard.Live{
+		Mode:      g.Mode().Name,
+		Modifiers: g.scoreFlags(),
+		Status:    status,
+		Score:     g.TotalScore(),
+		Lines:     g.Lines(),
+		Level:     g.Level(),
+		Pieces:    g.Pieces(),
+		Seconds:   float64(g.Frames()) / 60,
+	})
+}
+
+// publishHistory hands the dashboard a copy of the saved stats.
+func publishHistory() {
+	if dashboardServer == nil {
+		return
+	}
+	h := dashboard.History{Boards: map[string][]dashboard.Score{}, TotalPrestiges: profile.TotalPrestiges}
+	for mode, entries := range scores.Boards {
+		for _, e := range entries {
+			h.Boards[mode] = append(h.Boards[mode], dashboard.Score{
+				Score: e.Score, Lines: e.Lines, Level: e.Level, Date: e.Date, Flags: slices.Clone(e.Flags),
+			})
+		}
+	}
+	dashboardServer.SetHistory(h)
+}
@@ -0,0 +1,95 @@
+// Package dashboard serves the game's live state and stats history over
+// HTTP, as JSON for tools and stream overlays and as a small HTML page.
+package dashboard
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Live is the game as of the latest frame.
+type Live struct {
+	Mode      string   `json:"mode"`
+	Modifiers []string `json:"modifiers,omitempty"`
+	Status    string   `json:"status"` // "playing", "paused" or "game over"
+	Score     int      `json:"score"`
+	Lines     int      `json:"lines"`
+	Level     int      `json:"level"`
+	Pieces    int      `json:"pieces"`
+	Seconds   float64  `json:"seconds"` // of play, excluding pauses
+}
+
+// Score is one leaderboard entry.
+type Score struct {
+	Score int       `json:"score"`
+	Lines int       `json:"lines"`
+	Level int       `json:"level"`
+	Date  time.Time `json:"date"`
+	Flags []string  `json:"flags,omitempty"`
+}
+
+// History is the saved stats: leaderboards by mode and profile totals.
+type History struct {
+	Boards         map[string][]Score `json:"boards"`
+	TotalPrestiges int                `json:"total_prestiges"`
+}
+
+//go:embed dashboard.html
+var page []byte
+
+// Server holds the latest Live and History for its handler. It is safe for
+// concurrent use.
+type Server struct {
+	mu      sync.Mutex
+	live    Live
+	history History
+}
+
+// SetLive replaces the live state.
+func (s *Server) SetLive(l Live) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.live = l
+}
+
+// SetHistory replaces the stats history.
+func (s *Server) SetHistory(h History) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = h
+}
+
+// Handler serves the page at /, and /live and /history as JSON.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	})
+	mux.HandleFunc("GET /live", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		l := s.live
+		s.mu.Unlock()
+		writeJSON(w, l)
+	})
+	mux.HandleFunc("GET /history", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		h := s.history
+		s.mu.Unlock()
+		writeJSON(w, h)
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	// Overlays are usually served from elsewhere, such as a streaming app.
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Debug("dashboard write failed", "err", err)
+	}
+}
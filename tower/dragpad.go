@@ -0,0 +1,105 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	dragSoftDropPx = 32 // pixels below where a drag started that soft drop
+	flickFrames    = 4  // recent positions kept to measure a flick over
+	flickMinPx     = 48 // pixels down over them that make a flick
+)
+
+// dragPad is the Drag touch layout: one finger on the screen steers the
+// piece directly. Dragging sideways moves it a column for every tile
+// width the finger travels, dragging down soft drops, a quick flick down
+// hard drops, a tap rotates, and a long press holds. A second finger
+// tapping pauses.
+type dragPad struct {
+	id             ebiten.TouchID
+	down           bool
+	startX, startY int
+	anchorX        int // x where the last column step was taken
+	frames         int
+	moved          bool // wandered past tapSlop, so not a tap or long press
+	held           bool // the long press already held
+	ys             [flickFrames]int
+	ids            []ebiten.TouchID // reused each frame
+}
+
+// press starts tracking a finger put down at (x, y).
+func (p *dragPad) press(id ebiten.TouchID, x, y int) {
+	*p = dragPad{id: id, down: true, startX: x, startY: y, anchorX: x, ids: p.ids}
+	for i := range p.ys {
+		p.ys[i] = y
+	}
+}
+
+// move follows the finger to (x, y) for a frame, with col pixels to a
+// column.
+func (p *dragPad) move(x, y int, col float32) frameInput {
+	var in frameInput
+	p.frames++
+	p.ys[p.frames%flickFrames] = y
+	if abs(x-p.startX) > tapSlop || abs(y-p.startY) > tapSlop {
+		p.moved = true
+	}
+	step := max(1, int(col))
+	for ; x-p.anchorX >= step; p.anchorX += step {
+		in.shift++
+	}
+	for ; p.anchorX-x >= step; p.anchorX -= step {
+		in.shift--
+	}
+	in.softDrop = y-p.startY >= dragSoftDropPx && y-p.startY > abs(x-p.startX)
+	if !p.moved && !p.held && p.frames >= longPressFrames {
+		p.held = true
+		in.hold = true
+	}
+	return in
+}
+
+// release ends the drag: a tap rotates and a fast downward finish hard
+// drops.
+func (p *dragPad) release() frameInput {
+	y := p.ys[p.frames%flickFrames]
+	var in frameInput
+	switch {
+	case !p.moved && !p.held && p.frames <= tapMaxFrames:
+		in.rotCW = true
+	case y-p.ys[(p.frames+1)%flickFrames] >= flickMinPx:
+		in.hardDrop = true
+	}
+	p.down = false
+	return in
+}
+
+// update feeds this frame's touches on the playfield; just are the IDs
+// pressed this frame.
+func (p *dragPad) update(just []ebiten.TouchID, col float32) frameInput {
+	var in frameInput
+	switch {
+	case p.down && p.touching():
+		x, y := ebiten.TouchPosition(p.id)
+		in = p.move(x, y, col)
+	case p.down:
+		in = p.release()
+	}
+	for _, id := range just {
+		if p.down {
+			in.pause = true
+			continue
+		}
+		x, y := ebiten.TouchPosition(id)
+		p.press(id, x, y)
+	}
+	return in
+}
+
+// touching reports whether the tracked finger is still down.
+func (p *dragPad) touching() bool {
+	p.ids = touchIDs(p.ids)
+	return slices.Contains(p.ids, p.id)
+}
@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+// drag presses at (x, y), moves through the points one frame each, and
+// releases, returning the inputs from every frame.
+func drag(x, y int, path ...[2]int) []frameInput {
+	var p dragPad
+	p.press(0, x, y)
+	var ins []frameInput
+	for _, pt := range path {
+		ins = append(ins, p.move(pt[0], pt[1], 20))
+	}
+	return append(ins, p.release())
+}
+
+func TestDragPad(t *testing.T) {
+	still := func(n int) [][2]int {
+		pts := make([][2]int, n)
+		for i := range pts {
+			pts[i] = [2]int{100, 100}
+		}
+		return pts
+	}
+	count := func(ins []frameInput, f func(frameInput) bool) int {
+		n := 0
+		for _, in := range ins {
+			if f(in) {
+				n++
+			}
+		}
+		return n
+	}
+
+	if ins := drag(100, 100, still(3)...); !ins[len(ins)-1].rotCW {
+		t.Error("a tap didn't rotate")
+	}
+
+	ins := drag(100, 100, still(longPressFrames+5)...)
+	if n := count(ins, func(in frameInput) bool { return in.hold }); n != 1 {
+		t.Errorf("a long press held %d times, want once", n)
+	}
+	if ins[len(ins)-1].rotCW {
+		t.Error("a long press also rotated on release")
+	}
+
+	// A slow drag right across 3.5 columns moves three, one at a time.
+	var path [][2]int
+	for x := 105; x <= 170; x += 5 {
+		path = append(path, [2]int{x, 100})
+	}
+	shift := 0
+	for _, in := range drag(100, 100, path...) {
+		if in.shift > 1 || in.rotCW || in.softDrop || in.hardDrop {
+			t.Fatalf("dragging sideways gave %+v", in)
+		}
+		shift += in.shift
+	}
+	if shift != 3 {
+		t.Errorf("dragged 70px over 20px columns, moved %d", shift)
+	}
+	if ins := drag(100, 100, [2]int{60, 100}); ins[0].shift != -2 {
+		t.Errorf("a jump 40px left moved %d, want -2", ins[0].shift)
+	}
+
+	// Down slowly soft drops and doesn't hard drop on release.
+	path = nil
+	for y := 104; y <= 160; y += 4 {
+		path = append(path, [2]int{100, y})
+	}
+	ins = drag(100, 100, path...)
+	if count(ins, func(in frameInput) bool { return in.softDrop }) == 0 || ins[len(ins)-1].hardDrop {
+		t.Error("a slow drag down didn't soft drop, or hard dropped on release")
+	}
+
+	ins = drag(100, 100, [2]int{100, 130}, [2]int{100, 170}, [2]int{100, 210})
+	if !ins[len(ins)-1].hardDrop {
+		t.Error("a flick down didn't hard drop")
+	}
+}
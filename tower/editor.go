@@ -0,0 +1,341 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"tetris/engine"
+)
+
+const (
+	// editorPuzzle names the puzzle the board editor plays its draft as.
+	// It isn't listed with the others or marked solved.
+	editorPuzzle = "Editor"
+	// maxEditorPieces caps the editor's queue.
+	maxEditorPieces = 30
+	// maxEditorLines is the most lines a draft can ask for.
+	maxEditorLines = 4
+)
+
+// editorKinds are the keys that add each piece kind to the queue, in kind
+// order.
+var editorKinds = []ebiten.Key{ebiten.KeyI, ebiten.KeyO, ebiten.KeyT, ebiten.KeyS, ebiten.KeyZ, ebiten.KeyJ, ebiten.KeyL}
+
+// boardDraft is a board editor setup: garbage painted onto the board, and
+// the pieces and goal to clear it with.
+type boardDraft struct {
+	board   engine.Board
+	queue   []int
+	lines   int // 0 leaves the perfect clear as the only goal
+	perfect bool
+	brush   int    // what a touch drag paints: engine.GarbageCell, or 0 to erase
+	note    string // the last save, play or error
+}
+
+// editorDraft is kept between visits, like customGame, so a setup can be
+// played, tweaked and played again.
+var editorDraft = newBoardDraft()
+
+func newBoardDraft() boardDraft {
+	return boardDraft{board: engine.NewBoard(engine.BoardW, engine.BoardH), lines: 1}
+}
+
+// paint sets cell (x, y) to c. Only the rows a puzzle can fill take paint,
+// so the top stays clear for pieces to enter.
+func (d *boardDraft) paint(x, y, c int) {
+	if x < 0 || x >= engine.BoardW || y < engine.BoardH-engine.MaxPuzzleRows || y >= engine.BoardH {
+		return
+	}
+	d.board[y][x] = c
+}
+
+func (d *boardDraft) addPiece(kind int) {
+	if len(d.queue) < maxEditorPieces {
+		d.queue = append(d.queue, kind)
+	}
+}
+
+func (d *boardDraft) removePiece() {
+	if n := len(d.queue); n > 0 {
+		d.queue = d.queue[:n-1]
+	}
+}
+
+// goal says what the draft asks for, as a puzzle's goal line.
+func (d *boardDraft) goal() string {
+	switch {
+	case d.lines == 0:
+		return "Clear the whole board"
+	case d.lines == 1 && d.perfect:
+		return "Clear a line and the board"
+	case d.lines == 1:
+		return "Clear a line"
+	case d.perfect:
+		return fmt.Sprintf("Clear %d lines and the board", d.lines)
+	}
+	return fmt.Sprintf("Clear %d lines", d.lines)
+}
+
+// puzzle is the draft as a puzzle called name, checked as a puzzle file
+// would be.
+func (d *boardDraft) puzzle(name string) (engine.Puzzle, error) {
+	p := engine.NewPuzzle(name, d.goal(), d.board, d.queue, d.lines, d.perfect || d.lines == 0)
+	return p, engine.AddPuzzle(p)
+}
+
+// openEditor shows the board editor over a fresh standard-width game, so
+// the board sits where it will when the draft is played.
+func (g *Game) openEditor() {
+	for g.menuOpen() {
+		g.closeMenu()
+	}
+	g.modifiers = Modifiers{}
+	g.start(modes[0])
+	g.countdown = 0
+	g.setState(stateEditor)
+}
+
+// playDraft starts the draft as a puzzle.
+func (g *Game) playDraft() {
+	d := &editorDraft
+	if _, err := d.puzzle(editorPuzzle); err != nil {
+		d.note = trf("Can't play: %s", err)
+		return
+	}
+	d.note = ""
+	g.startMode(puzzleMode(editorPuzzle), Modifiers{})
+}
+
+// saveDraft writes the draft to the puzzles folder and lists it with the
+// other puzzles.
+func (g *Game) saveDraft() {
+	d := &editorDraft
+	now := time.Now()
+	p, err := d.puzzle("Made " + now.Format("Jan 2 15:04:05"))
+	if err == nil {
+		err = writePuzzle(p, now.Format("2006-01-02_150405")+".json")
+	}
+	if err != nil {
+		slog.Error("puzzle save failed", "err", err)
+		d.note = trf("Can't save: %s", err)
+		return
+	}
+	puzzlePage.items = puzzleItems()
+	d.note = trf("Saved as %s", p.Name)
+}
+
+func userPuzzleDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "puzzles"), nil
+}
+
+func writePuzzle(p engine.Puzzle, file string) error {
+	dir, err := userPuzzleDir()
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeSave(filepath.Join(dir, file), b)
+}
+
+// loadUserPuzzles lists the puzzles saved from the editor after the
+// embedded ones. A broken file is logged and skipped.
+func loadUserPuzzles() {
+	dir, err := userPuzzleDir()
+	if err != nil {
+		return
+	}
+	files, err := listSaves(dir)
+	if err != nil {
+		slog.Warn("puzzles unreadable", "dir", dir, "err", err)
+	}
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".json") {
+			continue
+		}
+		b, err := readSave(filepath.Join(dir, f))
+		var p engine.Puzzle
+		if err == nil {
+			p, err = engine.ParsePuzzle(b)
+		}
+		if err == nil {
+			err = engine.AddPuzzle(p)
+		}
+		if err != nil {
+			slog.Warn("puzzle skipped", "file", f, "err", err)
+		}
+	}
+	puzzlePage.items = puzzleItems()
+}
+
+// editorButton is one of the panel's buttons, for the mouse and touch.
+type editorButton struct {
+	label string
+	r     rect
+	press func(g *Game)
+}
+
+// editorButtons lays out the panel: a button per piece kind to queue it,
+// then the goal and the actions.
+func (g *Game) editorButtons() []editorButton {
+	l := g.layout()
+	k := l.uiScale
+	d := &editorDraft
+	var bs []editorButton
+	size := 18 * k
+	for kind := range editorKinds {
+		bs = append(bs, editorButton{
+			label: kindLetters[kind : kind+1],
+			r:     rect{x: l.panelX + float32(kind%4)*(size+2*k), y: l.originY + float32(kind/4)*(size+2*k), w: size, h: size},
+			press: func(g *Game) { d.addPiece(kind) },
+		})
+	}
+	rows := []editorButton{
+		{label: "Remove Piece", press: func(g *Game) { d.removePiece() }},
+		{label: trf("Lines: %d", d.lines), press: func(g *Game) { d.lines = wrap(d.lines+1, maxEditorLines+1) }},
+		{label: trf("Perfect: %s", tr(onOff(d.perfect || d.lines == 0))), press: func(g *Game) { d.perfect = !d.perfect }},
+		{label: "Clear Board", press: func(g *Game) { d.board = engine.NewBoard(engine.BoardW, engine.BoardH) }},
+		{label: "Play", press: (*Game).playDraft},
+		{label: "Save", press: (*Game).saveDraft},
+		{label: "Back", press: func(g *Game) { g.setState(stateTitle) }},
+	}
+	for i := range rows {
+		rows[i].r = rect{x: l.panelX, y: l.originY + 110*k + float32(i)*24*k, w: 110 * k, h: 20 * k}
+	}
+	return append(bs, rows...)
+}
+
+// updateEditor paints with the mouse (left fills, right erases) or a
+// finger (a drag fills or erases, whichever the first cell touched
+// wasn't), presses the panel's buttons, and takes the same from the keys:
+// the piece letters queue, Backspace unqueues, G and P set the goal,
+// Delete clears, Enter plays, Ctrl+S saves and Esc goes back.
+func (g *Game) updateEditor() {
+	d := &editorDraft
+	l := g.layout()
+	x, y := ebiten.CursorPosition()
+	if l.board().contains(x, y) {
+		switch {
+		case ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft):
+			d.paint(l.column(x), l.row(y), engine.GarbageCell)
+		case ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight):
+			d.paint(l.column(x), l.row(y), 0)
+		}
+	} else if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		g.pressEditorButton(x, y)
+	}
+	for _, id := range justTouchIDs(nil) {
+		tx, ty := ebiten.TouchPosition(id)
+		if !l.board().contains(tx, ty) {
+			g.pressEditorButton(tx, ty)
+			continue
+		}
+		d.brush = engine.GarbageCell
+		if col, row := l.column(tx), l.row(ty); d.board[row][col] != 0 {
+			d.brush = 0
+		}
+	}
+	for _, id := range touchIDs(nil) {
+		if tx, ty := ebiten.TouchPosition(id); l.board().contains(tx, ty) {
+			d.paint(l.column(tx), l.row(ty), d.brush)
+		}
+	}
+
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl)
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape) || g.pad.justPressed(ebiten.StandardGamepadButtonRightRight):
+		g.setState(stateTitle)
+		return
+	case ctrl && inpututil.IsKeyJustPressed(ebiten.KeyS):
+		g.saveDraft()
+		return
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		g.playDraft()
+		return
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace):
+		d.removePiece()
+	case inpututil.IsKeyJustPressed(ebiten.KeyG):
+		d.lines = wrap(d.lines+1, maxEditorLines+1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyP):
+		d.perfect = !d.perfect
+	case inpututil.IsKeyJustPressed(ebiten.KeyDelete):
+		d.board = engine.NewBoard(engine.BoardW, engine.BoardH)
+	}
+	if ctrl {
+		return
+	}
+	for kind, key := range editorKinds {
+		if inpututil.IsKeyJustPressed(key) {
+			d.addPiece(kind)
+		}
+	}
+}
+
+// pressEditorButton presses the panel button at (x, y), if there is one.
+func (g *Game) pressEditorButton(x, y int) {
+	for _, b := range g.editorButtons() {
+		if b.r.contains(x, y) {
+			b.press(g)
+			return
+		}
+	}
+}
+
+// drawEditor draws the draft on the board, the rows too high to paint
+// shaded, and the panel's queue and buttons.
+func (g *Game) drawEditor(screen *ebiten.Image) {
+	th := g.theme()
+	pal := th.Palette
+	l := g.layout()
+	k := l.uiScale
+	d := &editorDraft
+	g.drawGrid(screen, l.originX, l.originY, l.tile, th)
+	for y, row := range d.board {
+		for x, c := range row {
+			if c != 0 {
+				drawTile(screen, l.originX+float32(x)*l.tile, l.originY+float32(y)*l.tile, l.tile, pal.Pieces[c-1], tileGarbage)
+			}
+		}
+	}
+	top := float32(engine.BoardH-engine.MaxPuzzleRows) * l.tile
+	vector.DrawFilledRect(screen, l.originX, l.originY, l.boardPxW, top, alpha(pal.Shade, 120), false)
+	g.drawText(screen, tr(d.goal()), l.originX+4*k, l.originY+16*k, pal.Text)
+
+	for _, b := range g.editorButtons() {
+		vector.DrawFilledRect(screen, b.r.x, b.r.y, b.r.w, b.r.h, pal.Select, false)
+		g.drawText(screen, tr(b.label), b.r.x+4*k, b.r.y+b.r.h*0.7, pal.Text)
+	}
+	var queue strings.Builder
+	for _, kind := range d.queue {
+		queue.WriteByte(kindLetters[kind])
+	}
+	qy := l.originY + 56*k
+	g.drawText(screen, trf("Pieces (%d)", len(d.queue)), l.panelX, qy, pal.Dim)
+	for s := queue.String(); s != ""; qy += 14 * k {
+		n := min(len(s), 15)
+		g.drawText(screen, s[:n], l.panelX, qy+14*k, pal.Text)
+		s = s[n:]
+	}
+	y := l.originY + 110*k + 7*24*k + 12*k
+	if d.note != "" {
+		g.drawText(screen, d.note, l.panelX, y, pal.Info)
+	}
+	if !touchScreen() {
+		g.drawText(screen, tr("Left paints, right erases"), l.panelX, y+16*k, pal.Dim)
+		g.drawText(screen, tr("IOTSZJL queue, Enter plays"), l.panelX, y+32*k, pal.Dim)
+	}
+}
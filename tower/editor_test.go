@@ -0,0 +1,77 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"tetris/engine"
+)
+
+func TestEditorDraftPlaysAsAPuzzle(t *testing.T) {
+	tempConfig(t)
+	saved, savedProfile := editorDraft, profile
+	t.Cleanup(func() { editorDraft, profile = saved, savedProfile })
+	profile.SolvedPuzzles = nil
+
+	editorDraft = newBoardDraft()
+	for x := range engine.BoardW - 1 {
+		editorDraft.paint(x, engine.BoardH-1, engine.GarbageCell)
+	}
+	editorDraft.paint(0, 0, engine.GarbageCell) // too high: ignored
+	editorDraft.addPiece(0)
+
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.openEditor()
+	if g.state != stateEditor {
+		t.Fatalf("state %v, want the editor", g.state)
+	}
+	g.playDraft()
+	if g.Mode().Puzzle != editorPuzzle || g.Cell(0, engine.BoardH-1) != engine.GarbageCell || g.Cell(0, 0) != 0 {
+		t.Fatalf("playing the draft started %q with the wrong board", g.Mode().Name)
+	}
+	g.countdown = 0
+	for _, in := range []frameInput{{rotCW: true}, {shift: 4}, {hardDrop: true}} {
+		g.stepPlayers(in)
+	}
+	if !g.Won() {
+		t.Fatalf("the I didn't solve the draft: lines %d", g.Lines())
+	}
+	if len(profile.SolvedPuzzles) != 0 {
+		t.Errorf("the editor's draft was marked solved: %v", profile.SolvedPuzzles)
+	}
+	for _, it := range puzzlePage.items {
+		if it.label == editorPuzzle {
+			t.Error("the editor's draft is listed with the puzzles")
+		}
+	}
+}
+
+func TestSavingADraft(t *testing.T) {
+	tempConfig(t)
+	saved := editorDraft
+	t.Cleanup(func() { editorDraft = saved })
+
+	editorDraft = newBoardDraft()
+	editorDraft.lines = 0
+	editorDraft.paint(4, engine.BoardH-1, engine.GarbageCell)
+	editorDraft.addPiece(2)
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.saveDraft()
+	p, err := editorDraft.puzzle("check")
+	if err != nil || !p.PerfectClear || !slices.Equal(p.Rows, []string{"....X....."}) || p.Pieces != "T" {
+		t.Fatalf("the draft is %+v, %v", p, err)
+	}
+	dir, _ := userPuzzleDir()
+	files, _ := listSaves(dir)
+	if len(files) != 1 {
+		t.Fatalf("saved %v, want one puzzle file", files)
+	}
+	b, err := readSave(filepath.Join(dir, files[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := engine.ParsePuzzle(b); err != nil || editorDraft.note != "Saved as "+p.Name {
+		t.Errorf("the file holds %+v, %v; the editor says %q", p, err, editorDraft.note)
+	}
+}
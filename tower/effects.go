@@ -0,0 +1,183 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"tetris/engine"
+)
+
+const (
+	// clearFrames is the line clear animation: the rows flash white for
+	// clearFlashFrames, then shrink away (or dissolve, on high quality).
+	clearFrames      = 15
+	clearFlashFrames = 5
+	lockFlashFrames  = 8
+	punchFrames      = 24   // length of the zoom-punch, in real frames
+	punchZoom        = 0.08 // peak extra zoom
+	slowMoScale      = 0.35 // effect time rate during the punch
+)
+
+// effects is rendering-only state; nothing here feeds back into game logic.
+// Its timers run on a dilated clock so big clears can play in slow motion
+// while the simulation keeps its normal pace.
+type effects struct {
+	clock    float32             // dilated frames
+	cleared  []engine.ClearedRow // rows kept around for the dissolve
+	clearAge float32
+	// lockFlash holds the cells of the piece that just locked, where they
+	// are on the board after any clear.
+	lockFlash    []engine.Point
+	lockFlashAge float32
+	tweens       [2]tween // per player
+	punch        int      // real frames left in the camera punch
+	juice        []juice  // trails, shakes and border flashes; see juice.go
+	canvas       *ebiten.Image
+	// boardCanvas holds the board while the flip modifier turns it.
+	boardCanvas *ebiten.Image
+	grid        *ebiten.Image // the empty board, see drawGrid
+	gridKey     gridKey
+	lastLock    int // the engine frame of the last lock, for Flash
+}
+
+func (fx *effects) update() {
+	dt := float32(1)
+	if fx.punch > 0 {
+		fx.punch--
+		dt = slowMoScale
+	}
+	fx.clock += dt
+	for i := range fx.tweens {
+		fx.tweens[i].update(dt)
+	}
+	fx.updateJuice(dt)
+	if fx.cleared != nil {
+		fx.clearAge += dt
+		if fx.clearAge >= clearFrames {
+			fx.cleared = nil
+		}
+	}
+	if fx.lockFlash != nil {
+		fx.lockFlashAge += dt
+		if fx.lockFlashAge >= lockFlashFrames {
+			fx.lockFlash = nil
+		}
+	}
+}
+
+func (fx *effects) startDissolve(rows []engine.ClearedRow) {
+	fx.cleared = rows
+	fx.clearAge = 0
+}
+
+// startLockFlash flashes cells, the piece just locked. Cells in rows the
+// lock cleared are left to the clear animation, and the rest drop by the
+// cleared rows below them.
+func (fx *effects) startLockFlash(cells []engine.Point) {
+	var cleared []engine.ClearedRow
+	if fx.clearAge == 0 {
+		cleared = fx.cleared // not left from an earlier lock
+	}
+	fx.lockFlash = fx.lockFlash[:0]
+	for _, c := range cells {
+		y, gone := c.Y, false
+		for _, r := range cleared {
+			switch {
+			case r.Y == c.Y:
+				gone = true
+			case r.Y > c.Y:
+				y++
+			}
+		}
+		if !gone && y >= 0 {
+			fx.lockFlash = append(fx.lockFlash, engine.Point{X: c.X, Y: y})
+		}
+	}
+	fx.lockFlashAge = 0
+}
+
+func (fx *effects) startPunch() {
+	fx.punch = punchFrames
+}
+
+func (fx *effects) time() float32 {
+	return fx.clock / 60
+}
+
+// zoom is the camera scale for this frame: a quick push in that settles back.
+func (fx *effects) zoom() float64 {
+	if fx.punch <= 0 {
+		return 1
+	}
+	t := 1 - float64(fx.punch)/punchFrames
+	return 1 + punchZoom*math.Sin(t*math.Pi)*(1-t)
+}
+
+// drawWithCamera renders the scene through the camera. With no camera motion
+// it draws straight to the screen.
+func (g *Game) drawWithCamera(screen *ebiten.Image, scene func(*ebiten.Image)) {
+	z := g.fx.zoom()
+	dx, dy := g.fx.shake()
+	if z == 1 && dx == 0 && dy == 0 {
+		scene(screen)
+		return
+	}
+	b := screen.Bounds()
+	if g.fx.canvas == nil || g.fx.canvas.Bounds() != b {
+		g.fx.canvas = ebiten.NewImage(b.Dx(), b.Dy())
+	}
+	g.fx.canvas.Clear()
+	scene(g.fx.canvas)
+	cx, cy := float64(b.Dx())/2, float64(b.Dy())/2
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-cx, -cy)
+	op.GeoM.Scale(z, z)
+	op.GeoM.Translate(cx+dx, cy+dy)
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(g.fx.canvas, op)
+}
+
+// drawClearedRows plays the line clear over the emptied rows: a white
+// flash, then the cells shrink to the row's middle, or dissolve on high
+// quality.
+func (g *Game) drawClearedRows(screen *ebiten.Image, originX, originY, tile float32) {
+	pal := g.palette()
+	if g.fx.cleared == nil {
+		return
+	}
+	age := g.fx.clearAge
+	if age < clearFlashFrames {
+		a := uint8(255 * (1 - age/clearFlashFrames))
+		for _, r := range g.fx.cleared {
+			vector.DrawFilledRect(screen, originX, originY+float32(r.Y)*tile, float32(g.Width())*tile, tile, color.RGBA{a, a, a, a}, false)
+		}
+		return
+	}
+	progress := (age - clearFlashFrames) / (clearFrames - clearFlashFrames)
+	if g.drawDissolve(screen, originX, originY, tile, progress) {
+		return
+	}
+	h := (tile - 2) * (1 - progress)
+	for _, r := range g.fx.cleared {
+		for x, k := range r.Cells {
+			if k != 0 {
+				y := originY + float32(r.Y)*tile + (tile-h)/2
+				vector.DrawFilledRect(screen, originX+float32(x)*tile+1, y, tile-2, h, pal.Pieces[k-1], false)
+			}
+		}
+	}
+}
+
+// drawLockFlash whitens the piece that just locked, fading out.
+func (g *Game) drawLockFlash(screen *ebiten.Image, originX, originY, tile float32) {
+	if g.fx.lockFlash == nil {
+		return
+	}
+	a := uint8(160 * (1 - g.fx.lockFlashAge/lockFlashFrames))
+	for _, p := range g.fx.lockFlash {
+		vector.DrawFilledRect(screen, originX+float32(p.X)*tile+1, originY+float32(p.Y)*tile+1, tile-2, tile-2, color.RGBA{a, a, a, a}, false)
+	}
+}
@@ -0,0 +1,232 @@
+package engine
+
+import (
+	"hash/fnv"
+	"slices"
+	"strconv"
+)
+
+// ColumnHeights is each column's height: the rows from the floor up to its
+// highest filled cell, 0 for an empty column.
+func (b Board) ColumnHeights() []int {
+	heights := make([]int, b.Width())
+	for x := range heights {
+		for y, row := range b {
+			if row[x] != 0 {
+				heights[x] = len(b) - y
+				break
+			}
+		}
+	}
+	return heights
+}
+
+// Holes counts the empty cells with a filled cell somewhere above them in
+// their column.
+func (b Board) Holes() int {
+	holes := 0
+	for x := range b.Width() {
+		covered := false
+		for _, row := range b {
+			switch {
+			case row[x] != 0:
+				covered = true
+			case covered:
+				holes++
+			}
+		}
+	}
+	return holes
+}
+
+// Surface is the profile of the top of the stack: each column's height
+// less the height of the column to its left, one fewer than the columns.
+func (b Board) Surface() []int {
+	heights := b.ColumnHeights()
+	if len(heights) == 0 {
+		return nil
+	}
+	steps := make([]int, len(heights)-1)
+	for x := range steps {
+		steps[x] = heights[x+1] - heights[x]
+	}
+	return steps
+}
+
+// Bumpiness sums the steps of the Surface, up or down.
+func (b Board) Bumpiness() int {
+	n := 0
+	for _, s := range b.Surface() {
+		n += max(s, -s)
+	}
+	return n
+}
+
+// Lock is a copy of b with v in cells and its full rows cleared, and how
+// many rows were cleared. Cells off the board are left out.
+func (b Board) Lock(cells []Point, v int) (Board, int) {
+	c := b.Clone()
+	for _, p := range cells {
+		if p.Y >= 0 && p.Y < len(c) && p.X >= 0 && p.X < c.Width() {
+			c[p.Y][p.X] = v
+		}
+	}
+	kept := make(Board, 0, len(c))
+	for _, row := range c {
+		full := true
+		for _, cell := range row {
+			full = full && cell != 0
+		}
+		if !full {
+			kept = append(kept, row)
+		}
+	}
+	lines := len(c) - len(kept)
+	if lines == 0 {
+		return c, 0
+	}
+	// Empty rows in at the top, keeping the board's shape.
+	empty := NewBoard(c.Width(), lines)
+	copy(c, append(empty, kept...))
+	return c, lines
+}
+
+// Hash is a digest of the board's size and cells, for telling positions
+// apart. Unlike Game.Hash it covers nothing else.
+func (b Board) Hash() uint64 {
+	h := fnv.New64a()
+	buf := strconv.AppendInt(nil, int64(b.Width()), 10)
+	for _, row := range b {
+		for _, v := range row {
+			buf = append(buf, ',')
+			buf = strconv.AppendInt(buf, int64(v), 10)
+		}
+	}
+	h.Write(buf)
+	return h.Sum64()
+}
+
+// Placement is a spot the current piece can lock in, and the board it
+// leaves behind.
+type Placement struct {
+	Piece Piece // where it locks
+	Board Board // the stack after the lock, its full rows cleared
+	Lines int   // rows the lock clears
+	Hash  uint64
+}
+
+// Placements are the spots the active player's piece can reach from where
+// it is by the game's own shifts, rotations with their kicks, and soft
+// drops, hold aside. There's one per board left behind, the spots fewest
+// steps away first, and none that would lock above the board. The game is
+// unchanged.
+func (g *Game) Placements() []Placement {
+	spots, _ := reach(g.cur, g.collides, g.mode.Rules.NoRotation)
+	return g.board.placements(g.shapes, spots, g.collides)
+}
+
+// Placements are the spots p can reach on b the way Game.Placements finds
+// them, for working on a copy of a game's board. shapes is the game's
+// piece set, nil for the standard one. Cells above the board are open.
+func (b Board) Placements(shapes *PieceSet, p Piece, noRotation bool) []Placement {
+	if shapes == nil {
+		shapes = &Shapes
+	}
+	collides := func(p Piece) bool {
+		for _, c := range shapes[p.Kind][p.Rot] {
+			x, y := p.X+c.X, p.Y+c.Y
+			if x < 0 || x >= b.Width() || y < -VanishRows || y >= len(b) || y >= 0 && b[y][x] != 0 {
+				return true
+			}
+		}
+		return false
+	}
+	spots, _ := reach(p, collides, noRotation)
+	return b.placements(shapes, spots, collides)
+}
+
+// Route is the way the active player's piece gets to p by the steps
+// Placements explores: where it is after each shift, rotation or row of
+// soft drop, ending at p. ok is false if p can't be reached from where the
+// piece is.
+func (g *Game) Route(p Piece) (route []Piece, ok bool) {
+	_, from := reach(g.cur, g.collides, g.mode.Rules.NoRotation)
+	if _, ok := from[p]; !ok {
+		return nil, false
+	}
+	for ; p != g.cur; p = from[p] {
+		route = append(route, p)
+	}
+	slices.Reverse(route)
+	return route, true
+}
+
+// reach is every spot start can get to, fewest steps away first, and the
+// spot each was first reached from.
+func reach(start Piece, collides func(Piece) bool, noRotation bool) ([]Piece, map[Piece]Piece) {
+	from := map[Piece]Piece{start: start}
+	spots := []Piece{start}
+	for i := 0; i < len(spots); i++ {
+		for _, n := range steps(spots[i], collides, noRotation) {
+			if _, ok := from[n]; !ok {
+				from[n] = spots[i]
+				spots = append(spots, n)
+			}
+		}
+	}
+	return spots, from
+}
+
+// steps are where p can go in one rotation, shift or row of soft drop, in
+// that order, so the routes reach finds turn and slide a piece before
+// dropping it.
+func steps(p Piece, collides func(Piece) bool, noRotation bool) []Piece {
+	var next []Piece
+	for _, dir := range []int{1, -1} {
+		if noRotation {
+			break
+		}
+		for _, k := range kicks(p.Kind, p.Rot, dir) {
+			q := Piece{Kind: p.Kind, Rot: (p.Rot + dir + 4) % 4, X: p.X + k.X, Y: p.Y - k.Y}
+			if !collides(q) {
+				next = append(next, q)
+				break
+			}
+		}
+	}
+	for _, d := range []Point{{-1, 0}, {1, 0}, {0, 1}} {
+		q := p
+		q.X, q.Y = q.X+d.X, q.Y+d.Y
+		if !collides(q) {
+			next = append(next, q)
+		}
+	}
+	return next
+}
+
+// placements are the locks on b of the spots that rest on something, one
+// per board left behind, leaving out any partly above the board.
+func (b Board) placements(shapes *PieceSet, spots []Piece, collides func(Piece) bool) []Placement {
+	boards := map[uint64]bool{}
+	var out []Placement
+spot:
+	for _, p := range spots {
+		below := p
+		below.Y++
+		if !collides(below) {
+			continue
+		}
+		cells := shapes.Cells(p)
+		for _, c := range cells {
+			if c.Y < 0 {
+				continue spot
+			}
+		}
+		locked, lines := b.Lock(cells, p.Kind+1)
+		if h := locked.Hash(); !boards[h] {
+			boards[h] = true
+			out = append(out, Placement{Piece: p, Board: locked, Lines: lines, Hash: h})
+		}
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBoardAnalysis(t *testing.T) {
+	b := NewBoard(4, 5)
+	b[2][1] = 1 // over two holes
+	b[4][0], b[4][2], b[4][3] = 1, 1, 1
+	b[3][3] = 1
+	if got := b.ColumnHeights(); !slices.Equal(got, []int{1, 3, 1, 2}) {
+		t.Errorf("heights %v", got)
+	}
+	if got := b.Holes(); got != 2 {
+		t.Errorf("%d holes, want 2", got)
+	}
+	if got := b.Surface(); !slices.Equal(got, []int{2, -2, 1}) {
+		t.Errorf("surface %v", got)
+	}
+	if got := b.Bumpiness(); got != 5 {
+		t.Errorf("bumpiness %d, want 5", got)
+	}
+
+	locked, lines := b.Lock([]Point{{1, 4}, {1, 3}, {0, -1}}, 5)
+	if lines != 1 || locked.Height() != 5 || !slices.Equal(locked.ColumnHeights(), []int{0, 2, 0, 1}) {
+		t.Errorf("lock cleared %d rows, leaving heights %v", lines, locked.ColumnHeights())
+	}
+	if b[4][1] != 0 {
+		t.Error("Lock changed the board it was given")
+	}
+	if b.Hash() == locked.Hash() || b.Hash() != b.Clone().Hash() {
+		t.Error("board hashes don't tell the boards apart")
+	}
+}
+
+func TestPlacements(t *testing.T) {
+	g := New(1, testMode)
+	setPiece(g, 1, 0, 4, 0) // O
+	before := g.Hash()
+	ps := g.Placements()
+	if g.Hash() != before {
+		t.Error("Placements changed the game")
+	}
+	// An O on an empty board lands in any of nine columns.
+	if len(ps) != BoardW-1 {
+		t.Fatalf("%d placements for an O, want %d", len(ps), BoardW-1)
+	}
+	for _, p := range ps {
+		if p.Lines != 0 || p.Board.Hash() != p.Hash || !slices.Contains(p.Board[BoardH-1], 2) {
+			t.Errorf("placement %+v", p.Piece)
+		}
+	}
+
+	// An I slides under an overhang only by soft dropping first.
+	g = New(1, testMode)
+	fillRow(g, BoardH-1, 0, 1, 2, 3, 4, 5, 6)
+	fillRow(g, BoardH-2, 3, 4, 5, 6, 7, 8, 9)
+	setPiece(g, 0, 0, 3, 0)
+	var tucked bool
+	for _, p := range g.Placements() {
+		tucked = tucked || p.Board[BoardH-1][0] == 1
+	}
+	if !tucked {
+		t.Error("no placement tucks the I under the overhang")
+	}
+	if n, m := len(g.Placements()), len(g.board.Placements(nil, g.cur, false)); n != m {
+		t.Errorf("the game has %d placements and its board %d", n, m)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	g := New(1, testMode)
+	fillRow(g, BoardH-1, 0, 1, 2, 3, 4, 5, 6)
+	fillRow(g, BoardH-2, 3, 4, 5, 6, 7, 8, 9)
+	setPiece(g, 0, 0, 3, 0)
+	tuck := Piece{Kind: 0, Rot: 0, X: 0, Y: BoardH - 2}
+	route, ok := g.Route(tuck)
+	if !ok || route[len(route)-1] != tuck {
+		t.Fatalf("route %v, ok %v", route, ok)
+	}
+	// Down past the overhang, then left under it.
+	prev := g.cur
+	for i, p := range route {
+		if p.Y > prev.Y && p.X != prev.X || p.Y < BoardH-2 && p.X != 3 {
+			t.Errorf("step %d goes from %+v to %+v", i, prev, p)
+		}
+		prev = p
+	}
+	if _, ok := g.Route(Piece{Kind: 0, Rot: 0, X: 3, Y: -1}); ok {
+		t.Error("a route leads upward")
+	}
+}
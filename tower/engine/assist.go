@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"log/slog"
+	"math/rand"
+)
+
+const (
+	// nearTopRows is how close to the top of the board the stack must
+	// reach after a lock for Ruleset.Adaptive to count a near top out.
+	nearTopRows = 4
+	// easeAfter near top outs ease gravity by easeLevels levels.
+	easeAfter  = 3
+	easeLevels = 2
+)
+
+// newDeck makes the randomizer rules deal with, nothing dealt yet.
+func newDeck(r Ruleset) Randomizer {
+	d := r.Randomizer.New()
+	if r.EasyStart {
+		d = &easyStart{Randomizer: d}
+	}
+	return d
+}
+
+// checkEase counts a near top out when the stack ends a lock within
+// nearTopRows of the top, and eases gravity every easeAfter of them.
+func (g *Game) checkEase() {
+	if !g.mode.Rules.Adaptive || !g.nearTop() {
+		return
+	}
+	g.nearTops++
+	if g.nearTops < easeAfter {
+		return
+	}
+	g.nearTops = 0
+	g.ease += easeLevels
+	slog.Info("gravity eased", "levels", g.ease)
+}
+
+func (g *Game) nearTop() bool {
+	for _, row := range g.board[:min(nearTopRows, len(g.board))] {
+		for _, c := range row {
+			if c != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Eased is how many levels of gravity Ruleset.Adaptive has taken off.
+func (g *Game) Eased() int { return g.ease }
+
+// easyStartPieces is how many opening pieces Ruleset.EasyStart keeps S
+// and Z out of: the first piece and the three shown after it.
+const easyStartPieces = 4
+
+// easyStart deals from another randomizer, setting aside any S or Z it
+// deals among the opening pieces and dealing those once they're over.
+// State is the opening pieces dealt, the number set aside and their
+// kinds, then the other randomizer's state.
+type easyStart struct {
+	Randomizer
+	dealt int
+	held  []int
+}
+
+func (e *easyStart) Next(rng *rand.Rand) int {
+	if e.dealt == easyStartPieces && len(e.held) > 0 {
+		v := e.held[0]
+		e.held = e.held[1:]
+		return v
+	}
+	for {
+		v := e.Randomizer.Next(rng)
+		if e.dealt < easyStartPieces && (v == 3 || v == 4) { // S or Z
+			e.held = append(e.held, v)
+			continue
+		}
+		e.dealt = min(e.dealt+1, easyStartPieces)
+		return v
+	}
+}
+
+func (e *easyStart) State() []int {
+	s := append([]int{e.dealt, len(e.held)}, e.held...)
+	return append(s, e.Randomizer.State()...)
+}
+
+func (e *easyStart) SetState(s []int) {
+	e.dealt, e.held = 0, nil
+	if len(s) < 2 || len(s) < 2+s[1] {
+		e.Randomizer.SetState(nil)
+		return
+	}
+	e.dealt, e.held = s[0], append([]int(nil), s[2:2+s[1]]...)
+	e.Randomizer.SetState(s[2+s[1]:])
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestGravityCap(t *testing.T) {
+	m := testMode
+	m.StartLevel, m.Rules.GravityCap = 15, 12
+	if got := New(1, m).dropFrames(); got != 12 {
+		t.Errorf("capped level 15 falls a row every %d frames, want 12", got)
+	}
+	m.StartLevel = 0
+	if got := New(1, m).dropFrames(); got != gravityFrames(0) {
+		t.Errorf("the cap sped level 0 up to %d frames", got)
+	}
+}
+
+func TestEasyStart(t *testing.T) {
+	for _, k := range Randomizers {
+		for seed := range int64(50) {
+			rng := rand.New(rand.NewSource(seed))
+			d := newDeck(Ruleset{Randomizer: k, EasyStart: true})
+			var deal []int
+			for range 14 {
+				deal = append(deal, d.Next(rng))
+			}
+			if slices.Contains(deal[:easyStartPieces], 3) || slices.Contains(deal[:easyStartPieces], 4) {
+				t.Fatalf("%v seed %d opened %v", k, seed, deal[:easyStartPieces])
+			}
+			if k == RandomizerBag {
+				bags := slices.Sorted(slices.Values(deal))
+				if !slices.Equal(bags, []int{0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 6, 6}) {
+					t.Errorf("seed %d: two bags dealt as %v", seed, deal)
+				}
+			}
+		}
+	}
+
+	// The set-aside pieces go through State.
+	rng := rand.New(rand.NewSource(3))
+	a := newDeck(Ruleset{EasyStart: true})
+	for range 2 {
+		a.Next(rng)
+	}
+	b := newDeck(Ruleset{EasyStart: true})
+	b.SetState(a.State())
+	for i := range 12 {
+		seed := int64(i)
+		if x, y := a.Next(rand.New(rand.NewSource(seed))), b.Next(rand.New(rand.NewSource(seed))); x != y {
+			t.Fatalf("piece %d after a restore: %d, want %d", i, y, x)
+		}
+	}
+}
+
+func TestAdaptiveEasesGravity(t *testing.T) {
+	m := testMode
+	m.StartLevel, m.Rules.Adaptive = 10, true
+	g := New(1, m)
+	fast := g.dropFrames()
+	g.board[nearTopRows][0] = GarbageCell
+	g.checkEase()
+	if g.nearTops != 0 {
+		t.Error("a stack below the top counted as a near top out")
+	}
+	g.board[nearTopRows-1][0] = GarbageCell
+	for range easeAfter {
+		g.checkEase()
+	}
+	if g.Eased() != easeLevels || g.dropFrames() != gravityFrames(10-easeLevels) || g.dropFrames() <= fast {
+		t.Errorf("eased %d levels to %d frames a row", g.Eased(), g.dropFrames())
+	}
+
+	b, err := g.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New(2, m)
+	if err := r.Restore(b); err != nil {
+		t.Fatal(err)
+	}
+	if r.Hash() != g.Hash() {
+		t.Error("the ease didn't survive a save")
+	}
+}
@@ -0,0 +1,49 @@
+package engine
+
+const (
+	blitzStageFrames = 30 * 60
+	// Garbage rises every blitzGarbageStart frames in the first stage,
+	// blitzGarbageStep sooner each stage after, down to blitzGarbageMin.
+	blitzGarbageStart = 15 * 60
+	blitzGarbageStep  = 2 * 60
+	blitzGarbageMin   = 3 * 60
+	// blitzGravityStep is how many levels of gravity each stage adds.
+	blitzGravityStep = 3
+)
+
+func (g *Game) blitzStage() int {
+	if !g.mode.Blitz {
+		return 0
+	}
+	return g.frames / blitzStageFrames
+}
+
+// gravityLevel is the level gravity is taken from.
+func (g *Game) gravityLevel() int {
+	if g.mode.Zen {
+		return 0
+	}
+	return max(0, g.level+blitzGravityStep*g.blitzStage()+prestigeGravityStep*g.prestige-g.ease)
+}
+
+func (g *Game) scoreMultiplier() int {
+	return 1 + g.blitzStage()
+}
+
+// garbageInterval is the frames between rising garbage rows, or 0 for none.
+func (g *Game) garbageInterval() int {
+	switch {
+	case g.mode.Blitz:
+		return max(blitzGarbageMin, blitzGarbageStart-blitzGarbageStep*g.blitzStage())
+	case g.prestige >= prestigeGarbageFrom:
+		return max(prestigeGarbageMin, prestigeGarbageStart-prestigeGarbageStep*(g.prestige-prestigeGarbageFrom))
+	}
+	return 0
+}
+
+// updateGarbage raises a garbage row when one is due.
+func (g *Game) updateGarbage() {
+	if n := g.garbageInterval(); n > 0 && g.frames%n == 0 {
+		g.raiseGarbage(1, -1)
+	}
+}
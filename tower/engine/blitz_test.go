@@ -0,0 +1,50 @@
+package engine
+
+import "testing"
+
+var blitzMode = Mode{Name: "Blitz", Width: BoardW, Blitz: true}
+
+func TestBlitzEscalates(t *testing.T) {
+	g := New(1, blitzMode)
+	for _, tc := range []struct{ frames, stage, garbage int }{
+		{0, 0, blitzGarbageStart},
+		{blitzStageFrames - 1, 0, blitzGarbageStart},
+		{blitzStageFrames, 1, blitzGarbageStart - blitzGarbageStep},
+		{2 * blitzStageFrames, 2, blitzGarbageStart - 2*blitzGarbageStep},
+		{20 * blitzStageFrames, 20, blitzGarbageMin},
+	} {
+		g.frames = tc.frames
+		if n := g.BlitzStage(); n != tc.stage {
+			t.Errorf("frame %d: stage %d, want %d", tc.frames, n, tc.stage)
+		}
+		if n := g.gravityLevel(); n != blitzGravityStep*tc.stage {
+			t.Errorf("frame %d: gravity level %d, want %d", tc.frames, n, blitzGravityStep*tc.stage)
+		}
+		if n := g.scoreMultiplier(); n != 1+tc.stage {
+			t.Errorf("frame %d: score ×%d, want ×%d", tc.frames, n, 1+tc.stage)
+		}
+		if n := g.garbageInterval(); n != tc.garbage {
+			t.Errorf("frame %d: garbage every %d frames, want %d", tc.frames, n, tc.garbage)
+		}
+	}
+
+	g = New(1, testMode)
+	g.frames = blitzStageFrames
+	if g.BlitzStage() != 0 || g.scoreMultiplier() != 1 || g.garbageInterval() != 0 {
+		t.Error("a mode without Blitz escalated")
+	}
+}
+
+func TestBlitzGarbageRises(t *testing.T) {
+	g := New(1, blitzMode)
+	g.frames = blitzGarbageStart - 1
+	g.updateGarbage()
+	if !g.board[BoardH-1:].Empty() {
+		t.Fatal("garbage rose early")
+	}
+	g.frames++
+	g.updateGarbage()
+	if g.board[BoardH-1:].Empty() || !g.board[:BoardH-1].Empty() {
+		t.Error("one row of garbage didn't rise on time")
+	}
+}
@@ -0,0 +1,55 @@
+package engine
+
+import "slices"
+
+// Board is the stack, rows top to bottom: 0 for empty, 1..7 for piece
+// kinds, then GarbageCell and GemCell. Its size is the mode's, so it's
+// sliced rather than a fixed array.
+type Board [][]int
+
+// NewBoard is an empty board w columns by h rows.
+func NewBoard(w, h int) Board {
+	cells := make([]int, w*h)
+	b := make(Board, h)
+	for y := range b {
+		b[y] = cells[y*w : (y+1)*w : (y+1)*w]
+	}
+	return b
+}
+
+// Width is the board's columns.
+func (b Board) Width() int {
+	if len(b) == 0 {
+		return 0
+	}
+	return len(b[0])
+}
+
+// Height is the board's rows.
+func (b Board) Height() int { return len(b) }
+
+// Clone copies b, so either can change without the other.
+func (b Board) Clone() Board {
+	c := NewBoard(b.Width(), b.Height())
+	for y := range b {
+		copy(c[y], b[y])
+	}
+	return c
+}
+
+// Equal reports whether b and o are the same size with the same cells.
+func (b Board) Equal(o Board) bool {
+	return slices.EqualFunc(b, o, slices.Equal)
+}
+
+// Empty reports whether no cell is filled.
+func (b Board) Empty() bool {
+	for _, row := range b {
+		for _, c := range row {
+			if c != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
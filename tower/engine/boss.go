@@ -0,0 +1,101 @@
+package engine
+
+// bossAttack is one step of a boss script: after Wait frames, send Rows of
+// garbage with a hole in column Hole (negative for random).
+type bossAttack struct {
+	Wait, Rows, Hole int
+}
+
+// bossPhase is one health bar. Its attacks run in order and then repeat
+// until the player deals HP damage.
+type bossPhase struct {
+	Name    string
+	HP      int
+	Attacks []bossAttack
+}
+
+var bossPhases = []bossPhase{
+	{Name: "Stone Golem", HP: 10, Attacks: []bossAttack{
+		{Wait: 360, Rows: 1, Hole: -1},
+		{Wait: 360, Rows: 1, Hole: -1},
+		// A wall: a tall clean well to dig through.
+		{Wait: 480, Rows: 4, Hole: 0},
+	}},
+	{Name: "Twin Serpents", HP: 16, Attacks: []bossAttack{
+		// Combos: a quick run of small attacks.
+		{Wait: 300, Rows: 1, Hole: -1},
+		{Wait: 30, Rows: 1, Hole: -1},
+		{Wait: 30, Rows: 1, Hole: -1},
+		{Wait: 30, Rows: 2, Hole: -1},
+	}},
+	{Name: "The Tower", HP: 24, Attacks: []bossAttack{
+		{Wait: 480, Rows: 2, Hole: -1},
+		// A sudden spike.
+		{Wait: 180, Rows: 6, Hole: -1},
+	}},
+}
+
+// bossFight tracks the boss through its phases.
+type bossFight struct {
+	phase int
+	hp    int
+	step  int // next attack in the phase's script
+	wait  int // frames until it fires
+}
+
+func newBossFight() *bossFight {
+	b := &bossFight{}
+	b.enter(0)
+	return b
+}
+
+func (b *bossFight) enter(phase int) {
+	p := bossPhases[phase]
+	*b = bossFight{phase: phase, hp: p.HP, wait: p.Attacks[0].Wait}
+}
+
+// updateBoss fires the boss's scripted attacks into the incoming queue.
+func (g *Game) updateBoss() {
+	b := g.boss
+	if b == nil {
+		return
+	}
+	if b.wait--; b.wait > 0 {
+		return
+	}
+	attacks := bossPhases[b.phase].Attacks
+	a := attacks[b.step]
+	g.queueGarbage(a.Rows, a.Hole)
+	b.step = (b.step + 1) % len(attacks)
+	b.wait = attacks[b.step].Wait
+}
+
+// hitBoss deals attack the player sent, moving to the next phase when a
+// health bar empties and winning after the last.
+func (g *Game) hitBoss(attack int) {
+	b := g.boss
+	if b == nil || attack <= 0 || g.gameOver {
+		return
+	}
+	b.hp -= attack
+	if b.hp > 0 {
+		return
+	}
+	if b.phase+1 >= len(bossPhases) {
+		b.hp = 0
+		g.won = true
+		g.endGame("boss defeated")
+		return
+	}
+	b.enter(b.phase + 1)
+}
+
+// BossHealth is the share (0..1) of the current phase's health bar left,
+// and false outside Boss Battle.
+func (g *Game) BossHealth() (float64, bool) {
+	b := g.boss
+	if b == nil {
+		return 0, false
+	}
+	return float64(max(b.hp, 0)) / float64(bossPhases[b.phase].HP), true
+}
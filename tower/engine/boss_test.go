@@ -0,0 +1,78 @@
+package engine
+
+import "testing"
+
+var bossMode = Mode{Name: "Boss Battle", Width: BoardW, Boss: true}
+
+func TestBossAttacksCycle(t *testing.T) {
+	g := New(1, bossMode)
+	// The Stone Golem's script twice over: a row, a row, a four-row wall.
+	for i, want := range []int{1, 2, 6, 7, 8, 12} {
+		a := bossPhases[0].Attacks[i%3]
+		for range a.Wait - 1 {
+			g.updateBoss()
+		}
+		if n := g.IncomingRows(); n != want-a.Rows {
+			t.Fatalf("attack %d: %d rows incoming a frame early, want %d", i, n, want-a.Rows)
+		}
+		g.updateBoss()
+		if n := g.IncomingRows(); n != want {
+			t.Fatalf("attack %d: %d rows incoming, want %d", i, n, want)
+		}
+		if b := g.incoming[len(g.incoming)-1]; b.hole != a.Hole {
+			t.Errorf("attack %d: hole %d, want %d", i, b.hole, a.Hole)
+		}
+	}
+}
+
+func TestBossPhases(t *testing.T) {
+	g := New(1, bossMode)
+	g.hitBoss(4)
+	if hp, ok := g.BossHealth(); !ok || hp != 0.6 {
+		t.Fatalf("health %v after 4 of 10, want 0.6", hp)
+	}
+	b := g.boss
+	b.wait = 1
+	g.updateBoss() // partway through the first script
+	g.hitBoss(6)
+	if b.phase != 1 || b.hp != bossPhases[1].HP || b.step != 0 || b.wait != bossPhases[1].Attacks[0].Wait {
+		t.Fatalf("after the first bar: %+v, want the second phase from its start", *b)
+	}
+	if hp, _ := g.BossHealth(); hp != 1 {
+		t.Errorf("the second bar starts at %v, want full", hp)
+	}
+
+	g.hitBoss(0)
+	g.hitBoss(-3)
+	if b.hp != bossPhases[1].HP {
+		t.Error("an empty attack dealt damage")
+	}
+	g.hitBoss(100) // overkill doesn't carry into the next bar
+	if b.phase != 2 || b.hp != bossPhases[2].HP {
+		t.Fatalf("after overkill: phase %d hp %d, want the third at full", b.phase, b.hp)
+	}
+	if g.GameOver() {
+		t.Fatal("the fight ended with a bar to go")
+	}
+	g.hitBoss(bossPhases[2].HP)
+	if !g.GameOver() || !g.Won() {
+		t.Error("emptying the last bar didn't win")
+	}
+	if hp, _ := g.BossHealth(); hp != 0 {
+		t.Errorf("health %v after the win, want 0", hp)
+	}
+}
+
+func TestBossTakesSentGarbage(t *testing.T) {
+	g := New(1, bossMode)
+	for y := BoardH - 4; y < BoardH; y++ {
+		fillRow(g, y, 0)
+	}
+	place(g, 0, 1, -2) // a Tetris down the left well
+	if hp, _ := g.BossHealth(); hp != 0.6 {
+		t.Errorf("health %v after a Tetris, want 0.6", hp)
+	}
+	if _, ok := New(1, testMode).BossHealth(); ok {
+		t.Error("a game without a boss has a health bar")
+	}
+}
@@ -0,0 +1,48 @@
+package engine
+
+import "slices"
+
+// fillCheese raises garbage until the board holds Mode.Cheese rows of it.
+// Each new row's gap avoids the column of the gap in the row it lands
+// under, so no two stack into a well.
+func (g *Game) fillCheese() {
+	for n := g.cheeseRows(); n < g.mode.Cheese && !g.gameOver; n++ {
+		prev := -1
+		if bottom := g.board[len(g.board)-1]; isGarbageRow(bottom) {
+			prev = slices.Index(bottom[:g.width], 0)
+		}
+		hole := g.rng.Intn(g.width - 1)
+		if prev >= 0 && hole >= prev {
+			hole++
+		}
+		g.raiseGarbage(1, hole)
+	}
+}
+
+// cheeseRows counts the rows with garbage in them.
+func (g *Game) cheeseRows() int {
+	n := 0
+	for _, row := range g.board {
+		if isGarbageRow(row) {
+			n++
+		}
+	}
+	return n
+}
+
+func isGarbageRow(row []int) bool {
+	for _, c := range row {
+		if c == GarbageCell {
+			return true
+		}
+	}
+	return false
+}
+
+// CheesePerMinute is the garbage rows cleared per minute of play.
+func (g *Game) CheesePerMinute() float64 {
+	if g.frames == 0 {
+		return 0
+	}
+	return float64(g.cheese) * 60 * 60 / float64(g.frames)
+}
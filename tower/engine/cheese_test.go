@@ -0,0 +1,68 @@
+package engine
+
+import "testing"
+
+var cheeseMode = Mode{Name: "Cheese", Width: BoardW, Cheese: 6}
+
+func TestCheeseStartsWithGarbage(t *testing.T) {
+	g := New(1, cheeseMode)
+	if n := g.cheeseRows(); n != 6 {
+		t.Fatalf("%d garbage rows, want 6", n)
+	}
+	for y := BoardH - 6; y < BoardH; y++ {
+		gaps := 0
+		for x := range g.width {
+			if g.board[y][x] == 0 {
+				gaps++
+			}
+		}
+		if gaps != 1 {
+			t.Errorf("row %d has %d gaps, want 1", y, gaps)
+		}
+		if y > BoardH-6 && gapAt(g, y) == gapAt(g, y-1) {
+			t.Errorf("rows %d and %d share a gap", y-1, y)
+		}
+	}
+}
+
+func gapAt(g *Game, y int) int {
+	for x := range g.width {
+		if g.board[y][x] == 0 {
+			return x
+		}
+	}
+	return -1
+}
+
+func TestCheeseRefills(t *testing.T) {
+	g := New(1, cheeseMode)
+	// Fill the bottom row's gap to clear it.
+	x := gapAt(g, BoardH-1)
+	setPiece(g, 0, 1, x-2, BoardH-4) // an upright I, its column at x
+	g.HardDrop()
+	if g.lines != 1 || g.cheese != 1 {
+		t.Fatalf("lines %d, cheese %d; want the bottom row cleared", g.lines, g.cheese)
+	}
+	if n := g.cheeseRows(); n != 6 {
+		t.Errorf("%d garbage rows after the clear, want 6 again", n)
+	}
+	g.frames = 60 * 60
+	if got := g.CheesePerMinute(); got != 1 {
+		t.Errorf("%v cheese a minute, want 1", got)
+	}
+	if g.Status() != "Cheese: 1, 1.0/min" {
+		t.Errorf("status %q", g.Status())
+	}
+}
+
+func TestCheeseOffElsewhere(t *testing.T) {
+	g := New(1, testMode)
+	fillRow(g, BoardH-1, 0)
+	place(g, 0, 1, -2)
+	if g.lines != 1 {
+		t.Fatal("the garbage row didn't clear")
+	}
+	if g.cheese != 0 || g.cheeseRows() != 0 {
+		t.Error("garbage came back outside Cheese")
+	}
+}
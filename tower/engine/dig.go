@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"sync"
+)
+
+// GemCell is the board value of a buried gem, after garbage.
+const GemCell = 9
+
+// digBonus is awarded per stage cleared, times the stage number.
+const digBonus = 1000
+
+//go:embed stages/dig/*.json
+var digFS embed.FS
+
+// digStage is one Dig Quest formation. Rows are listed top to bottom and
+// sit on the floor: 'X' is garbage, '*' a gem, '.' empty.
+type digStage struct {
+	Name string   `json:"name"`
+	Rows []string `json:"rows"`
+}
+
+var (
+	digOnce   sync.Once
+	digStages []digStage
+)
+
+// loadDigStages reads the stage files in name order. A broken file is
+// logged and skipped so the rest stay playable.
+func loadDigStages() []digStage {
+	digOnce.Do(func() {
+		names, _ := fs.Glob(digFS, "stages/dig/*.json")
+		sort.Strings(names)
+		for _, name := range names {
+			st, err := readDigStage(name)
+			if err != nil {
+				slog.Warn("dig stage skipped", "file", name, "err", err)
+				continue
+			}
+			digStages = append(digStages, st)
+		}
+	})
+	return digStages
+}
+
+func readDigStage(name string) (digStage, error) {
+	var st digStage
+	b, err := digFS.ReadFile(name)
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(b, &st); err != nil {
+		return st, err
+	}
+	if st.Name == "" {
+		st.Name = path.Base(name)
+	}
+	if len(st.Rows) == 0 || len(st.Rows) > BoardH-4 {
+		return st, fmt.Errorf("%d rows, want 1 to %d", len(st.Rows), BoardH-4)
+	}
+	gems := 0
+	for i, r := range st.Rows {
+		if len(r) != BoardW {
+			return st, fmt.Errorf("row %d is %d wide, want %d", i, len(r), BoardW)
+		}
+		for _, c := range r {
+			switch c {
+			case '*':
+				gems++
+			case 'X', '.':
+			default:
+				return st, fmt.Errorf("row %d: unknown cell %q", i, c)
+			}
+		}
+	}
+	if gems == 0 {
+		return st, fmt.Errorf("no gems")
+	}
+	return st, nil
+}
+
+// startDigStage replaces the board with formation i.
+func (g *Game) startDigStage(i int) {
+	st := loadDigStages()[i]
+	g.digStage = i
+	g.board = NewBoard(g.width, g.Height())
+	g.lockedAt = NewBoard(g.width, g.Height())
+	g.clearVanish()
+	top := g.Height() - len(st.Rows)
+	for y, r := range st.Rows {
+		for x, c := range r {
+			switch c {
+			case 'X':
+				g.board[top+y][x] = GarbageCell
+			case '*':
+				g.board[top+y][x] = GemCell
+			}
+		}
+	}
+	slog.Debug("dig stage", "stage", i+1, "name", st.Name)
+}
+
+func (g *Game) gemsLeft() int {
+	n := 0
+	for y := range g.board {
+		for _, c := range g.board[y] {
+			if c == GemCell {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// checkDig advances to the next stage once every gem is dug out, and wins
+// the quest after the last.
+func (g *Game) checkDig() {
+	if !g.mode.Dig || g.gemsLeft() > 0 {
+		return
+	}
+	g.score += digBonus * (g.digStage + 1)
+	if g.digStage+1 >= len(loadDigStages()) {
+		g.won = true
+		g.endGame("quest complete")
+		return
+	}
+	g.startDigStage(g.digStage + 1)
+}
@@ -0,0 +1,62 @@
+package engine
+
+import "testing"
+
+var digMode = Mode{Name: "Dig Quest", Width: BoardW, Dig: true}
+
+func TestDigStagesLoad(t *testing.T) {
+	names, _ := digFS.ReadDir("stages/dig")
+	if n := len(loadDigStages()); n == 0 || n != len(names) {
+		t.Errorf("%d of %d stage files loaded", n, len(names))
+	}
+}
+
+func TestDigStageAdvances(t *testing.T) {
+	g := New(1, digMode)
+	if g.digStage != 0 || g.gemsLeft() != 1 {
+		t.Fatalf("stage %d with %d gems, want the first with its one", g.digStage, g.gemsLeft())
+	}
+	place(g, 0, 1, 2) // an upright I down the shaft, clearing the gem's row
+	if g.digStage != 1 || g.GameOver() {
+		t.Fatalf("stage %d, game over %v after the gem was dug out; want stage 2 and playing", g.digStage+1, g.GameOver())
+	}
+	if g.score < digBonus {
+		t.Errorf("score %d, want at least the %d stage bonus", g.score, digBonus)
+	}
+	want := 0
+	for _, r := range loadDigStages()[1].Rows {
+		for _, c := range r {
+			if c == '*' {
+				want++
+			}
+		}
+	}
+	if n := g.gemsLeft(); n != want {
+		t.Errorf("%d gems on the second stage, want %d", n, want)
+	}
+}
+
+func TestDigQuestComplete(t *testing.T) {
+	g := New(1, digMode)
+	last := len(loadDigStages()) - 1
+	g.startDigStage(last)
+	g.checkDig()
+	if g.GameOver() {
+		t.Fatal("the quest ended with gems left")
+	}
+	for y := range g.board {
+		for x, c := range g.board[y] {
+			if c == GemCell {
+				g.board[y][x] = 0
+			}
+		}
+	}
+	score := g.score
+	g.checkDig()
+	if !g.GameOver() || !g.Won() {
+		t.Error("digging out the last stage didn't win")
+	}
+	if d := g.score - score; d != digBonus*(last+1) {
+		t.Errorf("the last stage paid %d, want %d", d, digBonus*(last+1))
+	}
+}
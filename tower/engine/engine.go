@@ -0,0 +1,556 @@
+// Package engine is the game's rules: the board, falling pieces, gravity,
+// line clears and scoring, and every mode built on them. It knows nothing
+// about rendering or input devices, so a game can be stepped headlessly by
+// bots, servers and tests as well as by the front end.
+package engine
+
+import (
+	"log/slog"
+	"math/rand"
+)
+
+const (
+	BoardW    = 10 // standard board width
+	MaxBoardW = 20 // widest board a mode may use
+	BoardH    = 20 // standard board height
+	MaxBoardH = 40 // tallest board a mode may use
+
+	// SpawnDelayFrames is the entry delay between a lock and the next spawn.
+	SpawnDelayFrames = 6
+	// QueueLen is how many upcoming pieces are known and shown.
+	QueueLen = 5
+
+	// lockDelayFrames is how long a piece rests on the stack before it
+	// locks.
+	lockDelayFrames = 30
+	// maxLockResets caps how often moving or rotating a resting piece
+	// restarts its lock delay, until it reaches a new lowest row.
+	maxLockResets = 15
+)
+
+// Input is what a player asked for in one step.
+type Input struct {
+	Shift          int // cells to move this step; negative is left
+	RotCW, RotCCW  bool
+	HardDrop, Hold bool
+	SoftDrop       bool
+	Undo           bool
+	// RotCWHeld, RotCCWHeld and HoldHeld are the rotate and hold buttons
+	// being down, pressed this step or not. A piece entering takes them as
+	// its initial rotation and hold.
+	RotCWHeld, RotCCWHeld, HoldHeld bool
+}
+
+// Merge accumulates the one-shot presses of in; held state is not buffered.
+func (i *Input) Merge(in Input) {
+	i.RotCW = i.RotCW || in.RotCW
+	i.RotCCW = i.RotCCW || in.RotCCW
+	i.HardDrop = i.HardDrop || in.HardDrop
+	i.Hold = i.Hold || in.Hold
+}
+
+// mergeHeld adds the rotate and hold buttons held in in as presses, for
+// the initial rotation and hold of a piece entering.
+func (i *Input) mergeHeld(in Input) {
+	i.RotCW = i.RotCW || in.RotCWHeld
+	i.RotCCW = i.RotCCW || in.RotCCWHeld
+	i.Hold = i.Hold || in.HoldHeld
+}
+
+// Hooks let a front end follow what happens during a step, to animate it
+// or keep records. Any may be nil. player is 0, or 1 for the co-op partner.
+type Hooks struct {
+	// Moved reports a shift or rotation; dx includes any wall kick and rot
+	// is the rotation direction, 0 for a shift.
+	Moved func(player, dx, rot int)
+	// Spawned reports a piece entering at the top, including one returned
+	// there by undo.
+	Spawned func(player int)
+	// Dropped reports a hard drop, just before the lock: p is where the
+	// piece landed and rows how far it fell.
+	Dropped func(player int, p Piece, rows int)
+	// Cleared reports the rows a lock removed, as they were.
+	Cleared func(rows []ClearedRow)
+	// Locked reports a piece locking and the rows it cleared.
+	Locked func(player, cleared int, tspin bool)
+	// LevelUp reports the level rising after a clear.
+	LevelUp func(level int)
+	// Prestige reports an Endless score rollover and the new rank.
+	Prestige func(rank int)
+	// GameOver reports the end of the game and why.
+	GameOver func(reason string)
+}
+
+// ClearedRow is a row removed by a line clear.
+type ClearedRow struct {
+	Y     int
+	Cells []int
+}
+
+// pieceState is one player's falling piece and the timers around it. Game
+// embeds the active player's; in co-op the other player's is swapped in to
+// step it, so all piece logic is written once.
+type pieceState struct {
+	cur              Piece
+	dropFrameCounter int
+	softDropCounter  int
+	lastRotated      bool
+	hold             int // held kind, -1 for none
+	holdUsed         bool
+	spawnTimer       int   // frames left before the next piece appears
+	buffered         Input // presses seen while spawnTimer ran
+	respawn          bool  // retry spawning cur.kind when the timer ends
+	lockTimer        int   // frames cur has rested on the stack
+	lockResets       int   // lock delay restarts since lowestY
+	lowestY          int   // lowest row cur has reached
+	spawnX           int
+	finesse          finesse
+	// lateRotate is a rotation that failed on cur lateRotateAge frames ago,
+	// 1 or -1. If cur locks within lateRotateFrames of it, the next piece
+	// enters rotated that way.
+	lateRotate    int
+	lateRotateAge int
+}
+
+// Game is one game in progress.
+type Game struct {
+	board Board
+	// lockedAt is the frame, plus one, each cell's piece locked on, moving
+	// with its row; 0 where no piece locked. Like stats it never changes
+	// play, so Hash leaves it out.
+	lockedAt Board
+	// vanish and vanishAt are the hidden rows above the board and their
+	// lock frames; see VanishRows.
+	vanish   Board
+	vanishAt Board
+	width    int // columns in play
+	mode     Mode
+	queue    []int // the next QueueLen kinds, soonest first
+	deck     Randomizer
+	rng      *rand.Rand
+	src      *countedSource // rng's, counted for Save
+	score    int
+	lines    int
+	level    int
+	pieces   int // pieces spawned so far
+	combo    int // clearing locks in a row after the first, -1 for none
+	b2b      int // Tetrises and T-spin clears in a row after the first, -1 for none
+	pieceState
+	partner  *pieceState // the second player in co-op, nil otherwise
+	active   int         // which player pieceState belongs to
+	frames   int         // frames of play so far
+	gameOver bool
+	digStage int         // current Dig Quest formation
+	cheese   int         // garbage rows cleared in Cheese
+	prestige int         // Endless score rollovers this game
+	nearTops int         // near top outs toward the next ease, see Ruleset.Adaptive
+	ease     int         // levels of gravity eased so far
+	history  []placement // recent locks that can be undone, oldest first
+	incoming []garbageBatch
+	boss     *bossFight
+	won      bool // the mode's goal was reached
+	shapes   *PieceSet
+	stats    Stats
+	puzzle   *Puzzle
+	deal     []int  // a puzzle's pieces still to come
+	script   Script // the mode's, nil for built-in modes
+	rival    *Game  // the second player's game in versus, stepped with this one
+	opponent *Game  // in versus, the game this one attacks
+	// observers are the subscribers, see Subscribe.
+	observers []*Observer
+
+	// SoftDropFrames is the frames per row while soft dropping; 0 drops
+	// straight to the floor.
+	SoftDropFrames int
+	Hooks          Hooks
+}
+
+// New starts a game of mode m whose piece sequence is fixed by seed.
+func New(seed int64, m Mode) *Game {
+	g := newGame(seed, m)
+	if m.Versus {
+		// Both boards deal from the same seed, so neither gets the better
+		// pieces.
+		g.rival = newGame(seed, m)
+		g.opponent, g.rival.opponent = g.rival, g
+	}
+	return g
+}
+
+func newGame(seed int64, m Mode) *Game {
+	src := newCountedSource(seed)
+	g := &Game{
+		rng:        rand.New(src),
+		src:        src,
+		mode:       m,
+		combo:      -1,
+		b2b:        -1,
+		width:      m.Width,
+		board:      NewBoard(m.Width, m.Rows()),
+		lockedAt:   NewBoard(m.Width, m.Rows()),
+		vanish:     NewBoard(m.Width, VanishRows),
+		vanishAt:   NewBoard(m.Width, VanishRows),
+		level:      m.StartLevel,
+		pieceState: pieceState{hold: -1, spawnX: (m.Width - 4) / 2},
+		shapes:     &Shapes,
+		deck:       newDeck(m.Rules),
+	}
+	if m.Pieces != nil {
+		g.shapes = m.Pieces
+	}
+	if m.Script != nil {
+		g.script = m.Script()
+	}
+	if m.Coop {
+		g.spawnX = m.Width/4 - 2
+	}
+	if m.Dig && len(loadDigStages()) > 0 {
+		g.startDigStage(0)
+	}
+	if m.Boss {
+		g.boss = newBossFight()
+	}
+	g.fillCheese()
+	if m.Puzzle != "" {
+		if p := findPuzzle(m.Puzzle); p != nil {
+			g.startPuzzle(p)
+		}
+	}
+	for range QueueLen {
+		g.queue = append(g.queue, g.nextKind())
+	}
+	g.spawn()
+	if m.Coop {
+		g.partner = &pieceState{hold: -1, spawnX: 3*m.Width/4 - 2}
+		g.swapPlayers()
+		g.spawn()
+		g.swapPlayers()
+	}
+	return g
+}
+
+// swapPlayers exchanges the active player's piece with the partner's.
+func (g *Game) swapPlayers() {
+	g.pieceState, *g.partner = *g.partner, g.pieceState
+	g.active ^= 1
+}
+
+// Step advances one frame with an input per player, the partner or rival
+// moving second.
+func (g *Game) Step(ins ...Input) {
+	g.stepGame(ins...)
+	if g.rival != nil {
+		in := Input{}
+		if len(ins) > 1 {
+			in = ins[1]
+		}
+		g.rival.stepGame(in)
+	}
+}
+
+func (g *Game) stepGame(ins ...Input) {
+	g.frames++
+	g.ageGarbage()
+	g.updateGarbage()
+	g.updateBoss()
+	if g.gameOver {
+		return
+	}
+	g.step(ins[0])
+	if g.partner != nil && len(ins) > 1 && !g.gameOver {
+		g.swapPlayers()
+		g.step(ins[1])
+		g.swapPlayers()
+	}
+	g.checkRace()
+	if g.script != nil && !g.gameOver {
+		g.script.Frame(g)
+	}
+}
+
+// step advances the active player by one frame with the given input.
+func (g *Game) step(in Input) {
+	if in.Undo && g.undoPlacement() {
+		return
+	}
+	if g.spawnTimer > 0 {
+		// Between pieces: remember what was pressed for the next one.
+		g.buffered.Merge(in)
+		g.spawnTimer--
+		if g.spawnTimer == 0 {
+			g.buffered.mergeHeld(in)
+			if g.respawn {
+				g.respawn = false
+				g.spawnKind(g.cur.Kind)
+			} else {
+				g.spawn()
+			}
+			g.applyBuffered()
+		}
+		return
+	}
+
+	g.finesse.press(in)
+	if in.Hold {
+		g.holdPiece()
+	}
+	for i := 0; i < in.Shift && g.tryMove(1, 0); i++ {
+	}
+	for i := 0; i > in.Shift && g.tryMove(-1, 0); i-- {
+	}
+	g.ageLateRotate()
+	if in.RotCCW && !g.tryRotate(-1) {
+		g.missRotate(-1)
+	}
+	if in.RotCW && !g.tryRotate(1) {
+		g.missRotate(1)
+	}
+	if in.HardDrop {
+		g.HardDrop()
+	}
+	if g.spawnTimer > 0 || g.gameOver {
+		return
+	}
+
+	// Gravity and soft drop
+	g.dropFrameCounter++
+	if in.SoftDrop {
+		// faster drop when holding down, one row per SoftDropFrames
+		g.softDropCounter++
+		if g.softDropCounter >= g.SoftDropFrames {
+			g.softDropCounter = 0
+			if g.SoftDropFrames == 0 {
+				for g.tryMove(0, 1) {
+				}
+			} else {
+				g.tryMove(0, 1)
+			}
+		}
+		g.dropFrameCounter = 0
+	} else if g.dropFrameCounter >= g.dropFrames() {
+		g.tryMove(0, 1)
+		g.dropFrameCounter = 0
+	}
+
+	below := g.cur
+	below.Y++
+	if !g.collides(below) {
+		g.lockTimer = 0
+	} else if g.lockTimer++; g.lockTimer >= lockDelayFrames {
+		g.lockPiece()
+	}
+}
+
+// lateRotateFrames is how close to its lock a failed rotation can be and
+// still carry over to the next piece.
+const lateRotateFrames = 6
+
+// missRotate remembers a rotation cur couldn't make, in case it locks
+// before lateRotateFrames pass: at speed, a press meant for the next piece
+// lands a frame or two early.
+func (g *Game) missRotate(dir int) {
+	if !g.mode.Rules.NoRotation {
+		g.lateRotate, g.lateRotateAge = dir, 0
+	}
+}
+
+// ageLateRotate forgets a missed rotation once it's too old to carry over.
+func (g *Game) ageLateRotate() {
+	if g.lateRotate == 0 {
+		return
+	}
+	if g.lateRotateAge++; g.lateRotateAge > lateRotateFrames {
+		g.lateRotate, g.lateRotateAge = 0, 0
+	}
+}
+
+// applyBuffered replays inputs pressed during the spawn delay on the first
+// active frame of the new piece: hold first, then rotation, then hard drop.
+func (g *Game) applyBuffered() {
+	b := g.buffered
+	g.buffered = Input{}
+	if g.gameOver {
+		return
+	}
+	if b.Hold {
+		g.holdPiece()
+	}
+	switch {
+	case b.RotCW && !b.RotCCW:
+		g.finesse.keys++
+		g.tryRotate(1)
+	case b.RotCCW && !b.RotCW:
+		g.finesse.keys++
+		g.tryRotate(-1)
+	}
+	if b.HardDrop {
+		g.HardDrop()
+	}
+}
+
+func (g *Game) endGame(reason string) {
+	g.gameOver = true
+	g.Close()
+	slog.Info("game over", "reason", reason, "score", g.score, "lines", g.lines, "level", g.level, "pieces", g.pieces)
+	g.emitGameOver(reason)
+	if o := g.opponent; o != nil && !o.gameOver {
+		o.won = true
+		o.endGame("opponent topped out")
+	}
+}
+
+// End finishes the game early, as when a bot forfeits.
+func (g *Game) End(reason string) {
+	if !g.gameOver {
+		g.endGame(reason)
+	}
+}
+
+// Player is one player's piece as the front end sees it.
+type Player struct {
+	Piece    Piece
+	Hold     int // held kind, -1 for none
+	HoldUsed bool
+	// Spawning is set during the entry delay, when Piece isn't in play.
+	Spawning bool
+	// Fall is how far (0..1) the piece has come toward its next gravity
+	// step, for drawing it between rows.
+	Fall float64
+	// GhostY is the row a hard drop would leave the piece on.
+	GhostY int
+}
+
+// Players is 2 in co-op and 1 otherwise.
+func (g *Game) Players() int {
+	if g.partner != nil {
+		return 2
+	}
+	return 1
+}
+
+// Player returns player i's piece.
+func (g *Game) Player(i int) Player {
+	if i != g.active {
+		g.swapPlayers()
+		defer g.swapPlayers()
+	}
+	return Player{
+		Piece:    g.cur,
+		Hold:     g.hold,
+		HoldUsed: g.holdUsed,
+		Spawning: g.spawnTimer > 0,
+		Fall:     g.fallOffset(),
+		GhostY:   g.ghostY(),
+	}
+}
+
+// ghostY is the lowest row the current piece fits on straight down.
+func (g *Game) ghostY() int {
+	p := g.cur
+	for !g.collides(p) {
+		p.Y++
+	}
+	return p.Y - 1
+}
+
+// fallOffset is how far the current piece has slid toward its next
+// gravity step. Logic stays on the grid; this only smooths rendering at
+// low gravity.
+func (g *Game) fallOffset() float64 {
+	if g.gameOver || g.spawnTimer > 0 {
+		return 0
+	}
+	below := g.cur
+	below.Y++
+	if g.collides(below) {
+		return 0
+	}
+	return min(1, float64(g.dropFrameCounter)/float64(g.dropFrames()))
+}
+
+// Board returns a copy of the board.
+func (g *Game) Board() Board { return g.board.Clone() }
+
+// Cell is the board's cell at (x, y), 0 off the board. It reads one cell
+// without copying the board, for drawing.
+func (g *Game) Cell(x, y int) int {
+	if x < 0 || x >= g.width || y < 0 || y >= len(g.board) {
+		return 0
+	}
+	return g.board[y][x]
+}
+
+// SetBoard copies b over the board, for setting up scenes and tests, and
+// empties the vanish zone. Any of b outside the board is left out.
+func (g *Game) SetBoard(b Board) {
+	for y := range min(len(b), len(g.board)) {
+		copy(g.board[y], b[y])
+	}
+	g.lockedAt = NewBoard(g.width, g.Height())
+	g.clearVanish()
+}
+
+// Width is the number of columns in play.
+func (g *Game) Width() int { return g.width }
+
+// Height is the number of rows in play.
+func (g *Game) Height() int { return len(g.board) }
+
+func (g *Game) Mode() Mode      { return g.mode }
+func (g *Game) Next() int       { return g.queue[0] }
+func (g *Game) Score() int      { return g.score }
+func (g *Game) Lines() int      { return g.lines }
+func (g *Game) Level() int      { return g.level }
+func (g *Game) Pieces() int     { return g.pieces }
+func (g *Game) Frames() int     { return g.frames }
+func (g *Game) Prestige() int   { return g.prestige }
+func (g *Game) GameOver() bool  { return g.gameOver }
+func (g *Game) Won() bool       { return g.won }
+func (g *Game) BlitzStage() int { return g.blitzStage() }
+
+// Combo is the current combo: clearing locks in a row after the first.
+func (g *Game) Combo() int { return max(g.combo, 0) }
+
+// B2B is the current back-to-back chain: Tetrises and T-spin clears in a
+// row, not broken by other clears, after the first.
+func (g *Game) B2B() int { return max(g.b2b, 0) }
+
+// Queue returns the upcoming kinds, soonest first. In a puzzle it ends
+// with the puzzle's last piece.
+func (g *Game) Queue() []int {
+	q := g.queue
+	if g.puzzle != nil {
+		q = q[:min(len(q), g.puzzleLeft())]
+	}
+	return append([]int(nil), q...)
+}
+
+// Snapshot is a copy of the game as of the last step. It shares nothing
+// with the game, so it can be kept or worked on while the game goes on.
+type Snapshot struct {
+	Board    Board
+	Width    int
+	Next     int
+	Queue    []int
+	Players  []Player
+	Score    int
+	Lines    int
+	Level    int
+	Pieces   int
+	Frames   int
+	Prestige int
+	GameOver bool
+	Won      bool
+}
+
+func (g *Game) Snapshot() Snapshot {
+	s := Snapshot{
+		Board: g.board.Clone(), Width: g.width, Next: g.queue[0], Queue: g.Queue(),
+		Score: g.score, Lines: g.lines, Level: g.level, Pieces: g.pieces, Frames: g.frames,
+		Prestige: g.prestige, GameOver: g.gameOver, Won: g.won,
+	}
+	for i := range g.Players() {
+		s.Players = append(s.Players, g.Player(i))
+	}
+	return s
+}
@@ -0,0 +1,410 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+var testMode = Mode{Name: "Test", Width: BoardW}
+
+// fillRow fills row y except the columns in gaps.
+func fillRow(g *Game, y int, gaps ...int) {
+	for x := range g.width {
+		if !slices.Contains(gaps, x) {
+			g.board[y][x] = GarbageCell
+		}
+	}
+}
+
+// setPiece swaps the falling piece for kind in rotation rot at (x, y).
+func setPiece(g *Game, kind, rot, x, y int) {
+	g.cur = Piece{Kind: kind, Rot: rot, X: x, Y: y}
+	g.lockTimer, g.lockResets, g.lowestY = 0, 0, y
+}
+
+// place drops kind in rotation rot at column x and locks it.
+func place(g *Game, kind, rot, x int) {
+	setPiece(g, kind, rot, x, 0)
+	g.HardDrop()
+	for g.spawnTimer > 0 {
+		g.Step(Input{})
+	}
+}
+
+func TestStepAndSnapshot(t *testing.T) {
+	g := New(1, testMode)
+	kind, y := g.cur.Kind, g.cur.Y
+	for range gravityFrames(0) {
+		g.Step(Input{})
+	}
+	s := g.Snapshot()
+	if s.Frames != gravityFrames(0) || s.Players[0].Piece.Y != y+1 || s.Players[0].Piece.Kind != kind {
+		t.Fatalf("after %d frames: frames %d, piece %+v; want one row of gravity", gravityFrames(0), s.Frames, s.Players[0].Piece)
+	}
+	if len(s.Queue) != QueueLen || s.Next != s.Queue[0] {
+		t.Errorf("queue %v, next %d", s.Queue, s.Next)
+	}
+
+	g.Step(Input{Shift: -2})
+	if got := g.Player(0).Piece.X; got != s.Players[0].Piece.X-2 {
+		t.Errorf("shift -2 moved to x %d from %d", got, s.Players[0].Piece.X)
+	}
+
+	// The snapshot shares nothing with the game.
+	s.Board[0][0], s.Queue[0] = 5, -1
+	if g.board[0][0] != 0 || g.queue[0] == -1 {
+		t.Error("changing a snapshot changed the game")
+	}
+}
+
+func TestClassicGravity(t *testing.T) {
+	for _, tc := range []struct{ level, frames int }{{0, 48}, {9, 6}, {18, 3}, {19, 2}, {29, 1}} {
+		m := testMode
+		m.StartLevel, m.Rules.Gravity = tc.level, GravityClassic
+		g := New(1, m)
+		y := g.cur.Y
+		for range tc.frames - 1 {
+			g.Step(Input{})
+		}
+		if g.cur.Y != y {
+			t.Errorf("level %d: fell before %d frames", tc.level, tc.frames)
+		}
+		g.Step(Input{})
+		if g.cur.Y != y+1 {
+			t.Errorf("level %d: no fall after %d frames", tc.level, tc.frames)
+		}
+	}
+}
+
+func TestHashIsDeterministic(t *testing.T) {
+	inputs := []Input{{Shift: -1}, {RotCW: true}, {}, {HardDrop: true}, {Hold: true}, {Shift: 3, SoftDrop: true}, {RotCCW: true}, {HardDrop: true}}
+	play := func(seed int64) []uint64 {
+		g := New(seed, testMode)
+		var hashes []uint64
+		for i := range 300 {
+			g.Step(inputs[i%len(inputs)])
+			hashes = append(hashes, g.Hash())
+		}
+		return hashes
+	}
+	a, b := play(42), play(42)
+	if !slices.Equal(a, b) {
+		t.Fatal("the same seed and inputs hashed differently")
+	}
+	if c := play(43); slices.Equal(a, c) {
+		t.Error("a different seed hashed the same")
+	}
+	if a[0] == a[len(a)-1] {
+		t.Error("the hash didn't change over 300 frames of play")
+	}
+}
+
+func TestHold(t *testing.T) {
+	g := New(1, testMode)
+	first, next := g.cur.Kind, g.queue[0]
+	g.Step(Input{Hold: true})
+	if p := g.Player(0); p.Hold != first || p.Piece.Kind != next || !p.HoldUsed {
+		t.Fatalf("after hold: %+v, want %d held and %d falling", p, first, next)
+	}
+	g.Step(Input{Hold: true})
+	if p := g.Player(0); p.Hold != first || p.Piece.Kind != next {
+		t.Fatal("a second hold on the same piece swapped again")
+	}
+
+	g.Step(Input{HardDrop: true})
+	for g.spawnTimer > 0 {
+		g.Step(Input{})
+	}
+	if g.Player(0).HoldUsed {
+		t.Fatal("hold still used on the next piece")
+	}
+	cur := g.cur.Kind
+	g.Step(Input{Hold: true})
+	if p := g.Player(0); p.Piece.Kind != first || p.Hold != cur {
+		t.Errorf("swap gave %d falling and %d held, want %d and %d", p.Piece.Kind, p.Hold, first, cur)
+	}
+}
+
+func TestNoHoldBetweenPieces(t *testing.T) {
+	mode := testMode
+	mode.Rules.NoHold = true
+	for _, in := range []Input{{Hold: true}, {HoldHeld: true}} {
+		g := New(1, mode)
+		g.Step(Input{HardDrop: true})
+		for g.spawnTimer > 0 {
+			g.Step(in)
+		}
+		cur := g.cur.Kind
+		g.Step(in)
+		if p := g.Player(0); p.Hold != -1 || p.Piece.Kind != cur {
+			t.Errorf("%+v held %d with hold off", in, p.Hold)
+		}
+	}
+}
+
+func TestLineClearAndLevelUp(t *testing.T) {
+	g := New(1, testMode)
+	var cleared []ClearedRow
+	levelUp := -1
+	g.Hooks.Cleared = func(rows []ClearedRow) { cleared = rows }
+	g.Hooks.LevelUp = func(level int) { levelUp = level }
+	g.lines = 9
+	fillRow(g, BoardH-1, 0, 1, 2, 3)
+	fillRow(g, BoardH-2, 0, 1, 2, 3, 4)
+	place(g, 0, 0, 0) // a flat I into the gap
+
+	if g.lines != 10 || g.level != 1 || levelUp != 1 {
+		t.Errorf("lines %d, level %d, LevelUp(%d); want 10, 1, 1", g.lines, g.level, levelUp)
+	}
+	if len(cleared) != 1 || cleared[0].Y != BoardH-1 {
+		t.Errorf("Cleared reported %v, want the bottom row", cleared)
+	}
+	// 40 for a single, at the new level's multiplier.
+	if g.score != 40*2 {
+		t.Errorf("score %d, want 80", g.score)
+	}
+	// The row above came down, gap and all.
+	if g.board[BoardH-1][4] != 0 || g.board[BoardH-1][5] != GarbageCell || g.board[BoardH-2][5] != 0 {
+		t.Errorf("bottom rows after the clear: %v / %v", g.board[BoardH-2][:g.width], g.board[BoardH-1][:g.width])
+	}
+}
+
+func TestStartLevel(t *testing.T) {
+	m := testMode
+	m.StartLevel = 5
+	g := New(1, m)
+	if g.Level() != 5 {
+		t.Fatalf("level %d at the start, want 5", g.Level())
+	}
+	g.lines = 40
+	fillRow(g, BoardH-1, 0, 1, 2, 3)
+	place(g, 0, 0, 0)
+	if g.Level() != 5 {
+		t.Errorf("level %d at 41 lines, want 5 until the lines catch up", g.Level())
+	}
+	g.lines = 59
+	fillRow(g, BoardH-1, 0, 1, 2, 3)
+	place(g, 0, 0, 0)
+	if g.Level() != 6 {
+		t.Errorf("level %d at 60 lines, want 6", g.Level())
+	}
+}
+
+func TestUndoPlacement(t *testing.T) {
+	g := New(1, Mode{Name: "Zen", Width: BoardW, Zen: true})
+	before := g.Snapshot()
+	kind := g.cur.Kind
+	g.Step(Input{HardDrop: true})
+	if g.Pieces() == before.Pieces && g.Board().Equal(before.Board) {
+		t.Fatal("the hard drop didn't lock")
+	}
+	g.Step(Input{Undo: true})
+	after := g.Snapshot()
+	if !after.Board.Equal(before.Board) || after.Pieces != before.Pieces || after.Score != before.Score ||
+		!slices.Equal(after.Queue, before.Queue) {
+		t.Error("undo didn't restore the game from before the lock")
+	}
+	if p := after.Players[0].Piece; p.Kind != kind || p.X != g.spawnX || p.Y != 0 || p.Rot != 0 {
+		t.Errorf("undo returned %+v, want the %d piece at spawn", p, kind)
+	}
+	if g.undoPlacement() {
+		t.Error("undid a second time with nothing left to take back")
+	}
+
+	m := New(1, testMode)
+	m.Step(Input{HardDrop: true})
+	b := m.Board()
+	m.Step(Input{Undo: true})
+	if !m.Board().Equal(b) {
+		t.Error("undo worked outside Zen")
+	}
+}
+
+// resting reports whether the falling piece is on the stack or floor.
+func resting(g *Game) bool {
+	below := g.cur
+	below.Y++
+	return g.collides(below)
+}
+
+// locks counts the pieces g locks.
+func locks(g *Game) *int {
+	n := new(int)
+	g.Hooks.Locked = func(int, int, bool) { *n++ }
+	return n
+}
+
+func TestLockDelay(t *testing.T) {
+	g := New(1, testMode)
+	locked := locks(g)
+	setPiece(g, 1, 0, 3, BoardH-3)
+	for i := 1; i < lockDelayFrames; i++ {
+		g.Step(Input{})
+	}
+	if *locked != 0 {
+		t.Fatalf("locked before %d frames on the floor", lockDelayFrames)
+	}
+	g.Step(Input{})
+	if *locked != 1 {
+		t.Errorf("not locked after %d frames on the floor", lockDelayFrames)
+	}
+}
+
+func TestLockResetCap(t *testing.T) {
+	g := New(1, testMode)
+	locked := locks(g)
+	setPiece(g, 1, 0, 3, BoardH-3)
+	// Shuffling left and right restarts the delay on every frame but the
+	// first, until the resets run out.
+	want := 1 + maxLockResets + lockDelayFrames - 1
+	frames := 0
+	for *locked == 0 && frames < 200 {
+		frames++
+		g.Step(Input{Shift: 1 - 2*(frames%2)})
+	}
+	if frames != want {
+		t.Errorf("locked after %d frames of shuffling, want %d", frames, want)
+	}
+}
+
+func TestLockResetsOnANewRow(t *testing.T) {
+	g := New(1, testMode)
+	locked := locks(g)
+	// An O resting on a two-cell ledge, out of resets.
+	g.board[13][1], g.board[13][2] = GarbageCell, GarbageCell
+	setPiece(g, 1, 0, 0, 10)
+	g.SoftDropFrames = 1
+	g.lockTimer, g.lockResets = 10, maxLockResets
+	g.Step(Input{Shift: 2, SoftDrop: true})
+	if g.cur.Y != 11 || g.lowestY != 11 || g.lockResets != 0 {
+		t.Fatalf("off the ledge: y %d, lowest %d, resets %d; want 11, 11, 0", g.cur.Y, g.lowestY, g.lockResets)
+	}
+	// Down on the floor, moving restarts the delay again.
+	for g.cur.Y < BoardH-3 {
+		g.Step(Input{SoftDrop: true})
+	}
+	g.Step(Input{})
+	g.Step(Input{Shift: 1})
+	if g.lockTimer != 1 || g.lockResets != 1 || *locked != 0 {
+		t.Errorf("after moving on the floor: timer %d, resets %d, locks %d; want 1, 1, 0", g.lockTimer, g.lockResets, *locked)
+	}
+}
+
+func TestSoftDropDoesNotLock(t *testing.T) {
+	for _, frames := range []int{0, 1} {
+		g := New(1, testMode)
+		g.SoftDropFrames = frames
+		locked := locks(g)
+		for !resting(g) {
+			g.Step(Input{SoftDrop: true})
+		}
+		// The landing frame counts toward the delay.
+		for i := 2; i < lockDelayFrames; i++ {
+			g.Step(Input{SoftDrop: true})
+		}
+		if *locked != 0 {
+			t.Errorf("soft drop at %d frames/row locked on contact", frames)
+		}
+		g.Step(Input{SoftDrop: true})
+		if *locked != 1 {
+			t.Errorf("soft drop at %d frames/row never locked", frames)
+		}
+	}
+}
+
+func TestGhostY(t *testing.T) {
+	g := New(1, testMode)
+	setPiece(g, 1, 0, 3, 0)
+	if got := g.Player(0).GhostY; got != BoardH-3 {
+		t.Errorf("ghost on the empty board at row %d, want %d", got, BoardH-3)
+	}
+	g.board[10][4] = GarbageCell
+	if got := g.Player(0).GhostY; got != 7 {
+		t.Errorf("ghost over a block at row 10 at row %d, want 7", got)
+	}
+	g.HardDrop()
+	if g.board[9][4] == 0 || g.board[8][4] == 0 {
+		t.Error("the hard drop didn't land where the ghost was")
+	}
+}
+
+func TestBoardSize(t *testing.T) {
+	g := New(1, Mode{Name: "Big", Width: 5, Height: 10})
+	if g.Width() != 5 || g.Height() != 10 || g.Board().Height() != 10 || g.Board().Width() != 5 {
+		t.Fatalf("board is %dx%d, want 5x10", g.Board().Width(), g.Board().Height())
+	}
+	fillRow(g, 9, 4)
+	place(g, 0, 1, 2) // an upright I down the gap
+	if g.Lines() != 1 {
+		t.Errorf("%d lines cleared on the small board", g.Lines())
+	}
+	g.raiseGarbage(2, 0)
+	if g.board[9][0] != 0 || g.board[9][1] != GarbageCell || g.board[8][1] != GarbageCell {
+		t.Errorf("garbage didn't rise from the small board's floor: %v", g.board[8:])
+	}
+
+	tall := New(1, Mode{Name: "Tall", Width: BoardW, Height: MaxBoardH})
+	if got := tall.Player(0).GhostY; got < BoardH {
+		t.Errorf("ghost on the tall board at row %d, want near its floor", got)
+	}
+}
+
+func TestInitialRotationAndHold(t *testing.T) {
+	g := New(1, testMode)
+	g.Step(Input{HardDrop: true})
+	for g.spawnTimer > 1 {
+		g.Step(Input{})
+	}
+	g.Step(Input{RotCWHeld: true})
+	if g.cur.Rot != 1 {
+		t.Errorf("rotate held at spawn left rotation %d, want 1", g.cur.Rot)
+	}
+
+	g = New(1, testMode)
+	g.Step(Input{HardDrop: true})
+	next := g.queue[0]
+	for g.spawnTimer > 1 {
+		g.Step(Input{RotCCWHeld: true}) // held before spawn doesn't count twice
+	}
+	g.Step(Input{HoldHeld: true})
+	if p := g.Player(0); p.Hold != next || !p.HoldUsed || p.Piece.Rot != 0 {
+		t.Errorf("hold held at spawn: %+v, want %d held", p, next)
+	}
+}
+
+func TestLateRotationCarriesOver(t *testing.T) {
+	for _, tc := range []struct {
+		wait int
+		want int
+	}{{1, 1}, {lateRotateFrames + 2, 0}} {
+		g := New(1, testMode)
+		// An I flat on the floor under full rows can't turn upright.
+		for y := BoardH - 6; y < BoardH-1; y++ {
+			fillRow(g, y)
+		}
+		setPiece(g, 0, 0, 3, BoardH-2)
+		g.Step(Input{RotCW: true})
+		if g.cur.Rot != 0 {
+			t.Fatal("the I rotated in a spot with no room")
+		}
+		for range tc.wait {
+			g.Step(Input{})
+		}
+		g.Step(Input{HardDrop: true})
+		for g.spawnTimer > 0 {
+			g.Step(Input{})
+		}
+		if g.cur.Rot != tc.want {
+			t.Errorf("locking %d frames after a failed rotation: next piece at rotation %d, want %d", tc.wait, g.cur.Rot, tc.want)
+		}
+	}
+}
+
+func TestPlayerDoesNotAllocate(t *testing.T) {
+	g := New(1, testMode)
+	fillRow(g, BoardH-1, 0)
+	// The front end reads players, ghost included, every frame.
+	if n := testing.AllocsPerRun(100, func() { g.Player(0) }); n != 0 {
+		t.Errorf("Player allocates %v times a call", n)
+	}
+}
@@ -0,0 +1,87 @@
+package engine
+
+import "slices"
+
+// Observer follows a game's events. Any number can subscribe alongside
+// Hooks, so audio, stats and the network each keep to their own type;
+// embed NopObserver to handle only some events.
+type Observer interface {
+	// OnLineClear reports the rows a lock removed, as they were.
+	OnLineClear(rows []ClearedRow)
+	// OnLock reports a piece locking and the rows it cleared.
+	OnLock(player, cleared int)
+	// OnTSpin reports a lock that was a T-spin, after its OnLock.
+	OnTSpin(player, cleared int)
+	// OnAttack reports the garbage rows a lock sent, after cancelling
+	// the incoming.
+	OnAttack(rows int)
+	// OnLevelUp reports the level rising after a clear.
+	OnLevelUp(level int)
+	// OnGameOver reports the end of the game and why.
+	OnGameOver(reason string)
+}
+
+// NopObserver ignores every event.
+type NopObserver struct{}
+
+func (NopObserver) OnLineClear([]ClearedRow) {}
+func (NopObserver) OnLock(int, int)          {}
+func (NopObserver) OnTSpin(int, int)         {}
+func (NopObserver) OnAttack(int)             {}
+func (NopObserver) OnLevelUp(int)            {}
+func (NopObserver) OnGameOver(string)        {}
+
+// Subscribe sends the game's events to o, in subscription order after
+// Hooks, until the returned func is called.
+func (g *Game) Subscribe(o Observer) (unsubscribe func()) {
+	s := &o // matched by pointer, as observers needn't be comparable
+	g.observers = append(g.observers, s)
+	return func() {
+		g.observers = slices.DeleteFunc(g.observers, func(x *Observer) bool { return x == s })
+	}
+}
+
+func (g *Game) emitLineClear(rows []ClearedRow) {
+	if g.Hooks.Cleared != nil {
+		g.Hooks.Cleared(rows)
+	}
+	for _, o := range g.observers {
+		(*o).OnLineClear(rows)
+	}
+}
+
+func (g *Game) emitLock(cleared int, tspin bool) {
+	if g.Hooks.Locked != nil {
+		g.Hooks.Locked(g.active, cleared, tspin)
+	}
+	for _, o := range g.observers {
+		(*o).OnLock(g.active, cleared)
+		if tspin {
+			(*o).OnTSpin(g.active, cleared)
+		}
+	}
+}
+
+func (g *Game) emitAttack(rows int) {
+	for _, o := range g.observers {
+		(*o).OnAttack(rows)
+	}
+}
+
+func (g *Game) emitLevelUp(level int) {
+	if g.Hooks.LevelUp != nil {
+		g.Hooks.LevelUp(level)
+	}
+	for _, o := range g.observers {
+		(*o).OnLevelUp(level)
+	}
+}
+
+func (g *Game) emitGameOver(reason string) {
+	if g.Hooks.GameOver != nil {
+		g.Hooks.GameOver(reason)
+	}
+	for _, o := range g.observers {
+		(*o).OnGameOver(reason)
+	}
+}
@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// eventLog records the events it sees, in order.
+type eventLog struct {
+	NopObserver
+	events []string
+}
+
+func (l *eventLog) add(format string, a ...any) {
+	l.events = append(l.events, fmt.Sprintf(format, a...))
+}
+
+func (l *eventLog) OnLineClear(rows []ClearedRow) { l.add("clear %d", len(rows)) }
+func (l *eventLog) OnLock(_, cleared int)         { l.add("lock %d", cleared) }
+func (l *eventLog) OnTSpin(_, cleared int)        { l.add("tspin %d", cleared) }
+func (l *eventLog) OnLevelUp(level int)           { l.add("level %d", level) }
+func (l *eventLog) OnGameOver(reason string)      { l.add("over %s", reason) }
+
+func TestObservers(t *testing.T) {
+	g := New(1, testMode)
+	var hooked bool
+	g.Hooks.Locked = func(int, int, bool) { hooked = true }
+	a, b := &eventLog{}, &eventLog{}
+	g.Subscribe(a)
+	cancel := g.Subscribe(b)
+
+	g.lines = 9
+	fillRow(g, BoardH-1, 0, 1, 2, 3)
+	place(g, 0, 0, 0)
+	want := []string{"clear 1", "level 1", "lock 1"}
+	if !slices.Equal(a.events, want) || !slices.Equal(b.events, want) {
+		t.Errorf("events %v and %v, want %v", a.events, b.events, want)
+	}
+	if !hooked {
+		t.Error("Hooks stopped hearing locks once observers subscribed")
+	}
+
+	cancel()
+	place(g, 1, 0, 0)
+	if len(a.events) != 4 || len(b.events) != 3 {
+		t.Errorf("after unsubscribing b: %v and %v", a.events, b.events)
+	}
+	g.endGame("test")
+	if a.events[len(a.events)-1] != "over test" {
+		t.Errorf("last event %q, want the game over", a.events[len(a.events)-1])
+	}
+}
+
+func TestTSpinEvent(t *testing.T) {
+	g := New(1, testMode)
+	// The slot from TestTSpinTripleKick.
+	const x, y = 3, 15
+	for row := y + 2; row <= y+4; row++ {
+		fillRow(g, row)
+	}
+	for _, c := range []Point{{x, y + 2}, {x, y + 3}, {x + 1, y + 3}, {x, y + 4}} {
+		g.board[c.Y][c.X] = 0
+	}
+	g.board[y][x] = GarbageCell
+	setPiece(g, 2, 0, x, y)
+	l := &eventLog{}
+	g.Subscribe(l)
+	g.tryRotate(1)
+	g.HardDrop()
+	if !slices.Equal(l.events, []string{"clear 3", "lock 3", "tspin 3"}) {
+		t.Errorf("events %v, want a T-spin triple", l.events)
+	}
+}
@@ -0,0 +1,150 @@
+package engine
+
+import "slices"
+
+// GarbageCell is the board value of a garbage block, after the seven
+// piece kinds.
+const GarbageCell = 8
+
+// GarbageDelayFrames is how long queued garbage waits before it can rise,
+// the window the player has to cancel it with an attack of their own.
+const GarbageDelayFrames = 60
+
+// AttackTable is how much garbage clears send, to the opponent in versus
+// or at the boss.
+type AttackTable struct {
+	Clears [5]int // by rows cleared
+	TSpins [4]int // T-spin clears by rows cleared
+	B2B    int    // added to a back-to-back Tetris or T-spin clear
+	// Combo is added by combo count, the first clearing lock being 0.
+	// Longer combos get the last entry.
+	Combo []int
+}
+
+// GuidelineAttack is the usual versus table: a double sends one, a triple
+// two, a Tetris four, T-spins double the rows, plus one for back-to-back
+// and more for a long combo.
+var GuidelineAttack = AttackTable{
+	Clears: [5]int{0, 0, 1, 2, 4},
+	TSpins: [4]int{0, 2, 4, 6},
+	B2B:    1,
+	Combo:  []int{0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 4, 5},
+}
+
+// attack is what the lock just scored by clearLines sends.
+func (g *Game) attack(cleared int, tspin bool) int {
+	t := g.mode.Rules.Attack
+	if t == nil {
+		t = &GuidelineAttack
+	}
+	if cleared == 0 {
+		return 0
+	}
+	n := t.Clears[min(cleared, len(t.Clears)-1)]
+	if tspin {
+		n = t.TSpins[min(cleared, len(t.TSpins)-1)]
+	}
+	if g.b2b > 0 {
+		n += t.B2B
+	}
+	if len(t.Combo) > 0 {
+		n += t.Combo[min(g.combo, len(t.Combo)-1)]
+	}
+	return n
+}
+
+// garbageBatch is incoming garbage waiting to rise: rows sharing one hole
+// column, or a random one when hole is negative, and the frames left
+// before they can.
+type garbageBatch struct {
+	rows, hole, wait int
+}
+
+func (g *Game) queueGarbage(rows, hole int) {
+	g.incoming = append(g.incoming, garbageBatch{rows, hole, GarbageDelayFrames})
+}
+
+// ageGarbage counts down the incoming batches' delay, once a frame.
+func (g *Game) ageGarbage() {
+	for i := range g.incoming {
+		g.incoming[i].wait = max(g.incoming[i].wait-1, 0)
+	}
+}
+
+// IncomingRows is the garbage queued to rise.
+func (g *Game) IncomingRows() int {
+	n := 0
+	for _, b := range g.incoming {
+		n += b.rows
+	}
+	return n
+}
+
+// ReadyRows is the incoming garbage that has waited out its delay and
+// rises on the next lock that clears nothing.
+func (g *Game) ReadyRows() int {
+	n := 0
+	for _, b := range g.incoming {
+		if b.wait == 0 {
+			n += b.rows
+		}
+	}
+	return n
+}
+
+// settleGarbage runs after each lock. The lock's attack cancels incoming
+// garbage oldest first, and whatever is left over is returned to be sent.
+// A lock that cleared nothing lets the garbage that's done waiting rise.
+func (g *Game) settleGarbage(attack int, cleared bool) (sent int) {
+	for attack > 0 && len(g.incoming) > 0 {
+		n := min(attack, g.incoming[0].rows)
+		attack -= n
+		g.incoming[0].rows -= n
+		if g.incoming[0].rows == 0 {
+			g.incoming = g.incoming[1:]
+		}
+	}
+	if !cleared {
+		for len(g.incoming) > 0 && g.incoming[0].wait == 0 {
+			b := g.incoming[0]
+			g.incoming = g.incoming[1:]
+			g.raiseGarbage(b.rows, b.hole)
+		}
+	}
+	return attack
+}
+
+// raiseGarbage pushes the stack up and fills the bottom rows with garbage
+// that has one hole column (random when hole is negative). Falling pieces
+// are lifted with it, and a stack pushed up through the vanish zone ends
+// the game, or in Zen is forgiven.
+func (g *Game) raiseGarbage(rows, hole int) {
+	if hole < 0 || hole >= g.width {
+		hole = g.rng.Intn(g.width)
+	}
+	for ; rows > 0 && !g.gameOver; rows-- {
+		stack, ats := g.stack()
+		if slices.ContainsFunc(stack[0], func(c int) bool { return c != 0 }) {
+			if !g.forgive() {
+				g.endGame("top out")
+				return
+			}
+			stack, ats = g.stack()
+		}
+		bottom, at := stack[0], ats[0]
+		copy(stack, stack[1:])
+		copy(ats, ats[1:])
+		stack[len(stack)-1], ats[len(ats)-1] = bottom, at
+		g.setStack(stack, ats)
+		for x := range bottom {
+			bottom[x], at[x] = GarbageCell, 0
+		}
+		bottom[hole] = 0
+		g.liftPiece()
+		if g.partner != nil {
+			g.swapPlayers()
+			g.liftPiece()
+			g.swapPlayers()
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"hash/fnv"
+	"strconv"
+)
+
+// Hash is a stable digest of everything that affects future gameplay, for
+// checking that a replay or a refactor plays out exactly the same.
+func (g *Game) Hash() uint64 {
+	h := fnv.New64a()
+	var buf []byte
+	put := func(vs ...int) {
+		for _, v := range vs {
+			buf = strconv.AppendInt(buf, int64(v), 10)
+			buf = append(buf, ',')
+		}
+	}
+	for y := range g.board {
+		put(g.board[y]...)
+	}
+	for y := range g.vanish {
+		put(g.vanish[y]...)
+	}
+	put(g.width)
+	put(g.queue...)
+	deck := g.deck.State()
+	put(len(deck))
+	put(deck...)
+	for _, b := range g.incoming {
+		put(b.rows, b.hole, b.wait)
+	}
+	if b := g.boss; b != nil {
+		put(b.phase, b.hp, b.step, b.wait)
+	}
+	put(g.score, g.lines, g.level, g.pieces, g.frames, g.digStage, g.prestige, b2i(g.gameOver), b2i(g.won))
+	put(g.combo, g.b2b, g.cheese, g.nearTops, g.ease)
+	players := []*pieceState{&g.pieceState}
+	if g.partner != nil {
+		players = append(players, g.partner)
+	}
+	for _, ps := range players {
+		put(ps.cur.Kind, ps.cur.Rot, ps.cur.X, ps.cur.Y, ps.dropFrameCounter, ps.softDropCounter)
+		put(ps.hold, b2i(ps.holdUsed), b2i(ps.lastRotated), ps.spawnTimer, b2i(ps.respawn))
+		put(ps.lockTimer, ps.lockResets, ps.lowestY)
+		put(ps.buffered.presses(), ps.lateRotate, ps.lateRotateAge)
+	}
+	if g.rival != nil {
+		buf = strconv.AppendUint(buf, g.rival.Hash(), 10)
+	}
+	h.Write(buf)
+	return h.Sum64()
+}
+
+// presses packs the buffered one-shot presses as bits, in the order the
+// front end's input logs use.
+func (i Input) presses() int {
+	return b2i(i.RotCW) | b2i(i.RotCCW)<<1 | b2i(i.HardDrop)<<2 | b2i(i.Hold)<<3
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,38 @@
+package engine
+
+// Memory hides the stack, so it has to be played from memory. The game
+// underneath is unchanged; the front end draws it by LockAge.
+type Memory int
+
+const (
+	MemoryOff Memory = iota
+	// MemoryInvisible fades each locked block out a few seconds after it
+	// locks, as in TGM's invisible challenge.
+	MemoryInvisible
+	// MemoryFlash hides the stack but for a moment after each lock.
+	MemoryFlash
+)
+
+// LockAge is the frames since a piece locked the cell at (x, y), or -1 if
+// the cell is empty or no piece put it there, as with garbage, gems and a
+// puzzle's set-up.
+func (g *Game) LockAge(x, y int) int {
+	if y < 0 || y >= len(g.lockedAt) || x < 0 || x >= g.width || g.lockedAt[y][x] == 0 || g.board[y][x] == 0 {
+		return -1
+	}
+	return g.frames - (g.lockedAt[y][x] - 1)
+}
+
+// lockedNow records every piece cell of b as locked this frame, for rows
+// whose lock frames are lost, such as a restored board.
+func (g *Game) lockedNow(b Board) Board {
+	at := NewBoard(g.width, b.Height())
+	for y, row := range b {
+		for x, c := range row[:g.width] {
+			if c > 0 && c <= len(g.shapes) {
+				at[y][x] = g.frames + 1
+			}
+		}
+	}
+	return at
+}
@@ -0,0 +1,28 @@
+package engine
+
+import "testing"
+
+func TestLockAge(t *testing.T) {
+	g := New(1, testMode)
+	fillRow(g, BoardH-1, 4, 5)
+	place(g, 1, 0, 3) // an O: its bottom half clears the row
+	if g.LockAge(4, BoardH-2) != -1 || g.LockAge(4, BoardH-1) < 0 {
+		t.Fatalf("after the clear, ages %d above %d, want the O's top half to have moved down",
+			g.LockAge(4, BoardH-2), g.LockAge(4, BoardH-1))
+	}
+	age := g.LockAge(4, BoardH-1)
+	for range 30 {
+		g.Step(Input{})
+	}
+	if got := g.LockAge(4, BoardH-1); got != age+30 {
+		t.Errorf("30 frames on, age %d, want %d", got, age+30)
+	}
+
+	g.raiseGarbage(1, 0)
+	if g.LockAge(4, BoardH-2) != age+30 || g.LockAge(1, BoardH-1) != -1 {
+		t.Errorf("garbage raised: block's age %d, garbage's %d", g.LockAge(4, BoardH-2), g.LockAge(1, BoardH-1))
+	}
+	if g.LockAge(0, 0) != -1 || g.LockAge(-1, 0) != -1 {
+		t.Error("an empty or off-board cell has an age")
+	}
+}
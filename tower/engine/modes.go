@@ -0,0 +1,133 @@
+package engine
+
+import "fmt"
+
+// Mode is a way to play: the board it uses and who plays on it.
+type Mode struct {
+	Name  string
+	Width int // board columns, at most MaxBoardW
+	// Height is the board's rows, at most MaxBoardH; 0 is BoardH.
+	Height int
+	Coop   bool // two players share the board, each with their own piece
+	// Versus gives each of two players their own board. Clears send
+	// garbage to the other, and the first to top out loses.
+	Versus bool
+	// Flip mirrors or turns the drawn board from time to time; the game
+	// underneath is unchanged.
+	Flip bool
+	// Memory hides locked blocks, fading them out or flashing the stack.
+	Memory Memory
+	// Blitz ramps gravity, garbage and score multiplier every
+	// blitzStageFrames, whatever the line count.
+	Blitz bool
+	// Dig plays the Dig Quest formations: dig out every gem to move on.
+	Dig bool
+	// Boss fights the scripted bossPhases: send garbage to empty each
+	// health bar while cancelling the boss's attacks.
+	Boss bool
+	// Endless rolls the score over into a prestige rank at prestigeCap,
+	// each rank making the game harder.
+	Endless bool
+	// Cheese is downstacking practice: the board starts with this many
+	// rows of garbage, each with its own gap, and every one cleared is
+	// replaced from below.
+	Cheese int
+	// Puzzle plays the named puzzle, embedded or added with AddPuzzle:
+	// its board, its pieces in order, and its goal.
+	Puzzle string
+	// Zen keeps gravity at its slowest, leaves the leaderboard alone, lets
+	// the last undoDepth placements be undone, and never ends: topping out
+	// clears the top of the board instead.
+	Zen bool
+	// StartLevel is the level play begins at. The level stays there until
+	// the line count catches up with it.
+	StartLevel int
+	// LineGoal, if set, is a Sprint: the game is won on clearing that many
+	// lines, and the time taken is what counts.
+	LineGoal int
+	// TimeLimit, if set, is an Ultra: the game ends after that many frames
+	// and the score is what counts.
+	TimeLimit int
+	Rules     Ruleset
+	// Pieces replaces the standard tetrominoes, as piece set mods do.
+	Pieces *PieceSet
+	// Script, if set, makes the script each game of the mode runs, for
+	// modes defined outside the engine.
+	Script func() Script
+}
+
+// Rows is the height of the mode's board.
+func (m Mode) Rows() int {
+	if m.Height <= 0 {
+		return BoardH
+	}
+	return min(m.Height, MaxBoardH)
+}
+
+// Ruleset holds flags that change the core rules, checked where the rule
+// applies rather than by mode name.
+type Ruleset struct {
+	// NoRotation ignores rotation input.
+	NoRotation bool
+	// RandomSpawnRotation enters each piece in a random orientation.
+	RandomSpawnRotation bool
+	// NoHold ignores hold input.
+	NoHold bool
+	// Gravity is the curve of fall speed by level.
+	Gravity Gravity
+	// Attack is the garbage clears send; nil uses GuidelineAttack.
+	Attack *AttackTable
+	// Randomizer deals the pieces.
+	Randomizer RandomizerKind
+
+	// GravityCap, if set, is the fewest frames per row pieces fall at,
+	// however high the level.
+	GravityCap int
+	// EasyStart keeps S and Z out of the opening pieces; see
+	// easyStartPieces.
+	EasyStart bool
+	// Adaptive eases gravity by easeLevels every easeAfter times the stack
+	// comes within nearTopRows of the top.
+	Adaptive bool
+}
+
+// Gravity picks how fast pieces fall at each level.
+type Gravity int
+
+const (
+	// GravityLinear takes two frames off per level, down to two.
+	GravityLinear Gravity = iota
+	// GravityClassic follows the NES table: slow early levels, then a
+	// steep climb to a row every frame at 29.
+	GravityClassic
+)
+
+// Status is the panel line under Lines: the level, or the mode's own
+// progress.
+func (g *Game) Status() string {
+	format, args := g.StatusFormat()
+	return fmt.Sprintf(format, args...)
+}
+
+// StatusFormat is Status as a format string and its arguments, so the
+// front end can translate the format.
+func (g *Game) StatusFormat() (format string, args []any) {
+	switch {
+	case g.mode.Blitz:
+		return "Stage: %d (x%d)", []any{g.blitzStage() + 1, g.scoreMultiplier()}
+	case g.boss != nil:
+		b := g.boss
+		return "Boss %d/%d: %s", []any{b.phase + 1, len(bossPhases), bossPhases[b.phase].Name}
+	case g.mode.Endless:
+		return "Prestige %d, Lv %d", []any{g.prestige, g.level}
+	case g.puzzle != nil:
+		return "%s, %d left", []any{g.puzzle.Name, g.puzzleLeft()}
+	case g.mode.Cheese > 0:
+		return "Cheese: %d, %.1f/min", []any{g.cheese, g.CheesePerMinute()}
+	case g.mode.Dig:
+		return "Dig %d/%d, Gems %d", []any{g.digStage + 1, len(loadDigStages()), g.gemsLeft()}
+	case g.mode.LineGoal > 0:
+		return "Lines to go: %d", []any{max(0, g.mode.LineGoal-g.lines)}
+	}
+	return "Level: %d", []any{g.level}
+}
@@ -0,0 +1,464 @@
+package engine
+
+type Point struct {
+	X, Y int
+}
+
+// Piece is a piece of some kind (0..6: I, O, T, S, Z, J, L) in rotation
+// Rot, with its 4x4 box's top-left at (X, Y).
+type Piece struct {
+	Kind int
+	Rot  int
+	X, Y int
+}
+
+// PieceSet is a shape for each of the seven kinds in each rotation:
+// set[kind][rot] -> []Point in a 4x4 bounding box. Kicks and T-spins go by
+// kind, so a set keeps the standard tables.
+type PieceSet [7][4][]Point
+
+// Shapes is the standard tetromino set.
+var Shapes = PieceSet{
+	// I
+	{
+		{{0, 1}, {1, 1}, {2, 1}, {3, 1}},
+		{{2, 0}, {2, 1}, {2, 2}, {2, 3}},
+		{{0, 2}, {1, 2}, {2, 2}, {3, 2}},
+		{{1, 0}, {1, 1}, {1, 2}, {1, 3}},
+	},
+	// O
+	{
+		{{1, 1}, {2, 1}, {1, 2}, {2, 2}},
+		{{1, 1}, {2, 1}, {1, 2}, {2, 2}},
+		{{1, 1}, {2, 1}, {1, 2}, {2, 2}},
+		{{1, 1}, {2, 1}, {1, 2}, {2, 2}},
+	},
+	// T
+	{
+		{{1, 0}, {0, 1}, {1, 1}, {2, 1}},
+		{{1, 0}, {1, 1}, {2, 1}, {1, 2}},
+		{{0, 1}, {1, 1}, {2, 1}, {1, 2}},
+		{{1, 0}, {0, 1}, {1, 1}, {1, 2}},
+	},
+	// S
+	{
+		{{1, 0}, {2, 0}, {0, 1}, {1, 1}},
+		{{1, 0}, {1, 1}, {2, 1}, {2, 2}},
+		{{1, 1}, {2, 1}, {0, 2}, {1, 2}},
+		{{0, 0}, {0, 1}, {1, 1}, {1, 2}},
+	},
+	// Z
+	{
+		{{0, 0}, {1, 0}, {1, 1}, {2, 1}},
+		{{2, 0}, {1, 1}, {2, 1}, {1, 2}},
+		{{0, 1}, {1, 1}, {1, 2}, {2, 2}},
+		{{1, 0}, {0, 1}, {1, 1}, {0, 2}},
+	},
+	// J
+	{
+		{{0, 0}, {0, 1}, {1, 1}, {2, 1}},
+		{{1, 0}, {2, 0}, {1, 1}, {1, 2}},
+		{{0, 1}, {1, 1}, {2, 1}, {2, 2}},
+		{{1, 0}, {1, 1}, {0, 2}, {1, 2}},
+	},
+	// L
+	{
+		{{2, 0}, {0, 1}, {1, 1}, {2, 1}},
+		{{1, 0}, {1, 1}, {1, 2}, {2, 2}},
+		{{0, 1}, {1, 1}, {2, 1}, {0, 2}},
+		{{0, 0}, {1, 0}, {1, 1}, {1, 2}},
+	},
+}
+
+// PieceSet is the piece set this game plays with.
+func (g *Game) PieceSet() *PieceSet { return g.shapes }
+
+// Cells returns the board cells p covers with the standard shapes.
+func (p Piece) Cells() []Point {
+	return Shapes.Cells(p)
+}
+
+// Cells returns the board cells p covers with the set's shapes.
+func (s *PieceSet) Cells(p Piece) []Point {
+	return s.AppendCells(make([]Point, 0, len(s[p.Kind][p.Rot])), p)
+}
+
+// AppendCells appends the board cells p covers to dst, so a caller can
+// reuse one buffer rather than allocate per piece.
+func (s *PieceSet) AppendCells(dst []Point, p Piece) []Point {
+	for _, c := range s[p.Kind][p.Rot] {
+		dst = append(dst, Point{p.X + c.X, p.Y + c.Y})
+	}
+	return dst
+}
+
+// nextKind is the kind to join the back of the queue: a puzzle's next
+// piece, or the randomizer's.
+func (g *Game) nextKind() int {
+	if len(g.deal) > 0 {
+		v := g.deal[0]
+		g.deal = g.deal[1:]
+		return v
+	}
+	return g.deck.Next(g.rng)
+}
+
+func (g *Game) spawn() {
+	kind := g.queue[0]
+	g.queue = append(g.queue[1:], g.nextKind())
+	g.holdUsed = false
+	g.spawnKind(kind)
+}
+
+func (g *Game) spawnKind(kind int) {
+	g.cur = Piece{
+		Kind: kind,
+		Rot:  0,
+		X:    g.spawnX,
+		Y:    0,
+	}
+	if g.mode.Rules.RandomSpawnRotation {
+		g.cur.Rot = g.rng.Intn(4)
+	}
+	g.dropFrameCounter = 0
+	g.lockTimer, g.lockResets, g.lowestY = 0, 0, 0
+	g.finesse = finesse{}
+	g.lateRotate, g.lateRotateAge = 0, 0
+	g.pieces++
+	g.lastRotated = false
+	if g.Hooks.Spawned != nil {
+		g.Hooks.Spawned(g.active)
+	}
+	if g.boardCollides(g.cur) {
+		// Zen makes room, though a board can be too short even then.
+		if !g.forgive() || g.boardCollides(g.cur) {
+			g.endGame("block out")
+		}
+	} else if g.hitsPartner(g.cur) {
+		// The other player is in the way; try again next frame.
+		g.respawn = true
+		g.spawnTimer = 1
+	}
+}
+
+// holdPiece swaps the current piece with the held one, once per piece,
+// in modes that allow it.
+func (g *Game) holdPiece() {
+	if g.holdUsed || g.mode.Rules.NoHold {
+		return
+	}
+	prev := g.hold
+	g.hold = g.cur.Kind
+	if prev < 0 {
+		g.spawn()
+	} else {
+		g.spawnKind(prev)
+	}
+	g.holdUsed = true
+}
+
+func (g *Game) collides(p Piece) bool {
+	return g.boardCollides(p) || g.hitsPartner(p)
+}
+
+// boardCollides reports whether p overlaps the stack or leaves the field.
+// It runs many times a frame, for gravity and the ghost, so it reads the
+// shape in place rather than allocating its cells.
+func (g *Game) boardCollides(p Piece) bool {
+	for _, c := range g.shapes[p.Kind][p.Rot] {
+		if g.filled(p.X+c.X, p.Y+c.Y) {
+			return true
+		}
+	}
+	return false
+}
+
+// hitsPartner reports whether p overlaps the other player's falling piece.
+func (g *Game) hitsPartner(p Piece) bool {
+	if g.partner == nil || g.partner.spawnTimer > 0 {
+		return false
+	}
+	q := g.partner.cur
+	for _, c := range g.shapes[p.Kind][p.Rot] {
+		for _, o := range g.shapes[q.Kind][q.Rot] {
+			if p.X+c.X == q.X+o.X && p.Y+c.Y == q.Y+o.Y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (g *Game) lockPiece() {
+	// A piece resting on the other player's piece waits rather than locking
+	// in mid-air.
+	below := g.cur
+	below.Y++
+	if g.hitsPartner(below) && !g.boardCollides(below) {
+		return
+	}
+	g.rememberPlacement()
+	tspin := g.isTSpin()
+	switch {
+	case !g.lockedOut(g.cur):
+		for _, c := range g.shapes.Cells(g.cur) {
+			g.setCell(c.X, c.Y, g.cur.Kind+1, g.frames+1)
+		}
+	case !g.forgive():
+		g.endGame("lock out")
+		return
+	}
+	cleared := g.clearLines(tspin)
+	g.countLock(cleared, tspin)
+	if cleared > 0 {
+		g.checkDig()
+	}
+	g.sendAttack(g.settleGarbage(g.attack(cleared, tspin), cleared > 0))
+	if cleared > 0 {
+		g.fillCheese()
+	}
+	g.checkEase()
+	g.checkPuzzle()
+	// The lock that ends the game is still a lock.
+	g.emitLock(cleared, tspin)
+	if g.gameOver {
+		return
+	}
+	g.spawnTimer = SpawnDelayFrames
+	switch g.lateRotate {
+	case 1:
+		g.buffered.RotCW = true
+	case -1:
+		g.buffered.RotCCW = true
+	}
+	g.lateRotate, g.lateRotateAge = 0, 0
+	if cleared > 0 && g.partner != nil {
+		// Rows above a clear fall, possibly into the partner's piece.
+		g.swapPlayers()
+		g.liftPiece()
+		g.swapPlayers()
+	}
+}
+
+// liftPiece moves the active piece up until it no longer overlaps the
+// stack, after the board has shifted under it.
+func (g *Game) liftPiece() {
+	for i := 0; i < 4 && g.spawnTimer == 0 && g.collides(g.cur); i++ {
+		g.cur.Y--
+	}
+}
+
+// isTSpin reports whether the current piece is a T that got into place by
+// rotating, with at least three of the four corners around its center
+// blocked.
+func (g *Game) isTSpin() bool {
+	if g.cur.Kind != 2 || !g.lastRotated {
+		return false
+	}
+	blocked := 0
+	for _, c := range []Point{{0, 0}, {2, 0}, {0, 2}, {2, 2}} {
+		if g.filled(g.cur.X+c.X, g.cur.Y+c.Y) {
+			blocked++
+		}
+	}
+	return blocked >= 3
+}
+
+// clearLines removes full rows, updates score, level, combo and
+// back-to-back, and returns the number of rows cleared. Rows in the vanish
+// zone fall into view as those below clear.
+func (g *Game) clearLines(tspin bool) int {
+	rows, ats := g.stack()
+	newRows := make(Board, 0, len(rows))
+	newAt := make(Board, 0, len(rows))
+	var removed []ClearedRow
+	cleared := 0
+	for i, row := range rows {
+		full := true
+		for x := 0; x < g.width; x++ {
+			if row[x] == 0 {
+				full = false
+				break
+			}
+		}
+		if full {
+			cleared++
+			if g.mode.Cheese > 0 && isGarbageRow(row) {
+				g.cheese++
+			}
+			removed = append(removed, ClearedRow{Y: i - VanishRows, Cells: row})
+		} else {
+			newRows = append(newRows, row)
+			newAt = append(newAt, ats[i])
+		}
+	}
+	for len(newRows) < len(rows) {
+		newRows = append(Board{make([]int, g.width)}, newRows...)
+		newAt = append(Board{make([]int, g.width)}, newAt...)
+	}
+	g.setStack(newRows, newAt)
+	if cleared == 0 {
+		g.combo = -1
+		return 0
+	}
+	g.emitLineClear(removed)
+	g.lines += cleared
+	if level := max(g.mode.StartLevel, g.lines/10); level != g.level {
+		g.level = level
+		g.emitLevelUp(level)
+	}
+	g.combo++
+	if cleared == 4 || tspin {
+		g.b2b++
+	} else {
+		g.b2b = -1
+	}
+	scoreTable := []int{0, 40, 100, 300, 1200}
+	if cleared >= 0 && cleared <= 4 {
+		points := scoreTable[cleared]
+		if g.b2b > 0 {
+			points = points * 3 / 2
+		}
+		points += comboBonus * g.combo
+		g.score += points * (g.level + 1) * g.scoreMultiplier()
+		g.checkPrestige()
+	}
+	if g.script != nil {
+		g.script.Cleared(g, cleared)
+	}
+	return cleared
+}
+
+// comboBonus is added to a clear's points per lock of combo, before the
+// level and mode multipliers. Back-to-back clears score half again.
+const comboBonus = 50
+
+func (g *Game) tryMove(dx, dy int) bool {
+	next := g.cur
+	next.X += dx
+	next.Y += dy
+	if !g.collides(next) {
+		g.cur = next
+		if dx != 0 {
+			g.lastRotated = false
+			g.moved(dx, 0)
+		}
+		if g.cur.Y > g.lowestY {
+			g.lowestY, g.lockResets = g.cur.Y, 0
+		}
+		return true
+	}
+	return false
+}
+
+func (g *Game) tryRotate(dir int) bool {
+	if g.mode.Rules.NoRotation {
+		return false
+	}
+	next := g.cur
+	next.Rot = (next.Rot + dir + 4) % 4
+	for _, k := range kicks(g.cur.Kind, g.cur.Rot, dir) {
+		test := next
+		test.X += k.X
+		test.Y -= k.Y
+		if !g.collides(test) {
+			g.cur = test
+			g.lastRotated = true
+			g.moved(k.X, dir)
+			return true
+		}
+	}
+	return false
+}
+
+// SRS wall kicks, tried in order for a rotation out of each state: [from]
+// [0 clockwise, 1 counter-clockwise]. As in the guideline tables, y points
+// up.
+var (
+	jlstzKicks = [4][2][5]Point{
+		{{{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}}, {{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}}},
+		{{{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}}, {{0, 0}, {1, 0}, {1, -1}, {0, 2}, {1, 2}}},
+		{{{0, 0}, {1, 0}, {1, 1}, {0, -2}, {1, -2}}, {{0, 0}, {-1, 0}, {-1, 1}, {0, -2}, {-1, -2}}},
+		{{{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}}, {{0, 0}, {-1, 0}, {-1, -1}, {0, 2}, {-1, 2}}},
+	}
+	iKicks = [4][2][5]Point{
+		{{{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}}, {{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}}},
+		{{{0, 0}, {-1, 0}, {2, 0}, {-1, 2}, {2, -1}}, {{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}}},
+		{{{0, 0}, {2, 0}, {-1, 0}, {2, 1}, {-1, -2}}, {{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}}},
+		{{{0, 0}, {1, 0}, {-2, 0}, {1, -2}, {-2, 1}}, {{0, 0}, {-2, 0}, {1, 0}, {-2, -1}, {1, 2}}},
+	}
+)
+
+// kicks returns the offsets to try when a kind in rotation rot turns by
+// dir. O doesn't kick.
+func kicks(kind, rot, dir int) []Point {
+	d := 0
+	if dir < 0 {
+		d = 1
+	}
+	switch kind {
+	case 0:
+		return iKicks[rot][d][:]
+	case 1:
+		return []Point{{0, 0}}
+	}
+	return jlstzKicks[rot][d][:]
+}
+
+// moved follows a successful shift or rotation, restarting the lock delay
+// of a resting piece while resets last.
+func (g *Game) moved(dx, rot int) {
+	if g.lockTimer > 0 && g.lockResets < maxLockResets {
+		g.lockTimer = 0
+		g.lockResets++
+	}
+	if g.Hooks.Moved != nil {
+		g.Hooks.Moved(g.active, dx, rot)
+	}
+}
+
+// HardDrop drops the current piece to the floor and locks it.
+func (g *Game) HardDrop() {
+	from := g.cur.Y
+	for g.tryMove(0, 1) {
+	}
+	if g.Hooks.Dropped != nil {
+		g.Hooks.Dropped(g.active, g.cur, g.cur.Y-from)
+	}
+	g.lockPiece()
+	g.dropFrameCounter = 0
+}
+
+func gravityFrames(level int) int {
+	// Faster as level increases; min 2 frames
+	f := 30 - level*2
+	if f < 2 {
+		f = 2
+	}
+	return f
+}
+
+// classicGravity is the NES's frames per row by level; from level 29 on
+// pieces fall a row every frame.
+var classicGravity = [...]int{
+	48, 43, 38, 33, 28, 23, 18, 13, 8, 6, // 0-9
+	5, 5, 5, 4, 4, 4, 3, 3, 3, // 10-18
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 2, // 19-28
+}
+
+// dropFrames is the frames per row of gravity under the mode's curve, no
+// faster than its cap.
+func (g *Game) dropFrames() int {
+	return max(g.curveFrames(), g.mode.Rules.GravityCap)
+}
+
+func (g *Game) curveFrames() int {
+	level := g.gravityLevel()
+	if g.mode.Rules.Gravity == GravityClassic {
+		if level >= len(classicGravity) {
+			return 1
+		}
+		return classicGravity[level]
+	}
+	return gravityFrames(level)
+}
@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTSpinTripleKick(t *testing.T) {
+	g := New(1, testMode)
+	// A T in spawn rotation over a slot three rows deep, with the top of
+	// the slot roofed so that only the last 0->R kick, (-1, -2), gets in.
+	const x, y = 3, 15
+	for row := y + 2; row <= y+4; row++ {
+		fillRow(g, row)
+	}
+	for _, c := range []Point{{x, y + 2}, {x, y + 3}, {x + 1, y + 3}, {x, y + 4}} {
+		g.board[c.Y][c.X] = 0
+	}
+	g.board[y][x] = GarbageCell
+	setPiece(g, 2, 0, x, y)
+
+	var cleared int
+	var tspin bool
+	g.Hooks.Locked = func(_, n int, ts bool) { cleared, tspin = n, ts }
+	if !g.tryRotate(1) {
+		t.Fatal("the T didn't rotate into the slot")
+	}
+	if g.cur.X != x-1 || g.cur.Y != y+2 || g.cur.Rot != 1 {
+		t.Fatalf("kicked to %+v, want rot 1 at (%d, %d)", g.cur, x-1, y+2)
+	}
+	g.HardDrop()
+	if cleared != 3 || !tspin {
+		t.Errorf("locked clearing %d rows, T-spin %v; want a T-spin triple", cleared, tspin)
+	}
+}
+
+func TestIWallKicks(t *testing.T) {
+	// Clockwise out of each state, pushed off the floor or a wall.
+	tests := []struct {
+		name       string
+		from       Piece
+		wantX      int
+		wantY      int
+		wantKickNo int
+	}{
+		{"0->R on the floor", Piece{Kind: 0, Rot: 0, X: 3, Y: BoardH - 2}, 4, BoardH - 4, 4},
+		{"R->2 at the left wall", Piece{Kind: 0, Rot: 1, X: -2, Y: 5}, 0, 5, 2},
+		{"2->L on the floor", Piece{Kind: 0, Rot: 2, X: 3, Y: BoardH - 3}, 5, BoardH - 4, 3},
+		{"L->0 at the right wall", Piece{Kind: 0, Rot: 3, X: BoardW - 2, Y: 5}, BoardW - 4, 5, 2},
+	}
+	for _, tt := range tests {
+		g := New(1, testMode)
+		setPiece(g, 0, tt.from.Rot, tt.from.X, tt.from.Y)
+		if g.collides(g.cur) {
+			t.Fatalf("%s: the starting piece doesn't fit", tt.name)
+		}
+		if !g.tryRotate(1) {
+			t.Errorf("%s: no kick found", tt.name)
+			continue
+		}
+		want := Piece{Kind: 0, Rot: (tt.from.Rot + 1) % 4, X: tt.wantX, Y: tt.wantY}
+		if g.cur != want {
+			t.Errorf("%s: rotated to %+v, want %+v", tt.name, g.cur, want)
+		}
+		k := kicks(0, tt.from.Rot, 1)[tt.wantKickNo]
+		if tt.from.X+k.X != tt.wantX || tt.from.Y-k.Y != tt.wantY {
+			t.Errorf("%s: table kick %d is %v, which doesn't land there", tt.name, tt.wantKickNo, k)
+		}
+	}
+}
+
+func TestONeverKicks(t *testing.T) {
+	for rot := range 4 {
+		for _, dir := range []int{1, -1} {
+			if k := kicks(1, rot, dir); !slices.Equal(k, []Point{{0, 0}}) {
+				t.Errorf("O kicks from rot %d dir %d: %v", rot, dir, k)
+			}
+		}
+	}
+
+	// Boxed in, an O still turns on the spot.
+	g := New(1, testMode)
+	fillRow(g, BoardH-1)
+	fillRow(g, BoardH-2, 4, 5)
+	fillRow(g, BoardH-3, 4, 5)
+	setPiece(g, 1, 0, 3, BoardH-4)
+	for _, dir := range []int{1, 1, -1, 1, 1} {
+		if !g.tryRotate(dir) {
+			t.Fatal("the O couldn't turn in place")
+		}
+		if g.cur.X != 3 || g.cur.Y != BoardH-4 {
+			t.Fatalf("the O moved to (%d, %d) turning", g.cur.X, g.cur.Y)
+		}
+	}
+}
+
+// clearRows scores a lock that fills the bottom rows rows, a T-spin if tspin,
+// at level 0, and returns the points it made.
+func clearRows(g *Game, rows int, tspin bool) int {
+	for y := BoardH - rows; y < BoardH; y++ {
+		fillRow(g, y)
+	}
+	g.lines = 0
+	before := g.score
+	g.clearLines(tspin)
+	return g.score - before
+}
+
+func TestComboBonus(t *testing.T) {
+	g := New(1, testMode)
+	for i, want := range []int{40, 40 + comboBonus, 40 + 2*comboBonus, 100 + 3*comboBonus} {
+		rows := 1
+		if i == 3 {
+			rows = 2
+		}
+		if got := clearRows(g, rows, false); got != want {
+			t.Errorf("clear %d in a row scored %d, want %d", i+1, got, want)
+		}
+	}
+	if got := clearRows(g, 0, false); got != 0 || g.Combo() != 0 || g.combo != -1 {
+		t.Errorf("a lock clearing nothing scored %d and left combo %d", got, g.combo)
+	}
+	if got := clearRows(g, 1, false); got != 40 {
+		t.Errorf("a single after the combo broke scored %d, want 40", got)
+	}
+}
+
+func TestBackToBack(t *testing.T) {
+	type lock struct {
+		rows  int
+		tspin bool
+	}
+	tetris, single, tspinSingle := lock{4, false}, lock{1, false}, lock{1, true}
+	tests := []struct {
+		name  string
+		locks []lock
+		want  int // points for the last lock
+	}{
+		{"first Tetris", []lock{tetris}, 1200},
+		{"second Tetris", []lock{tetris, tetris}, 1800 + comboBonus},
+		{"T-spin single after a Tetris", []lock{tetris, tspinSingle}, 60 + comboBonus},
+		{"Tetris after a T-spin single", []lock{tspinSingle, tetris}, 1800 + comboBonus},
+		{"single after a Tetris", []lock{tetris, single}, 40 + comboBonus},
+		{"Tetris after a single broke it", []lock{tetris, single, tetris}, 1200 + 2*comboBonus},
+		{"Tetris after a zero-line T-spin", []lock{tetris, {0, true}, tetris}, 1800},
+		{"Tetris after a lock clearing nothing", []lock{tetris, {0, false}, tetris}, 1800},
+	}
+	for _, tt := range tests {
+		g := New(1, testMode)
+		var got int
+		for _, l := range tt.locks {
+			got = clearRows(g, l.rows, l.tspin)
+		}
+		if got != tt.want {
+			t.Errorf("%s: scored %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
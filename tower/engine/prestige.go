@@ -0,0 +1,38 @@
+package engine
+
+import "log/slog"
+
+// prestigeCap is the Endless score that rolls over into a prestige rank.
+const prestigeCap = 100000
+
+const (
+	// prestigeGravityStep is how many levels of gravity each prestige adds.
+	prestigeGravityStep = 2
+	// From prestigeGarbageFrom on, garbage rises every prestigeGarbageStart
+	// frames, prestigeGarbageStep sooner each prestige after, down to
+	// prestigeGarbageMin.
+	prestigeGarbageFrom  = 2
+	prestigeGarbageStart = 20 * 60
+	prestigeGarbageStep  = 3 * 60
+	prestigeGarbageMin   = 5 * 60
+)
+
+// checkPrestige rolls the score over once it reaches the cap.
+func (g *Game) checkPrestige() {
+	if !g.mode.Endless {
+		return
+	}
+	for g.score >= prestigeCap {
+		g.score -= prestigeCap
+		g.prestige++
+		slog.Info("prestige", "rank", g.prestige)
+		if g.Hooks.Prestige != nil {
+			g.Hooks.Prestige(g.prestige)
+		}
+	}
+}
+
+// TotalScore counts rolled-over points, for leaderboards.
+func (g *Game) TotalScore() int {
+	return g.prestige*prestigeCap + g.score
+}
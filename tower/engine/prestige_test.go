@@ -0,0 +1,50 @@
+package engine
+
+import "testing"
+
+var endlessMode = Mode{Name: "Endless", Width: BoardW, Endless: true}
+
+func TestPrestigeRollover(t *testing.T) {
+	g := New(1, endlessMode)
+	var ranks []int
+	g.Hooks.Prestige = func(rank int) { ranks = append(ranks, rank) }
+	g.score = prestigeCap - 1
+	g.checkPrestige()
+	if g.prestige != 0 || len(ranks) != 0 {
+		t.Fatal("the score rolled over short of the cap")
+	}
+	g.score = 2*prestigeCap + 5 // one lock can pass the cap twice
+	g.checkPrestige()
+	if g.prestige != 2 || g.score != 5 || len(ranks) != 2 || ranks[1] != 2 {
+		t.Errorf("prestige %d, score %d, hooks %v; want 2, 5 and ranks 1 and 2", g.prestige, g.score, ranks)
+	}
+	if n := g.TotalScore(); n != 2*prestigeCap+5 {
+		t.Errorf("total score %d, want %d", n, 2*prestigeCap+5)
+	}
+
+	g = New(1, testMode)
+	g.score = prestigeCap
+	g.checkPrestige()
+	if g.prestige != 0 || g.score != prestigeCap {
+		t.Error("a mode without prestige rolled its score over")
+	}
+}
+
+func TestPrestigeRampsUp(t *testing.T) {
+	g := New(1, endlessMode)
+	for _, tc := range []struct{ prestige, gravity, garbage int }{
+		{0, 0, 0},
+		{1, prestigeGravityStep, 0},
+		{prestigeGarbageFrom, 2 * prestigeGravityStep, prestigeGarbageStart},
+		{prestigeGarbageFrom + 1, 3 * prestigeGravityStep, prestigeGarbageStart - prestigeGarbageStep},
+		{50, 50 * prestigeGravityStep, prestigeGarbageMin},
+	} {
+		g.prestige = tc.prestige
+		if n := g.gravityLevel(); n != tc.gravity {
+			t.Errorf("prestige %d: gravity level %d, want %d", tc.prestige, n, tc.gravity)
+		}
+		if n := g.garbageInterval(); n != tc.garbage {
+			t.Errorf("prestige %d: garbage every %d frames, want %d", tc.prestige, n, tc.garbage)
+		}
+	}
+}
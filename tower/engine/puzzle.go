@@ -0,0 +1,212 @@
+package engine
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed stages/puzzles/*.json
+var puzzleFS embed.FS
+
+// kindLetters names the piece kinds in puzzle files, in kind order.
+const kindLetters = "IOTSZJL"
+
+// MaxPuzzleRows is the most rows a puzzle can fill, leaving the top of
+// the board clear for its pieces to enter.
+const MaxPuzzleRows = BoardH - 4
+
+// Puzzle is one Puzzle mode setup, as its JSON file has it. Rows are
+// listed top to bottom and sit on the floor like a dig stage's: 'X' is
+// garbage, '.' empty. Pieces are dealt in order by letter, and the puzzle
+// is solved by clearing Lines lines, and the whole board as well if
+// PerfectClear, before they run out.
+type Puzzle struct {
+	Name         string   `json:"name"`
+	Goal         string   `json:"goal"`
+	Rows         []string `json:"rows"`
+	Pieces       string   `json:"pieces"`
+	Lines        int      `json:"lines"`
+	PerfectClear bool     `json:"perfect_clear"`
+}
+
+var (
+	puzzleOnce sync.Once
+	puzzles    []Puzzle
+)
+
+// loadPuzzles reads the puzzle files in name order. A broken file is
+// logged and skipped so the rest stay playable.
+func loadPuzzles() []Puzzle {
+	puzzleOnce.Do(func() {
+		names, _ := fs.Glob(puzzleFS, "stages/puzzles/*.json")
+		sort.Strings(names)
+		for _, name := range names {
+			p, err := readPuzzle(name)
+			if err != nil {
+				slog.Warn("puzzle skipped", "file", name, "err", err)
+				continue
+			}
+			puzzles = append(puzzles, p)
+		}
+	})
+	return puzzles
+}
+
+func readPuzzle(name string) (Puzzle, error) {
+	b, err := puzzleFS.ReadFile(name)
+	if err != nil {
+		return Puzzle{}, err
+	}
+	p, err := ParsePuzzle(b)
+	if p.Name == "" {
+		p.Name = path.Base(name)
+	}
+	return p, err
+}
+
+// ParsePuzzle reads a puzzle file and checks it.
+func ParsePuzzle(b []byte) (Puzzle, error) {
+	var p Puzzle
+	if err := json.Unmarshal(b, &p); err != nil {
+		return p, err
+	}
+	return p, p.check()
+}
+
+// check reports what's wrong with p, if anything.
+func (p Puzzle) check() error {
+	if len(p.Rows) > MaxPuzzleRows {
+		return fmt.Errorf("%d rows, want at most %d", len(p.Rows), MaxPuzzleRows)
+	}
+	for i, r := range p.Rows {
+		if len(r) != BoardW {
+			return fmt.Errorf("row %d is %d wide, want %d", i, len(r), BoardW)
+		}
+		if strings.Trim(r, "X.") != "" {
+			return fmt.Errorf("row %d: cells other than 'X' and '.'", i)
+		}
+	}
+	if p.Pieces == "" || strings.Trim(p.Pieces, kindLetters) != "" {
+		return fmt.Errorf("pieces %q, want letters from %s", p.Pieces, kindLetters)
+	}
+	if p.Lines <= 0 && !p.PerfectClear {
+		return fmt.Errorf("no goal: set lines or perfect_clear")
+	}
+	return nil
+}
+
+// NewPuzzle sets up a puzzle on b, from its highest filled row to the
+// floor, with kinds dealt in order. Every filled cell of b is garbage in
+// the puzzle.
+func NewPuzzle(name, goal string, b Board, kinds []int, lines int, perfectClear bool) Puzzle {
+	p := Puzzle{Name: name, Goal: goal, Lines: lines, PerfectClear: perfectClear}
+	for _, row := range b {
+		r := []byte(strings.Repeat(".", len(row)))
+		filled := false
+		for x, c := range row {
+			if c != 0 {
+				r[x], filled = 'X', true
+			}
+		}
+		if filled || len(p.Rows) > 0 {
+			p.Rows = append(p.Rows, string(r))
+		}
+	}
+	for _, k := range kinds {
+		p.Pieces += string(kindLetters[k])
+	}
+	return p
+}
+
+// AddPuzzle checks p and lists it after the embedded puzzles, in place of
+// any already listed by its name, so Puzzle modes can play it.
+func AddPuzzle(p Puzzle) error {
+	if p.Name == "" {
+		return fmt.Errorf("puzzle has no name")
+	}
+	if err := p.check(); err != nil {
+		return err
+	}
+	loadPuzzles()
+	for i := range puzzles {
+		if puzzles[i].Name == p.Name {
+			puzzles[i] = p
+			return nil
+		}
+	}
+	puzzles = append(puzzles, p)
+	return nil
+}
+
+// Puzzles names the puzzles in order, the embedded ones first.
+func Puzzles() []string {
+	var names []string
+	for _, p := range loadPuzzles() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// findPuzzle copies the named puzzle, so a game keeps it as it started
+// even if AddPuzzle replaces it.
+func findPuzzle(name string) *Puzzle {
+	for _, p := range loadPuzzles() {
+		if p.Name == name {
+			return &p
+		}
+	}
+	return nil
+}
+
+// startPuzzle lays out the puzzle's board and deals its pieces.
+func (g *Game) startPuzzle(p *Puzzle) {
+	g.puzzle = p
+	top := g.Height() - len(p.Rows)
+	for y, r := range p.Rows {
+		for x, c := range r {
+			if c == 'X' {
+				g.board[top+y][x] = GarbageCell
+			}
+		}
+	}
+	for _, c := range p.Pieces {
+		g.deal = append(g.deal, strings.IndexRune(kindLetters, c))
+	}
+}
+
+// checkPuzzle runs after each lock: the puzzle is won once its goal is
+// met, and lost once the last piece locks without meeting it.
+func (g *Game) checkPuzzle() {
+	p := g.puzzle
+	if p == nil || g.gameOver {
+		return
+	}
+	if g.lines >= p.Lines && (!p.PerfectClear || g.board.Empty()) {
+		g.won = true
+		g.endGame("puzzle solved")
+		return
+	}
+	if g.pieces >= len(p.Pieces) {
+		g.endGame("out of pieces")
+	}
+}
+
+// PuzzleGoal is what the puzzle asks for, or "" outside Puzzle mode.
+func (g *Game) PuzzleGoal() string {
+	if g.puzzle == nil {
+		return ""
+	}
+	return g.puzzle.Goal
+}
+
+// puzzleLeft is the puzzle's pieces not yet spawned.
+func (g *Game) puzzleLeft() int {
+	return max(0, len(g.puzzle.Pieces)-g.pieces)
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func puzzleMode(name string) Mode {
+	return Mode{Name: "Puzzle", Width: BoardW, Puzzle: name, Rules: Ruleset{NoHold: true}}
+}
+
+func TestPuzzlesLoad(t *testing.T) {
+	files, _ := fs.Glob(puzzleFS, "stages/puzzles/*.json")
+	if len(files) == 0 || len(Puzzles()) != len(files) {
+		t.Fatalf("%d of %d puzzle files loaded", len(Puzzles()), len(files))
+	}
+}
+
+// move is a placement in a puzzle solution: the falling piece turned to
+// rot at column x and dropped, then turned by spin once it lands.
+type move struct {
+	rot, x, spin int
+}
+
+func TestPuzzleSolutions(t *testing.T) {
+	solutions := map[string][]move{
+		"Tetris Well":     {{1, 7, 0}},
+		"Square Peg":      {{0, 3, 0}},
+		"Clean Sweep":     {{0, 2, 0}, {0, 4, 0}},
+		"T Slot":          {{2, 4, 0}},
+		"T-Spin Double":   {{3, 1, -1}},
+		"Stack and Clear": {{1, 6, 0}, {3, 7, 0}, {1, 4, 0}},
+	}
+	for _, name := range Puzzles() {
+		moves, ok := solutions[name]
+		if !ok {
+			t.Errorf("%s: no solution to check it by", name)
+			continue
+		}
+		g := New(1, puzzleMode(name))
+		for _, m := range moves {
+			setPiece(g, g.cur.Kind, m.rot, m.x, 0)
+			for g.tryMove(0, 1) {
+			}
+			if m.spin != 0 && !g.tryRotate(m.spin) {
+				t.Errorf("%s: the spin didn't fit", name)
+			}
+			g.HardDrop()
+			for g.spawnTimer > 0 && !g.gameOver {
+				g.Step(Input{})
+			}
+		}
+		if !g.GameOver() || !g.Won() {
+			t.Errorf("%s: the solution left it unsolved, lines %d", name, g.Lines())
+		}
+	}
+}
+
+func TestPuzzleRunsOut(t *testing.T) {
+	g := New(1, puzzleMode("Clean Sweep"))
+	if q := g.Queue(); len(q) != 1 || q[0] != 1 || g.cur.Kind != 1 {
+		t.Fatalf("dealt %d then %v, want two Os", g.cur.Kind, q)
+	}
+	g.Step(Input{Hold: true})
+	if g.hold != -1 {
+		t.Error("held a piece in a puzzle")
+	}
+	locked := locks(g)
+	place(g, 1, 0, 0)
+	if g.GameOver() || len(g.Queue()) != 0 {
+		t.Fatalf("after the first piece: over %v, queue %v", g.GameOver(), g.Queue())
+	}
+	place(g, 1, 0, 0)
+	if !g.GameOver() || g.Won() {
+		t.Error("two wasted pieces didn't lose the puzzle")
+	}
+	if *locked != 2 {
+		t.Errorf("heard %d locks, want the last one too", *locked)
+	}
+}
+
+func TestAddPuzzle(t *testing.T) {
+	defer func(n int) { puzzles = puzzles[:n] }(len(loadPuzzles()))
+	b := NewBoard(BoardW, BoardH)
+	for x := 1; x < BoardW; x++ {
+		b[BoardH-1][x] = GarbageCell
+	}
+	b[BoardH-2][5] = 3
+	p := NewPuzzle("Made", "Clear a line", b, []int{0, 2}, 1, false)
+	if len(p.Rows) != 2 || p.Rows[0] != ".....X...." || p.Rows[1] != ".XXXXXXXXX" || p.Pieces != "IT" {
+		t.Fatalf("NewPuzzle gave rows %q and pieces %q", p.Rows, p.Pieces)
+	}
+	if err := AddPuzzle(p); err != nil {
+		t.Fatal(err)
+	}
+	g := New(1, puzzleMode("Made"))
+	if got := g.Board(); got[BoardH-2][5] != GarbageCell || got[BoardH-1][0] != 0 || g.cur.Kind != 0 {
+		t.Error("the added puzzle didn't set up its board and first piece")
+	}
+
+	p.Pieces = ""
+	if err := AddPuzzle(p); err == nil {
+		t.Error("added a puzzle with no pieces")
+	}
+}
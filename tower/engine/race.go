@@ -0,0 +1,26 @@
+package engine
+
+// checkRace ends a Sprint once its lines are cleared and an Ultra once its
+// time is up. Both count as won: finishing is the goal, and the time or
+// score says how well.
+func (g *Game) checkRace() {
+	if g.gameOver {
+		return
+	}
+	switch {
+	case g.mode.LineGoal > 0 && g.lines >= g.mode.LineGoal:
+		g.won = true
+		g.endGame("sprint finished")
+	case g.mode.TimeLimit > 0 && g.frames >= g.mode.TimeLimit:
+		g.won = true
+		g.endGame("time up")
+	}
+}
+
+// TimeLeft is the frames left in an Ultra, or 0 in other modes.
+func (g *Game) TimeLeft() int {
+	if g.mode.TimeLimit == 0 {
+		return 0
+	}
+	return max(0, g.mode.TimeLimit-g.frames)
+}
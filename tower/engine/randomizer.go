@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"math/rand"
+	"slices"
+)
+
+// Randomizer deals the piece sequence. It draws on the game's rng, so the
+// seed fixes the sequence, and keeps whatever it remembers between pieces
+// in State, which hashes, saves and undo go through.
+type Randomizer interface {
+	// Next is the kind of the next piece.
+	Next(rng *rand.Rand) int
+	// State is what the randomizer remembers. SetState puts it back.
+	State() []int
+	SetState(s []int)
+}
+
+// RandomizerKind picks a mode's Randomizer.
+type RandomizerKind int
+
+const (
+	// RandomizerBag deals each of the seven kinds once, shuffled, then
+	// shuffles a new bag: never more than twelve pieces between two Is.
+	RandomizerBag RandomizerKind = iota
+	// RandomizerClassic rolls any kind, rolling once more on a repeat of
+	// the last piece, as the NES did. Droughts and floods both happen.
+	RandomizerClassic
+	// RandomizerTGM rolls up to tgmTries times for a kind not among the
+	// last four dealt, as The Grand Master did, and never opens with S, Z
+	// or O.
+	RandomizerTGM
+)
+
+// Randomizers lists every kind in menu order.
+var Randomizers = []RandomizerKind{RandomizerBag, RandomizerClassic, RandomizerTGM}
+
+func (k RandomizerKind) String() string {
+	switch k {
+	case RandomizerClassic:
+		return "Classic"
+	case RandomizerTGM:
+		return "TGM"
+	}
+	return "7-Bag"
+}
+
+// New makes a randomizer of kind k with nothing dealt yet.
+func (k RandomizerKind) New() Randomizer {
+	switch k {
+	case RandomizerClassic:
+		return &classicRandomizer{last: -1}
+	case RandomizerTGM:
+		return &tgmRandomizer{}
+	}
+	return &bagRandomizer{}
+}
+
+// bagRandomizer is RandomizerBag. State is the bag's kinds still to come.
+type bagRandomizer struct {
+	bag []int
+}
+
+func (b *bagRandomizer) Next(rng *rand.Rand) int {
+	if len(b.bag) == 0 {
+		b.bag = []int{0, 1, 2, 3, 4, 5, 6}
+		rng.Shuffle(len(b.bag), func(i, j int) { b.bag[i], b.bag[j] = b.bag[j], b.bag[i] })
+	}
+	v := b.bag[0]
+	b.bag = b.bag[1:]
+	return v
+}
+
+func (b *bagRandomizer) State() []int     { return b.bag }
+func (b *bagRandomizer) SetState(s []int) { b.bag = slices.Clone(s) }
+
+// classicRandomizer is RandomizerClassic. State is the last kind dealt,
+// -1 before the first.
+type classicRandomizer struct {
+	last int
+}
+
+func (c *classicRandomizer) Next(rng *rand.Rand) int {
+	// Eight sides, the eighth a reroll, as on the NES.
+	v := rng.Intn(8)
+	if v == 7 || v == c.last {
+		v = rng.Intn(7)
+	}
+	c.last = v
+	return v
+}
+
+func (c *classicRandomizer) State() []int { return []int{c.last} }
+
+func (c *classicRandomizer) SetState(s []int) {
+	c.last = -1
+	if len(s) == 1 {
+		c.last = s[0]
+	}
+}
+
+// tgmTries is how many rolls RandomizerTGM makes for a piece before taking
+// a repeat.
+const tgmTries = 6
+
+// tgmFirst are the kinds a TGM game may open with: I, T, J and L.
+var tgmFirst = []int{0, 2, 5, 6}
+
+// tgmRandomizer is RandomizerTGM. State is the last four kinds dealt,
+// oldest first, once the first is dealt; before then the history is Z, S,
+// S, Z and State is empty.
+type tgmRandomizer struct {
+	history []int
+}
+
+func (t *tgmRandomizer) Next(rng *rand.Rand) int {
+	if t.history == nil {
+		v := tgmFirst[rng.Intn(len(tgmFirst))]
+		t.history = []int{3, 3, 4, v}
+		return v
+	}
+	v := rng.Intn(7)
+	for range tgmTries - 1 {
+		if !slices.Contains(t.history, v) {
+			break
+		}
+		v = rng.Intn(7)
+	}
+	t.history = append(t.history[1:], v)
+	return v
+}
+
+func (t *tgmRandomizer) State() []int     { return t.history }
+func (t *tgmRandomizer) SetState(s []int) { t.history = slices.Clone(s) }
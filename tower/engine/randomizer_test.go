@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestRandomizers(t *testing.T) {
+	for _, k := range Randomizers {
+		rng := rand.New(rand.NewSource(1))
+		r := k.New()
+		var seen [7]int
+		var deal []int
+		for range 700 {
+			v := r.Next(rng)
+			if v < 0 || v > 6 {
+				t.Fatalf("%v dealt kind %d", k, v)
+			}
+			seen[v]++
+			deal = append(deal, v)
+		}
+		for v, n := range seen {
+			if n < 50 {
+				t.Errorf("%v dealt kind %d %d times in 700", k, v, n)
+			}
+		}
+		switch k {
+		case RandomizerBag:
+			for i := 0; i < len(deal); i += 7 {
+				bag := slices.Sorted(slices.Values(deal[i : i+7]))
+				if !slices.Equal(bag, []int{0, 1, 2, 3, 4, 5, 6}) {
+					t.Errorf("bag %d is %v", i/7, deal[i:i+7])
+				}
+			}
+		case RandomizerTGM:
+			if !slices.Contains(tgmFirst, deal[0]) {
+				t.Errorf("TGM opened with kind %d", deal[0])
+			}
+		}
+	}
+}
+
+func TestRandomizerState(t *testing.T) {
+	for _, k := range Randomizers {
+		rng := rand.New(rand.NewSource(1))
+		r := k.New()
+		for range 10 {
+			r.Next(rng)
+		}
+		// A copy set to r's state deals what r does from the same rng.
+		c := k.New()
+		c.SetState(slices.Clone(r.State()))
+		seed := rng.Int63()
+		a, b := rand.New(rand.NewSource(seed)), rand.New(rand.NewSource(seed))
+		for i := range 20 {
+			if x, y := r.Next(a), c.Next(b); x != y {
+				t.Fatalf("%v: piece %d after SetState is %d, want %d", k, i, y, x)
+			}
+		}
+	}
+}
+
+func TestModeRandomizer(t *testing.T) {
+	m := testMode
+	m.Rules.Randomizer = RandomizerClassic
+	g := New(1, m)
+	if _, ok := g.deck.(*classicRandomizer); !ok {
+		t.Errorf("a Classic mode deals with %T", g.deck)
+	}
+	saved, err := g.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again := New(2, m)
+	if err := again.Restore(saved); err != nil || again.Hash() != g.Hash() {
+		t.Errorf("restored a Classic game: %v, hash equal %v", err, again.Hash() == g.Hash())
+	}
+}
@@ -0,0 +1,260 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// maxSaveDraws bounds how far Restore winds the random source forward, far
+// past what any game draws, so a corrupt count can't hang it.
+const maxSaveDraws = 1 << 26
+
+// SaveVersion is the version of the format Save writes. Restore reads
+// only this version; bump it whenever the saved fields change meaning.
+const SaveVersion = 3
+
+var (
+	// ErrSaveVersion means a save was written in another format version.
+	ErrSaveVersion = errors.New("save is from another version")
+	// ErrNotSaveable means the game's state can't all be saved: a versus
+	// game's rival or a script's own state.
+	ErrNotSaveable = errors.New("game can't be saved")
+)
+
+// countedSource counts the values drawn from a seeded source, which is all
+// it takes to get the source back to the same place.
+type countedSource struct {
+	src   rand.Source64
+	seed  int64
+	draws uint64
+}
+
+func newCountedSource(seed int64) *countedSource {
+	return &countedSource{src: rand.NewSource(seed).(rand.Source64), seed: seed}
+}
+
+func (s *countedSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countedSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+func (s *countedSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.seed, s.draws = seed, 0
+}
+
+// saveFile is the saved form of a Game.
+type saveFile struct {
+	Version  int
+	Mode     string
+	Width    int
+	Seed     int64
+	Draws    uint64 // values drawn from the seed so far
+	Board    Board
+	Vanish   Board `json:",omitempty"` // left out while empty
+	Queue    []int
+	Bag      []int // the randomizer's State, named for the 7-bag
+	Score    int
+	Lines    int
+	Level    int
+	Pieces   int
+	Combo    int
+	B2B      int
+	Player   savedPiece
+	Partner  *savedPiece `json:",omitempty"`
+	Active   int
+	Frames   int
+	GameOver bool
+	Won      bool
+	DigStage int
+	Cheese   int
+	Prestige int
+	NearTops int      `json:",omitempty"`
+	Ease     int      `json:",omitempty"`
+	Incoming [][3]int `json:",omitempty"` // rows, hole, wait
+	Boss     *[4]int  `json:",omitempty"` // phase, hp, step, wait
+	Stats    Stats
+	Deal     []int `json:",omitempty"`
+}
+
+// savedPiece is the saved form of a pieceState.
+type savedPiece struct {
+	Piece          Piece
+	DropFrames     int
+	SoftDropFrames int
+	LastRotated    bool
+	Hold           int
+	HoldUsed       bool
+	SpawnTimer     int
+	Buffered       Input
+	Respawn        bool
+	LockTimer      int
+	LockResets     int
+	LowestY        int
+	SpawnX         int
+	FinesseKeys    int
+	LastShift      int
+	Tucked         bool
+	LateRotate     int `json:",omitempty"`
+	LateRotateAge  int `json:",omitempty"`
+}
+
+func savePiece(ps pieceState) savedPiece {
+	return savedPiece{
+		Piece: ps.cur, DropFrames: ps.dropFrameCounter, SoftDropFrames: ps.softDropCounter,
+		LastRotated: ps.lastRotated, Hold: ps.hold, HoldUsed: ps.holdUsed,
+		SpawnTimer: ps.spawnTimer, Buffered: ps.buffered, Respawn: ps.respawn,
+		LockTimer: ps.lockTimer, LockResets: ps.lockResets, LowestY: ps.lowestY, SpawnX: ps.spawnX,
+		FinesseKeys: ps.finesse.keys, LastShift: ps.finesse.lastShift, Tucked: ps.finesse.tucked,
+		LateRotate: ps.lateRotate, LateRotateAge: ps.lateRotateAge,
+	}
+}
+
+func (s savedPiece) restore() pieceState {
+	return pieceState{
+		cur: s.Piece, dropFrameCounter: s.DropFrames, softDropCounter: s.SoftDropFrames,
+		lastRotated: s.LastRotated, hold: s.Hold, holdUsed: s.HoldUsed,
+		spawnTimer: s.SpawnTimer, buffered: s.Buffered, respawn: s.Respawn,
+		lockTimer: s.LockTimer, lockResets: s.LockResets, lowestY: s.LowestY, spawnX: s.SpawnX,
+		finesse:    finesse{keys: s.FinesseKeys, lastShift: s.LastShift, tucked: s.Tucked},
+		lateRotate: s.LateRotate, lateRotateAge: s.LateRotateAge,
+	}
+}
+
+// Save encodes everything that affects how the game goes on, RNG included,
+// for Restore to pick up later. The undo history is left out.
+func (g *Game) Save() ([]byte, error) {
+	if g.rival != nil || g.script != nil {
+		return nil, ErrNotSaveable
+	}
+	s := saveFile{
+		Version: SaveVersion, Mode: g.mode.Name, Width: g.width,
+		Seed: g.src.seed, Draws: g.src.draws,
+		Board: g.board.Clone(), Queue: g.queue, Bag: g.deck.State(),
+		Score: g.score, Lines: g.lines, Level: g.level, Pieces: g.pieces, Combo: g.combo, B2B: g.b2b,
+		Player: savePiece(g.pieceState), Active: g.active, Frames: g.frames,
+		GameOver: g.gameOver, Won: g.won, DigStage: g.digStage, Cheese: g.cheese, Prestige: g.prestige,
+		NearTops: g.nearTops, Ease: g.ease, Stats: g.stats, Deal: g.deal,
+	}
+	if !g.vanish.Empty() {
+		s.Vanish = g.vanish.Clone()
+	}
+	if g.partner != nil {
+		p := savePiece(*g.partner)
+		s.Partner = &p
+	}
+	for _, b := range g.incoming {
+		s.Incoming = append(s.Incoming, [3]int{b.rows, b.hole, b.wait})
+	}
+	if b := g.boss; b != nil {
+		s.Boss = &[4]int{b.phase, b.hp, b.step, b.wait}
+	}
+	return json.Marshal(s)
+}
+
+// Restore sets g, a game of the mode the save was made in, to the saved
+// state. Hooks, observers and SoftDropFrames are g's own and stay.
+func (g *Game) Restore(b []byte) error {
+	var s saveFile
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	switch {
+	case s.Version != SaveVersion:
+		return fmt.Errorf("%w: version %d, this build reads %d", ErrSaveVersion, s.Version, SaveVersion)
+	case g.rival != nil || g.script != nil:
+		return ErrNotSaveable
+	case s.Mode != g.mode.Name || s.Width != g.width:
+		return fmt.Errorf("save is of %s %d wide, not %s %d wide", s.Mode, s.Width, g.mode.Name, g.width)
+	case (s.Partner != nil) != (g.partner != nil):
+		return errors.New("save and game differ in players")
+	case s.Board.Height() != g.Height() || s.Board.Width() != g.width:
+		return fmt.Errorf("save's board is %dx%d, not %dx%d", s.Board.Width(), s.Board.Height(), g.width, g.Height())
+	case s.Vanish != nil && (s.Vanish.Height() != VanishRows || s.Vanish.Width() != g.width):
+		return fmt.Errorf("save's vanish zone is %dx%d, not %dx%d", s.Vanish.Width(), s.Vanish.Height(), g.width, VanishRows)
+	case len(s.Queue) != QueueLen:
+		return fmt.Errorf("save has %d queued pieces, want %d", len(s.Queue), QueueLen)
+	case s.Draws > maxSaveDraws:
+		return fmt.Errorf("save has drawn %d random values, more than a game could", s.Draws)
+	case s.Active != 0 && s.Active != 1:
+		return fmt.Errorf("save's active player is %d", s.Active)
+	}
+	if err := s.checkPieces(); err != nil {
+		return err
+	}
+	if s.Boss != nil {
+		if p := s.Boss[0]; p < 0 || p >= len(bossPhases) || s.Boss[2] < 0 || s.Boss[2] >= len(bossPhases[p].Attacks) {
+			return fmt.Errorf("save's boss is at phase %d attack %d", s.Boss[0], s.Boss[2])
+		}
+	}
+	if g.mode.Dig && (s.DigStage < 0 || s.DigStage >= len(loadDigStages())) {
+		return fmt.Errorf("save is on dig stage %d of %d", s.DigStage+1, len(loadDigStages()))
+	}
+	g.src = newCountedSource(s.Seed)
+	for range s.Draws {
+		g.src.Int63()
+	}
+	g.rng = rand.New(g.src)
+	g.board, g.queue = s.Board, s.Queue
+	g.deck.SetState(s.Bag)
+	g.score, g.lines, g.level, g.pieces, g.combo, g.b2b = s.Score, s.Lines, s.Level, s.Pieces, s.Combo, s.B2B
+	g.pieceState = s.Player.restore()
+	if s.Partner != nil {
+		*g.partner = s.Partner.restore()
+	}
+	g.active, g.frames = s.Active, s.Frames
+	g.clearVanish()
+	if s.Vanish != nil {
+		g.vanish = s.Vanish
+	}
+	g.lockedAt, g.vanishAt = g.lockedNow(g.board), g.lockedNow(g.vanish)
+	g.gameOver, g.won = s.GameOver, s.Won
+	g.digStage, g.cheese, g.prestige = s.DigStage, s.Cheese, s.Prestige
+	g.nearTops, g.ease = s.NearTops, s.Ease
+	g.incoming = nil
+	for _, b := range s.Incoming {
+		g.incoming = append(g.incoming, garbageBatch{b[0], b[1], b[2]})
+	}
+	if s.Boss != nil {
+		g.boss = &bossFight{phase: s.Boss[0], hp: s.Boss[1], step: s.Boss[2], wait: s.Boss[3]}
+	}
+	g.stats, g.deal, g.history = s.Stats, s.Deal, nil
+	return nil
+}
+
+// checkPieces checks every kind and rotation the save holds is one the
+// game can index its piece set with.
+func (s saveFile) checkPieces() error {
+	players := []savedPiece{s.Player}
+	if s.Partner != nil {
+		players = append(players, *s.Partner)
+	}
+	for _, p := range players {
+		if k, r := p.Piece.Kind, p.Piece.Rot; k < 0 || k >= len(Shapes) || r < 0 || r >= len(Shapes[0]) {
+			return fmt.Errorf("save's piece is kind %d rotation %d", k, r)
+		}
+		if p.Hold < -1 || p.Hold >= len(Shapes) {
+			return fmt.Errorf("save holds kind %d", p.Hold)
+		}
+	}
+	// The classic randomizer keeps -1 before its first piece.
+	for _, l := range []struct {
+		name  string
+		kinds []int
+		min   int
+	}{{"queue", s.Queue, 0}, {"deal", s.Deal, 0}, {"randomizer", s.Bag, -1}} {
+		for _, k := range l.kinds {
+			if k < l.min || k >= len(Shapes) {
+				return fmt.Errorf("save's %s has kind %d", l.name, k)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// randomInputs are n frames of presses, the same for a seed.
+func randomInputs(seed int64, n int) []Input {
+	r := rand.New(rand.NewSource(seed))
+	ins := make([]Input, n)
+	for i := range ins {
+		ins[i] = Input{Shift: r.Intn(3) - 1, RotCW: r.Intn(8) == 0, Hold: r.Intn(40) == 0, HardDrop: r.Intn(30) == 0, SoftDrop: r.Intn(10) == 0}
+	}
+	return ins
+}
+
+func TestSaveRestore(t *testing.T) {
+	for _, m := range []Mode{
+		testMode,
+		{Name: "Co-op", Width: 2 * BoardW, Coop: true},
+		{Name: "Cheese", Width: BoardW, Cheese: 10},
+		{Name: "Boss", Width: BoardW, Boss: true},
+	} {
+		g := New(7, m)
+		for _, in := range randomInputs(1, 600) {
+			g.Step(in, in)
+		}
+		b, err := g.Save()
+		if err != nil {
+			t.Fatalf("%s: %v", m.Name, err)
+		}
+		r := New(99, m)
+		if err := r.Restore(b); err != nil {
+			t.Fatalf("%s: %v", m.Name, err)
+		}
+		if r.Hash() != g.Hash() {
+			t.Fatalf("%s: restored game hashes differently", m.Name)
+		}
+		// It goes on the same way, random garbage and bags included.
+		for i, in := range randomInputs(2, 1200) {
+			g.Step(in, in)
+			r.Step(in, in)
+			if r.Hash() != g.Hash() {
+				t.Fatalf("%s: frame %d after restoring, the games differ", m.Name, i)
+			}
+		}
+		if r.Stats() != g.Stats() {
+			t.Errorf("%s: stats %+v, want %+v", m.Name, r.Stats(), g.Stats())
+		}
+	}
+}
+
+func TestRestoreRejects(t *testing.T) {
+	b, err := New(1, testMode).Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := strings.Replace(string(b), fmt.Sprintf(`"Version":%d`, SaveVersion), `"Version":0`, 1)
+	if err := New(1, testMode).Restore([]byte(old)); !errors.Is(err, ErrSaveVersion) {
+		t.Errorf("restoring an old version: %v, want ErrSaveVersion", err)
+	}
+	if err := New(1, Mode{Name: "Other", Width: BoardW}).Restore(b); err == nil {
+		t.Error("restored a save into another mode")
+	}
+	if _, err := New(1, Mode{Name: "Versus", Width: BoardW, Versus: true}).Save(); !errors.Is(err, ErrNotSaveable) {
+		t.Errorf("saving versus: %v, want ErrNotSaveable", err)
+	}
+}
+
+func TestRestoreRejectsCorruptSaves(t *testing.T) {
+	b, err := New(1, testMode).Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		edit func(s map[string]any)
+	}{
+		{"kind", func(s map[string]any) { s["Player"].(map[string]any)["Piece"].(map[string]any)["Kind"] = 7 }},
+		{"rotation", func(s map[string]any) { s["Player"].(map[string]any)["Piece"].(map[string]any)["Rot"] = -1 }},
+		{"hold", func(s map[string]any) { s["Player"].(map[string]any)["Hold"] = 9 }},
+		{"queue", func(s map[string]any) { s["Queue"].([]any)[2] = -1 }},
+		{"deal", func(s map[string]any) { s["Deal"] = []int{1, 12} }},
+		{"bag", func(s map[string]any) { s["Bag"] = []int{-2} }},
+		{"draws", func(s map[string]any) { s["Draws"] = uint64(1) << 62 }},
+		{"active", func(s map[string]any) { s["Active"] = 2 }},
+		{"boss", func(s map[string]any) { s["Boss"] = []int{len(bossPhases), 1, 0, 1} }},
+	} {
+		var s map[string]any
+		if err := json.Unmarshal(b, &s); err != nil {
+			t.Fatal(err)
+		}
+		tc.edit(s)
+		bad, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := New(1, testMode)
+		h := g.Hash()
+		if err := g.Restore(bad); err == nil {
+			t.Errorf("%s: a corrupt save restored", tc.name)
+		}
+		if g.Hash() != h {
+			t.Errorf("%s: the rejected save changed the game", tc.name)
+		}
+	}
+
+	g := New(1, digMode)
+	b, err = g.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := strings.Replace(string(b), `"DigStage":0`, `"DigStage":-3`, 1)
+	if err := g.Restore([]byte(bad)); err == nil {
+		t.Error("a save on dig stage -2 restored")
+	}
+}
@@ -0,0 +1,30 @@
+package engine
+
+// Script drives a mode defined outside the engine, such as a Lua mode
+// from a mod. Its callbacks run inside Step, so whatever they change is
+// part of the game and replays with it.
+type Script interface {
+	// Frame runs at the end of every step while the game is on.
+	Frame(g *Game)
+	// Cleared runs after a lock clears lines.
+	Cleared(g *Game, lines int)
+	// Close frees what the script holds once its game is over or
+	// dropped. It may run more than once, and from inside a callback.
+	Close()
+}
+
+// Close frees the mode's script, for a game dropped before it's over. The
+// game shouldn't be stepped again.
+func (g *Game) Close() {
+	if g.script != nil {
+		g.script.Close()
+	}
+}
+
+// AddGarbage queues rows of garbage with one random hole column. Like
+// any incoming garbage it rises on the next lock that clears nothing.
+func (g *Game) AddGarbage(rows int) {
+	if rows > 0 {
+		g.queueGarbage(rows, -1)
+	}
+}
@@ -0,0 +1,155 @@
+package engine
+
+import "slices"
+
+// Stats are a game's running totals, for the front end's stats panel and
+// summary. They never change play, so Hash leaves them out.
+type Stats struct {
+	Frames   int    // frames of play
+	Lines    int    // lines cleared
+	Placed   int    // pieces locked
+	Kinds    [7]int // pieces locked, by kind
+	Attack   int    // garbage rows the clears were worth, before cancelling
+	Tetrises int
+	MaxCombo int // the longest combo, clearing locks in a row after the first
+	// FinesseFaults counts pieces placed with more presses than the fewest
+	// that reach the same spot on an open board. Pieces soft dropped into
+	// place aren't judged, as tucks and spins take more.
+	FinesseFaults int
+}
+
+// finesse follows the presses spent on the falling piece.
+type finesse struct {
+	keys      int
+	lastShift int
+	tucked    bool // soft dropped, so not judged
+}
+
+// press counts the presses in a frame's input. A shift held over from the
+// last frame is one press, however far it carries the piece.
+func (f *finesse) press(in Input) {
+	if in.Shift != 0 && (f.lastShift == 0 || (in.Shift > 0) != (f.lastShift > 0)) {
+		f.keys++
+	}
+	f.lastShift = in.Shift
+	if in.RotCW {
+		f.keys++
+	}
+	if in.RotCCW {
+		f.keys++
+	}
+	if in.SoftDrop {
+		f.tucked = true
+	}
+}
+
+// Stats returns the totals so far.
+func (g *Game) Stats() Stats {
+	s := g.stats
+	s.Frames, s.Lines = g.frames, g.lines
+	return s
+}
+
+// PPS is pieces locked per second of play.
+func (s Stats) PPS() float64 {
+	if s.Frames == 0 {
+		return 0
+	}
+	return float64(s.Placed) * 60 / float64(s.Frames)
+}
+
+// APM is attack per minute of play.
+func (s Stats) APM() float64 {
+	if s.Frames == 0 {
+		return 0
+	}
+	return float64(s.Attack) * 60 * 60 / float64(s.Frames)
+}
+
+// TetrisRate is the share of cleared lines that went in Tetrises, 0 to 1.
+func (s Stats) TetrisRate() float64 {
+	if s.Lines == 0 {
+		return 0
+	}
+	return float64(4*s.Tetrises) / float64(s.Lines)
+}
+
+// countLock adds the piece locking now, which cleared rows rows.
+func (g *Game) countLock(rows int, tspin bool) {
+	s := &g.stats
+	s.Placed++
+	if k := g.cur.Kind; k < len(s.Kinds) {
+		s.Kinds[k]++
+	}
+	s.Attack += g.attack(rows, tspin)
+	if rows == 4 {
+		s.Tetrises++
+	}
+	s.MaxCombo = max(s.MaxCombo, g.combo)
+	if !g.finesse.tucked && g.finesse.keys > g.finesseKeys(g.cur) {
+		s.FinesseFaults++
+	}
+}
+
+// finesseKeys is the fewest presses that bring a freshly spawned piece
+// over p's spot on an open board: one-column taps, shifts held to a wall,
+// and turns either way.
+func (g *Game) finesseKeys(p Piece) int {
+	type state struct{ rot, x int }
+	footprint := func(st state) []Point {
+		cells := g.shapes.Cells(Piece{Kind: p.Kind, Rot: st.rot, X: st.x})
+		top := cells[0].Y
+		for _, c := range cells {
+			top = min(top, c.Y)
+		}
+		for i := range cells {
+			cells[i].Y -= top
+		}
+		slices.SortFunc(cells, func(a, b Point) int { return a.X*BoardH + a.Y - b.X*BoardH - b.Y })
+		return cells
+	}
+	fits := func(st state) bool {
+		for _, c := range g.shapes.Cells(Piece{Kind: p.Kind, Rot: st.rot, X: st.x}) {
+			if c.X < 0 || c.X >= g.width {
+				return false
+			}
+		}
+		return true
+	}
+	want := footprint(state{p.Rot, p.X})
+	start := state{0, g.spawnX}
+	dist := map[state]int{start: 0}
+	queue := []state{start}
+	for len(queue) > 0 {
+		st := queue[0]
+		queue = queue[1:]
+		if slices.Equal(footprint(st), want) {
+			return dist[st]
+		}
+		var next []state
+		for _, dx := range []int{-1, 1} {
+			if n := (state{st.rot, st.x + dx}); fits(n) {
+				next = append(next, n)
+				for fits(state{n.rot, n.x + dx}) {
+					n.x += dx
+				}
+				next = append(next, n)
+			}
+		}
+		for _, dir := range []int{1, -1} {
+			for _, k := range kicks(p.Kind, st.rot, dir) {
+				if n := (state{(st.rot + dir + 4) % 4, st.x + k.X}); fits(n) {
+					next = append(next, n)
+					break
+				}
+			}
+		}
+		for _, n := range next {
+			if _, seen := dist[n]; !seen {
+				dist[n] = dist[st] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package engine
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	g := New(1, testMode)
+	for y := BoardH - 4; y < BoardH; y++ {
+		fillRow(g, y, 9)
+	}
+	place(g, 0, 1, 7) // a Tetris
+	place(g, 1, 0, 3)
+	g.frames = 60
+	s := g.Stats()
+	if s.Placed != 2 || s.Kinds[0] != 1 || s.Kinds[1] != 1 || s.Tetrises != 1 || s.Attack != 4 {
+		t.Errorf("stats = %+v", s)
+	}
+	if s.PPS() != 2 || s.APM() != 240 || s.TetrisRate() != 1 {
+		t.Errorf("PPS %v, APM %v, Tetris rate %v", s.PPS(), s.APM(), s.TetrisRate())
+	}
+}
+
+func TestMaxCombo(t *testing.T) {
+	g := New(1, testMode)
+	for y := BoardH - 4; y < BoardH; y++ {
+		fillRow(g, y, 9)
+	}
+	place(g, 0, 1, 7) // a Tetris starts the combo
+	fillRow(g, BoardH-1, 9)
+	place(g, 0, 1, 7) // a single carries it on
+	place(g, 1, 0, 3) // and this ends it
+	if s := g.Stats(); s.MaxCombo != 1 || g.Combo() != 0 {
+		t.Errorf("max combo %d, combo %d, want 1 and 0", s.MaxCombo, g.Combo())
+	}
+}
+
+func TestFinesseKeys(t *testing.T) {
+	g := New(1, testMode)
+	spawn := g.spawnX
+	tests := []struct {
+		name string
+		p    Piece
+		want int
+	}{
+		{"O where it spawns", Piece{Kind: 1, X: spawn}, 0},
+		{"O one over", Piece{Kind: 1, X: spawn + 1}, 1},
+		{"O at the left wall", Piece{Kind: 1, X: -1}, 1},
+		{"O one off the left wall", Piece{Kind: 1, X: 0}, 2},
+		{"T turned", Piece{Kind: 2, Rot: 1, X: spawn}, 1},
+		{"T flipped", Piece{Kind: 2, Rot: 2, X: spawn}, 2},
+		{"upright I at the right wall", Piece{Kind: 0, Rot: 1, X: BoardW - 3}, 2},
+		// An upright S covers the same cells from either vertical state.
+		{"S upright", Piece{Kind: 3, Rot: 3, X: spawn + 1}, 1},
+	}
+	for _, tt := range tests {
+		if got := g.finesseKeys(tt.p); got != tt.want {
+			t.Errorf("%s: %d presses, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFinesseFaults(t *testing.T) {
+	g := New(1, testMode)
+	kind := g.cur.Kind
+	// Right then back left: two presses for a piece that needed none.
+	g.Step(Input{Shift: 1})
+	g.Step(Input{Shift: -1})
+	g.Step(Input{HardDrop: true})
+	if s := g.Stats(); s.FinesseFaults != 1 {
+		t.Fatalf("%d faults dropping the %d after a wasted move, want 1", s.FinesseFaults, kind)
+	}
+	for g.spawnTimer > 0 {
+		g.Step(Input{})
+	}
+	// A held shift is one press, straight to the wall.
+	for range 5 {
+		g.Step(Input{Shift: -1})
+	}
+	g.Step(Input{HardDrop: true})
+	if s := g.Stats(); s.FinesseFaults != 1 {
+		t.Errorf("%d faults after a clean drop at the wall, want still 1", s.FinesseFaults)
+	}
+}
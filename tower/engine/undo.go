@@ -0,0 +1,77 @@
+package engine
+
+import "slices"
+
+// undoDepth is how many placements can be taken back in a row.
+const undoDepth = 10
+
+// placement is the game just before a piece locked.
+type placement struct {
+	board    Board
+	lockedAt Board
+	vanish   Board
+	vanishAt Board
+	queue    []int
+	deck     []int // the randomizer's state
+	score    int
+	lines    int
+	level    int
+	pieces   int
+	combo    int
+	b2b      int
+	piece    pieceState
+}
+
+// CanUndo reports whether the mode lets placements be taken back.
+func (g *Game) CanUndo() bool {
+	return g.mode.Zen && g.partner == nil
+}
+
+// rememberPlacement saves the game before the current piece locks,
+// dropping the oldest once undoDepth are kept.
+func (g *Game) rememberPlacement() {
+	if !g.CanUndo() {
+		return
+	}
+	g.history = append(g.history, placement{
+		board:    g.board.Clone(),
+		lockedAt: g.lockedAt.Clone(),
+		vanish:   g.vanish.Clone(),
+		vanishAt: g.vanishAt.Clone(),
+		queue:    append([]int(nil), g.queue...),
+		deck:     slices.Clone(g.deck.State()),
+		score:    g.score,
+		lines:    g.lines,
+		level:    g.level,
+		pieces:   g.pieces,
+		combo:    g.combo,
+		b2b:      g.b2b,
+		piece:    g.pieceState,
+	})
+	if len(g.history) > undoDepth {
+		g.history = g.history[1:]
+	}
+}
+
+// undoPlacement takes back the last lock, returning its piece to the spawn
+// position. It reports whether there was one to take back.
+func (g *Game) undoPlacement() bool {
+	if !g.CanUndo() || len(g.history) == 0 {
+		return false
+	}
+	p := g.history[len(g.history)-1]
+	g.history = g.history[:len(g.history)-1]
+	g.board, g.lockedAt, g.queue = p.board, p.lockedAt, p.queue
+	g.vanish, g.vanishAt = p.vanish, p.vanishAt
+	g.deck.SetState(p.deck)
+	g.score, g.lines, g.level, g.pieces = p.score, p.lines, p.level, p.pieces
+	g.combo, g.b2b = p.combo, p.b2b
+	g.pieceState = p.piece
+	g.cur.X, g.cur.Y, g.cur.Rot = g.spawnX, 0, 0
+	g.dropFrameCounter, g.lastRotated = 0, false
+	g.lockTimer, g.lockResets, g.lowestY = 0, 0, 0
+	if g.Hooks.Spawned != nil {
+		g.Hooks.Spawned(g.active)
+	}
+	return true
+}
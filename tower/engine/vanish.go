@@ -0,0 +1,61 @@
+package engine
+
+// VanishRows is how many hidden rows sit above the board, rows -VanishRows
+// to -1. Pieces can rotate through them and lock partly inside them; the
+// game is lost only to a piece that locks entirely out of sight (lock out),
+// a new piece with no room to enter (block out), or a stack pushed up past
+// them (top out).
+const VanishRows = 4
+
+// filled reports whether (x, y) is taken, counting the vanish zone and
+// treating everything past the walls, floor and vanish zone as solid.
+func (g *Game) filled(x, y int) bool {
+	switch {
+	case x < 0 || x >= g.width || y < -VanishRows || y >= len(g.board):
+		return true
+	case y < 0:
+		return g.vanish[y+VanishRows][x] != 0
+	}
+	return g.board[y][x] != 0
+}
+
+// setCell fills (x, y), in the board or the vanish zone, with c locked on
+// frame at.
+func (g *Game) setCell(x, y, c, at int) {
+	rows, ats := g.board, g.lockedAt
+	if y < 0 {
+		rows, ats, y = g.vanish, g.vanishAt, y+VanishRows
+	}
+	rows[y][x], ats[y][x] = c, at
+}
+
+// lockedOut reports whether every cell of p is above the board.
+func (g *Game) lockedOut(p Piece) bool {
+	for _, c := range g.shapes[p.Kind][p.Rot] {
+		if p.Y+c.Y >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// stack is the vanish zone and board as one run of rows, top to bottom,
+// with their lock frames. The rows are shared, not copied.
+func (g *Game) stack() (rows, at Board) {
+	rows = append(append(make(Board, 0, VanishRows+len(g.board)), g.vanish...), g.board...)
+	at = append(append(make(Board, 0, len(rows)), g.vanishAt...), g.lockedAt...)
+	return rows, at
+}
+
+// setStack splits rows and at, laid out as stack returns them, back into
+// the vanish zone and board.
+func (g *Game) setStack(rows, at Board) {
+	g.vanish, g.board = rows[:VanishRows:VanishRows], rows[VanishRows:]
+	g.vanishAt, g.lockedAt = at[:VanishRows:VanishRows], at[VanishRows:]
+}
+
+// clearVanish empties the vanish zone, for a board laid out afresh.
+func (g *Game) clearVanish() {
+	g.vanish = NewBoard(g.width, VanishRows)
+	g.vanishAt = NewBoard(g.width, VanishRows)
+}
@@ -0,0 +1,71 @@
+package engine
+
+import "testing"
+
+func TestLockInVanishZone(t *testing.T) {
+	g := New(1, testMode)
+	fillRow(g, 0, 0)
+	fillRow(g, 1, 0)
+	for y := 2; y < BoardH; y++ {
+		fillRow(g, y, 9)
+	}
+	// An upright I in the left column, half of it above the board: its
+	// lower half clears the top two rows and its upper half falls into view.
+	setPiece(g, 0, 1, -2, -2)
+	g.HardDrop()
+	if g.GameOver() {
+		t.Fatal("a piece locking partly above the board ended the game")
+	}
+	if b := g.Board(); g.Lines() != 2 || b[0][0] != 1 || b[1][0] != 1 || b[0][1] != 0 {
+		t.Errorf("after the clear, %d lines and top rows %v %v; want the I's upper half in view", g.Lines(), b[0], b[1])
+	}
+	if !g.vanish.Empty() {
+		t.Error("the vanish zone kept blocks that fell into view")
+	}
+}
+
+func TestLockOut(t *testing.T) {
+	g := New(1, testMode)
+	for y := range BoardH {
+		fillRow(g, y, 9)
+	}
+	setPiece(g, 0, 0, 3, -2) // a flat I in row -1
+	g.HardDrop()
+	if !g.GameOver() {
+		t.Error("a piece locking wholly above the board didn't end the game")
+	}
+}
+
+func TestVanishZoneCeiling(t *testing.T) {
+	g := New(1, testMode)
+	setPiece(g, 0, 1, 3, -VanishRows)
+	if g.collides(g.cur) {
+		t.Error("an upright I at the top of the vanish zone collides")
+	}
+	g.cur.Y--
+	if !g.collides(g.cur) {
+		t.Error("a piece poking above the vanish zone fits")
+	}
+}
+
+func TestSaveKeepsVanishZone(t *testing.T) {
+	g := New(1, testMode)
+	fillRow(g, 0, 0, 9)
+	fillRow(g, 1, 0, 9)
+	for y := 2; y < BoardH; y++ {
+		fillRow(g, y, 9)
+	}
+	setPiece(g, 0, 1, -2, -2)
+	g.HardDrop()
+	b, err := g.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New(2, testMode)
+	if err := r.Restore(b); err != nil {
+		t.Fatal(err)
+	}
+	if r.Hash() != g.Hash() || r.vanish[VanishRows-1][0] != 1 {
+		t.Error("the restored game lost the blocks above the board")
+	}
+}
@@ -0,0 +1,22 @@
+package engine
+
+// Rival is the second player's game in versus, nil otherwise. Step runs it
+// with the second input, and Hash covers it.
+func (g *Game) Rival() *Game { return g.rival }
+
+// sendAttack passes the garbage a lock sent, after cancelling its own, to
+// the opponent in versus or to the boss. Observers hear of it too, so a
+// front end can send it to an opponent across the network, where
+// AddGarbage queues it.
+func (g *Game) sendAttack(rows int) {
+	if rows > 0 {
+		g.emitAttack(rows)
+	}
+	if g.opponent == nil {
+		g.hitBoss(rows)
+		return
+	}
+	if rows > 0 && !g.gameOver {
+		g.opponent.queueGarbage(rows, -1)
+	}
+}
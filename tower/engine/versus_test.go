@@ -0,0 +1,116 @@
+package engine
+
+import "testing"
+
+var versusMode = Mode{Name: "Versus", Width: BoardW, Versus: true}
+
+func TestVersusSendsGarbage(t *testing.T) {
+	g := New(1, versusMode)
+	r := g.Rival()
+	if r == nil || r.Rival() != nil {
+		t.Fatal("versus didn't make exactly one rival game")
+	}
+	if g.Queue()[0] != r.Queue()[0] || g.cur.Kind != r.cur.Kind {
+		t.Error("the two boards deal different pieces")
+	}
+	for y := BoardH - 4; y < BoardH; y++ {
+		fillRow(g, y, 0)
+	}
+	place(g, 0, 1, -2) // an upright I down the left well
+	if r.IncomingRows() != 4 || g.IncomingRows() != 0 {
+		t.Fatalf("after a Tetris: rival has %d rows incoming, sender %d; want 4 and 0", r.IncomingRows(), g.IncomingRows())
+	}
+
+	if r.ReadyRows() != 0 {
+		t.Fatal("the garbage is ready to rise without waiting")
+	}
+	place(r, 1, 0, 3)
+	if r.IncomingRows() != 4 || r.board[BoardH-1][0] != 0 {
+		t.Fatal("the garbage rose before its delay was up")
+	}
+	for r.ReadyRows() == 0 {
+		r.ageGarbage()
+	}
+	place(r, 1, 0, 3)
+	hole := -1
+	for y := BoardH - 4; y < BoardH; y++ {
+		for x := range r.width {
+			if r.board[y][x] == 0 {
+				if hole >= 0 && x != hole {
+					t.Fatalf("garbage row %d has a second gap at %d", y, x)
+				}
+				hole = x
+			}
+		}
+	}
+	if hole < 0 || r.IncomingRows() != 0 {
+		t.Errorf("the garbage didn't rise with one gap: hole %d, %d rows still incoming", hole, r.IncomingRows())
+	}
+}
+
+func TestVersusTopOutLoses(t *testing.T) {
+	g := New(1, versusMode)
+	r := g.Rival()
+	r.raiseGarbage(BoardH+VanishRows+1, 0)
+	if !r.GameOver() || r.Won() || !g.GameOver() || !g.Won() {
+		t.Errorf("rival over %v won %v, player 1 over %v won %v; want the rival lost and player 1 won",
+			r.GameOver(), r.Won(), g.GameOver(), g.Won())
+	}
+}
+
+func TestVersusStepsAndHashesBoth(t *testing.T) {
+	g := New(1, versusMode)
+	h := g.Hash()
+	g.Step(Input{}, Input{Shift: 1})
+	if g.Frames() != 1 || g.Rival().Frames() != 1 || g.Rival().cur.X != g.cur.X+1 {
+		t.Fatal("Step didn't run the rival with the second input")
+	}
+	h2 := g.Hash()
+	g.rival.score++
+	if g.Hash() == h2 || h2 == h {
+		t.Error("the rival's state isn't part of the hash")
+	}
+}
+
+func TestAttackCancelsIncoming(t *testing.T) {
+	g := New(1, versusMode)
+	r := g.Rival()
+	g.queueGarbage(3, 0)
+	for y := BoardH - 4; y < BoardH; y++ {
+		fillRow(g, y, 0)
+	}
+	place(g, 0, 1, -2)
+	if g.IncomingRows() != 0 || r.IncomingRows() != 1 {
+		t.Errorf("a Tetris against 3 incoming: %d left incoming, %d sent; want 0 and 1", g.IncomingRows(), r.IncomingRows())
+	}
+}
+
+func TestAttackTable(t *testing.T) {
+	g := New(1, versusMode)
+	for _, c := range []struct {
+		cleared    int
+		tspin      bool
+		combo, b2b int
+		want       int
+	}{
+		{1, false, 0, -1, 0},
+		{2, false, 0, -1, 1},
+		{4, false, 0, -1, 4},
+		{4, false, 0, 1, 5},
+		{2, true, 0, -1, 4},
+		{2, true, 0, 2, 5},
+		{1, false, 4, -1, 2},
+		{1, false, 40, -1, 5},
+		{0, false, -1, -1, 0},
+	} {
+		g.combo, g.b2b = c.combo, c.b2b
+		if got := g.attack(c.cleared, c.tspin); got != c.want {
+			t.Errorf("%d rows (T-spin %v) at combo %d, b2b %d: sends %d, want %d", c.cleared, c.tspin, c.combo, c.b2b, got, c.want)
+		}
+	}
+	g.mode.Rules.Attack = &AttackTable{Clears: [5]int{0, 1, 2, 3, 4}}
+	g.combo, g.b2b = 5, 3
+	if got := g.attack(1, false); got != 1 {
+		t.Errorf("a single with a custom table sends %d, want 1", got)
+	}
+}
@@ -0,0 +1,21 @@
+package engine
+
+// forgiveRows is how much of the top of the board Zen clears in place of
+// a top out.
+const forgiveRows = 8
+
+// forgive clears the vanish zone and the top forgiveRows rows, in Zen,
+// where topping out makes room rather than ending the game. It reports
+// whether it did; other modes end the game instead.
+func (g *Game) forgive() bool {
+	if !g.mode.Zen {
+		return false
+	}
+	g.clearVanish()
+	for y := range min(forgiveRows, len(g.board)) {
+		for x := range g.board[y] {
+			g.board[y][x], g.lockedAt[y][x] = 0, 0
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package engine
+
+import "testing"
+
+var zenMode = Mode{Name: "Zen", Width: BoardW, Zen: true}
+
+func TestZenForgivesBlockOut(t *testing.T) {
+	for _, m := range []Mode{zenMode, testMode} {
+		g := New(1, m)
+		for y := range BoardH {
+			fillRow(g, y, 9)
+		}
+		g.spawn()
+		if over := g.GameOver(); over != !m.Zen {
+			t.Errorf("%s: game over %v after a block out", m.Name, over)
+		}
+	}
+
+	g := New(1, zenMode)
+	for y := range BoardH {
+		fillRow(g, y, 9)
+	}
+	g.spawn()
+	b := g.Board()
+	if !b[:forgiveRows].Empty() || b[forgiveRows:].Empty() {
+		t.Errorf("Zen cleared the wrong rows: %v", b)
+	}
+}
+
+func TestZenForgivesLockOut(t *testing.T) {
+	g := New(1, zenMode)
+	for y := range BoardH {
+		fillRow(g, y, 9)
+	}
+	setPiece(g, 0, 0, 3, -2) // a flat I in row -1
+	g.HardDrop()
+	if g.GameOver() || !g.vanish.Empty() || !g.Board()[:forgiveRows].Empty() {
+		t.Error("Zen didn't clear the top in place of a lock out")
+	}
+}
+
+func TestZenForgivesGarbageTopOut(t *testing.T) {
+	g := New(1, zenMode)
+	g.raiseGarbage(BoardH+VanishRows+1, 0)
+	if g.GameOver() {
+		t.Error("garbage topped out a Zen game")
+	}
+}
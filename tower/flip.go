@@ -0,0 +1,67 @@
+package main
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// flipPeriod is how long, in frames of play, each orientation lasts under
+// the flip modifier.
+const flipPeriod = 30 * 60
+
+// orientation is how the board is drawn. Game logic never sees it.
+type orientation int
+
+const (
+	upright orientation = iota
+	mirrored
+	turned // rotated 180°
+)
+
+func (g *Game) orientation() orientation {
+	if !g.Mode().Flip {
+		return upright
+	}
+	return orientation(g.Frames() / flipPeriod % 3)
+}
+
+// reversed reports whether o draws the columns right to left, so pointing
+// at the board has to be turned back the same way.
+func (o orientation) reversed() bool {
+	return o == mirrored || o == turned
+}
+
+// column is the column of a board width wide that o draws at column col
+// of the upright layout.
+func (o orientation) column(col, width int) int {
+	if o.reversed() {
+		return width - 1 - col
+	}
+	return col
+}
+
+// drawBoardView draws the playfield, through an offscreen canvas when the
+// flip modifier has mirrored or turned it. The canvas keeps dangerPad
+// around the board so the danger glow turns with it.
+func (g *Game) drawBoardView(screen *ebiten.Image, l layout) {
+	o := g.orientation()
+	if o == upright {
+		g.drawBoard(screen, l.originX, l.originY, l)
+		return
+	}
+	w, h := int(l.boardPxW)+2*dangerPad, int(l.boardPxH)+2*dangerPad
+	if c := g.fx.boardCanvas; c == nil || c.Bounds().Dx() != w || c.Bounds().Dy() != h {
+		g.fx.boardCanvas = ebiten.NewImage(w, h)
+	}
+	c := g.fx.boardCanvas
+	c.Clear()
+	g.drawBoard(c, dangerPad, dangerPad, l)
+	op := &ebiten.DrawImageOptions{}
+	switch o {
+	case mirrored:
+		op.GeoM.Scale(-1, 1)
+		op.GeoM.Translate(float64(w), 0)
+	case turned:
+		op.GeoM.Scale(-1, -1)
+		op.GeoM.Translate(float64(w), float64(h))
+	}
+	op.GeoM.Translate(float64(l.originX-dangerPad), float64(l.originY-dangerPad))
+	screen.DrawImage(c, op)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// textFace draws the game's text: the built-in 7x13 bitmap font for
+// ASCII, and Go Mono, at the same advance, for everything else, such as
+// accented letters, Cyrillic, Greek and the arrows in the controls help.
+var textFace font.Face = fallbackFace{basicfont.Face7x13, monoFace()}
+
+func monoFace() font.Face {
+	f, err := opentype.Parse(gomono.TTF)
+	if err == nil {
+		// Go Mono's advance is 0.6 em, so this matches the bitmap font's 7px.
+		var face font.Face
+		if face, err = opentype.NewFace(f, &opentype.FaceOptions{Size: 7 / 0.6, DPI: 72, Hinting: font.HintingFull}); err == nil {
+			return face
+		}
+	}
+	slog.Warn("fallback font unavailable", "err", err)
+	return basicfont.Face7x13
+}
+
+// fallbackFace draws each rune with the first face that has it.
+type fallbackFace []font.Face
+
+func (f fallbackFace) pick(r rune) font.Face {
+	for _, face := range f {
+		if _, ok := face.GlyphAdvance(r); ok {
+			return face
+		}
+	}
+	return f[0]
+}
+
+func (f fallbackFace) Glyph(dot fixed.Point26_6, r rune) (dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, ok bool) {
+	return f.pick(r).Glyph(dot, r)
+}
+
+func (f fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.pick(r).GlyphBounds(r)
+}
+
+func (f fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.pick(r).GlyphAdvance(r)
+}
+
+func (f fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 { return 0 }
+
+func (f fallbackFace) Metrics() font.Metrics { return f[0].Metrics() }
+
+func (f fallbackFace) Close() error { return nil }
+
+// textW is how wide s is drawn, before the UI scale.
+func textW(s string) float32 {
+	return float32(font.MeasureString(textFace, s)) / 64
+}
+
+// drawCentered draws s centred on x, with its baseline at y.
+func (g *Game) drawCentered(screen *ebiten.Image, s string, x, y float32, clr color.Color) {
+	g.drawText(screen, s, x-textW(s)*float32(g.settings.UIScale)/2, y, clr)
+}
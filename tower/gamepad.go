@@ -0,0 +1,75 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// padStickDeadzone is how far the left stick must lean to count as a press.
+const padStickDeadzone = 0.5
+
+// padReader follows the gamepad in use: the last one to press a button, or
+// the latest to connect.
+type padReader struct {
+	id     ebiten.GamepadID
+	active bool
+}
+
+// poll tracks connections and reports whether the active pad just went
+// away.
+func (p *padReader) poll() (lost bool) {
+	if ids := inpututil.AppendJustConnectedGamepadIDs(nil); len(ids) > 0 {
+		p.id, p.active = ids[len(ids)-1], true
+	}
+	if p.active && inpututil.IsGamepadJustDisconnected(p.id) {
+		p.active = false
+		return true
+	}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if len(inpututil.AppendJustPressedStandardGamepadButtons(id, nil)) > 0 {
+			p.id, p.active = id, true
+		}
+	}
+	return false
+}
+
+// read polls the active pad's standard layout, returning held left/right
+// separately for the shifter.
+func (p *padReader) read() (in frameInput, left, right bool) {
+	if !p.active || !ebiten.IsStandardGamepadLayoutAvailable(p.id) {
+		return in, false, false
+	}
+	pressed := func(b ebiten.StandardGamepadButton) bool {
+		return ebiten.IsStandardGamepadButtonPressed(p.id, b)
+	}
+	just := inpututil.AppendJustPressedStandardGamepadButtons(p.id, nil)
+	justPressed := func(bs ...ebiten.StandardGamepadButton) bool {
+		return slices.ContainsFunc(bs, func(b ebiten.StandardGamepadButton) bool { return slices.Contains(just, b) })
+	}
+	in.rotCW = justPressed(ebiten.StandardGamepadButtonRightBottom)
+	in.rotCCW = justPressed(ebiten.StandardGamepadButtonRightRight)
+	in.hardDrop = justPressed(ebiten.StandardGamepadButtonLeftTop)
+	in.hold = justPressed(ebiten.StandardGamepadButtonFrontTopLeft, ebiten.StandardGamepadButtonFrontTopRight)
+	in.undo = justPressed(ebiten.StandardGamepadButtonCenterLeft)
+	in.pause = p.pausePressed()
+	in.heldCW = pressed(ebiten.StandardGamepadButtonRightBottom)
+	in.heldCCW = pressed(ebiten.StandardGamepadButtonRightRight)
+	in.heldHold = pressed(ebiten.StandardGamepadButtonFrontTopLeft) || pressed(ebiten.StandardGamepadButtonFrontTopRight)
+	x := ebiten.StandardGamepadAxisValue(p.id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	y := ebiten.StandardGamepadAxisValue(p.id, ebiten.StandardGamepadAxisLeftStickVertical)
+	in.softDrop = pressed(ebiten.StandardGamepadButtonLeftBottom) || y > padStickDeadzone
+	left = pressed(ebiten.StandardGamepadButtonLeftLeft) || x < -padStickDeadzone
+	right = pressed(ebiten.StandardGamepadButtonLeftRight) || x > padStickDeadzone
+	return in, left, right
+}
+
+func (p *padReader) pausePressed() bool {
+	return p.justPressed(ebiten.StandardGamepadButtonCenterRight)
+}
+
+// justPressed reports whether b on the active pad went down this frame.
+func (p *padReader) justPressed(b ebiten.StandardGamepadButton) bool {
+	return p.active && inpututil.IsStandardGamepadButtonJustPressed(p.id, b)
+}
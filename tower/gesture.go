@@ -0,0 +1,173 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Gesture is a touch gesture made on the playfield (outside the buttons).
+type Gesture string
+
+const (
+	TapLeft      Gesture = "tap-left"
+	TapRight     Gesture = "tap-right"
+	TwoFingerTap Gesture = "two-finger-tap"
+	SwipeLeft    Gesture = "swipe-left"
+	SwipeRight   Gesture = "swipe-right"
+	SwipeUp      Gesture = "swipe-up"
+	SwipeDown    Gesture = "swipe-down"
+	TapCorner    Gesture = "tap-corner"
+	LongPress    Gesture = "long-press"
+)
+
+// gestures lists every Gesture in menu order.
+var gestures = []Gesture{TapLeft, TapRight, TwoFingerTap, TapCorner, LongPress, SwipeLeft, SwipeRight, SwipeUp, SwipeDown}
+
+func (g Gesture) Label() string {
+	switch g {
+	case TapLeft:
+		return "Tap left half"
+	case TapRight:
+		return "Tap right half"
+	case TwoFingerTap:
+		return "Two-finger tap"
+	case SwipeLeft:
+		return "Swipe left"
+	case SwipeRight:
+		return "Swipe right"
+	case SwipeUp:
+		return "Swipe up"
+	case SwipeDown:
+		return "Swipe down"
+	case TapCorner:
+		return "Tap top corner"
+	case LongPress:
+		return "Long press"
+	}
+	return string(g)
+}
+
+// touchActions are the actions a gesture can be bound to, in menu order.
+var touchActions = []Action{ActNone, ActLeft, ActRight, ActRotateCW, ActRotateCCW, ActHardDrop, ActHold, ActPause}
+
+func defaultGestureMap() map[Gesture]Action {
+	return map[Gesture]Action{
+		TapLeft:      ActRotateCCW,
+		TapRight:     ActRotateCW,
+		TwoFingerTap: ActHold,
+		TapCorner:    ActHold,
+		LongPress:    ActPause,
+		SwipeLeft:    ActLeft,
+		SwipeRight:   ActRight,
+		SwipeUp:      ActHold,
+		SwipeDown:    ActHardDrop,
+	}
+}
+
+const (
+	tapMaxFrames    = 15 // longer presses are not taps
+	longPressFrames = 30 // a still single finger held this long
+	tapSlop         = 12 // pixels a tap may wander
+	swipeMinDelta   = 40 // pixels a swipe must travel
+	cornerSize      = 64 // pixels from the top corners that count as a corner
+)
+
+type touchTrack struct {
+	startX, startY int
+	x, y           int
+}
+
+// gestureReader classifies touches on the playfield. A gesture spans from
+// the first finger down to the last finger up, and is recognized on release.
+type gestureReader struct {
+	tracks  map[ebiten.TouchID]*touchTrack
+	first   *touchTrack // first finger of the current gesture
+	fingers int         // most fingers down at once
+	frames  int
+	moved   bool             // any finger wandered past tapSlop
+	fired   bool             // a long press already fired for this gesture
+	down    []ebiten.TouchID // reused each frame
+}
+
+// update feeds this frame's touches. just are the IDs pressed this frame
+// that the playfield owns; ids outside the playfield are never tracked.
+func (r *gestureReader) update(just []ebiten.TouchID, screenW int) (Gesture, bool) {
+	if r.tracks == nil {
+		r.tracks = map[ebiten.TouchID]*touchTrack{}
+	}
+	for _, id := range just {
+		x, y := ebiten.TouchPosition(id)
+		t := &touchTrack{startX: x, startY: y, x: x, y: y}
+		if len(r.tracks) == 0 && r.first == nil {
+			r.first, r.fingers, r.frames, r.moved, r.fired = t, 0, 0, false, false
+		}
+		r.tracks[id] = t
+	}
+	if r.first == nil {
+		return "", false
+	}
+	r.frames++
+	r.down = touchIDs(r.down)
+	down := r.down
+	for id, t := range r.tracks {
+		if !slices.Contains(down, id) {
+			// Released, possibly while the game was not reading input.
+			delete(r.tracks, id)
+			continue
+		}
+		t.x, t.y = ebiten.TouchPosition(id)
+		x, y := t.x, t.y
+		if abs(x-t.startX) > tapSlop || abs(y-t.startY) > tapSlop {
+			r.moved = true
+		}
+	}
+	if n := len(r.tracks); n > r.fingers {
+		r.fingers = n
+	}
+	if len(r.tracks) > 0 {
+		if r.fingers == 1 && !r.moved && !r.fired && r.frames >= longPressFrames {
+			r.fired = true
+			return LongPress, true
+		}
+		return "", false
+	}
+
+	f := r.first
+	r.first = nil
+	dx, dy := f.x-f.startX, f.y-f.startY
+	switch {
+	case r.fired:
+	case r.fingers >= 2:
+		if !r.moved && r.frames <= tapMaxFrames {
+			return TwoFingerTap, true
+		}
+	case abs(dx) >= swipeMinDelta || abs(dy) >= swipeMinDelta:
+		if abs(dx) > abs(dy) {
+			if dx < 0 {
+				return SwipeLeft, true
+			}
+			return SwipeRight, true
+		}
+		if dy < 0 {
+			return SwipeUp, true
+		}
+		return SwipeDown, true
+	case !r.moved && r.frames <= tapMaxFrames:
+		if f.startY < cornerSize && (f.startX < cornerSize || f.startX >= screenW-cornerSize) {
+			return TapCorner, true
+		}
+		if f.startX < screenW/2 {
+			return TapLeft, true
+		}
+		return TapRight, true
+	}
+	return "", false
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -4,14 +4,17 @@ go 1.24
 
 require (
 	github.com/hajimehoshi/ebiten/v2 v2.8.8
+	github.com/yuin/gopher-lua v1.1.1
 	golang.org/x/image v0.24.0
 )
 
 require (
 	github.com/ebitengine/gomobile v0.0.0-20240911145611-4856209ac325 // indirect
 	github.com/ebitengine/hideconsole v1.0.0 // indirect
+	github.com/ebitengine/oto/v3 v3.3.3 // indirect
 	github.com/ebitengine/purego v0.8.0 // indirect
 	github.com/jezek/xgb v1.1.1 // indirect
-	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/sync v0.11.0 // indirect
 	golang.org/x/sys v0.25.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
 )
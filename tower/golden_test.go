@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden replays in testdata/replays from CPU play")
+
+const (
+	goldenDir    = "testdata/replays"
+	goldenFrames = 20 * 60
+	goldenSeed   = 979
+)
+
+// recordGolden has the CPU play m for goldenFrames and writes the log.
+func recordGolden(t *testing.T, path string, mode int) {
+	g := newGameSeeded(goldenSeed, modes[mode], Modifiers{})
+	g.offline = true
+	rec := newRecorder(path, g)
+	d, d2 := newCPU(), newCPU()
+	for range goldenFrames {
+		for d.pending != nil && len(d.pending) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		ins := []frameInput{d.input(g)}
+		switch {
+		case g.Mode().Coop:
+			ins = append(ins, frameInput{})
+		case g.rival != nil:
+			for d2.pending != nil && len(d2.pending) == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			ins = append(ins, d2.input(g.rival))
+		}
+		g.stepPlayers(ins...)
+		rec.frame(g.Hash(), ins...)
+		if g.GameOver() {
+			break
+		}
+	}
+	if err := rec.save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func goldenPath(mode string) string {
+	return filepath.Join(goldenDir, strings.ReplaceAll(strings.ToLower(mode), " ", "-")+".json")
+}
+
+// TestGoldenReplays replays a recorded game of every mode and checks each
+// frame's state hash, so a change to gameplay shows up as a failure. Run
+// with -update after a deliberate change to record them again.
+func TestGoldenReplays(t *testing.T) {
+	if *updateGolden {
+		if err := os.MkdirAll(goldenDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for i, m := range modes {
+			recordGolden(t, goldenPath(m.Name), i)
+		}
+	}
+	for _, m := range modes {
+		path := goldenPath(m.Name)
+		if err := verifyInputLog(path); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+		if v := goldenVersion(t, path); v != len(inputLogMigrations) {
+			t.Errorf("%s is an input log version %d; record it again with -update", path, v)
+		}
+	}
+}
+
+// goldenVersion reads the input log version a golden was recorded at.
+func goldenVersion(t *testing.T, path string) int {
+	b, err := readSave(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var l struct{ Version int }
+	if err := json.Unmarshal(b, &l); err != nil {
+		t.Fatal(err)
+	}
+	return l.Version
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// maxScores is how many entries each leaderboard keeps.
+const maxScores = 10
+
+type scoreEntry struct {
+	// Name is the initials entered for the score.
+	Name                string `json:",omitempty"`
+	Score, Lines, Level int
+	// Frames is how long a Sprint took; Sprint boards rank by it.
+	Frames int `json:",omitempty"`
+	Date   time.Time
+	// Flags lists the challenge modifiers the score was set with.
+	Flags []string `json:",omitempty"`
+}
+
+// highScores holds a local leaderboard per mode.
+type highScores struct {
+	Version int
+	Boards  map[string][]scoreEntry
+}
+
+// highScoreMigrations upgrade older high score files; see loadSave.
+var highScoreMigrations []saveMigration
+
+var scores = highScores{Boards: map[string][]scoreEntry{}}
+
+// scoresReadOnly protects a high score file this build can't read.
+var scoresReadOnly bool
+
+func highScoresPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "highscores.json"), nil
+}
+
+func loadHighScores() {
+	path, err := highScoresPath()
+	if err != nil {
+		return
+	}
+	var h highScores
+	if err := loadSave(path, &h, highScoreMigrations); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("high scores unreadable, not saving new ones", "path", path, "err", err)
+			scoresReadOnly = true
+		}
+		return
+	}
+	if h.Boards == nil {
+		h.Boards = map[string][]scoreEntry{}
+	}
+	scores = h
+}
+
+func saveHighScores() error {
+	if scoresReadOnly {
+		return nil
+	}
+	path, err := highScoresPath()
+	if err != nil {
+		return err
+	}
+	scores.Version = len(highScoreMigrations)
+	b, err := json.MarshalIndent(scores, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeSave(path, b)
+}
+
+// add records e on board and returns its 1-based rank, or 0 if it didn't
+// make the list. Timed boards put the fastest first instead of the highest
+// score.
+func (h *highScores) add(board string, e scoreEntry, timed bool) int {
+	list := h.Boards[board]
+	// Ties go below existing scores.
+	i := sort.Search(len(list), func(i int) bool {
+		if timed {
+			return list[i].Frames > e.Frames
+		}
+		return list[i].Score < e.Score
+	})
+	if i >= maxScores {
+		return 0
+	}
+	list = append(list[:i], append([]scoreEntry{e}, list[i:]...)...)
+	h.Boards[board] = list[:min(len(list), maxScores)]
+	return i + 1
+}
+
+// scoreFlags marks what set this game apart on the leaderboard.
+func (g *Game) scoreFlags() []string {
+	f := g.modifiers.flags()
+	if g.assisted {
+		f = append(f, assistedFlag)
+	}
+	return f
+}
+
+// submitScore adds the finished game to its mode's leaderboard. Only a
+// finished Sprint has a time to enter.
+func (g *Game) submitScore() {
+	timed := g.Mode().LineGoal > 0
+	board := g.scoreBoard()
+	if g.offline || g.cpuPlayed || g.Mode().Zen || g.Mode().Versus || g.Mode().Puzzle != "" || g.TotalScore() == 0 || timed && !g.Won() || board == "" {
+		return
+	}
+	e := scoreEntry{
+		Score: g.TotalScore(), Lines: g.Lines(), Level: g.Level(), Date: time.Now().UTC(), Flags: g.scoreFlags(),
+	}
+	if timed {
+		e.Frames = g.Frames()
+	}
+	g.submitOnline(board, e)
+	g.rank = scores.add(board, e, timed)
+	if g.rank == 0 {
+		return
+	}
+	scores.pruneDaily(time.Now())
+	g.naming = &initialsPrompt{name: profile.Initials}
+	if err := saveHighScores(); err != nil {
+		slog.Error("high scores save failed", "err", err)
+	}
+	publishHistory()
+}
+
+// drawHighScores lists the top of the current mode's leaderboard from y
+// down, marking the entry just set.
+func (g *Game) drawHighScores(screen *ebiten.Image, y float32) {
+	pal := g.palette()
+	list := scores.Boards[g.scoreBoard()]
+	if len(list) == 0 {
+		return
+	}
+	w := float32(screen.Bounds().Dx())
+	k := float32(g.settings.UIScale)
+	g.drawCentered(screen, trf("%s High Scores", g.Mode().Name), w/2, y, pal.Text)
+	for i, e := range list[:min(5, len(list))] {
+		name := e.Name
+		if i+1 == g.rank && g.naming != nil {
+			name = g.naming.name + "_"
+		}
+		s := trf("%d. %-3s %7s  %3d lines", i+1, name, e.result(), e.Lines)
+		if len(e.Flags) > 0 {
+			s += " [" + strings.Join(e.Flags, ", ") + "]"
+		}
+		c := pal.Dim
+		if i+1 == g.rank {
+			c = pal.Accent
+		}
+		g.drawCentered(screen, s, w/2, y+float32(i+1)*16*k, c)
+	}
+}
+
+// result is what the entry ranks by: its time on a Sprint board, otherwise
+// its score.
+func (e scoreEntry) result() string {
+	if e.Frames > 0 {
+		return raceTime(e.Frames)
+	}
+	return fmt.Sprint(e.Score)
+}
+
+// maxInitials caps the name entered for a high score.
+const maxInitials = 3
+
+// initialsPrompt is the game over screen's name entry for a score that made
+// the leaderboard.
+type initialsPrompt struct {
+	name string
+}
+
+// updateInitials edits the initials: Enter or a tap puts them on the
+// entry.
+func (g *Game) updateInitials() {
+	p := g.naming
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		if len(p.name) < maxInitials && (r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			p.name += string(r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && p.name != "" {
+		p.name = p.name[:len(p.name)-1]
+	}
+	if !inpututil.IsKeyJustPressed(ebiten.KeyEnter) && len(justTouchIDs(nil)) == 0 {
+		return
+	}
+	g.naming = nil
+	scores.Boards[g.scoreBoard()][g.rank-1].Name = p.name
+	if err := saveHighScores(); err != nil {
+		slog.Error("high scores save failed", "err", err)
+	}
+	publishHistory()
+	if p.name != profile.Initials {
+		profile.Initials = p.name
+		if err := saveProfile(); err != nil {
+			slog.Error("profile save failed", "err", err)
+		}
+	}
+}
+
+func (g *Game) drawInitialsPrompt(screen *ebiten.Image, y float32) {
+	pal := g.palette()
+	w := float32(screen.Bounds().Dx())
+	k := float32(g.settings.UIScale)
+	lines := []string{trf("New high score, #%d! Initials: %s_", g.rank, g.naming.name), tr("Type up to 3, Enter saves")}
+	for i, s := range lines {
+		g.drawCentered(screen, s, w/2, y+float32(i)*16*k, pal.Accent)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"tetris/engine"
+)
+
+// frameInput is what the player asked for this frame, from any device.
+type frameInput struct {
+	shift         int // cells to move this frame; negative is left
+	rotCW, rotCCW bool
+	hardDrop      bool
+	hold          bool
+	softDrop      bool
+	pause         bool
+	undo          bool
+	// heldCW, heldCCW and heldHold are the rotate and hold buttons down,
+	// for a piece entering to take as its initial rotation and hold.
+	heldCW, heldCCW, heldHold bool
+}
+
+// input is f as the engine takes it.
+func (f frameInput) input() engine.Input {
+	return engine.Input{
+		Shift: f.shift,
+		RotCW: f.rotCW, RotCCW: f.rotCCW,
+		HardDrop: f.hardDrop, Hold: f.hold,
+		SoftDrop: f.softDrop,
+		Undo:     f.undo,
+
+		RotCWHeld: f.heldCW, RotCCWHeld: f.heldCCW, HoldHeld: f.heldHold,
+	}
+}
+
+// Action is one thing a player does in a frame. Gestures are bound to
+// actions too, so they're saved by name.
+type Action string
+
+const (
+	ActNone      Action = "none"
+	ActLeft      Action = "left" // one cell
+	ActRight     Action = "right"
+	ActRotateCW  Action = "rotate-cw"
+	ActRotateCCW Action = "rotate-ccw"
+	ActSoftDrop  Action = "soft-drop" // held
+	ActHardDrop  Action = "hard-drop"
+	ActHold      Action = "hold"
+	ActUndo      Action = "undo"
+	ActPause     Action = "pause"
+	// ActHeldLeft and ActHeldRight are left or right held down, which the
+	// player's shifter turns into moves with DAS and ARR.
+	ActHeldLeft  Action = "held-left"
+	ActHeldRight Action = "held-right"
+	// ActHeldRotateCW, ActHeldRotateCCW and ActHeldHold are the rotate and
+	// hold buttons down, for initial rotation and hold.
+	ActHeldRotateCW  Action = "held-rotate-cw"
+	ActHeldRotateCCW Action = "held-rotate-ccw"
+	ActHeldHold      Action = "held-hold"
+)
+
+func (a Action) Label() string {
+	switch a {
+	case ActLeft:
+		return "Move Left"
+	case ActRight:
+		return "Move Right"
+	case ActRotateCW:
+		return "Rotate CW"
+	case ActRotateCCW:
+		return "Rotate CCW"
+	case ActSoftDrop:
+		return "Soft Drop"
+	case ActHardDrop:
+		return "Hard Drop"
+	case ActHold:
+		return "Hold"
+	case ActUndo:
+		return "Undo"
+	case ActPause:
+		return "Pause"
+	}
+	return "None"
+}
+
+// apply adds a to the frame input. Held directions are the shifter's and
+// are left out.
+func (a Action) apply(in *frameInput) {
+	switch a {
+	case ActLeft:
+		in.shift--
+	case ActRight:
+		in.shift++
+	case ActRotateCW:
+		in.rotCW = true
+	case ActRotateCCW:
+		in.rotCCW = true
+	case ActSoftDrop:
+		in.softDrop = true
+	case ActHardDrop:
+		in.hardDrop = true
+	case ActHold:
+		in.hold = true
+	case ActUndo:
+		in.undo = true
+	case ActPause:
+		in.pause = true
+	case ActHeldRotateCW:
+		in.heldCW = true
+	case ActHeldRotateCCW:
+		in.heldCCW = true
+	case ActHeldHold:
+		in.heldHold = true
+	}
+}
+
+// appendActions appends in as actions, a move per cell of its shift, and
+// left and right if they're held.
+func appendActions(acts []Action, in frameInput, left, right bool) []Action {
+	for range in.shift {
+		acts = append(acts, ActRight)
+	}
+	for range -in.shift {
+		acts = append(acts, ActLeft)
+	}
+	for _, a := range []struct {
+		on  bool
+		act Action
+	}{
+		{in.rotCW, ActRotateCW}, {in.rotCCW, ActRotateCCW}, {in.softDrop, ActSoftDrop},
+		{in.hardDrop, ActHardDrop}, {in.hold, ActHold}, {in.undo, ActUndo}, {in.pause, ActPause},
+		{in.heldCW, ActHeldRotateCW}, {in.heldCCW, ActHeldRotateCCW}, {in.heldHold, ActHeldHold},
+		{left, ActHeldLeft}, {right, ActHeldRight},
+	} {
+		if a.on {
+			acts = append(acts, a.act)
+		}
+	}
+	return acts
+}
+
+// merge accumulates the one-shot presses of in; held state is not buffered.
+func (f *frameInput) merge(in frameInput) {
+	f.rotCW = f.rotCW || in.rotCW
+	f.rotCCW = f.rotCCW || in.rotCCW
+	f.hardDrop = f.hardDrop || in.hardDrop
+	f.hold = f.hold || in.hold
+}
+
+var coopKeys = [2]KeyMap{{
+	BindLeft:      []ebiten.Key{ebiten.KeyA},
+	BindRight:     []ebiten.Key{ebiten.KeyD},
+	BindSoftDrop:  []ebiten.Key{ebiten.KeyS},
+	BindRotateCW:  []ebiten.Key{ebiten.KeyW},
+	BindRotateCCW: []ebiten.Key{ebiten.KeyQ},
+	BindHardDrop:  []ebiten.Key{ebiten.KeySpace},
+	BindHold:      []ebiten.Key{ebiten.KeyE, ebiten.KeyShiftLeft},
+}, {
+	BindLeft:      []ebiten.Key{ebiten.KeyLeft},
+	BindRight:     []ebiten.Key{ebiten.KeyRight},
+	BindSoftDrop:  []ebiten.Key{ebiten.KeyDown},
+	BindRotateCW:  []ebiten.Key{ebiten.KeyUp},
+	BindRotateCCW: []ebiten.Key{ebiten.KeySlash},
+	BindHardDrop:  []ebiten.Key{ebiten.KeyEnter},
+	BindHold:      []ebiten.Key{ebiten.KeyShiftRight, ebiten.KeyPeriod},
+}}
+
+func anyPressed(keys []ebiten.Key) bool {
+	for _, k := range keys {
+		if ebiten.IsKeyPressed(k) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyJustPressed(keys []ebiten.Key) bool {
+	for _, k := range keys {
+		if inpututil.IsKeyJustPressed(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// read polls the map's keys, returning held left/right separately for the
+// shifter.
+func (m KeyMap) read() (in frameInput, left, right bool) {
+	in.rotCW = anyJustPressed(m[BindRotateCW])
+	in.rotCCW = anyJustPressed(m[BindRotateCCW])
+	in.hardDrop = anyJustPressed(m[BindHardDrop])
+	in.hold = anyJustPressed(m[BindHold])
+	in.softDrop = anyPressed(m[BindSoftDrop])
+	in.undo = anyJustPressed(m[BindUndo])
+	in.pause = anyJustPressed(m[BindPause])
+	in.heldCW = anyPressed(m[BindRotateCW])
+	in.heldCCW = anyPressed(m[BindRotateCCW])
+	in.heldHold = anyPressed(m[BindHold])
+	return in, anyPressed(m[BindLeft]), anyPressed(m[BindRight])
+}
+
+// pausePressed reports a press of the solo layout's pause key.
+func (g *Game) pausePressed() bool {
+	return anyJustPressed(g.keyPreset().keys[BindPause])
+}
+
+// mirror swaps left with right and clockwise with counter-clockwise.
+func (f *frameInput) mirror() {
+	f.shift = -f.shift
+	f.rotCW, f.rotCCW = f.rotCCW, f.rotCW
+	f.heldCW, f.heldCCW = f.heldCCW, f.heldCW
+}
+
+// ShiftPriority decides what happens while left and right are both held.
+type ShiftPriority int
+
+const (
+	// LastPressed moves toward whichever direction went down most recently.
+	LastPressed ShiftPriority = iota
+	// Neutral stops horizontal movement until one is released.
+	Neutral
+)
+
+func (p ShiftPriority) String() string {
+	if p == Neutral {
+		return "Neutral"
+	}
+	return "Last Pressed"
+}
+
+// shifter turns held left/right state into moves with DAS (delay before
+// auto-repeat) and ARR (frames between repeats).
+type shifter struct {
+	leftHeld, rightHeld bool
+	last                int // direction pressed most recently
+	dir                 int // direction acted on last frame
+	charge              int // frames dir has been held
+}
+
+// update returns the number of cells to shift this frame.
+func (s *shifter) update(left, right bool, st Settings) int {
+	if left && !s.leftHeld {
+		s.last = -1
+	}
+	if right && !s.rightHeld {
+		s.last = 1
+	}
+	s.leftHeld, s.rightHeld = left, right
+
+	dir := 0
+	switch {
+	case left && right:
+		if st.ShiftPriority == LastPressed {
+			dir = s.last
+		}
+	case left:
+		dir = -1
+	case right:
+		dir = 1
+	}
+	if dir != s.dir {
+		s.dir, s.charge = dir, 0
+		return dir
+	}
+	if dir == 0 {
+		return 0
+	}
+	s.charge++
+	if s.charge < st.DAS {
+		return 0
+	}
+	if st.ARR <= 0 {
+		return dir * engine.MaxBoardW
+	}
+	if (s.charge-st.DAS)%st.ARR == 0 {
+		return dir
+	}
+	return 0
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"tetris/engine"
+)
+
+const (
+	trailFrames       = 12 // hard drop trail fade
+	shakeFrames       = 10 // Tetris screen shake
+	shakePx           = 5  // peak shake offset
+	borderFlashFrames = 30 // level up border flash
+	levelBannerFrames = 90 // the level up banner, fading over its last third
+)
+
+// juiceKind is what a juice effect draws.
+type juiceKind int
+
+const (
+	juiceTrail juiceKind = iota
+	juiceShake
+	juiceBorder
+	juiceBanner
+)
+
+// juice is one short-lived effect in the effects list. Each ages on the
+// effects clock and is dropped once it reaches life.
+type juice struct {
+	kind      juiceKind
+	age, life float32
+	// A trail runs up from the landed piece's cells by the rows it fell.
+	cells []engine.Point
+	rows  int
+	piece int // kind, for the trail colour
+	level int // a banner's new level
+}
+
+func (fx *effects) addJuice(j juice) {
+	fx.juice = append(fx.juice, j)
+}
+
+func (fx *effects) updateJuice(dt float32) {
+	live := fx.juice[:0]
+	for _, j := range fx.juice {
+		if j.age += dt; j.age < j.life {
+			live = append(live, j)
+		}
+	}
+	fx.juice = live
+}
+
+// startTrail streaks the column above each cell of p, a piece hard dropped
+// rows rows.
+func (fx *effects) startTrail(set *engine.PieceSet, p engine.Piece, rows int) {
+	if rows <= 0 {
+		return
+	}
+	fx.addJuice(juice{kind: juiceTrail, life: trailFrames, cells: set.Cells(p), rows: rows, piece: p.Kind})
+}
+
+func (fx *effects) startShake() {
+	fx.addJuice(juice{kind: juiceShake, life: shakeFrames})
+}
+
+func (fx *effects) startBorderFlash() {
+	fx.addJuice(juice{kind: juiceBorder, life: borderFlashFrames})
+}
+
+// startLevelBanner announces level. It shows whatever the Juice setting,
+// since it tells the player something; a newer banner replaces an older.
+func (fx *effects) startLevelBanner(level int) {
+	fx.juice = slices.DeleteFunc(fx.juice, func(j juice) bool { return j.kind == juiceBanner })
+	fx.addJuice(juice{kind: juiceBanner, life: levelBannerFrames, level: level})
+}
+
+// shake is this frame's camera offset: a quick jitter that dies away.
+func (fx *effects) shake() (dx, dy float64) {
+	for _, j := range fx.juice {
+		if j.kind != juiceShake {
+			continue
+		}
+		a := shakePx * float64(1-j.age/j.life)
+		dx += a * math.Sin(float64(j.age)*2.3)
+		dy += a * math.Cos(float64(j.age)*3.1)
+	}
+	return dx, dy
+}
+
+// drawTrails fades a streak above each column of a hard dropped piece, up
+// to where it fell from.
+func (g *Game) drawTrails(screen *ebiten.Image, originX, originY, tile float32) {
+	pal := g.palette()
+	for _, j := range g.fx.juice {
+		if j.kind != juiceTrail {
+			continue
+		}
+		// The top cell of each column the piece covers.
+		tops := map[int]int{}
+		for _, c := range j.cells {
+			if y, ok := tops[c.X]; !ok || c.Y < y {
+				tops[c.X] = c.Y
+			}
+		}
+		a := 1 - j.age/j.life
+		for x, y := range tops {
+			from := max(0, y-j.rows)
+			if from >= y {
+				continue
+			}
+			c := alpha(pal.Pieces[j.piece], uint8(90*a))
+			vector.DrawFilledRect(screen, originX+float32(x)*tile+tile/4, originY+float32(from)*tile, tile/2, float32(y-from)*tile, c, false)
+		}
+	}
+}
+
+// drawBorderFlash lights the board's edge after a level up.
+func (g *Game) drawBorderFlash(screen *ebiten.Image, originX, originY, w, h float32) {
+	pal := g.palette()
+	for _, j := range g.fx.juice {
+		if j.kind == juiceBorder {
+			a := 1 - j.age/j.life
+			vector.StrokeRect(screen, originX-2, originY-2, w+4, h+4, 3, alpha(pal.Accent, uint8(255*a)), false)
+		}
+	}
+}
+
+// drawLevelBanner shows the new level across the middle of the board for a
+// moment after a level up.
+func (g *Game) drawLevelBanner(screen *ebiten.Image, l layout) {
+	pal := g.palette()
+	for _, j := range g.fx.juice {
+		if j.kind != juiceBanner {
+			continue
+		}
+		a := min(1, 3*(1-j.age/j.life))
+		s := trf("LEVEL %d", j.level)
+		k := 2 * l.uiScale
+		y := l.originY + l.boardPxH/2
+		vector.DrawFilledRect(screen, l.originX, y-20*k, l.boardPxW, 28*k, alpha(pal.Shade, uint8(160*a)), false)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(float64(k), float64(k))
+		op.GeoM.Translate(float64(l.originX+l.boardPxW/2-textW(s)/2*k), float64(y))
+		op.ColorScale.ScaleWithColor(pal.Accent)
+		op.ColorScale.ScaleAlpha(a)
+		text.DrawWithOptions(screen, s, textFace, op)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestHardDropTrail(t *testing.T) {
+	tempConfig(t)
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.settings = DefaultSettings()
+	g.stepPlayers(frameInput{hardDrop: true})
+	if len(g.fx.juice) != 1 || g.fx.juice[0].kind != juiceTrail || g.fx.juice[0].rows == 0 {
+		t.Fatalf("effects after a hard drop: %+v", g.fx.juice)
+	}
+	for range trailFrames {
+		g.fx.update()
+	}
+	if len(g.fx.juice) != 0 {
+		t.Errorf("the trail outlived its %d frames: %+v", trailFrames, g.fx.juice)
+	}
+
+	g.settings.Juice = false
+	for i := 0; g.Stats().Placed < 2 && i < 600; i++ {
+		g.stepPlayers(frameInput{hardDrop: true})
+	}
+	if len(g.fx.juice) != 0 {
+		t.Errorf("effects with Juice off: %+v", g.fx.juice)
+	}
+}
+
+func TestShakeSettles(t *testing.T) {
+	var fx effects
+	fx.startShake()
+	if dx, dy := fx.shake(); dx == 0 && dy == 0 {
+		t.Error("a fresh shake doesn't move the camera")
+	}
+	for range shakeFrames {
+		fx.update()
+	}
+	if dx, dy := fx.shake(); dx != 0 || dy != 0 {
+		t.Errorf("shake (%v, %v) after it ended", dx, dy)
+	}
+}
+
+func TestRestartKeepsEffectsOnTheGame(t *testing.T) {
+	tempConfig(t)
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.Reset()
+	g.stepPlayers(frameInput{hardDrop: true})
+	if len(g.fx.juice) == 0 {
+		t.Error("after a restart the hard drop's trail went to another game")
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// KeyAction is something a keyboard key can be bound to.
+type KeyAction string
+
+const (
+	BindLeft      KeyAction = "left"
+	BindRight     KeyAction = "right"
+	BindSoftDrop  KeyAction = "soft-drop"
+	BindRotateCW  KeyAction = "rotate-cw"
+	BindRotateCCW KeyAction = "rotate-ccw"
+	BindHardDrop  KeyAction = "hard-drop"
+	BindHold      KeyAction = "hold"
+	BindUndo      KeyAction = "undo"
+	BindPause     KeyAction = "pause"
+)
+
+// keyActions lists every KeyAction in menu order.
+var keyActions = []KeyAction{BindLeft, BindRight, BindSoftDrop, BindRotateCW, BindRotateCCW, BindHardDrop, BindHold, BindUndo, BindPause}
+
+func (a KeyAction) Label() string {
+	switch a {
+	case BindLeft:
+		return "Move Left"
+	case BindRight:
+		return "Move Right"
+	case BindSoftDrop:
+		return "Soft Drop"
+	case BindRotateCW:
+		return "Rotate CW"
+	case BindRotateCCW:
+		return "Rotate CCW"
+	case BindHardDrop:
+		return "Hard Drop"
+	case BindHold:
+		return "Hold"
+	case BindUndo:
+		return "Undo (Zen)"
+	case BindPause:
+		return "Pause"
+	}
+	return string(a)
+}
+
+// KeyMap binds each action to the keys that trigger it. Keys are saved by
+// name, as in "ArrowLeft" or "Space".
+type KeyMap map[KeyAction][]ebiten.Key
+
+// maxBoundKeys is how many keys one action can have on the Custom layout.
+const maxBoundKeys = 3
+
+func (m KeyMap) clone() KeyMap {
+	c := make(KeyMap, len(m))
+	for a, keys := range m {
+		c[a] = slices.Clone(keys)
+	}
+	return c
+}
+
+// bind adds k to a, taking it off any other action first.
+func (m KeyMap) bind(a KeyAction, k ebiten.Key) {
+	for b := range maps.Keys(m) {
+		m[b] = slices.DeleteFunc(m[b], func(x ebiten.Key) bool { return x == k })
+	}
+	keys := append(m[a], k)
+	m[a] = keys[max(0, len(keys)-maxBoundKeys):]
+}
+
+// keyLabel is a short name for k for help text and menus.
+func keyLabel(k ebiten.Key) string {
+	switch k {
+	case ebiten.KeyLeft:
+		return "←"
+	case ebiten.KeyRight:
+		return "→"
+	case ebiten.KeyUp:
+		return "↑"
+	case ebiten.KeyDown:
+		return "↓"
+	case ebiten.KeyBackspace:
+		return "Bksp"
+	}
+	return k.String()
+}
+
+// keysLabel names every key in keys, or "-" when there are none.
+func keysLabel(keys []ebiten.Key) string {
+	if len(keys) == 0 {
+		return "-"
+	}
+	s := make([]string, len(keys))
+	for i, k := range keys {
+		s[i] = keyLabel(k)
+	}
+	return strings.Join(s, "/")
+}
+
+// firstKey names the first key bound to a, for the compact help lines.
+func (m KeyMap) firstKey(a KeyAction) string {
+	return keysLabel(m[a][:min(1, len(m[a]))])
+}
+
+// customPreset is the Custom layout over m, with help lines made from its
+// keys.
+func customPreset(m KeyMap) keyPreset {
+	return keyPreset{
+		name: "Custom",
+		keys: m,
+		help: []string{
+			m.firstKey(BindLeft) + "/" + m.firstKey(BindRight) + " Move",
+			m.firstKey(BindSoftDrop) + " Soft Drop",
+			m.firstKey(BindRotateCW) + "/" + m.firstKey(BindRotateCCW) + " Rotate",
+			m.firstKey(BindHardDrop) + " Hard Drop",
+			m.firstKey(BindHold) + " Hold",
+		},
+		undoHelp: m.firstKey(BindUndo) + " Undo",
+	}
+}
+
+// customKeys switches to the Custom layout, starting it from the layout in
+// use if there isn't one yet, and returns it for editing.
+func (g *Game) customKeys() KeyMap {
+	if g.settings.KeyPreset != CustomKeys || g.settings.Keys == nil {
+		g.settings.Keys = g.keyPreset().keys.clone()
+		g.settings.KeyPreset = CustomKeys
+	}
+	return g.settings.Keys
+}
+
+var keyBindingsPage = &menuPage{
+	title: "Key Bindings",
+	items: keyBindingItems(),
+	hint:  "Enter adds a key, Left removes one",
+}
+
+func keyBindingItems() []settingItem {
+	items := make([]settingItem, len(keyActions))
+	for i, a := range keyActions {
+		items[i] = settingItem{
+			label: a.Label(),
+			value: func(g *Game) string {
+				if g.rebinding && g.menuSel == i {
+					return "Press a key"
+				}
+				return keysLabel(g.keyPreset().keys[a])
+			},
+			adjust: func(g *Game, dir int) {
+				m := g.customKeys()
+				if dir < 0 {
+					if n := len(m[a]); n > 0 {
+						m[a] = m[a][:n-1]
+					}
+					return
+				}
+				g.rebinding = true
+			},
+		}
+	}
+	return append(items, settingItem{
+		label: "Reset to Standard",
+		value: func(g *Game) string { return "" },
+		adjust: func(g *Game, dir int) {
+			g.settings.Keys = keyPresets[StandardKeys].keys.clone()
+			g.settings.KeyPreset = CustomKeys
+		},
+	})
+}
+
+// updateRebind waits for the key to add to the selected action; Esc
+// cancels.
+func (g *Game) updateRebind() {
+	keys := inpututil.AppendJustPressedKeys(nil)
+	if len(keys) == 0 {
+		return
+	}
+	g.rebinding = false
+	if keys[0] == ebiten.KeyEscape {
+		return
+	}
+	g.customKeys().bind(keyActions[g.menuSel], keys[0])
+}
@@ -0,0 +1,162 @@
+// Package leaderboard submits results to an online leaderboard and fetches
+// its rankings. Players are known to the server only by an anonymous
+// token made on their device; friends find each other by the short code
+// derived from it, which doesn't give the token away.
+//
+// The server takes a result as JSON posted to <endpoint>/scores, and
+// answers GET <endpoint>/boards/<board>?friends=<codes> with Rankings.
+// Both carry the token as a bearer token.
+package leaderboard
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Score is one result submitted to a board.
+type Score struct {
+	Board string `json:"board"`
+	Name  string `json:"name"` // initials, possibly empty
+	Score int    `json:"score"`
+	// Frames is a Sprint's time; Sprint boards rank by it.
+	Frames int       `json:"frames,omitempty"`
+	Lines  int       `json:"lines"`
+	Level  int       `json:"level"`
+	Date   time.Time `json:"date"`
+}
+
+// Entry is one row of a ranking.
+type Entry struct {
+	Rank   int       `json:"rank"`
+	Name   string    `json:"name"`
+	Score  int       `json:"score"`
+	Frames int       `json:"frames,omitempty"`
+	Lines  int       `json:"lines"`
+	Date   time.Time `json:"date"`
+	// Mine marks the asking player's own entry.
+	Mine bool `json:"mine,omitempty"`
+}
+
+// Rankings are a board's top entries among everyone and among the asking
+// player and their friends.
+type Rankings struct {
+	Global  []Entry `json:"global"`
+	Friends []Entry `json:"friends"`
+	// Fetched is when Fetch got them, for showing how old a cached copy is.
+	Fetched time.Time `json:"fetched"`
+}
+
+// ErrRejected is wrapped by the errors of requests the server refused and
+// will go on refusing, such as a result it won't accept. Other errors,
+// like the server being unreachable or failing, may clear up on a retry.
+var ErrRejected = errors.New("leaderboard: rejected")
+
+// Client talks to one leaderboard server as one player.
+type Client struct {
+	endpoint string
+	token    string
+	http     *http.Client
+}
+
+// New returns a client for the server at endpoint, acting as token.
+func New(endpoint, token string) *Client {
+	return &Client{endpoint: strings.TrimRight(endpoint, "/"), token: token, http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Submit sends s to the server.
+func (c *Client) Submit(ctx context.Context, s Score) error {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/scores", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// SubmitAll sends scores in order, calling done with each the server has
+// dealt with: err is nil if it took the score, or wraps ErrRejected if it
+// never will. It stops at the first score that might go through later
+// and returns why.
+func (c *Client) SubmitAll(ctx context.Context, scores []Score, done func(s Score, err error)) error {
+	for _, s := range scores {
+		err := c.Submit(ctx, s)
+		if err != nil && !errors.Is(err, ErrRejected) {
+			return err
+		}
+		done(s, err)
+	}
+	return nil
+}
+
+// Fetch gets board's rankings, the friends ones among the players with
+// the given friend codes.
+func (c *Client) Fetch(ctx context.Context, board string, friends []string) (Rankings, error) {
+	u := c.endpoint + "/boards/" + url.PathEscape(board)
+	if len(friends) > 0 {
+		u += "?" + url.Values{"friends": {strings.Join(friends, ",")}}.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return Rankings{}, err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return Rankings{}, err
+	}
+	defer resp.Body.Close()
+	var r Rankings
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return Rankings{}, err
+	}
+	r.Fetched = time.Now().UTC()
+	return r, nil
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		resp.Body.Close()
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, fmt.Errorf("%w: %s", ErrRejected, resp.Status)
+		}
+		return nil, fmt.Errorf("leaderboard: %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// NewToken makes a random anonymous player token.
+func NewToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// FriendCode is the code friends add to see token's player among their
+// friends rankings. The server works it out from the token the same way.
+func FriendCode(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return strings.ToUpper(hex.EncodeToString(sum[:4]))
+}
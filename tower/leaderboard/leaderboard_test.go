@@ -0,0 +1,104 @@
+package leaderboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	var got Score
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, "who are you", http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/scores":
+			json.NewDecoder(r.Body).Decode(&got)
+		case r.URL.Path == "/boards/Daily Challenge 2026-10-15" && r.URL.Query().Get("friends") == "AB,CD":
+			json.NewEncoder(w).Encode(Rankings{
+				Global:  []Entry{{Rank: 1, Name: "ZZZ", Score: 900}, {Rank: 7, Name: "ME", Score: 100, Mine: true}},
+				Friends: []Entry{{Rank: 1, Name: "ME", Score: 100, Mine: true}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL+"/", "tok")
+	ctx := context.Background()
+	if err := c.Submit(ctx, Score{Board: "Sprint", Name: "ME", Frames: 3600, Lines: 40}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Board != "Sprint" || got.Frames != 3600 {
+		t.Errorf("server got %+v", got)
+	}
+	r, err := c.Fetch(ctx, "Daily Challenge 2026-10-15", []string{"AB", "CD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Global) != 2 || !r.Global[1].Mine || len(r.Friends) != 1 || r.Fetched.IsZero() {
+		t.Errorf("rankings %+v", r)
+	}
+	if _, err := New(srv.URL, "nope").Fetch(ctx, "Sprint", nil); err == nil {
+		t.Error("a refused fetch succeeded")
+	}
+}
+
+func TestFriendCode(t *testing.T) {
+	a, b := NewToken(), NewToken()
+	if a == b || len(a) != 32 {
+		t.Fatalf("tokens %q and %q", a, b)
+	}
+	if FriendCode(a) != FriendCode(a) || FriendCode(a) == FriendCode(b) || len(FriendCode(a)) != 8 {
+		t.Errorf("friend codes %q and %q", FriendCode(a), FriendCode(b))
+	}
+}
+
+func TestSubmitAll(t *testing.T) {
+	status := http.StatusOK
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var s Score
+		json.NewDecoder(r.Body).Decode(&s)
+		switch {
+		case status != http.StatusOK:
+			w.WriteHeader(status)
+		case s.Board == "Bogus":
+			http.Error(w, "no such board", http.StatusBadRequest)
+		default:
+			got = append(got, s.Board)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "tok")
+	queue := []Score{{Board: "Sprint"}, {Board: "Bogus"}, {Board: "Ultra"}}
+	var done, rejected []string
+	err := c.SubmitAll(context.Background(), queue, func(s Score, err error) {
+		done = append(done, s.Board)
+		if errors.Is(err, ErrRejected) {
+			rejected = append(rejected, s.Board)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []string{"Sprint", "Ultra"}) || !slices.Equal(done, []string{"Sprint", "Bogus", "Ultra"}) || !slices.Equal(rejected, []string{"Bogus"}) {
+		t.Errorf("server took %v; done %v, rejected %v", got, done, rejected)
+	}
+
+	// A failing server keeps everything for the next try.
+	status = http.StatusServiceUnavailable
+	done = nil
+	err = c.SubmitAll(context.Background(), queue, func(s Score, err error) { done = append(done, s.Board) })
+	if err == nil || errors.Is(err, ErrRejected) || len(done) != 0 {
+		t.Errorf("on a 503: err %v, done %v", err, done)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+)
+
+// localeFS holds a JSON file per language, named by its code. The game's
+// text is written in English, which is the key into each file's strings;
+// text a file leaves out stays in English.
+//
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// locale is one language.
+type locale struct {
+	Code    string            `json:"-"`
+	Name    string            `json:"name"` // in the language itself, for the picker
+	Strings map[string]string `json:"strings"`
+}
+
+// locales are the embedded languages, English first and the rest by code.
+var locales = loadLocales()
+
+// lang is the language in use, set by applyLanguage.
+var lang = &locales[0]
+
+func loadLocales() []locale {
+	names, _ := fs.Glob(localeFS, "locales/*.json")
+	all := []locale{{Code: "en", Name: "English"}}
+	for _, name := range names {
+		code := strings.TrimSuffix(path.Base(name), ".json")
+		b, err := localeFS.ReadFile(name)
+		var l locale
+		if err == nil {
+			err = json.Unmarshal(b, &l)
+		}
+		if err != nil {
+			slog.Warn("language skipped", "file", name, "err", err)
+			continue
+		}
+		l.Code = code
+		if code == "en" {
+			all[0] = l
+			continue
+		}
+		all = append(all, l)
+	}
+	sort.Slice(all[1:], func(i, j int) bool { return all[1+i].Code < all[1+j].Code })
+	return all
+}
+
+// applyLanguage switches the game's text to the language with the given
+// code, or to English if there's none.
+func applyLanguage(code string) {
+	lang = &locales[localeIndex(code)]
+}
+
+func localeIndex(code string) int {
+	for i, l := range locales {
+		if l.Code == code {
+			return i
+		}
+	}
+	return 0
+}
+
+// tr is s in the current language.
+func tr(s string) string {
+	if t, ok := lang.Strings[s]; ok {
+		return t
+	}
+	return s
+}
+
+// trf formats args with format in the current language.
+func trf(format string, args ...any) string {
+	return fmt.Sprintf(tr(format), args...)
+}
+
+// trHelp translates a controls help line, "<keys> <action>", leaving the
+// key names as they are.
+func trHelp(line string) string {
+	keys, action, ok := strings.Cut(line, " ")
+	if !ok {
+		return tr(line)
+	}
+	return keys + " " + tr(action)
+}
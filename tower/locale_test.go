@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestLocaleKeys checks every translated string is still text the game
+// shows, with the same format verbs in the same order. A key may end a
+// longer literal, as the actions in the controls help do.
+func TestLocaleKeys(t *testing.T) {
+	var src strings.Builder
+	for _, pattern := range []string{"*.go", "engine/*.go"} {
+		names, _ := filepath.Glob(pattern)
+		for _, name := range names {
+			b, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			src.Write(b)
+		}
+	}
+	verbs := regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+	for _, l := range locales[1:] {
+		if len(l.Strings) == 0 {
+			t.Errorf("%s has no strings", l.Code)
+		}
+		for key, s := range l.Strings {
+			if !strings.Contains(src.String(), strconv.Quote(key)[1:]) {
+				t.Errorf("%s: %q isn't in the source", l.Code, key)
+			}
+			if a, b := verbs.FindAllString(key, -1), verbs.FindAllString(s, -1); strings.Join(a, " ") != strings.Join(b, " ") {
+				t.Errorf("%s: %q has verbs %v, its translation %v", l.Code, key, a, b)
+			}
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer applyLanguage("en")
+	applyLanguage("ru")
+	if lang.Code != "ru" {
+		t.Fatalf("applyLanguage picked %q", lang.Code)
+	}
+	if got := trf("Score: %d", 5); got != "Очки: 5" {
+		t.Errorf("trf gave %q", got)
+	}
+	if got := tr("Not a key"); got != "Not a key" {
+		t.Errorf("a missing string became %q", got)
+	}
+	if got := trHelp("←/→ Move"); got != "←/→ Сдвиг" {
+		t.Errorf("trHelp gave %q", got)
+	}
+	for _, r := range "Очки ←→" {
+		if _, ok := textFace.GlyphAdvance(r); !ok {
+			t.Errorf("no glyph for %q", r)
+		}
+	}
+	applyLanguage("xx")
+	if lang.Code != "en" {
+		t.Errorf("an unknown language gave %q", lang.Code)
+	}
+}
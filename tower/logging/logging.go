@@ -0,0 +1,63 @@
+// Package logging sets up the game's leveled, structured logger. Code logs
+// through log/slog; Setup decides where records go.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Options configures Setup.
+type Options struct {
+	Level slog.Level
+	// File, if set, also receives every record, rotated by size.
+	File       string
+	MaxSize    int64 // bytes before rotating; 0 uses DefaultMaxSize
+	MaxBackups int   // rotated files kept; 0 uses DefaultMaxBackups
+}
+
+const (
+	DefaultMaxSize    = 1 << 20
+	DefaultMaxBackups = 3
+)
+
+// Setup installs the default slog logger, writing text records to stderr
+// and optionally a rotating file. The returned Closer closes the file; it
+// is a no-op without one.
+func Setup(opts Options) (io.Closer, error) {
+	var w io.Writer = os.Stderr
+	var c io.Closer = nopCloser{}
+	if opts.File != "" {
+		f, err := OpenRotating(opts.File, opts.MaxSize, opts.MaxBackups)
+		if err != nil {
+			return c, err
+		}
+		w = io.MultiWriter(os.Stderr, f)
+		c = f
+	}
+	h := slog.NewTextHandler(w, &slog.HandlerOptions{Level: opts.Level})
+	slog.SetDefault(slog.New(h))
+	return c, nil
+}
+
+// ParseLevel accepts debug, info, warn, or error (any case).
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("logging: unknown level %q", s)
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
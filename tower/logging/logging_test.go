@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want slog.Level
+		ok   bool
+	}{
+		{"debug", slog.LevelDebug, true},
+		{" WARN ", slog.LevelWarn, true},
+		{"warning", slog.LevelWarn, true},
+		{"Error", slog.LevelError, true},
+		{"", slog.LevelInfo, true},
+		{"info", slog.LevelInfo, true},
+		{"loud", slog.LevelInfo, false},
+	} {
+		got, err := ParseLevel(tc.in)
+		if got != tc.want || (err == nil) != tc.ok {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, ok %v", tc.in, got, err, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestSetupFile(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	name := filepath.Join(t.TempDir(), "game.log")
+	c, err := Setup(Options{Level: slog.LevelWarn, File: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.Info("quiet")
+	slog.Warn("loud", "n", 1)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := read(t, name)
+	if strings.Contains(got, "quiet") || !strings.Contains(got, "msg=loud n=1") {
+		t.Errorf("the file holds %q, want only the warning", got)
+	}
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func read(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRotate(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "logs", "game.log")
+	r, err := OpenRotating(name, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for _, s := range []string{"aaaaa", "aaaaa", "bbbbbbbb", "cccc", "cccc", "dddd"} {
+		if _, err := r.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, want := range map[string]string{name: "dddd", name + ".1": "cccccccc", name + ".2": "bbbbbbbb"} {
+		if got := read(t, name); got != want {
+			t.Errorf("%s holds %q, want %q", filepath.Base(name), got, want)
+		}
+	}
+	if _, err := os.Stat(name + ".3"); !errors.Is(err, os.ErrNotExist) {
+		t.Error("kept more backups than asked")
+	}
+}
+
+func TestRotateAppends(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "game.log")
+	if err := os.WriteFile(name, []byte("earlier "), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenRotating(name, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Write([]byte("now"))
+	r.Close()
+	if got := read(t, name+".1"); got != "earlier " {
+		t.Errorf("the backup holds %q, want the earlier run's log", got)
+	}
+	if _, err := r.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("writing after Close: %v, want ErrClosed", err)
+	}
+}
+
+func TestRotateFailureKeepsLogging(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "game.log")
+	r, err := OpenRotating(name, 4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	// A directory in the backup's place, with something in it so it
+	// can't be removed either, stops the file being moved aside.
+	if err := os.MkdirAll(filepath.Join(name+".1", "x"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"abc", "def", "ghi"} {
+		if _, err := r.Write([]byte(s)); err != nil {
+			t.Fatalf("writing %q: %v", s, err)
+		}
+	}
+	if got := read(t, name); got != "abcdefghi" {
+		t.Errorf("the log holds %q, want every write", got)
+	}
+
+	// Once the way is clear, the next write rotates.
+	if err := os.RemoveAll(name + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	r.Write([]byte("jkl"))
+	if a, b := read(t, name), read(t, name+".1"); a != "jkl" || b != "abcdefghi" {
+		t.Errorf("after clearing the way: %q and %q, want a new file and the old one backed up", a, b)
+	}
+}
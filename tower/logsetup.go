@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"tetris/logging"
+)
+
+var logCloser io.Closer
+
+// setupLogging configures slog from TOWER_LOG_LEVEL (debug, info, warn,
+// error) and, when s.LogToFile is set, a rotating log file under the config
+// directory. It may be called again after the setting changes.
+func setupLogging(s Settings) {
+	closeLogging()
+	level, err := logging.ParseLevel(os.Getenv("TOWER_LOG_LEVEL"))
+	opts := logging.Options{Level: level}
+	if s.LogToFile {
+		if p, perr := logPath(); perr == nil {
+			opts.File = p
+		}
+	}
+	c, ferr := logging.Setup(opts)
+	logCloser = c
+	if err != nil {
+		slog.Warn("bad TOWER_LOG_LEVEL, using info", "err", err)
+	}
+	if ferr != nil {
+		slog.Error("log file unavailable", "path", opts.File, "err", ferr)
+	}
+}
+
+func closeLogging() {
+	if logCloser != nil {
+		logCloser.Close()
+		logCloser = nil
+	}
+}
@@ -0,0 +1,194 @@
+// Package luamode runs game modes written in Lua. A script defines any of
+// these globals, called from inside the engine's Step:
+//
+//	on_frame(frame)  every frame while the game is on
+//	on_clear(lines)  after a lock clears lines
+//
+// and acts on the game through the game table: game.score(),
+// game.lines(), game.level() and game.frames() read it, and
+// game.add_garbage(rows) and game.end_game(reason) change it. Scripts get
+// the base, table, string and math libraries without file access or
+// math.random, so a mode replays exactly. A callback that errors or runs
+// longer than callbackTimeout ends the game there, with the reason "script
+// stopped": the end is part of the game like anything else the script
+// does, rather than the mode playing on without its rules.
+package luamode
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+
+	"tetris/engine"
+)
+
+// callbackTimeout is as long as the script's top level or any one
+// callback may run, so a stuck loop can't hang the game.
+const callbackTimeout = 100 * time.Millisecond
+
+// stopped is the game over reason when the script fails.
+const stopped = "script stopped"
+
+// Load compiles the script at path and runs it once to check it, then
+// returns what engine.Mode.Script wants: a fresh script state per game.
+func Load(path string) (func() engine.Script, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return compile(path, string(src))
+}
+
+func compile(name, src string) (func() engine.Script, error) {
+	chunk, err := parse.Parse(strings.NewReader(src), name)
+	if err != nil {
+		return nil, err
+	}
+	proto, err := lua.Compile(chunk, name)
+	if err != nil {
+		return nil, err
+	}
+	s, err := start(name, proto)
+	if err != nil {
+		return nil, err
+	}
+	s.Close()
+	return func() engine.Script {
+		s, err := start(name, proto)
+		if err != nil {
+			// It ran when loaded, so this is unexpected; end the game
+			// as a failing callback would.
+			slog.Warn("lua mode failed to start", "script", name, "err", err)
+			return &script{name: name, failed: true}
+		}
+		return s
+	}, nil
+}
+
+// script is one game's Lua state.
+type script struct {
+	name   string
+	L      *lua.LState
+	g      *engine.Game // the game being called back for
+	broken bool         // closed, or a callback failed; the rest are skipped
+	failed bool         // the state didn't start; the first callback ends the game
+}
+
+func start(name string, proto *lua.FunctionProto) (*script, error) {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring", "require", "module"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+	math := L.GetGlobal("math").(*lua.LTable)
+	math.RawSetString("random", lua.LNil)
+	math.RawSetString("randomseed", lua.LNil)
+
+	s := &script{name: name, L: L}
+	L.SetGlobal("game", L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"score":  s.number(func(g *engine.Game) int { return g.Score() }),
+		"lines":  s.number(func(g *engine.Game) int { return g.Lines() }),
+		"level":  s.number(func(g *engine.Game) int { return g.Level() }),
+		"frames": s.number(func(g *engine.Game) int { return g.Frames() }),
+		"add_garbage": func(L *lua.LState) int {
+			if s.g != nil {
+				s.g.AddGarbage(L.CheckInt(1))
+			}
+			return 0
+		},
+		"end_game": func(L *lua.LState) int {
+			if s.g != nil {
+				s.g.End(L.OptString(1, "script ended the game"))
+			}
+			return 0
+		},
+	}))
+	L.Push(L.NewFunctionFromProto(proto))
+	ctx, cancel := context.WithTimeout(context.Background(), callbackTimeout)
+	defer cancel()
+	L.SetContext(ctx)
+	err := L.PCall(0, lua.MultRet, nil)
+	L.RemoveContext()
+	if err != nil {
+		L.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// number makes a game table getter.
+func (s *script) number(get func(g *engine.Game) int) lua.LGFunction {
+	return func(L *lua.LState) int {
+		n := 0
+		if s.g != nil {
+			n = get(s.g)
+		}
+		L.Push(lua.LNumber(n))
+		return 1
+	}
+}
+
+func (s *script) Frame(g *engine.Game) { s.call(g, "on_frame", g.Frames()) }
+
+func (s *script) Cleared(g *engine.Game, lines int) { s.call(g, "on_clear", lines) }
+
+// Close frees the Lua state, or marks it to be freed as soon as the
+// callback running now returns.
+func (s *script) Close() {
+	s.broken = true
+	if s.g == nil && s.L != nil {
+		s.L.Close()
+		s.L = nil
+	}
+}
+
+// call runs the global fn if the script defines it. An error or a
+// callback running past callbackTimeout ends the game.
+func (s *script) call(g *engine.Game, fn string, arg int) {
+	if s.failed {
+		s.failed = false
+		g.End(stopped)
+		return
+	}
+	if s.broken {
+		return
+	}
+	f, ok := s.L.GetGlobal(fn).(*lua.LFunction)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), callbackTimeout)
+	s.g = g
+	s.L.SetContext(ctx)
+	defer func() {
+		cancel()
+		s.L.RemoveContext()
+		s.g = nil
+		if s.broken {
+			s.Close()
+		}
+	}()
+	if err := s.L.CallByParam(lua.P{Fn: f, Protect: true}, lua.LNumber(arg)); err != nil {
+		slog.Warn("lua mode stopped", "script", s.name, "callback", fn, "err", fmt.Sprint(err))
+		s.broken = true
+		g.End(stopped)
+	}
+}
@@ -0,0 +1,118 @@
+package luamode
+
+import (
+	"testing"
+	"time"
+
+	"tetris/engine"
+)
+
+func game(t *testing.T, src string) *engine.Game {
+	t.Helper()
+	mk, err := compile("test.lua", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return engine.New(1, engine.Mode{Name: "Lua", Width: engine.BoardW, Script: mk})
+}
+
+func TestFrameCallback(t *testing.T) {
+	g := game(t, `
+function on_frame(frame)
+  if frame == 5 then game.add_garbage(2) end
+  if frame == 10 then game.end_game("ten frames") end
+end`)
+	for range 20 {
+		g.Step(engine.Input{})
+	}
+	if !g.GameOver() {
+		t.Fatal("on_frame never ended the game")
+	}
+	if g.Frames() != 20 || g.IncomingRows() != 2 {
+		t.Errorf("frames %d, incoming %d; want 20 and the 2 rows added on frame 5", g.Frames(), g.IncomingRows())
+	}
+}
+
+func TestEachGameGetsItsOwnState(t *testing.T) {
+	src := `n = 0
+function on_frame() n = n + 1; if n == 3 then game.end_game() end end`
+	mk, err := compile("test.lua", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := engine.Mode{Name: "Lua", Width: engine.BoardW, Script: mk}
+	a := engine.New(1, m)
+	for range 2 {
+		a.Step(engine.Input{})
+	}
+	b := engine.New(1, m)
+	for range 2 {
+		b.Step(engine.Input{})
+	}
+	if a.GameOver() || b.GameOver() {
+		t.Error("the two games shared one counter")
+	}
+}
+
+func TestSandbox(t *testing.T) {
+	for _, src := range []string{"dofile('x')", "math.random()", "require('os')", "io.write('x')"} {
+		if _, err := compile("test.lua", src); err == nil {
+			t.Errorf("%q ran", src)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := compile("test.lua", "function ("); err == nil {
+		t.Error("a syntax error compiled")
+	}
+	// A failing callback ends the game on the frame it failed.
+	g := game(t, `function on_frame(frame) if frame == 2 then error("boom") end end`)
+	g.Step(engine.Input{})
+	if g.GameOver() {
+		t.Fatal("the game ended before the script failed")
+	}
+	g.Step(engine.Input{})
+	if !g.GameOver() {
+		t.Error("a script error left the game running")
+	}
+}
+
+func TestStuckScript(t *testing.T) {
+	if _, err := compile("test.lua", "while true do end"); err == nil {
+		t.Error("a script stuck at the top level loaded")
+	}
+	g := game(t, `function on_frame() while true do end end`)
+	start := time.Now()
+	g.Step(engine.Input{})
+	if d := time.Since(start); d > 5*callbackTimeout {
+		t.Errorf("the stuck frame took %v", d)
+	}
+	if !g.GameOver() {
+		t.Error("the stuck script left the game running")
+	}
+}
+
+func TestStatesClosed(t *testing.T) {
+	mk, err := compile("test.lua", `function on_frame(frame) if frame == 2 then game.end_game() end end`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s *script
+	m := engine.Mode{Name: "Lua", Width: engine.BoardW, Script: func() engine.Script {
+		s = mk().(*script)
+		return s
+	}}
+	g := engine.New(1, m)
+	for range 3 {
+		g.Step(engine.Input{})
+	}
+	if !g.GameOver() || s.L != nil {
+		t.Error("the state outlived its game")
+	}
+
+	engine.New(1, m).Close()
+	if s.L != nil {
+		t.Error("closing a dropped game left its state open")
+	}
+}
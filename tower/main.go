@@ -1,483 +1,738 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image/color"
-	"log"
-	"math/rand"
+	"log/slog"
+	"os"
 	"runtime"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 	"github.com/hajimehoshi/ebiten/v2/vector"
-	"golang.org/x/image/font/basicfont"
+
+	"tetris/engine"
+	"tetris/sound"
 )
 
 const (
-	boardW = 10
-	boardH = 20
+	// countdownFrames holds play still for a 3-2-1 when a run starts and
+	// after unpausing.
+	countdownFrames = 3 * 60
 
 	logicalW = 480
 	logicalH = 640
 )
 
-var (
-	bgColor     = color.RGBA{18, 18, 24, 255}
-	gridColor   = color.RGBA{40, 40, 55, 255}
-	ghostAlpha  = uint8(96)
-	pieceColors = []color.RGBA{
-		{0, 255, 255, 255}, // I
-		{255, 255, 0, 255}, // O
-		{160, 0, 240, 255}, // T
-		{0, 200, 0, 255},   // S
-		{220, 0, 0, 255},   // Z
-		{0, 80, 220, 255},  // J
-		{255, 140, 0, 255}, // L
-	}
-)
+var ghostAlpha = uint8(96)
 
-type point struct {
-	x, y int
+type Game struct {
+	*engine.Game
+	modifiers   Modifiers
+	shifters    [2]shifter      // per player
+	sources     [][]InputSource // per player, from playerSources
+	tilt        tiltShifter
+	state       appState
+	under       appState // what Settings or High Scores opened over
+	pauseSel    int      // highlighted pause menu row
+	summarySel  int      // highlighted summary row, Retry or Menu
+	titleSel    int      // highlighted title menu row
+	titleMode   int      // mode the title screen starts, an index into modes
+	quitting    bool     // Quit was picked on the title screen
+	canContinue bool     // there's a quit run for the title's Continue
+	rebinding   bool     // the Key Bindings page is waiting for a key
+	padLost     bool     // paused because the gamepad in use disconnected
+	restartHold int      // frames the quick-restart key has been held, -1 until released
+	startedAt   time.Time
+	rank        int        // leaderboard place of the finished game, 0 if none
+	offline     bool       // replays don't submit scores
+	cpu         *botDriver // non-nil while the CPU plays
+	rivalCPU    *botDriver // non-nil while the CPU plays the versus rival
+	cpuPlayed   bool       // the CPU played some of this run
+	rival       *Game      // player 2's board in versus, nil otherwise
+	screenW     int        // the screen's size from the last Layout
+	screenH     int
+
+	settings    Settings
+	fx          effects
+	menu        []*menuPage // open menu pages, innermost last
+	menuSel     int
+	gestures    gestureReader
+	drag        dragPad
+	pad         padReader
+	tuner       *tuner
+	touchEdit   *touchEditor // non-nil while the touch buttons are edited
+	bench       *benchmark   // non-nil while the benchmark scene runs
+	seed        int64
+	rec         *recorder       // records this run's inputs
+	prompt      *replayPrompt   // asks to save the finished run's replay
+	naming      *initialsPrompt // asks for initials for a new high score
+	scoreView   *scoreView      // the high score screen's page
+	replays     *replayBrowser  // the saved replay list
+	playback    *playback       // the replay being watched
+	countdown   int             // frames of the 3-2-1 countdown left
+	quitConfirm bool            // asking whether to abandon the run
+	cardNote    string          // where the result card went, for the game over screen
+	capture     capture         // F12 screenshots and the clip recorder
+	assisted    bool            // played any of the game in slow mode
+	slowAcc     float64         // slow mode's share of a step built up so far
+	heldBack    []frameInput    // input from frames slow mode skipped
+	qr          *ebiten.Image   // the finished run's challenge link
 }
 
-// pieceShapes[kind][rot] -> []point in a 4x4 bounding box
-var pieceShapes = [7][4][]point{
-	// I
-	{
-		{{0, 1}, {1, 1}, {2, 1}, {3, 1}},
-		{{2, 0}, {2, 1}, {2, 2}, {2, 3}},
-		{{0, 2}, {1, 2}, {2, 2}, {3, 2}},
-		{{1, 0}, {1, 1}, {1, 2}, {1, 3}},
-	},
-	// O
-	{
-		{{1, 1}, {2, 1}, {1, 2}, {2, 2}},
-		{{1, 1}, {2, 1}, {1, 2}, {2, 2}},
-		{{1, 1}, {2, 1}, {1, 2}, {2, 2}},
-		{{1, 1}, {2, 1}, {1, 2}, {2, 2}},
-	},
-	// T
-	{
-		{{1, 0}, {0, 1}, {1, 1}, {2, 1}},
-		{{1, 0}, {1, 1}, {2, 1}, {1, 2}},
-		{{0, 1}, {1, 1}, {2, 1}, {1, 2}},
-		{{1, 0}, {0, 1}, {1, 1}, {1, 2}},
-	},
-	// S
-	{
-		{{1, 0}, {2, 0}, {0, 1}, {1, 1}},
-		{{1, 0}, {1, 1}, {2, 1}, {2, 2}},
-		{{1, 1}, {2, 1}, {0, 2}, {1, 2}},
-		{{0, 0}, {0, 1}, {1, 1}, {1, 2}},
-	},
-	// Z
-	{
-		{{0, 0}, {1, 0}, {1, 1}, {2, 1}},
-		{{2, 0}, {1, 1}, {2, 1}, {1, 2}},
-		{{0, 1}, {1, 1}, {1, 2}, {2, 2}},
-		{{1, 0}, {0, 1}, {1, 1}, {0, 2}},
-	},
-	// J
-	{
-		{{0, 0}, {0, 1}, {1, 1}, {2, 1}},
-		{{1, 0}, {2, 0}, {1, 1}, {1, 2}},
-		{{0, 1}, {1, 1}, {2, 1}, {2, 2}},
-		{{1, 0}, {1, 1}, {0, 2}, {1, 2}},
-	},
-	// L
-	{
-		{{2, 0}, {0, 1}, {1, 1}, {2, 1}},
-		{{1, 0}, {1, 1}, {1, 2}, {2, 2}},
-		{{0, 1}, {1, 1}, {2, 1}, {0, 2}},
-		{{0, 0}, {1, 0}, {1, 1}, {1, 2}},
-	},
+func NewGame() *Game {
+	return newGameSeeded(seedFor(modes[0], time.Now()), modes[0], Modifiers{})
 }
 
-type activePiece struct {
-	kind int
-	rot  int
-	x, y int
+// newGameSeeded starts a game of mode m with modifiers mods whose piece
+// sequence is fixed by seed.
+func newGameSeeded(seed int64, m engine.Mode, mods Modifiers) *Game {
+	g := &Game{}
+	g.init(seed, m, mods)
+	return g
 }
 
-type Game struct {
-	board            [boardH][boardW]int // 0 empty, 1..7 piece kinds
-	cur              activePiece
-	nextKind         int
-	bag              []int
-	rng              *rand.Rand
-	score            int
-	lines            int
-	level            int
-	dropFrameCounter int
-	gameOver         bool
+// init sets g up in place for a new game, so the engine's hooks and
+// observers point at g itself, closing the game it replaces.
+func (g *Game) init(seed int64, m engine.Mode, mods Modifiers) {
+	if g.Game != nil {
+		g.Game.Close()
+	}
+	*g = Game{
+		Game:      engine.New(seed, mods.apply(m)),
+		modifiers: mods,
+		state:     statePlaying,
+		seed:      seed,
+		startedAt: time.Now(),
+		settings:  DefaultSettings(),
+	}
+	g.Hooks = g.hooks()
+	g.Subscribe(sfx{g: g})
+	g.Subscribe(statusFeed{g: g})
+	g.attachRival()
+	g.sources = g.playerSources()
 }
 
-func NewGame() *Game {
-	g := &Game{
-		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+// hooks ties the engine's events to effects, scores and the profile. The
+// lock, clear and game over sounds are sfx's.
+func (g *Game) hooks() engine.Hooks {
+	return engine.Hooks{
+		Moved: func(player, dx, rot int) {
+			g.fx.tweens[player].push(float32(dx), rot)
+			if rot != 0 {
+				g.play(sound.Rotate)
+			} else {
+				g.play(sound.Move)
+			}
+		},
+		Spawned: func(player int) {
+			g.fx.tweens[player] = tween{age: tweenFrames}
+		},
+		Dropped: func(player int, p engine.Piece, rows int) {
+			if g.settings.Juice {
+				g.fx.startTrail(g.PieceSet(), p, rows)
+			}
+		},
+		Cleared: func(rows []engine.ClearedRow) {
+			g.fx.startDissolve(rows)
+		},
+		Locked: func(player, cleared int, tspin bool) {
+			g.fx.lastLock = g.Frames()
+			g.fx.startLockFlash(g.PieceSet().Cells(g.Player(player).Piece))
+			if (cleared == 4 || (tspin && cleared == 3)) && !g.settings.ReduceMotion {
+				g.fx.startPunch()
+			}
+			if cleared == 4 && g.settings.Juice && !g.settings.ReduceMotion {
+				g.fx.startShake()
+			}
+		},
+		LevelUp: func(level int) {
+			g.fx.startLevelBanner(level)
+			if g.settings.Juice {
+				g.fx.startBorderFlash()
+			}
+		},
+		Prestige: func(int) { g.countPrestige() },
+		GameOver: func(string) {
+			g.submitScore()
+			g.markSolved()
+			if !g.offline && g.bench == nil {
+				recordRun(strings.ToLower(g.Mode().Name), time.Since(g.startedAt))
+			}
+		},
 	}
-	g.nextKind = g.popBag()
-	g.spawn()
-	return g
 }
 
+// Reset starts a new game of the same mode and modifiers, with the CPU
+// still playing whoever it was.
 func (g *Game) Reset() {
-	*g = *NewGame()
+	cpu, rival := g.cpu != nil, g.rivalCPU != nil
+	g.start(g.Mode())
+	if cpu {
+		g.cpu, g.cpuPlayed = newCPU(), true
+	}
+	if rival {
+		g.rivalCPU = newCPU()
+	}
+	g.sources = g.playerSources()
 }
 
-func (g *Game) popBag() int {
-	if len(g.bag) == 0 {
-		g.bag = []int{0, 1, 2, 3, 4, 5, 6}
-		g.rng.Shuffle(len(g.bag), func(i, j int) { g.bag[i], g.bag[j] = g.bag[j], g.bag[i] })
-	}
-	v := g.bag[0]
-	g.bag = g.bag[1:]
-	return v
+// start begins a new game of m behind the countdown, keeping the
+// settings, modifiers, gamepad and any run to continue.
+func (g *Game) start(m engine.Mode) {
+	s, mods, pad, cont := g.settings, g.modifiers, g.pad, g.canContinue
+	g.init(seedFor(m, time.Now()), m, mods)
+	g.settings, g.pad, g.canContinue = s, pad, cont
+	g.countdown = countdownFrames
+	g.publishStatus()
 }
 
-func (g *Game) spawn() {
-	g.cur = activePiece{
-		kind: g.nextKind,
-		rot:  0,
-		x:    3,
-		y:    0,
+func (g *Game) Update() error {
+	g.fx.update()
+	if g.rival != nil {
+		g.rival.fx.update()
+	}
+	updateBanner()
+	g.publishLive()
+	g.updateMusic()
+	if g.quitting {
+		return ebiten.Termination
+	}
+	// The key that answers the quit prompt shouldn't also reach the pause
+	// menu.
+	asked := g.quitConfirm
+	if err := g.updateQuit(); err != nil || asked || g.quitConfirm {
+		return err
+	}
+	if g.bench != nil {
+		g.updateBenchmark()
+		return nil
 	}
-	g.nextKind = g.popBag()
-	if g.collides(g.cur) {
-		g.gameOver = true
+	if g.pad.poll() && g.state == statePlaying {
+		g.pause()
+		g.padLost = true
 	}
-}
-
-func (g *Game) pieceCells(ap activePiece) []point {
-	src := pieceShapes[ap.kind][ap.rot]
-	dst := make([]point, len(src))
-	for i, p := range src {
-		dst[i] = point{ap.x + p.x, ap.y + p.y}
+	if !ebiten.IsFocused() && g.state == statePlaying && g.activeRun() {
+		// The game keeps running unfocused; don't let gravity play on.
+		g.pause()
 	}
-	return dst
-}
-
-func (g *Game) collides(ap activePiece) bool {
-	for _, p := range g.pieceCells(ap) {
-		if p.x < 0 || p.x >= boardW || p.y >= boardH {
-			return true
-		}
-		if p.y >= 0 && g.board[p.y][p.x] != 0 {
-			return true
+	g.updateFullscreen()
+	g.updateCapture()
+	if g.touchEdit != nil {
+		g.updateTouchEdit()
+		return nil
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF1) && g.state != stateHighScores {
+		if g.state == stateSettings {
+			g.menu = g.menu[:1]
+			g.closeMenu()
+		} else {
+			g.openMenu(settingsPage)
 		}
+		return nil
 	}
-	return false
-}
 
-func (g *Game) lockPiece() {
-	for _, p := range g.pieceCells(g.cur) {
-		if p.y < 0 {
-			g.gameOver = true
-			return
+	switch g.state {
+	case stateTitle:
+		g.updateTitle()
+	case stateSettings:
+		g.updateMenu()
+	case stateHighScores:
+		g.updateScoreView()
+	case stateSummary:
+		g.updateSummary()
+	case statePaused:
+		if g.padLost {
+			// Only the pad coming back or a deliberate keypress resumes.
+			if g.pad.active || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+				g.resume()
+			}
+			return nil
 		}
-		g.board[p.y][p.x] = g.cur.kind + 1
+		g.updatePauseMenu()
+	case stateGameOver:
+		g.updateGameOver()
+	case stateReplays:
+		g.updateReplays()
+	case stateReplay:
+		g.updatePlayback()
+	case stateEditor:
+		g.updateEditor()
+	case statePlaying:
+		g.updatePlaying()
 	}
-	g.clearLines()
-	g.spawn()
+	return nil
 }
 
-func (g *Game) clearLines() {
-	newRows := make([][boardW]int, 0, boardH)
-	cleared := 0
-	for y := 0; y < boardH; y++ {
-		full := true
-		for x := 0; x < boardW; x++ {
-			if g.board[y][x] == 0 {
-				full = false
-				break
+// updatePlaying reads input and steps the game one frame.
+func (g *Game) updatePlaying() {
+	if g.updateQuickRestart() {
+		return
+	}
+	if g.countdown > 0 {
+		g.countdown--
+		return
+	}
+	g.updateCPUToggle()
+
+	ins := g.pollInputs()
+	if g.state != statePlaying {
+		// A touch on the settings button opened the menu.
+		return
+	}
+	if ins[0].pause {
+		g.pause()
+		return
+	}
+	if g.modifiers.MirrorControls && g.cpu == nil {
+		for i := range ins {
+			if i == 0 || g.rivalCPU == nil {
+				ins[i].mirror()
 			}
 		}
-		if full {
-			cleared++
-		} else {
-			newRows = append(newRows, g.board[y])
-		}
 	}
-	for len(newRows) < boardH {
-		newRows = append([][boardW]int{{}}, newRows...)
+	if !g.slowStep(ins) {
+		return
 	}
-	for y := 0; y < boardH; y++ {
-		g.board[y] = newRows[y]
+	if g.rec == nil {
+		g.rec = newRecorder("", g)
 	}
-	if cleared > 0 {
-		g.lines += cleared
-		g.level = g.lines / 10
-		scoreTable := []int{0, 40, 100, 300, 1200}
-		if cleared >= 0 && cleared <= 4 {
-			g.score += scoreTable[cleared] * (g.level + 1)
-		}
+	g.stepPlayers(ins...)
+	stepsTotal.Add(1)
+	g.rec.frame(g.Hash(), ins...)
+	if g.GameOver() {
+		g.finishRecording()
+		g.saveClip()
+		g.setState(stateGameOver)
 	}
 }
 
-func (g *Game) tryMove(dx, dy int) bool {
-	next := g.cur
-	next.x += dx
-	next.y += dy
-	if !g.collides(next) {
-		g.cur = next
-		return true
+// updateGameOver runs the game over screen's prompts, then waits for a
+// restart or Esc back to the title.
+func (g *Game) updateGameOver() {
+	if g.updateQuickRestart() {
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF2) {
+		g.exportResultCard()
+	}
+	switch {
+	case g.naming != nil:
+		g.updateInitials()
+	case g.prompt != nil:
+		g.updateReplayPrompt()
+	case inpututil.IsKeyJustPressed(ebiten.KeyH):
+		g.openScoreView(g.Mode().Name)
+	case inpututil.IsKeyJustPressed(ebiten.KeyS):
+		g.setState(stateSummary)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape) || g.pad.justPressed(ebiten.StandardGamepadButtonRightRight):
+		if g.Mode().Puzzle == editorPuzzle {
+			g.openEditor()
+		} else {
+			g.setState(stateTitle)
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace) ||
+		inpututil.IsKeyJustPressed(ebiten.KeyEnter) ||
+		g.pad.justPressed(ebiten.StandardGamepadButtonRightBottom) ||
+		len(justTouchIDs(nil)) > 0:
+		g.Reset()
 	}
-	return false
 }
 
-func (g *Game) tryRotate(dir int) bool {
-	next := g.cur
-	next.rot = (next.rot + dir + 4) % 4
-	// simple wall kicks
-	for _, ox := range []int{0, -1, 1, -2, 2} {
-		test := next
-		test.x += ox
-		if !g.collides(test) {
-			g.cur = test
-			return true
+// resume unpauses behind a countdown, so the player can find the piece
+// again before it moves.
+func (g *Game) resume() {
+	g.setState(statePlaying)
+	g.padLost = false
+	g.countdown = countdownFrames
+}
+
+// stopRecording drops the run's input log, first writing it if it was
+// asked for with -record.
+func (g *Game) stopRecording() {
+	if g.rec.path != "" {
+		if err := g.rec.save(); err != nil {
+			slog.Error("input log save failed", "err", err)
 		}
 	}
-	return false
+	g.rec = nil
 }
 
-func (g *Game) hardDrop() {
-	for g.tryMove(0, 1) {
+// stepPlayers advances one frame with an input per player.
+func (g *Game) stepPlayers(ins ...frameInput) {
+	steps := make([]engine.Input, len(ins))
+	for i, in := range ins {
+		steps[i] = in.input()
 	}
-	g.lockPiece()
-	g.dropFrameCounter = 0
+	g.Step(steps...)
 }
 
-func gravityFrames(level int) int {
-	// Faster as level increases; min 2 frames
-	f := 30 - level*2
-	if f < 2 {
-		f = 2
+func (g *Game) Draw(screen *ebiten.Image) {
+	if g.bench != nil {
+		g.bench.benchFrame()
+	}
+	g.drawWithCamera(screen, g.drawScene)
+	g.drawCapture(screen)
+	if g.bench != nil {
+		g.drawBenchmark(screen)
 	}
-	return f
 }
 
-func (g *Game) Update() error {
-	if g.gameOver {
-		// Any key or touch to restart
-		if inpututil.IsKeyJustPressed(ebiten.KeySpace) ||
-			inpututil.IsKeyJustPressed(ebiten.KeyEnter) ||
-			len(inpututil.AppendJustPressedTouchIDs(nil)) > 0 {
-			g.Reset()
+func (g *Game) drawScene(screen *ebiten.Image) {
+	pal := g.palette()
+	g.drawBackground(screen)
+	switch g.base() {
+	case stateTitle:
+		g.drawTitle(screen)
+	case stateReplays:
+		g.drawReplays(screen)
+	case stateEditor:
+		g.drawEditor(screen)
+	case stateReplay:
+		g.drawPlayback(screen)
+	default:
+		g.drawPlayfield(screen)
+	}
+	g.drawUpdateBanner(screen)
+
+	w, h := screen.Size()
+	if g.base() == statePaused {
+		vector.DrawFilledRect(screen, 0, 0, float32(w), float32(h), alpha(pal.Shade, 160), false)
+		if g.padLost {
+			g.drawCentered(screen, tr("Controller disconnected"), float32(w)/2, float32(h)/2-10, pal.Text)
+			g.drawCentered(screen, tr("Reconnect it, or press Enter to use the keyboard"), float32(w)/2, float32(h)/2+8, pal.Text)
+		} else if g.state == statePaused {
+			g.drawPauseMenu(screen)
 		}
-		return nil
+	}
+	if g.countdown > 0 {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(4, 4)
+		op.GeoM.Translate(float64(w/2-14), float64(h/2))
+		text.DrawWithOptions(screen, fmt.Sprint((g.countdown+59)/60), textFace, op)
 	}
 
-	// Keyboard inputs
-	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) {
-		g.tryMove(-1, 0)
+	if g.state == stateHighScores {
+		g.drawScoreView(screen)
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) {
-		g.tryMove(1, 0)
+	if g.state == stateSummary {
+		g.drawSummary(screen)
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
-		g.tryRotate(-1)
+	if g.state == stateSettings {
+		g.drawMenu(screen)
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyX) || inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
-		g.tryRotate(1)
+	if g.touchEdit != nil {
+		g.drawTouchEdit(screen)
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		g.hardDrop()
+	if g.quitConfirm {
+		g.drawQuitConfirm(screen)
 	}
+}
 
-	softDrop := ebiten.IsKeyPressed(ebiten.KeyDown) || ebiten.IsKeyPressed(ebiten.KeyS)
-
-	// Touch inputs for mobile: simple 4-button layout at bottom
-	if runtime.GOOS == "ios" || runtime.GOOS == "android" {
-		w, h := ebiten.WindowSize()
-		if w == 0 || h == 0 {
-			w, h = logicalW, logicalH
-		}
-		ctrlH := 160
-		btnY := h - ctrlH
-		btnW := w / 4
-
-		justIDs := inpututil.AppendJustPressedTouchIDs(nil)
-		downIDs := ebiten.AppendTouchIDs(nil)
-
-		justPressIn := func(ix int) bool {
-			for _, id := range justIDs {
-				x, y := ebiten.TouchPosition(id)
-				if y >= btnY && x >= ix*btnW && x < (ix+1)*btnW {
-					return true
-				}
-			}
-			return false
-		}
-		pressIn := func(ix int) bool {
-			for _, id := range downIDs {
-				x, y := ebiten.TouchPosition(id)
-				if y >= btnY && x >= ix*btnW && x < (ix+1)*btnW {
-					return true
-				}
-			}
-			return false
+// drawPlayfield draws the board, the side panel and, once the game ends,
+// the game over screen.
+func (g *Game) drawPlayfield(screen *ebiten.Image) {
+	pal := g.palette()
+	if g.rival != nil {
+		g.drawVersus(screen)
+		g.drawGameOver(screen)
+		return
+	}
+	l := g.layout()
+	tile, originY := l.tile, l.originY
+
+	g.drawBoardView(screen, l)
+	g.drawRestartHold(screen, l)
+	g.drawStreaks(screen, l)
+	g.drawRaceClock(screen, l)
+	g.drawLevelBanner(screen, l)
+	th := g.theme()
+
+	// Right panel info, sized by the UI scale
+	panelX := l.panelX
+	k := float32(g.settings.UIScale)
+	if g.cpu != nil {
+		g.drawText(screen, tr("CPU (F3 takes over)"), l.originX+4*k, originY+l.boardPxH-8*k, pal.Info)
+	}
+	g.drawText(screen, tr("Next"), panelX, originY+14*k, pal.Text)
+	// A puzzle's queue runs out.
+	if queue := g.Queue(); len(queue) > 0 {
+		drawNext(screen, g.PieceSet(), panelX, originY+20*k, tile, queue[0], pal.Pieces[queue[0]], th)
+		// The rest of the queue, smaller, in a column beside the next piece.
+		for i, kind := range queue[1:] {
+			drawNext(screen, g.PieceSet(), panelX+100*k, originY+float32(18+19*i)*k, tile*3/7, kind, pal.Pieces[kind], th)
 		}
+	}
 
-		// Buttons: [0]=Left [1]=Right [2]=Rotate [3]=Drop (hard)
-		if justPressIn(0) {
-			g.tryMove(-1, 0)
-		}
-		if justPressIn(1) {
-			g.tryMove(1, 0)
+	switch {
+	case g.Mode().Rules.NoHold:
+	case !g.Mode().Coop:
+		g.drawText(screen, tr("Hold"), panelX, originY+90*k, pal.Text)
+		drawHold(screen, g.PieceSet(), panelX, originY+96*k, tile, g.Player(0), th)
+	default:
+		g.drawText(screen, tr("Hold 1"), panelX, originY+90*k, pal.Text)
+		drawHold(screen, g.PieceSet(), panelX, originY+96*k, tile, g.Player(0), th)
+		g.drawText(screen, tr("Hold 2"), panelX+64*k, originY+90*k, pal.Text)
+		drawHold(screen, g.PieceSet(), panelX+64*k, originY+96*k, tile, g.Player(1), th)
+	}
+
+	g.drawText(screen, trf("Score: %d", g.Score()), panelX, originY+170*k, pal.Text)
+	g.drawText(screen, trf("Lines: %d", g.Lines()), panelX, originY+190*k, pal.Text)
+	g.drawText(screen, trf(g.StatusFormat()), panelX, originY+210*k, pal.Text)
+	g.drawBossHealth(screen, panelX, originY+218*k)
+
+	if g.Mode().Coop {
+		g.drawText(screen, tr("Player 1:"), panelX, originY+240*k, pal.Text)
+		g.drawText(screen, tr("A/D Move, S Soft"), panelX, originY+256*k, pal.Text)
+		g.drawText(screen, tr("W/Q Rotate, E Hold"), panelX, originY+272*k, pal.Text)
+		g.drawText(screen, trHelp("Space Hard Drop"), panelX, originY+288*k, pal.Text)
+		g.drawText(screen, tr("Player 2:"), panelX, originY+312*k, pal.Text)
+		g.drawText(screen, tr("←/→ Move, ↓ Soft"), panelX, originY+328*k, pal.Text)
+		g.drawText(screen, tr("↑// Rotate, RShift Hold"), panelX, originY+344*k, pal.Text)
+		g.drawText(screen, trHelp("Enter Hard Drop"), panelX, originY+360*k, pal.Text)
+	} else if g.settings.ShowStats {
+		g.drawStatsPanel(screen, panelX, originY+240*k)
+	} else if !touchScreen() {
+		g.drawText(screen, tr("Controls:"), panelX, originY+240*k, pal.Text)
+		lines := append(slices.Clone(g.keyPreset().help), keysLabel(g.keyPreset().keys[BindPause])+" Pause", "F1 Settings")
+		if g.CanUndo() {
+			lines = append(lines, g.keyPreset().undoHelp)
 		}
-		if justPressIn(2) {
-			g.tryRotate(1)
+		for i, s := range lines {
+			lines[i] = trHelp(s)
 		}
-		if justPressIn(3) {
-			g.hardDrop()
+		if g.settings.RestartKey != "" {
+			lines = append(lines, trf("Hold %s Restart", g.settings.RestartKey))
 		}
-		// Soft drop when any touch is held in the left half of the bottom area
-		if pressIn(0) || pressIn(1) {
-			softDrop = true
+		for i, s := range lines {
+			g.drawText(screen, s, panelX, originY+float32(256+16*i)*k, pal.Text)
 		}
 	}
 
-	// Gravity and soft drop
-	g.dropFrameCounter++
-	if softDrop {
-		// faster drop when holding down
-		if !g.tryMove(0, 1) {
-			g.lockPiece()
-		}
-		g.dropFrameCounter = 0
-	} else if g.dropFrameCounter >= gravityFrames(g.level) {
-		if !g.tryMove(0, 1) {
-			g.lockPiece()
-		}
-		g.dropFrameCounter = 0
+	// Touch buttons
+	if touchScreen() {
+		b := l.settingsButton()
+		vector.DrawFilledRect(screen, b.x, b.y, b.w, b.h, alpha(pal.Text, 20), false)
+		g.drawText(screen, tr("Settings"), b.x+4*k, b.y+b.h*0.7, pal.Text)
+		g.drawTouchControls(screen)
 	}
 
-	return nil
+	g.drawGameOver(screen)
 }
 
-func (g *Game) ghostPieceY() int {
-	ghost := g.cur
-	for !g.collides(activePiece{kind: ghost.kind, rot: ghost.rot, x: ghost.x, y: ghost.y + 1}) {
-		ghost.y++
+// drawGameOver is the overlay over a finished game: the result, the
+// prompts and the leaderboard.
+func (g *Game) drawGameOver(screen *ebiten.Image) {
+	pal := g.palette()
+	if g.base() != stateGameOver {
+		return
+	}
+	w, h := screen.Size()
+	overlay := alpha(pal.Shade, 160)
+	vector.DrawFilledRect(screen, 0, 0, float32(w), float32(h), overlay, false)
+	msg := tr("Game Over")
+	switch {
+	case g.Mode().Versus && g.Won():
+		msg = tr("Player 1 Wins!")
+	case g.Mode().Versus:
+		msg = tr("Player 2 Wins!")
+	case g.Mode().Puzzle != "" && g.Won():
+		msg = tr("Solved!")
+	case g.Mode().Puzzle != "":
+		msg = tr("Out of Pieces")
+	case g.Won() && g.Mode().LineGoal > 0:
+		msg = trf("Finished in %s", raceTime(g.Frames()))
+	case g.Won() && g.Mode().TimeLimit > 0:
+		msg = tr("Time's Up!")
+	case g.Won():
+		msg = tr("You Win!")
+	}
+	g.drawChallengeQR(screen, float32(h)/2-30)
+	cx := float32(w) / 2
+	g.drawCentered(screen, msg, cx, float32(h)/2-10, pal.Text)
+	g.drawCentered(screen, tr("Tap or Space/Enter to restart, Esc for title"), cx, float32(h)/2+8, pal.Text)
+	card := tr("F2 saves a result card, H high scores, S stats")
+	if g.cardNote != "" {
+		card = g.cardNote
+	}
+	g.drawCentered(screen, card, cx, float32(h)/2+22, pal.Dim)
+	if g.naming != nil {
+		g.drawInitialsPrompt(screen, float32(h)/2+28)
+		g.drawHighScores(screen, float32(h)/2+72)
+	} else if g.prompt != nil {
+		g.drawReplayPrompt(screen, float32(h)/2+28)
+		g.drawHighScores(screen, float32(h)/2+72)
+	} else {
+		g.drawHighScores(screen, float32(h)/2+40)
 	}
-	return ghost.y
 }
 
-func (g *Game) Draw(screen *ebiten.Image) {
-	screen.Fill(bgColor)
+func drawCell(screen *ebiten.Image, originX, originY, tile float32, x, y int, c color.RGBA, style BlockStyle) {
+	drawCellPx(screen, originX+float32(x)*tile, originY+float32(y)*tile, tile, c, style)
+}
 
-	// Layout
-	w, h := screen.Size()
-	rightPanel := 150.0
-	margin := 16.0
-	ctrlH := 0.0
-	if runtime.GOOS == "ios" || runtime.GOOS == "android" {
-		ctrlH = 160.0
-	}
-	playWidth := float32(w) - float32(rightPanel) - float32(margin*3)
-	playHeight := float32(h) - float32(margin*2) - float32(ctrlH)
-	tile := minF(playWidth/boardW, playHeight/boardH)
-	boardPxW := tile * boardW
-	boardPxH := tile * boardH
-	originX := float32(margin)
-	originY := float32(margin)
-
-	// Grid background
-	vector.DrawFilledRect(screen, originX-2, originY-2, boardPxW+4, boardPxH+4, gridColor, false)
+func drawCellPx(screen *ebiten.Image, px, py, tile float32, c color.RGBA, style BlockStyle) {
+	drawTile(screen, px, py, tile, c, style.tile())
+}
+
+// drawBoard draws the playfield and everything on it with its top-left
+// cell at (originX, originY).
+func (g *Game) drawBoard(screen *ebiten.Image, originX, originY float32, l layout) {
+	th := g.theme()
+	pal := th.Palette
+	tile, boardPxW, boardPxH := l.tile, l.boardPxW, l.boardPxH
+	style := th.Block
+
+	g.drawDangerGlow(screen, originX, originY, boardPxW, boardPxH)
+
+	g.drawGrid(screen, originX, originY, tile, th)
 
 	// Board cells
-	for y := 0; y < boardH; y++ {
-		for x := 0; x < boardW; x++ {
-			if g.board[y][x] != 0 {
-				pc := pieceColors[g.board[y][x]-1]
-				drawCell(screen, originX, originY, tile, x, y, pc)
-			} else {
-				// subtle grid
-				gc := color.RGBA{30, 30, 44, 255}
-				drawCell(screen, originX, originY, tile, x, y, gc)
+	for y := range g.Height() {
+		for x := range g.Width() {
+			v := g.Cell(x, y)
+			if v == 0 {
+				continue
+			}
+			a := g.cellAlpha(x, y)
+			if a <= 0 {
+				continue
+			}
+			t := style.tile()
+			if v == engine.GarbageCell {
+				t = tileGarbage
+			}
+			px, py := originX+float32(x)*tile, originY+float32(y)*tile
+			drawTile(screen, px, py, tile, alpha(pal.Pieces[v-1], uint8(255*a)), t)
+			if a == 1 {
+				drawMark(screen, px, py, tile, v-1, th.Marks)
 			}
 		}
 	}
+	g.drawTrails(screen, originX, originY, tile)
 
-	// Current piece
-	for _, p := range pieceShapes[g.cur.kind][g.cur.rot] {
-		x := g.cur.x + p.x
-		y := g.cur.y + p.y
-		if y >= 0 && y < boardH && x >= 0 && x < boardW {
-			pc := pieceColors[g.cur.kind]
-			drawCell(screen, originX, originY, tile, x, y, pc)
-		}
+	for i := range g.Players() {
+		g.drawActivePiece(screen, originX, originY, tile, style, i)
 	}
 
-	// Right panel info
-	panelX := originX + boardPxW + float32(margin)
-	text.Draw(screen, "Next", basicfont.Face7x13, int(panelX), int(originY+14), color.White)
-	drawNext(screen, panelX, originY+20, tile, g.nextKind)
-
-	text.Draw(screen, fmt.Sprintf("Score: %d", g.score), basicfont.Face7x13, int(panelX), int(originY+120), color.White)
-	text.Draw(screen, fmt.Sprintf("Lines: %d", g.lines), basicfont.Face7x13, int(panelX), int(originY+140), color.White)
-	text.Draw(screen, fmt.Sprintf("Level: %d", g.level), basicfont.Face7x13, int(panelX), int(originY+160), color.White)
+	g.drawLockFlash(screen, originX, originY, tile)
+	g.drawClearedRows(screen, originX, originY, tile)
+	g.drawIncoming(screen, originX, originY, tile, boardPxH)
+	g.drawBorderFlash(screen, originX, originY, boardPxW, boardPxH)
+}
 
-	if !(runtime.GOOS == "ios" || runtime.GOOS == "android") {
-		text.Draw(screen, "Controls:", basicfont.Face7x13, int(panelX), int(originY+190), color.White)
-		text.Draw(screen, "←/→ Move", basicfont.Face7x13, int(panelX), int(originY+206), color.White)
-		text.Draw(screen, "↓ Soft Drop", basicfont.Face7x13, int(panelX), int(originY+222), color.White)
-		text.Draw(screen, "Z/X or ↑ Rotate", basicfont.Face7x13, int(panelX), int(originY+238), color.White)
-		text.Draw(screen, "Space Hard Drop", basicfont.Face7x13, int(panelX), int(originY+254), color.White)
+// drawActivePiece draws player i's piece, eased between gravity steps and
+// after moves and rotations, over its ghost where a hard drop would land it.
+func (g *Game) drawActivePiece(screen *ebiten.Image, originX, originY, tile float32, style BlockStyle, i int) {
+	pal := g.palette()
+	pl := g.Player(i)
+	if pl.Spawning {
+		return
+	}
+	cur := pl.Piece
+	pc := pal.Pieces[cur.Kind]
+	for _, p := range g.PieceSet()[cur.Kind][cur.Rot] {
+		if y := pl.GhostY + p.Y; y >= 0 && g.settings.Ghost {
+			drawTile(screen, originX+float32(cur.X+p.X)*tile, originY+float32(y)*tile, tile, alpha(pc, ghostAlpha), tileGhost)
+		}
 	}
-
-	// Touch buttons
-	if runtime.GOOS == "ios" || runtime.GOOS == "android" {
-		drawTouchControls(screen)
+	fall := float32(pl.Fall) * tile
+	for _, p := range g.PieceSet()[cur.Kind][cur.Rot] {
+		if cur.Y+p.Y < 0 {
+			continue
+		}
+		cx, cy := g.tweenedCell(p, cur, g.fx.tweens[i])
+		px, py := originX+cx*tile, originY+cy*tile+fall
+		drawCellPx(screen, px, py, tile, pc, style)
+		drawMark(screen, px, py, tile, cur.Kind, g.settings.PieceMarks)
 	}
+}
 
-	// Game over overlay
-	if g.gameOver {
-		overlay := color.RGBA{0, 0, 0, 160}
-		vector.DrawFilledRect(screen, 0, 0, float32(w), float32(h), overlay, false)
-		msg := "Game Over"
-		text.Draw(screen, msg, basicfont.Face7x13, w/2-len(msg)*3, h/2-10, color.White)
-		hint := "Tap or Space/Enter to restart"
-		text.Draw(screen, hint, basicfont.Face7x13, w/2-len(hint)*3, h/2+8, color.White)
+// drawStreaks shows a puzzle's goal and the running combo and back-to-back
+// chain over the board's top-left corner.
+func (g *Game) drawStreaks(screen *ebiten.Image, l layout) {
+	pal := g.palette()
+	k := float32(g.settings.UIScale)
+	y := l.originY + 16*k
+	if goal := g.PuzzleGoal(); goal != "" {
+		g.drawText(screen, tr(goal), l.originX+4*k, y, pal.Text)
+		y += 16 * k
+	}
+	if c := g.Combo(); c > 0 {
+		g.drawText(screen, trf("%d Combo", c), l.originX+4*k, y, pal.Accent)
+		y += 16 * k
+	}
+	if b := g.B2B(); b > 0 {
+		g.drawText(screen, trf("Back-to-Back x%d", b), l.originX+4*k, y, pal.Info)
 	}
 }
 
-func drawCell(screen *ebiten.Image, originX, originY, tile float32, x, y int, c color.RGBA) {
-	px := originX + float32(x)*tile
-	py := originY + float32(y)*tile
-	vector.DrawFilledRect(screen, px+1, py+1, tile-2, tile-2, c, false)
+// drawRaceClock shows a Sprint's elapsed time or an Ultra's time left over
+// the board's top-right corner.
+func (g *Game) drawRaceClock(screen *ebiten.Image, l layout) {
+	pal := g.palette()
+	var s string
+	switch {
+	case g.Mode().LineGoal > 0:
+		s = raceTime(g.Frames())
+	case g.Mode().TimeLimit > 0:
+		s = raceTime(g.TimeLeft())
+	default:
+		return
+	}
+	k := float32(g.settings.UIScale)
+	g.drawText(screen, s, l.originX+l.boardPxW-textW(s)*k-4*k, l.originY+16*k, pal.Text)
+}
+
+// drawHold draws a player's held piece, dimmed once used for this piece.
+func drawHold(screen *ebiten.Image, set *engine.PieceSet, px, py, tile float32, pl engine.Player, th Theme) {
+	if pl.Hold < 0 {
+		return
+	}
+	hc := th.Palette.Pieces[pl.Hold]
+	if pl.HoldUsed {
+		hc = shade(hc, 0.4)
+	}
+	drawNext(screen, set, px, py, tile, pl.Hold, hc, th)
 }
 
-func drawNext(screen *ebiten.Image, px, py, tile float32, kind int) {
+func drawNext(screen *ebiten.Image, set *engine.PieceSet, px, py, tile float32, kind int, c color.RGBA, th Theme) {
 	scale := tile * 0.7
 	offX := px + 8
 	offY := py + 8
-	c := pieceColors[kind]
-	for _, p := range pieceShapes[kind][0] {
-		x := offX + float32(p.x)*scale
-		y := offY + float32(p.y)*scale
-		vector.DrawFilledRect(screen, x+1, y+1, scale-2, scale-2, c, false)
+	for _, p := range set[kind][0] {
+		x := offX + float32(p.X)*scale
+		y := offY + float32(p.Y)*scale
+		drawCellPx(screen, x, y, scale, c, th.Block)
+		drawMark(screen, x, y, scale, kind, th.Marks)
 	}
 }
 
-func drawTouchControls(screen *ebiten.Image) {
+func (g *Game) drawTouchControls(screen *ebiten.Image) {
+	pal := g.palette()
+	if g.settings.TouchLayout == TouchDrag {
+		return
+	}
 	w, h := screen.Size()
-	ctrlH := float32(160)
-	btnW := float32(w) / 4
-	y := float32(h) - ctrlH
-	bg := color.RGBA{255, 255, 255, 20}
-	lblColor := color.RGBA{255, 255, 255, 200}
-	for i := 0; i < 4; i++ {
-		vector.DrawFilledRect(screen, float32(i)*btnW, y, btnW-2, ctrlH-2, bg, false)
-	}
-	labels := []string{"Left", "Right", "Rotate", "Drop"}
-	for i, s := range labels {
-		tx := int(float32(i)*btnW + btnW/2 - float32(len(s))*3)
-		ty := int(y + ctrlH/2)
-		text.Draw(screen, s, basicfont.Face7x13, tx, ty, lblColor)
+	o := g.settings.TouchOpacity
+	bg := alpha(pal.Text, uint8(20*o))
+	lblColor := alpha(pal.Text, uint8(200*o))
+	for i, b := range g.touchRects(float32(w), float32(h)) {
+		vector.DrawFilledRect(screen, b.x, b.y, b.w-2, b.h-2, bg, false)
+		g.drawCentered(screen, tr(touchLabels[i]), b.x+b.w/2, b.y+b.h/2, lblColor)
+	}
+}
+
+func onOff(b bool) string {
+	if b {
+		return "On"
 	}
+	return "Off"
 }
 
 func minF(a, b float32) float32 {
@@ -487,15 +742,96 @@ func minF(a, b float32) float32 {
 	return b
 }
 
-func (g *Game) Layout(ow, oh int) (int, int) {
-	return logicalW, logicalH
-}
-
 func main() {
-	ebiten.SetWindowSize(logicalW, logicalH)
 	ebiten.SetWindowTitle("Tetris Clone (Go + Ebitengine)")
+	settings := loadSettings()
+	applyLanguage(settings.Language)
+	setupWindow(settings)
+	setupLogging(settings)
+	defer closeLogging()
+	bench := flag.Bool("bench", false, "run the rendering benchmark and report frame times")
+	record := flag.String("record", "", "write the first run's inputs and state hashes to this file")
+	verify := flag.Bool("verify", false, "replay the input logs given as arguments and check their state hashes")
+	render := flag.String("render-replay", "", "write the frames of this input log to stdout as raw RGBA (see cmd/render-replay)")
+	statsAddr := flag.String("stats-server", "", "serve a live stats dashboard and JSON API on this address, such as :8080")
+	flag.Int64Var(&fixedSeed, "seed", 0, "deal every run from this seed, to practise one piece sequence (the Daily Challenge keeps the day's)")
+	challenge := flag.String("challenge", "", "start the game in a challenge link ("+challengeScheme+"?...), as shown on the results screen")
+	flag.Parse()
+	// Before replaying anything, so logs of mod modes, piece sets and
+	// saved puzzles play.
+	loadMods()
+	loadUserPuzzles()
+	if *render != "" {
+		if err := renderReplay(*render); err != nil {
+			slog.Error("replay render failed", "err", err)
+			closeLogging()
+			os.Exit(1)
+		}
+		return
+	}
+	if *verify {
+		failed := false
+		for _, path := range flag.Args() {
+			if err := verifyInputLog(path); err != nil {
+				slog.Error("input log diverged", "path", path, "err", err)
+				failed = true
+			} else {
+				slog.Info("input log matches", "path", path)
+			}
+		}
+		if failed {
+			closeLogging()
+			os.Exit(1)
+		}
+		return
+	}
+
+	slog.Info("starting", "os", runtime.GOOS, "arch", runtime.GOARCH)
+	beginSession()
+	loadHighScores()
+	loadProfile()
+	loadOnline()
+	if *statsAddr != "" {
+		defer startDashboard(*statsAddr)()
+	}
+	applyTelemetry(settings)
+	applyOnline(settings)
+	applyStatus(settings)
+	checkForUpdate(settings)
+
 	game := NewGame()
+	if *challenge != "" {
+		m, mods, seed, err := parseChallenge(*challenge)
+		if err != nil {
+			slog.Error("bad challenge link", "err", err)
+			closeLogging()
+			os.Exit(2)
+		}
+		game = newGameSeeded(seed, m, mods)
+	}
+	game.settings = settings
+	if !*bench && *record == "" && *challenge == "" {
+		game.state, game.canContinue = stateTitle, hasAutosave()
+	}
+	game.publishStatus()
+	if *bench {
+		game.startBenchmark()
+	}
+	if *record != "" {
+		game.rec = newRecorder(*record, game)
+	}
+	soundPlayer = sound.New()
+	applySound(game.settings)
+	ebiten.SetWindowClosingHandled(true)
 	if err := ebiten.RunGame(game); err != nil {
-		log.Fatal(err)
+		slog.Error("game exited", "err", err)
+		closeLogging()
+		os.Exit(1)
+	}
+	if game.rec != nil {
+		game.stopRecording()
 	}
+	shutdownTelemetry()
+	shutdownStatus()
+	endSession()
 }
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// PieceMarks picks what, besides colour, tells the piece kinds apart.
+type PieceMarks int
+
+const (
+	MarksOff PieceMarks = iota
+	// MarksPatterns overlays a pattern per kind: stripes, a dot, a cross,
+	// diagonals and a ring.
+	MarksPatterns
+	// MarksLetters overlays the kind's letter.
+	MarksLetters
+)
+
+func (m PieceMarks) String() string {
+	switch m {
+	case MarksPatterns:
+		return "Patterns"
+	case MarksLetters:
+		return "Letters"
+	}
+	return "Off"
+}
+
+// kindLetters name the kinds, I O T S Z J L.
+const kindLetters = "IOTSZJL"
+
+// minMarkTile is the smallest cell worth marking; the queue's small
+// previews go without.
+const minMarkTile = 10
+
+// markColor is drawn over any piece colour, so it darkens rather than
+// picking a colour of its own.
+var markColor = color.RGBA{0, 0, 0, 150}
+
+// drawMark overlays kind's mark on the size-pixel cell at (px, py).
+// Garbage, gems and any kinds past the seven go unmarked.
+func drawMark(screen *ebiten.Image, px, py, size float32, kind int, m PieceMarks) {
+	if m == MarksOff || kind < 0 || kind >= len(kindLetters) || size < minMarkTile {
+		return
+	}
+	if m == MarksLetters {
+		op := &ebiten.DrawImageOptions{}
+		k := float64(size) / 20
+		op.GeoM.Scale(k, k)
+		op.GeoM.Translate(float64(px+size/2)-3.5*k, float64(py+size/2)+4*k)
+		op.ColorScale.ScaleWithColor(markColor)
+		text.DrawWithOptions(screen, kindLetters[kind:kind+1], textFace, op)
+		return
+	}
+	// Inset past the tile's gap and bevel.
+	x0, y0 := px+size*0.2, py+size*0.2
+	s := size * 0.6
+	w := max(1, size/10)
+	line := func(ax, ay, bx, by float32) {
+		vector.StrokeLine(screen, x0+ax*s, y0+ay*s, x0+bx*s, y0+by*s, w, markColor, true)
+	}
+	switch kindLetters[kind] {
+	case 'I': // horizontal stripes
+		for _, y := range []float32{0.15, 0.5, 0.85} {
+			line(0, y, 1, y)
+		}
+	case 'O': // a dot
+		vector.DrawFilledCircle(screen, x0+s/2, y0+s/2, s/4, markColor, true)
+	case 'T': // a cross
+		line(0.5, 0, 0.5, 1)
+		line(0, 0.5, 1, 0.5)
+	case 'S': // rising diagonals
+		line(0, 0.5, 0.5, 0)
+		line(0, 1, 1, 0)
+		line(0.5, 1, 1, 0.5)
+	case 'Z': // falling diagonals
+		line(0, 0.5, 0.5, 1)
+		line(0, 0, 1, 1)
+		line(0.5, 0, 1, 0.5)
+	case 'J': // vertical stripes
+		for _, x := range []float32{0.15, 0.5, 0.85} {
+			line(x, 0, x, 1)
+		}
+	case 'L': // a ring
+		vector.StrokeRect(screen, x0+s*0.15, y0+s*0.15, s*0.7, s*0.7, w, markColor, true)
+	}
+}
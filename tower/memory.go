@@ -0,0 +1,31 @@
+package main
+
+import "tetris/engine"
+
+const (
+	// Invisible shows a locked block for invisibleHoldFrames, then fades
+	// it out over invisibleFadeFrames.
+	invisibleHoldFrames = 3 * 60
+	invisibleFadeFrames = 30
+	// flashFrames is how long Flash shows the stack after each lock.
+	flashFrames = 12
+)
+
+// cellAlpha is how much of the board cell at (x, y) shows under the mode's
+// Memory, from 0 to 1. Garbage and other cells no piece locked always
+// show, and the whole stack is revealed when the game ends.
+func (g *Game) cellAlpha(x, y int) float32 {
+	m := g.Mode().Memory
+	if m == engine.MemoryOff || g.GameOver() {
+		return 1
+	}
+	age := g.LockAge(x, y)
+	if age < 0 {
+		return 1
+	}
+	if m == engine.MemoryFlash {
+		age = g.Frames() - g.fx.lastLock
+		return max(0, 1-float32(age)/flashFrames)
+	}
+	return max(0, min(1, 1-float32(age-invisibleHoldFrames)/invisibleFadeFrames))
+}
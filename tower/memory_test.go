@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"tetris/engine"
+)
+
+func TestMemoryModes(t *testing.T) {
+	tempConfig(t)
+	for _, name := range []string{"Invisible", "Flash"} {
+		g := newGameSeeded(1, modeByName(name), Modifiers{})
+		if g.Mode().Name != name {
+			t.Fatalf("no %s mode", name)
+		}
+		g.stepPlayers(frameInput{hardDrop: true})
+		x, y := -1, -1
+		for yy, row := range g.Board() {
+			for xx, c := range row {
+				if c != 0 {
+					x, y = xx, yy
+				}
+			}
+		}
+		if g.cellAlpha(x, y) != 1 {
+			t.Errorf("%s: a block just locked shows %v", name, g.cellAlpha(x, y))
+		}
+		for range invisibleHoldFrames + invisibleFadeFrames {
+			g.stepPlayers(frameInput{})
+		}
+		if g.Board()[y][x] == 0 {
+			t.Fatalf("%s: the block is gone", name)
+		}
+		if a := g.cellAlpha(x, y); a != 0 {
+			t.Errorf("%s: a block locked seconds ago shows %v", name, a)
+		}
+	}
+	if g := newGameSeeded(1, modes[0], Modifiers{}); g.Mode().Memory != engine.MemoryOff {
+		t.Error("Marathon hides its stack")
+	}
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// settingItem is one row of the settings menu.
+type settingItem struct {
+	label  string
+	value  func(g *Game) string
+	adjust func(g *Game, dir int)
+}
+
+var settingItems = []settingItem{
+	subPage("New Game", newGamePage),
+	{
+		label: "Starting Level",
+		value: func(g *Game) string { return fmt.Sprint(g.settings.StartLevel) },
+		adjust: func(g *Game, dir int) {
+			g.settings.StartLevel = wrap(g.settings.StartLevel+dir, maxStartLevel+1)
+		},
+	},
+	{
+		label: "Effects",
+		value: func(g *Game) string { return g.settings.Quality.String() },
+		adjust: func(g *Game, dir int) {
+			g.settings.Quality = Quality(wrap(int(g.settings.Quality)+dir, int(QualityHigh)+1))
+		},
+	},
+	{
+		label:  "Tweens",
+		value:  func(g *Game) string { return onOff(g.settings.Tweens) },
+		adjust: func(g *Game, dir int) { g.settings.Tweens = !g.settings.Tweens },
+	},
+	{
+		label:  "Juice Effects",
+		value:  func(g *Game) string { return onOff(g.settings.Juice) },
+		adjust: func(g *Game, dir int) { g.settings.Juice = !g.settings.Juice },
+	},
+	{
+		label:  "Ghost Piece",
+		value:  func(g *Game) string { return onOff(g.settings.Ghost) },
+		adjust: func(g *Game, dir int) { g.settings.Ghost = !g.settings.Ghost },
+	},
+	{
+		label: "Theme",
+		value: func(g *Game) string { return g.theme().Name },
+		adjust: func(g *Game, dir int) {
+			g.cycleTheme(dir)
+		},
+	},
+	{
+		label: "Background",
+		value: func(g *Game) string {
+			if g.settings.Background == "" {
+				return "Theme"
+			}
+			return g.settings.Background
+		},
+		adjust: func(g *Game, dir int) { g.cycleBackground(dir) },
+	},
+	{
+		label:  "Reduce Motion",
+		value:  func(g *Game) string { return onOff(g.settings.ReduceMotion) },
+		adjust: func(g *Game, dir int) { g.settings.ReduceMotion = !g.settings.ReduceMotion },
+	},
+	{
+		label:  "Stats Panel",
+		value:  func(g *Game) string { return onOff(g.settings.ShowStats) },
+		adjust: func(g *Game, dir int) { g.settings.ShowStats = !g.settings.ShowStats },
+	},
+	{
+		label: "UI Scale",
+		value: func(g *Game) string { return fmt.Sprintf("%d%%", int(g.settings.UIScale*100+0.5)) },
+		adjust: func(g *Game, dir int) {
+			s := g.settings.UIScale + float64(dir)*uiScaleStep
+			g.settings.UIScale = max(minUIScale, min(maxUIScale, s))
+		},
+	},
+	{
+		label: "Language",
+		value: func(g *Game) string { return locales[localeIndex(g.settings.Language)].Name },
+		adjust: func(g *Game, dir int) {
+			g.settings.Language = locales[wrap(localeIndex(g.settings.Language)+dir, len(locales))].Code
+			applyLanguage(g.settings.Language)
+		},
+	},
+	{
+		label:  "Fullscreen",
+		value:  func(g *Game) string { return onOff(g.settings.Fullscreen) },
+		adjust: func(g *Game, dir int) { g.setFullscreen(!g.settings.Fullscreen) },
+	},
+	{
+		label: "Log to File",
+		value: func(g *Game) string { return onOff(g.settings.LogToFile) },
+		adjust: func(g *Game, dir int) {
+			g.settings.LogToFile = !g.settings.LogToFile
+			setupLogging(g.settings)
+		},
+	},
+	{
+		label: "Share Anon. Stats",
+		value: func(g *Game) string {
+			if g.settings.Telemetry && g.settings.TelemetryEndpoint == "" {
+				return "On (no endpoint)"
+			}
+			return onOff(g.settings.Telemetry)
+		},
+		adjust: func(g *Game, dir int) {
+			g.settings.Telemetry = !g.settings.Telemetry
+			applyTelemetry(g.settings)
+		},
+	},
+	{
+		label: "Online Scores",
+		value: func(g *Game) string {
+			if g.settings.OnlineScores && g.settings.LeaderboardEndpoint == "" {
+				return "On (no endpoint)"
+			}
+			return onOff(g.settings.OnlineScores)
+		},
+		adjust: func(g *Game, dir int) {
+			g.settings.OnlineScores = !g.settings.OnlineScores
+			applyOnline(g.settings)
+		},
+	},
+	{
+		label:  "Check for Updates",
+		value:  func(g *Game) string { return onOff(g.settings.CheckUpdates) },
+		adjust: func(g *Game, dir int) { g.settings.CheckUpdates = !g.settings.CheckUpdates },
+	},
+	{
+		label: "Quick Restart",
+		value: func(g *Game) string {
+			if g.settings.RestartKey == "" {
+				return "Off"
+			}
+			return trf("Hold %s", g.settings.RestartKey)
+		},
+		adjust: func(g *Game, dir int) { g.settings.RestartKey = cycleRestartKey(g.settings.RestartKey, dir) },
+	},
+	{
+		label: "Save Replays",
+		value: func(g *Game) string { return g.settings.ReplaySave.String() },
+		adjust: func(g *Game, dir int) {
+			g.settings.ReplaySave = ReplaySave(wrap(int(g.settings.ReplaySave)+dir, int(ReplayNever)+1))
+		},
+	},
+	{
+		label:  "Record Clips",
+		value:  func(g *Game) string { return onOff(g.settings.RecordClips) },
+		adjust: func(g *Game, dir int) { g.settings.RecordClips = !g.settings.RecordClips },
+	},
+	{
+		label: "Share Status",
+		value: func(g *Game) string {
+			if !statusShared {
+				return "Unavailable"
+			}
+			return onOff(g.settings.ShareStatus)
+		},
+		adjust: func(g *Game, dir int) {
+			g.settings.ShareStatus = !g.settings.ShareStatus && statusShared
+			applyStatus(g.settings)
+			g.publishStatus()
+		},
+	},
+	subPage("Sound", soundPage),
+	subPage("Handling", handlingPage),
+	subPage("Accessibility", accessibilityPage),
+	subPage("Touch Gestures", gesturePage),
+	subPage("Tilt Controls", tiltPage),
+	subPage("Mods", modsPage),
+	{
+		label:  "Run Benchmark (30s)",
+		value:  func(g *Game) string { return "" },
+		adjust: func(g *Game, dir int) { g.startBenchmark() },
+	},
+}
+
+// menuPage is one screen of the settings menu.
+type menuPage struct {
+	title string
+	items []settingItem
+	live  bool   // shows the handling test board and leaves arrows to it
+	hint  string // replaces the usual key hint
+}
+
+var settingsPage = &menuPage{title: "Settings", items: settingItems}
+
+var gesturePage = &menuPage{title: "Touch Gestures", items: gestureItems()}
+
+var tiltPage = &menuPage{title: "Tilt Controls (Experimental)", items: []settingItem{
+	{
+		label: "Tilt to Move",
+		value: func(g *Game) string {
+			if !tiltAvailable() {
+				return "Unavailable"
+			}
+			return onOff(g.settings.TiltControls)
+		},
+		adjust: func(g *Game, dir int) {
+			if p, ok := tiltSource.(tiltPermitter); ok {
+				// Readings start once allowed; the next press turns it on.
+				p.Permit()
+			}
+			g.settings.TiltControls = !g.settings.TiltControls && tiltAvailable()
+		},
+	},
+	{
+		label:  "Sensitivity",
+		value:  func(g *Game) string { return fmt.Sprint(g.settings.TiltSensitivity) },
+		adjust: func(g *Game, dir int) { g.settings.TiltSensitivity = max(1, min(10, g.settings.TiltSensitivity+dir)) },
+	},
+	{
+		label:  "Calibrate (hold level)",
+		value:  func(g *Game) string { return fmt.Sprintf("%.2f", g.settings.TiltZero) },
+		adjust: func(g *Game, dir int) { g.calibrateTilt() },
+	},
+}}
+
+func gestureItems() []settingItem {
+	items := make([]settingItem, len(gestures))
+	for i, ge := range gestures {
+		items[i] = settingItem{
+			label: ge.Label(),
+			value: func(g *Game) string { return g.settings.Gestures[ge].Label() },
+			adjust: func(g *Game, dir int) {
+				cur := 0
+				for j, a := range touchActions {
+					if a == g.settings.Gestures[ge] {
+						cur = j
+					}
+				}
+				g.settings.Gestures[ge] = touchActions[wrap(cur+dir, len(touchActions))]
+			},
+		}
+	}
+	return items
+}
+
+// subPage is a menu row that opens another page.
+func subPage(label string, p *menuPage) settingItem {
+	return settingItem{
+		label:  label,
+		value:  func(g *Game) string { return "" },
+		adjust: func(g *Game, dir int) { g.openMenu(p) },
+	}
+}
+
+func wrap(i, n int) int {
+	return ((i % n) + n) % n
+}
+
+// menuRowH is the unscaled height of a settings row.
+const menuRowH = 22
+
+func (g *Game) menuOpen() bool {
+	return len(g.menu) > 0
+}
+
+func (g *Game) page() *menuPage {
+	return g.menu[len(g.menu)-1]
+}
+
+func (g *Game) openMenu(p *menuPage) {
+	g.setState(stateSettings)
+	g.menu = append(g.menu, p)
+	g.menuSel = 0
+	if p.live {
+		g.tuner = newTuner()
+	}
+}
+
+// closeMenu backs out one page, saving settings when the menu closes.
+func (g *Game) closeMenu() {
+	g.menu = g.menu[:len(g.menu)-1]
+	g.menuSel = 0
+	if !g.menuOpen() {
+		g.closeOverlay()
+		g.storeSettings()
+	}
+}
+
+// storeSettings writes the settings out, logging a failure.
+func (g *Game) storeSettings() {
+	if err := saveSettings(g.settings); err != nil {
+		slog.Error("settings save failed", "err", err)
+	}
+}
+
+// updateMenu handles input while the settings menu is open.
+func (g *Game) updateMenu() {
+	if g.rebinding {
+		g.updateRebind()
+		return
+	}
+	if g.page().live {
+		g.updateLiveMenu()
+	} else {
+		g.updateMenuKeys()
+	}
+	if g.menuOpen() {
+		g.updateMenuTouch()
+	}
+}
+
+func (g *Game) updateMenuKeys() {
+	items := g.page().items
+	n := len(items)
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		g.menuSel = wrap(g.menuSel-1, n)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		g.menuSel = wrap(g.menuSel+1, n)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		items[g.menuSel].adjust(g, -1)
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) ||
+		inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		items[g.menuSel].adjust(g, 1)
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.closeMenu()
+	}
+}
+
+// updateMenuTouch steps a row when its left or right half is tapped, and
+// backs out on a tap outside the rows.
+func (g *Game) updateMenuTouch() {
+	items := g.page().items
+	k := float32(g.settings.UIScale)
+	top, rowH := g.menuTop(), menuRowH*k
+	for _, id := range justTouchIDs(nil) {
+		x, y := ebiten.TouchPosition(id)
+		row := int((float32(y) - top) / rowH)
+		if float32(y) < top || row >= len(items) {
+			g.closeMenu()
+			return
+		}
+		g.menuSel = row
+		if sw, _ := g.screenSize(); float32(x) < sw/2 {
+			items[row].adjust(g, -1)
+		} else {
+			items[row].adjust(g, 1)
+		}
+		return
+	}
+}
+
+func (g *Game) menuTop() float32 {
+	if g.page().live {
+		return 80 * float32(g.settings.UIScale)
+	}
+	_, sh := g.screenSize()
+	return sh/2 - float32(len(g.page().items))*menuRowH*float32(g.settings.UIScale)/2
+}
+
+func (g *Game) drawMenu(screen *ebiten.Image) {
+	pal := g.palette()
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	vector.DrawFilledRect(screen, 0, 0, float32(w), float32(h), alpha(pal.Shade, 190), false)
+	p := g.page()
+	k := float32(g.settings.UIScale)
+	top, rowH := g.menuTop(), menuRowH*k
+	g.drawCentered(screen, tr(p.title), float32(w)/2, top-rowH, pal.Text)
+	for i, it := range p.items {
+		y := top + float32(i)*rowH
+		if i == g.menuSel {
+			vector.DrawFilledRect(screen, 24, y, float32(w)-48, rowH-2, pal.Select, false)
+		}
+		g.drawText(screen, tr(it.label), 36, y+rowH*0.7, pal.Text)
+		v := ">"
+		if s := it.value(g); s != "" {
+			v = "< " + tr(s) + " >"
+		}
+		g.drawText(screen, v, float32(w)-36-textW(v)*k, y+rowH*0.7, pal.Text)
+	}
+	hint := "Arrows adjust, Esc goes back"
+	if p.live {
+		hint = "Up/Tab select, -/+ adjust, Esc back"
+	}
+	if p.hint != "" {
+		hint = p.hint
+	}
+	if touchScreen() {
+		hint = "Tap left/right half to adjust"
+	}
+	bottom := top + float32(len(p.items))*rowH + rowH
+	g.drawCentered(screen, tr(hint), float32(w)/2, bottom, pal.Dim)
+	if p.live && g.tuner != nil {
+		const tile = 20
+		g.drawText(screen, tr("Try it: arrows move, Down soft drops"), 36, bottom+2*rowH, pal.Text)
+		g.tuner.draw(screen, float32(w)/2-tile*tunerW/2, bottom+3*rowH, tile, pal)
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+import "tetris/metrics"
+
+// gameMetrics is served at /metrics by -stats-server.
+var (
+	gameMetrics = &metrics.Registry{}
+
+	stepsTotal = gameMetrics.Counter("tower_steps_total",
+		"Game frames simulated; rate() gives steps per second.")
+	activeRuns = gameMetrics.Gauge("tower_active_runs",
+		"Runs in progress: 1 while a game is being played, 0 otherwise.")
+	botDecisionSeconds = gameMetrics.Histogram("tower_bot_decision_seconds",
+		"Time bots took to return a move.", metrics.ExpBuckets(0.001, 2, 10))
+)
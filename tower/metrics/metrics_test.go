@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func scrape(r *Registry) string {
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	return w.Body.String()
+}
+
+func TestHistogramExposition(t *testing.T) {
+	var r Registry
+	h := r.Histogram("decide_seconds", "Bot decision time.", []float64{0.01, 0.1, 1})
+	for _, v := range []float64{0.005, 0.01, 0.05, 0.5, 3} {
+		h.Observe(v)
+	}
+	want := `# HELP decide_seconds Bot decision time.
+# TYPE decide_seconds histogram
+decide_seconds_bucket{le="0.01"} 2
+decide_seconds_bucket{le="0.1"} 3
+decide_seconds_bucket{le="1"} 4
+decide_seconds_bucket{le="+Inf"} 5
+decide_seconds_sum 3.565
+decide_seconds_count 5
+`
+	if got := scrape(&r); got != want {
+		t.Errorf("scraped:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCounterAndGauge(t *testing.T) {
+	var r Registry
+	c := r.Counter("runs_total", "Runs finished.")
+	g := r.Gauge("fps", "Frames per second.")
+	c.Add(2)
+	c.Add(1)
+	g.Set(60)
+	g.Add(-0.5)
+	want := `# HELP runs_total Runs finished.
+# TYPE runs_total counter
+runs_total 3
+# HELP fps Frames per second.
+# TYPE fps gauge
+fps 59.5
+`
+	if got := scrape(&r); got != want {
+		t.Errorf("scraped:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExpBuckets(t *testing.T) {
+	b := ExpBuckets(0.001, 10, 4)
+	want := []float64{0.001, 0.01, 0.1, 1}
+	for i := range want {
+		if d := b[i] - want[i]; d > 1e-12 || d < -1e-12 {
+			t.Errorf("bucket %d = %v, want %v", i, b[i], want[i])
+		}
+	}
+}
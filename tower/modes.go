@@ -0,0 +1,387 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"tetris/engine"
+)
+
+var modes = []engine.Mode{
+	{Name: "Marathon", Width: engine.BoardW},
+	{Name: "Sprint", Width: engine.BoardW, LineGoal: 40},
+	{Name: "Ultra", Width: engine.BoardW, TimeLimit: 2 * 60 * 60},
+	{Name: dailyName, Width: engine.BoardW, TimeLimit: 2 * 60 * 60},
+	{Name: "Co-op", Width: 16, Coop: true},
+	{Name: "Versus", Width: engine.BoardW, Versus: true},
+	{Name: "Flip", Width: engine.BoardW, Flip: true},
+	{Name: "Blitz", Width: engine.BoardW, Blitz: true},
+	{Name: "Dig Quest", Width: engine.BoardW, Dig: true},
+	{Name: "Cheese", Width: engine.BoardW, Cheese: 9},
+	{Name: "Boss Battle", Width: engine.BoardW, Boss: true},
+	{Name: "Endless", Width: engine.BoardW, Endless: true},
+	{Name: "Zen", Width: engine.BoardW, Zen: true},
+	{Name: "No Rotation", Width: engine.BoardW, Rules: engine.Ruleset{NoRotation: true, RandomSpawnRotation: true}},
+	{Name: "Invisible", Width: engine.BoardW, Memory: engine.MemoryInvisible},
+	{Name: "Flash", Width: engine.BoardW, Memory: engine.MemoryFlash},
+}
+
+// Modifiers are challenge toggles layered on a mode from the Custom Game
+// page. Scores set with any of them on are flagged on the leaderboard.
+type Modifiers struct {
+	// MirrorControls swaps left with right and the two rotation directions.
+	MirrorControls bool
+	// Pieces names a piece set mod played instead of the tetrominoes;
+	// empty for the standard set.
+	Pieces string
+	// CheeseRows is Cheese's garbage height, 0 for the mode's own.
+	CheeseRows int
+	// StartLevel is the level play begins at.
+	StartLevel int
+	// ClassicGravity plays the NES gravity table instead of the mode's.
+	ClassicGravity bool
+	// Board names a boardSizes preset; empty for the mode's own board.
+	Board string
+	// Randomizer deals the pieces in place of the mode's 7-bag.
+	Randomizer engine.RandomizerKind `json:",omitempty"`
+
+	// GravityCap, EasyStart and Adaptive are the assists: gravity no
+	// faster than assistGravityCap, no S or Z among the opening pieces,
+	// and gravity that eases after repeated near top outs.
+	GravityCap bool `json:",omitempty"`
+	EasyStart  bool `json:",omitempty"`
+	Adaptive   bool `json:",omitempty"`
+}
+
+// assistGravityCap is the fewest frames per row the GravityCap assist
+// lets pieces fall at, level 9's on the standard curve.
+const assistGravityCap = 12
+
+// boardSize is a board preset for the Custom Game page.
+type boardSize struct {
+	name          string
+	width, height int
+}
+
+// boardSizes are the presets past the mode's own board: a tall one for
+// practice, a small one whose blocks are drawn twice the size, and a wide
+// one with two more columns to spread the stack over.
+var boardSizes = []boardSize{
+	{"Tall", engine.BoardW, 2 * engine.BoardH},
+	{"Big", engine.BoardW / 2, engine.BoardH / 2},
+	{"Wide", engine.BoardW + 2, engine.BoardH},
+}
+
+// boardSizeLabel names the preset with its size, for the menu.
+func boardSizeLabel(name string) string {
+	for _, b := range boardSizes {
+		if b.name == name {
+			return fmt.Sprintf("%s %dx%d", b.name, b.width, b.height)
+		}
+	}
+	return fmt.Sprintf("Classic %dx%d", engine.BoardW, engine.BoardH)
+}
+
+// resizable reports whether mode's board can take a preset size. Co-op
+// needs its wide board, and Dig Quest and puzzles bring their own.
+func resizable(mode engine.Mode) bool {
+	return !mode.Coop && !mode.Dig && mode.Puzzle == ""
+}
+
+// piecesFlag prefixes the piece set's name in flags, cheeseFlag the
+// garbage height, levelFlag the starting level, boardFlag the board
+// preset, and randomizerFlag the randomizer.
+const (
+	piecesFlag  = "Pieces: "
+	cheeseFlag  = "Cheese: "
+	levelFlag   = "Level: "
+	classicFlag = "Classic Gravity"
+	boardFlag   = "Board: "
+
+	gravityCapFlag = "Gravity Cap"
+	easyStartFlag  = "Easy Start"
+	adaptiveFlag   = "Adaptive"
+
+	randomizerFlag = "Randomizer: "
+)
+
+const (
+	// maxCheeseRows leaves room above the garbage to play.
+	maxCheeseRows = engine.BoardH - 4
+	// maxStartLevel is the highest level a game can start at.
+	maxStartLevel = 19
+)
+
+// flags names the modifiers that are on, for leaderboard entries.
+func (m Modifiers) flags() []string {
+	var f []string
+	if m.MirrorControls {
+		f = append(f, "Mirror")
+	}
+	if m.Pieces != "" {
+		f = append(f, piecesFlag+m.Pieces)
+	}
+	if m.CheeseRows > 0 {
+		f = append(f, cheeseFlag+strconv.Itoa(m.CheeseRows))
+	}
+	if m.StartLevel > 0 {
+		f = append(f, levelFlag+strconv.Itoa(m.StartLevel))
+	}
+	if m.ClassicGravity {
+		f = append(f, classicFlag)
+	}
+	if m.Board != "" {
+		f = append(f, boardFlag+m.Board)
+	}
+	if m.Randomizer != engine.RandomizerBag {
+		f = append(f, randomizerFlag+m.Randomizer.String())
+	}
+	if m.GravityCap {
+		f = append(f, gravityCapFlag)
+	}
+	if m.EasyStart {
+		f = append(f, easyStartFlag)
+	}
+	if m.Adaptive {
+		f = append(f, adaptiveFlag)
+	}
+	return f
+}
+
+// modifiersFromFlags turns names from flags back into modifiers.
+func modifiersFromFlags(f []string) Modifiers {
+	m := Modifiers{MirrorControls: slices.Contains(f, "Mirror"), ClassicGravity: slices.Contains(f, classicFlag)}
+	m.GravityCap, m.EasyStart, m.Adaptive = slices.Contains(f, gravityCapFlag), slices.Contains(f, easyStartFlag), slices.Contains(f, adaptiveFlag)
+	for _, s := range f {
+		if name, ok := strings.CutPrefix(s, piecesFlag); ok {
+			m.Pieces = name
+		}
+		if rows, ok := strings.CutPrefix(s, cheeseFlag); ok {
+			m.CheeseRows, _ = strconv.Atoi(rows)
+		}
+		if level, ok := strings.CutPrefix(s, levelFlag); ok {
+			m.StartLevel, _ = strconv.Atoi(level)
+		}
+		if board, ok := strings.CutPrefix(s, boardFlag); ok {
+			m.Board = board
+		}
+		if name, ok := strings.CutPrefix(s, randomizerFlag); ok {
+			m.Randomizer, _ = randomizerByName(name)
+		}
+	}
+	return m
+}
+
+// apply sets up mode for the modifiers that change the game itself. A
+// piece set that isn't loaded leaves the standard pieces.
+func (m Modifiers) apply(mode engine.Mode) engine.Mode {
+	mode.Pieces = nil
+	if m.Pieces != "" {
+		mode.Pieces = modPieceSets[m.Pieces]
+	}
+	for _, b := range boardSizes {
+		if b.name == m.Board && resizable(mode) {
+			mode.Width, mode.Height = b.width, b.height
+		}
+	}
+	if mode.Cheese > 0 && m.CheeseRows > 0 {
+		mode.Cheese = min(m.CheeseRows, maxCheeseRows)
+	}
+	// Leave room above the garbage to play.
+	mode.Cheese = min(mode.Cheese, mode.Rows()-4)
+	mode.StartLevel = max(0, min(m.StartLevel, maxStartLevel))
+	if m.ClassicGravity {
+		mode.Rules.Gravity = engine.GravityClassic
+	}
+	if m.Randomizer != engine.RandomizerBag && mode.Puzzle == "" {
+		mode.Rules.Randomizer = m.Randomizer
+	}
+	if m.GravityCap {
+		mode.Rules.GravityCap = assistGravityCap
+	}
+	mode.Rules.EasyStart = m.EasyStart && mode.Puzzle == ""
+	mode.Rules.Adaptive = m.Adaptive
+	return mode
+}
+
+// randomizerByName finds a randomizer by its String, in any case.
+func randomizerByName(name string) (engine.RandomizerKind, bool) {
+	for _, k := range engine.Randomizers {
+		if strings.EqualFold(k.String(), name) {
+			return k, true
+		}
+	}
+	return engine.RandomizerBag, false
+}
+
+// raceTime formats frames as m:ss.cc, for Sprint and Ultra clocks.
+func raceTime(frames int) string {
+	cs := frames * 100 / 60
+	return fmt.Sprintf("%d:%02d.%02d", cs/6000, cs/100%60, cs%100)
+}
+
+// modeByName finds a mode, falling back to the first for unknown names.
+func modeByName(name string) engine.Mode {
+	for _, m := range modes {
+		if m.Name == name {
+			return m
+		}
+	}
+	if m, ok := puzzleByModeName(name); ok {
+		return m
+	}
+	return modes[0]
+}
+
+// startMode closes the menu and begins a fresh game of m, at the starting
+// level and with the gravity from Settings unless mods pick them. Zen and
+// puzzles have no levels to speak of.
+func (g *Game) startMode(m engine.Mode, mods Modifiers) {
+	for g.menuOpen() {
+		g.closeMenu()
+	}
+	if mods.StartLevel == 0 && !m.Zen && m.Puzzle == "" {
+		mods.StartLevel = g.settings.StartLevel
+	}
+	if g.settings.ClassicGravity[m.Name] {
+		mods.ClassicGravity = true
+	}
+	g.modifiers = mods
+	g.start(m)
+}
+
+// addModMode registers a mode from a mod after the built-in ones.
+func addModMode(m engine.Mode) {
+	modes = append(modes, m)
+	newGamePage.items = modeItems()
+}
+
+var newGamePage = &menuPage{title: "New Game", items: modeItems()}
+
+func modeItems() []settingItem {
+	items := make([]settingItem, len(modes))
+	for i, m := range modes {
+		items[i] = settingItem{
+			label:  m.Name,
+			value:  func(g *Game) string { return "" },
+			adjust: func(g *Game, dir int) { g.startMode(m, Modifiers{}) },
+		}
+	}
+	return append(items, subPage("Puzzles", puzzlePage), subPage("Custom Game", customGamePage), settingItem{
+		label:  "Board Editor",
+		value:  func(g *Game) string { return "" },
+		adjust: func(g *Game, dir int) { g.openEditor() },
+	})
+}
+
+// customGame is what the Custom Game page will start.
+var customGame struct {
+	mode int
+	mods Modifiers
+}
+
+var customGamePage = &menuPage{title: "Custom Game", items: []settingItem{
+	{
+		label:  "Mode",
+		value:  func(g *Game) string { return modes[customGame.mode].Name },
+		adjust: func(g *Game, dir int) { customGame.mode = wrap(customGame.mode+dir, len(modes)) },
+	},
+	{
+		label:  "Mirror Controls",
+		value:  func(g *Game) string { return onOff(customGame.mods.MirrorControls) },
+		adjust: func(g *Game, dir int) { customGame.mods.MirrorControls = !customGame.mods.MirrorControls },
+	},
+	{
+		label: "Pieces",
+		value: func(g *Game) string {
+			if customGame.mods.Pieces == "" {
+				return "Standard"
+			}
+			return customGame.mods.Pieces
+		},
+		adjust: func(g *Game, dir int) {
+			sets := append([]string{""}, modPieceSetNames...)
+			customGame.mods.Pieces = sets[wrap(slices.Index(sets, customGame.mods.Pieces)+dir, len(sets))]
+		},
+	},
+	{
+		label: "Cheese Height",
+		value: func(g *Game) string {
+			if customGame.mods.CheeseRows == 0 {
+				return "Default"
+			}
+			return fmt.Sprintf("%d rows", customGame.mods.CheeseRows)
+		},
+		adjust: func(g *Game, dir int) {
+			customGame.mods.CheeseRows = wrap(customGame.mods.CheeseRows+dir, maxCheeseRows+1)
+		},
+	},
+	{
+		label: "Board",
+		value: func(g *Game) string { return boardSizeLabel(customGame.mods.Board) },
+		adjust: func(g *Game, dir int) {
+			names := []string{""}
+			for _, b := range boardSizes {
+				names = append(names, b.name)
+			}
+			customGame.mods.Board = names[wrap(slices.Index(names, customGame.mods.Board)+dir, len(names))]
+		},
+	},
+	{
+		label:  "Gravity",
+		value:  func(g *Game) string { return gravityName(customGame.mods.ClassicGravity) },
+		adjust: func(g *Game, dir int) { customGame.mods.ClassicGravity = !customGame.mods.ClassicGravity },
+	},
+	{
+		label: "Randomizer",
+		value: func(g *Game) string { return customGame.mods.Randomizer.String() },
+		adjust: func(g *Game, dir int) {
+			n := len(engine.Randomizers)
+			customGame.mods.Randomizer = engine.Randomizers[wrap(int(customGame.mods.Randomizer)+dir, n)]
+		},
+	},
+	subPage("Assists", assistsPage),
+	{
+		label: "Start",
+		value: func(g *Game) string { return "" },
+		adjust: func(g *Game, dir int) {
+			m, mods := modes[customGame.mode], customGame.mods
+			if m.Cheese == 0 {
+				mods.CheeseRows = 0 // only Cheese has a garbage height to flag
+			}
+			if !resizable(m) {
+				mods.Board = ""
+			}
+			g.startMode(m, mods)
+		},
+	},
+}}
+
+// assistsPage turns the Custom Game's assists on and off.
+var assistsPage = &menuPage{title: "Assists", items: []settingItem{
+	{
+		label:  "Gravity Cap",
+		value:  func(g *Game) string { return onOff(customGame.mods.GravityCap) },
+		adjust: func(g *Game, dir int) { customGame.mods.GravityCap = !customGame.mods.GravityCap },
+	},
+	{
+		label:  "Easy Start",
+		value:  func(g *Game) string { return onOff(customGame.mods.EasyStart) },
+		adjust: func(g *Game, dir int) { customGame.mods.EasyStart = !customGame.mods.EasyStart },
+	},
+	{
+		label:  "Adaptive Gravity",
+		value:  func(g *Game) string { return onOff(customGame.mods.Adaptive) },
+		adjust: func(g *Game, dir int) { customGame.mods.Adaptive = !customGame.mods.Adaptive },
+	},
+}}
+
+// gravityName labels a gravity choice for the menus.
+func gravityName(classic bool) string {
+	if classic {
+		return "Classic"
+	}
+	return "Standard"
+}
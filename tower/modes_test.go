@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+
+	"tetris/engine"
+)
+
+func TestStartingLevel(t *testing.T) {
+	tempConfig(t)
+	mods := Modifiers{StartLevel: 12}
+	if got := modifiersFromFlags(mods.flags()); got != mods {
+		t.Errorf("round trip = %+v, want %+v", got, mods)
+	}
+	if got := (Modifiers{StartLevel: 40}).apply(modes[0]).StartLevel; got != maxStartLevel {
+		t.Errorf("start level %d, want it capped at %d", got, maxStartLevel)
+	}
+
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.settings.StartLevel = 7
+	g.startMode(modeByName("Marathon"), Modifiers{})
+	if g.Level() != 7 || g.modifiers.StartLevel != 7 {
+		t.Errorf("level %d (modifiers %+v), want the settings' 7", g.Level(), g.modifiers)
+	}
+	g.Reset()
+	if g.Level() != 7 {
+		t.Errorf("level %d after a restart, want 7", g.Level())
+	}
+	g.startMode(modeByName("Zen"), Modifiers{})
+	if g.Level() != 0 {
+		t.Errorf("Zen started at level %d", g.Level())
+	}
+}
+
+func TestClassicGravityPerMode(t *testing.T) {
+	tempConfig(t)
+	mods := Modifiers{ClassicGravity: true, StartLevel: 3}
+	if got := modifiersFromFlags(mods.flags()); got != mods {
+		t.Errorf("round trip = %+v, want %+v", got, mods)
+	}
+
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.toggleClassicGravity("Marathon")
+	g.startMode(modeByName("Marathon"), Modifiers{})
+	if g.Mode().Rules.Gravity != engine.GravityClassic || !g.modifiers.ClassicGravity {
+		t.Error("Marathon didn't start on the classic table")
+	}
+	g.startMode(modeByName("Sprint"), Modifiers{})
+	if g.Mode().Rules.Gravity != engine.GravityLinear {
+		t.Error("the choice for Marathon carried over to Sprint")
+	}
+	g.toggleClassicGravity("Marathon")
+	if len(g.settings.ClassicGravity) != 0 {
+		t.Errorf("toggling back left %v", g.settings.ClassicGravity)
+	}
+}
+
+func TestBoardSizes(t *testing.T) {
+	tempConfig(t)
+	mods := Modifiers{Board: "Big"}
+	if got := modifiersFromFlags(mods.flags()); got != mods {
+		t.Errorf("round trip = %+v, want %+v", got, mods)
+	}
+	g := newGameSeeded(1, modeByName("Marathon"), Modifiers{Board: "Tall"})
+	if g.Width() != engine.BoardW || g.Height() != 2*engine.BoardH {
+		t.Errorf("Tall board is %dx%d", g.Width(), g.Height())
+	}
+	g = newGameSeeded(1, modeByName("Cheese"), mods)
+	if g.Width() != 5 || g.Height() != 10 || g.Mode().Cheese != 6 {
+		t.Errorf("Big Cheese is %dx%d with %d rows of garbage, want 5x10 with 6", g.Width(), g.Height(), g.Mode().Cheese)
+	}
+	for range 600 {
+		g.stepPlayers(frameInput{hardDrop: true})
+	}
+	if !g.GameOver() {
+		t.Error("hard dropping on a Big board never topped out")
+	}
+	if g = newGameSeeded(1, modeByName("Co-op"), mods); g.Width() != 16 || g.Height() != engine.BoardH {
+		t.Errorf("Co-op took the Big board: %dx%d", g.Width(), g.Height())
+	}
+}
+
+func TestRandomizerModifier(t *testing.T) {
+	mods := Modifiers{Randomizer: engine.RandomizerTGM}
+	if got := modifiersFromFlags(mods.flags()); got != mods {
+		t.Errorf("round trip = %+v, want %+v", got, mods)
+	}
+	if got := mods.apply(modes[0]).Rules.Randomizer; got != engine.RandomizerTGM {
+		t.Errorf("Marathon deals with %v, want TGM", got)
+	}
+	if mods.apply(puzzleMode(engine.Puzzles()[0])).Rules.Randomizer != engine.RandomizerBag {
+		t.Error("a puzzle took the randomizer")
+	}
+}
+
+func TestAssistModifiers(t *testing.T) {
+	mods := Modifiers{GravityCap: true, EasyStart: true, Adaptive: true, Board: "Wide"}
+	if got := modifiersFromFlags(mods.flags()); got != mods {
+		t.Errorf("round trip = %+v, want %+v", got, mods)
+	}
+	m := mods.apply(modes[0])
+	if r := m.Rules; r.GravityCap != assistGravityCap || !r.EasyStart || !r.Adaptive {
+		t.Errorf("Marathon's rules with every assist: %+v", r)
+	}
+	if m.Width != 12 || m.Rows() != engine.BoardH {
+		t.Errorf("the Wide board is %dx%d", m.Width, m.Rows())
+	}
+	if mods.apply(puzzleMode(engine.Puzzles()[0])).Rules.EasyStart {
+		t.Error("a puzzle's pieces were rearranged")
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"tetris/engine"
+	"tetris/luamode"
+)
+
+// modDir is scanned at startup. Each mod is a folder holding a mod.json
+// manifest and any files it refers to.
+const modDir = "mods"
+
+// Mod kinds a manifest may declare.
+const (
+	ModTheme    = "theme"
+	ModSkin     = "skin"
+	ModPieceSet = "pieces"
+	ModRuleset  = "ruleset"
+	ModLuaMode  = "lua-mode"
+)
+
+type modManifest struct {
+	Name    string              `json:"name"`
+	Type    string              `json:"type"`
+	Theme   *themeJSON          `json:"theme,omitempty"`
+	Pieces  map[string][]string `json:"pieces,omitempty"`  // kind letter -> rotations
+	Ruleset *rulesetJSON        `json:"ruleset,omitempty"` // also a Lua mode's base rules
+	Script  string              `json:"script,omitempty"`  // a Lua mode's script
+}
+
+// themeJSON is the on-disk form of a Theme.
+type themeJSON struct {
+	Block          string       `json:"block"` // "flat" or "beveled"
+	Grid           string       `json:"grid"`  // "cells", "lines" or "none"
+	Palette        *paletteJSON `json:"palette,omitempty"`
+	Animated       *bool        `json:"animated"` // the shader background, on if left out
+	Background     string       `json:"background"`
+	BackgroundDim  float64      `json:"background_dim"`
+	BackgroundBlur int          `json:"background_blur"`
+}
+
+// paletteJSON is a Palette as "#rrggbb" or "#rrggbbaa" strings. Colours
+// left out keep the Dark theme's.
+type paletteJSON struct {
+	Pieces []string `json:"pieces"` // I, O, T, S, Z, J, L, garbage, gem
+	Back   string   `json:"back"`
+	Frame  string   `json:"frame"`
+	Cell   string   `json:"cell"`
+	Text   string   `json:"text"`
+	Dim    string   `json:"dim"`
+	Accent string   `json:"accent"`
+	Info   string   `json:"info"`
+	Alert  string   `json:"alert"`
+	Select string   `json:"select"`
+	Shade  string   `json:"shade"`
+}
+
+func (t themeJSON) toTheme(name, dir string) (Theme, error) {
+	th := Theme{Name: name, Palette: darkPalette, Animated: t.Animated == nil || *t.Animated, BackgroundDim: t.BackgroundDim, BackgroundBlur: t.BackgroundBlur}
+	switch t.Block {
+	case "", "flat":
+		th.Block = BlockFlat
+	case "beveled":
+		th.Block = BlockBeveled
+	default:
+		return th, fmt.Errorf("unknown block style %q", t.Block)
+	}
+	switch t.Grid {
+	case "", "cells":
+		th.Grid = GridCells
+	case "lines":
+		th.Grid = GridLines
+	case "none":
+		th.Grid = GridNone
+	default:
+		return th, fmt.Errorf("unknown grid style %q", t.Grid)
+	}
+	if t.Palette != nil {
+		if err := t.Palette.apply(&th.Palette); err != nil {
+			return th, err
+		}
+	}
+	if t.BackgroundDim < 0 || t.BackgroundDim > 1 {
+		return th, fmt.Errorf("background_dim %v out of range 0..1", t.BackgroundDim)
+	}
+	if t.Background != "" {
+		th.Background = filepath.Join(dir, t.Background)
+	}
+	return th, nil
+}
+
+// apply sets the colours given in p over pal.
+func (p *paletteJSON) apply(pal *Palette) error {
+	if len(p.Pieces) > len(pal.Pieces) {
+		return fmt.Errorf("%d piece colours, want at most %d", len(p.Pieces), len(pal.Pieces))
+	}
+	for i, s := range p.Pieces {
+		if err := parseHexColor(s, &pal.Pieces[i]); err != nil {
+			return fmt.Errorf("piece colour %d: %w", i+1, err)
+		}
+	}
+	for _, c := range []struct {
+		name string
+		s    string
+		c    *color.RGBA
+	}{
+		{"back", p.Back, &pal.Back},
+		{"frame", p.Frame, &pal.Frame},
+		{"cell", p.Cell, &pal.Cell},
+		{"text", p.Text, &pal.Text},
+		{"dim", p.Dim, &pal.Dim},
+		{"accent", p.Accent, &pal.Accent},
+		{"info", p.Info, &pal.Info},
+		{"alert", p.Alert, &pal.Alert},
+		{"select", p.Select, &pal.Select},
+		{"shade", p.Shade, &pal.Shade},
+	} {
+		if c.s == "" {
+			continue
+		}
+		if err := parseHexColor(c.s, c.c); err != nil {
+			return fmt.Errorf("%s: %w", c.name, err)
+		}
+	}
+	return nil
+}
+
+// parseHexColor reads "#rrggbb" or "#rrggbbaa" into c.
+func parseHexColor(s string, c *color.RGBA) error {
+	h, ok := strings.CutPrefix(s, "#")
+	b, err := hex.DecodeString(h)
+	if !ok || err != nil || len(b) != 3 && len(b) != 4 {
+		return fmt.Errorf("colour %q is not #rrggbb or #rrggbbaa", s)
+	}
+	*c = color.RGBA{b[0], b[1], b[2], 255}
+	if len(b) == 4 {
+		c.A = b[3]
+	}
+	return nil
+}
+
+// pieceKinds are the letters piece set mods name kinds by, in kind order.
+const pieceKinds = "IOTSZJL"
+
+// toPieceSet reads each kind's four rotations, each a 4x4 box as four
+// rows of '.' (empty) and 'X' (block). Kinds left out keep their
+// standard shapes.
+func toPieceSet(kinds map[string][]string) (*engine.PieceSet, error) {
+	set := engine.Shapes
+	for k, rots := range kinds {
+		kind := strings.Index(pieceKinds, k)
+		if len(k) != 1 || kind < 0 {
+			return nil, fmt.Errorf("unknown piece %q (want one of %s)", k, pieceKinds)
+		}
+		if len(rots) != 4 {
+			return nil, fmt.Errorf("piece %s: %d rotations, want 4", k, len(rots))
+		}
+		for rot, box := range rots {
+			rows := strings.Split(box, "/")
+			if len(rows) != 4 {
+				return nil, fmt.Errorf("piece %s rotation %d: %d rows, want 4", k, rot, len(rows))
+			}
+			var cells []engine.Point
+			for y, row := range rows {
+				if len(row) != 4 {
+					return nil, fmt.Errorf("piece %s rotation %d: row %q isn't 4 wide", k, rot, row)
+				}
+				for x, c := range row {
+					switch c {
+					case 'X':
+						cells = append(cells, engine.Point{X: x, Y: y})
+					case '.':
+					default:
+						return nil, fmt.Errorf("piece %s rotation %d: unexpected %q", k, rot, c)
+					}
+				}
+			}
+			if len(cells) == 0 {
+				return nil, fmt.Errorf("piece %s rotation %d is empty", k, rot)
+			}
+			set[kind][rot] = cells
+		}
+	}
+	return &set, nil
+}
+
+// rulesetJSON is the on-disk form of a mode from a mod: a built-in mode
+// with some rules changed.
+type rulesetJSON struct {
+	Base                string `json:"base"` // a built-in mode's name; Marathon if empty
+	Width               int    `json:"width"`
+	LineGoal            int    `json:"line_goal"`
+	TimeLimit           int    `json:"time_limit"` // seconds
+	NoRotation          bool   `json:"no_rotation"`
+	RandomSpawnRotation bool   `json:"random_spawn_rotation"`
+	Randomizer          string `json:"randomizer"` // "7-bag", "classic" or "tgm"; the base mode's if empty
+}
+
+func (r rulesetJSON) toMode(name string) (engine.Mode, error) {
+	m := modes[0]
+	if r.Base != "" {
+		i := slices.IndexFunc(modes, func(m engine.Mode) bool { return m.Name == r.Base })
+		if i < 0 {
+			return m, fmt.Errorf("unknown base mode %q", r.Base)
+		}
+		m = modes[i]
+	}
+	if slices.ContainsFunc(modes, func(m engine.Mode) bool { return m.Name == name }) {
+		return m, fmt.Errorf("a mode named %q already exists", name)
+	}
+	m.Name = name
+	if r.Width != 0 {
+		if r.Width < 4 || r.Width > engine.MaxBoardW {
+			return m, fmt.Errorf("width %d out of range 4..%d", r.Width, engine.MaxBoardW)
+		}
+		m.Width = r.Width
+	}
+	if r.LineGoal < 0 || r.TimeLimit < 0 {
+		return m, errors.New("line_goal and time_limit can't be negative")
+	}
+	if r.LineGoal > 0 {
+		m.LineGoal = r.LineGoal
+	}
+	if r.TimeLimit > 0 {
+		m.TimeLimit = r.TimeLimit * 60
+	}
+	m.Rules.NoRotation = m.Rules.NoRotation || r.NoRotation
+	m.Rules.RandomSpawnRotation = m.Rules.RandomSpawnRotation || r.RandomSpawnRotation
+	if r.Randomizer != "" {
+		k, ok := randomizerByName(r.Randomizer)
+		if !ok {
+			return m, fmt.Errorf("unknown randomizer %q", r.Randomizer)
+		}
+		m.Rules.Randomizer = k
+	}
+	return m, nil
+}
+
+// loadedMod is one entry on the Mods screen.
+type loadedMod struct {
+	dir  string
+	name string
+	kind string
+	err  error
+}
+
+var (
+	mods             []loadedMod
+	modThemes        []Theme
+	modPieceSets     = map[string]*engine.PieceSet{}
+	modPieceSetNames []string // in load order, for the Custom Game page
+)
+
+// loadMods scans modDir and registers what it can. Problems are kept with
+// the mod so the Mods screen can show them; a missing folder is not one.
+func loadMods() {
+	entries, err := os.ReadDir(modDir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("mods folder unreadable", "dir", modDir, "err", err)
+		}
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		m := loadMod(filepath.Join(modDir, e.Name()))
+		if m.err != nil {
+			slog.Warn("mod failed to load", "mod", m.dir, "err", m.err)
+		} else {
+			slog.Info("mod loaded", "mod", m.name, "type", m.kind)
+		}
+		mods = append(mods, m)
+	}
+	modsPage.items = modItems()
+}
+
+func loadMod(dir string) loadedMod {
+	m := loadedMod{dir: dir, name: filepath.Base(dir)}
+	b, err := os.ReadFile(filepath.Join(dir, "mod.json"))
+	if err != nil {
+		m.err = err
+		return m
+	}
+	var mf modManifest
+	if err := json.Unmarshal(b, &mf); err != nil {
+		m.err = fmt.Errorf("mod.json: %w", err)
+		return m
+	}
+	if mf.Name != "" {
+		m.name = mf.Name
+	}
+	m.kind = mf.Type
+	switch mf.Type {
+	case ModTheme, ModSkin:
+		// A skin is a theme that only changes how blocks look.
+		if mf.Theme == nil {
+			m.err = errors.New(`mod.json: missing "theme"`)
+			return m
+		}
+		th, err := mf.Theme.toTheme(m.name, dir)
+		if err != nil {
+			m.err = err
+			return m
+		}
+		modThemes = append(modThemes, th)
+	case ModPieceSet:
+		if len(mf.Pieces) == 0 {
+			m.err = errors.New(`mod.json: missing "pieces"`)
+			return m
+		}
+		if _, dup := modPieceSets[m.name]; dup {
+			m.err = fmt.Errorf("a piece set named %q already exists", m.name)
+			return m
+		}
+		set, err := toPieceSet(mf.Pieces)
+		if err != nil {
+			m.err = err
+			return m
+		}
+		modPieceSets[m.name] = set
+		modPieceSetNames = append(modPieceSetNames, m.name)
+	case ModRuleset, ModLuaMode:
+		var r rulesetJSON
+		if mf.Ruleset != nil {
+			r = *mf.Ruleset
+		} else if mf.Type == ModRuleset {
+			m.err = errors.New(`mod.json: missing "ruleset"`)
+			return m
+		}
+		mode, err := r.toMode(m.name)
+		if err != nil {
+			m.err = err
+			return m
+		}
+		if mf.Type == ModLuaMode {
+			if mf.Script == "" {
+				m.err = errors.New(`mod.json: missing "script"`)
+				return m
+			}
+			if mode.Script, err = luamode.Load(filepath.Join(dir, mf.Script)); err != nil {
+				m.err = err
+				return m
+			}
+		}
+		addModMode(mode)
+	default:
+		m.err = fmt.Errorf("unknown mod type %q", mf.Type)
+	}
+	return m
+}
+
+var modsPage = &menuPage{title: "Mods"}
+
+func modItems() []settingItem {
+	items := make([]settingItem, len(mods))
+	for i, m := range mods {
+		items[i] = settingItem{
+			label: m.name,
+			value: func(g *Game) string {
+				if m.err != nil {
+					return trf("Error: %s", m.err)
+				}
+				return trf("Loaded (%s)", m.kind)
+			},
+			adjust: func(g *Game, dir int) {},
+		}
+	}
+	return items
+}
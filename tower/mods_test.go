@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"tetris/engine"
+)
+
+// writeMod writes a mod folder holding files and returns it.
+func writeMod(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// keepModes puts the mode list back after a test registers mod modes.
+func keepModes(t *testing.T) {
+	saved := slices.Clone(modes)
+	t.Cleanup(func() {
+		modes = saved
+		newGamePage.items = modeItems()
+	})
+}
+
+func TestToPieceSet(t *testing.T) {
+	set, err := toPieceSet(map[string][]string{"O": {"XX../XX../..../....", "XX../XX../..../....", "XX../XX../..../....", "XXX./XXX./..../...."}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(set[1][3]); got != 6 {
+		t.Errorf("O rotation 3 has %d cells, want 6", got)
+	}
+	if !slices.Equal(set[0][0], engine.Shapes[0][0]) {
+		t.Error("the I, left out, changed")
+	}
+	for _, bad := range []map[string][]string{
+		{"Q": {"X.../..../..../....", "X.../..../..../....", "X.../..../..../....", "X.../..../..../...."}},
+		{"I": {"XXXX/..../..../...."}},
+		{"I": {"XXXX/..../....", "XXXX/..../..../....", "XXXX/..../..../....", "XXXX/..../..../...."}},
+		{"I": {"XXXXX/..../..../....", "XXXX/..../..../....", "XXXX/..../..../....", "XXXX/..../..../...."}},
+		{"I": {"..../..../..../....", "XXXX/..../..../....", "XXXX/..../..../....", "XXXX/..../..../...."}},
+		{"I": {"XXXO/..../..../....", "XXXX/..../..../....", "XXXX/..../..../....", "XXXX/..../..../...."}},
+	} {
+		if _, err := toPieceSet(bad); err == nil {
+			t.Errorf("%v loaded", bad)
+		}
+	}
+}
+
+func TestRulesetToMode(t *testing.T) {
+	m, err := rulesetJSON{Base: "Sprint", Width: 6, LineGoal: 20, TimeLimit: 90, NoRotation: true}.toMode("Narrow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "Narrow" || m.Width != 6 || m.LineGoal != 20 || m.TimeLimit != 90*60 || !m.Rules.NoRotation {
+		t.Errorf("mode = %+v", m)
+	}
+	for _, bad := range []rulesetJSON{{Base: "Nope"}, {Width: 3}, {Width: engine.MaxBoardW + 1}, {LineGoal: -1}} {
+		if _, err := bad.toMode("Bad"); err == nil {
+			t.Errorf("%+v loaded", bad)
+		}
+	}
+	if _, err := (rulesetJSON{}).toMode("Marathon"); err == nil {
+		t.Error("a mod mode took a built-in mode's name")
+	}
+}
+
+func TestLoadModKinds(t *testing.T) {
+	keepModes(t)
+	t.Cleanup(func() { delete(modPieceSets, "Big O"); modPieceSetNames = nil })
+
+	pieces := loadMod(writeMod(t, map[string]string{"mod.json": `{"name": "Big O", "type": "pieces",
+		"pieces": {"O": ["XXX./XXX./..../....", "XXX./XXX./..../....", "XXX./XXX./..../....", "XXX./XXX./..../...."]}}`}))
+	if pieces.err != nil || modPieceSets["Big O"] == nil {
+		t.Fatalf("piece set: %v", pieces.err)
+	}
+	ruleset := loadMod(writeMod(t, map[string]string{"mod.json": `{"name": "Tiny", "type": "ruleset", "ruleset": {"width": 6}}`}))
+	if ruleset.err != nil || modeByName("Tiny").Width != 6 {
+		t.Fatalf("ruleset: %v", ruleset.err)
+	}
+	lua := loadMod(writeMod(t, map[string]string{
+		"mod.json": `{"name": "Short", "type": "lua-mode", "script": "mode.lua"}`,
+		"mode.lua": `function on_frame(f) if f == 30 then game.end_game("short") end end`,
+	}))
+	if lua.err != nil || modeByName("Short").Script == nil {
+		t.Fatalf("lua mode: %v", lua.err)
+	}
+	if !slices.ContainsFunc(newGamePage.items, func(it settingItem) bool { return it.label == "Short" }) {
+		t.Error("the Lua mode isn't on the New Game page")
+	}
+
+	// The modes play: the piece set's O is six cells, and the script ends
+	// its game.
+	g := newGameSeeded(1, modes[0], Modifiers{Pieces: "Big O"})
+	if len(g.PieceSet()[1][0]) != 6 {
+		t.Error("the game didn't pick up the piece set")
+	}
+	g = newGameSeeded(1, modeByName("Short"), Modifiers{})
+	g.offline = true
+	for range 40 {
+		g.stepPlayers(frameInput{})
+	}
+	if !g.GameOver() {
+		t.Error("the Lua mode's script never ran")
+	}
+
+	for _, bad := range []string{
+		`{"type": "pieces"}`,
+		`{"type": "ruleset"}`,
+		`{"type": "lua-mode"}`,
+		`{"type": "lua-mode", "script": "missing.lua"}`,
+	} {
+		if m := loadMod(writeMod(t, map[string]string{"mod.json": bad})); m.err == nil {
+			t.Errorf("%s loaded", bad)
+		}
+	}
+}
+
+func TestPiecesFlagRoundTrip(t *testing.T) {
+	m := Modifiers{MirrorControls: true, Pieces: "Big O"}
+	if got := modifiersFromFlags(m.flags()); got != m {
+		t.Errorf("round trip = %+v, want %+v", got, m)
+	}
+}
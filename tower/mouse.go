@@ -0,0 +1,63 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+
+	"tetris/engine"
+)
+
+// mouseSource is the mouse scheme, on with Settings.MouseControl: over
+// the board the piece follows the cursor's column, the wheel rotates, a
+// left click hard drops and a right click holds. Off the board the mouse
+// is left to the panel.
+type mouseSource struct {
+	g     *Game
+	wheel float64 // scrolled toward the next notch, for smooth trackpads
+}
+
+func (m *mouseSource) Poll() []Action {
+	g := m.g
+	if !g.settings.MouseControl {
+		return nil
+	}
+	l := g.layout()
+	x, y := ebiten.CursorPosition()
+	if !l.board().contains(x, y) {
+		m.wheel = 0
+		return nil
+	}
+	var acts []Action
+	if pl := g.Player(0); !pl.Spawning {
+		shift := mouseShift(g.orientation().column(l.column(x), g.Width()), pl.Piece, g.PieceSet())
+		if g.modifiers.MirrorControls {
+			shift = -shift // the mirror turns it back, so the piece still follows
+		}
+		acts = appendActions(acts, frameInput{shift: shift}, false, false)
+	}
+	_, dy := ebiten.Wheel()
+	m.wheel += dy
+	for ; m.wheel >= 1; m.wheel-- {
+		acts = append(acts, ActRotateCW)
+	}
+	for ; m.wheel <= -1; m.wheel++ {
+		acts = append(acts, ActRotateCCW)
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		acts = append(acts, ActHardDrop)
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		acts = append(acts, ActHold)
+	}
+	return acts
+}
+
+// mouseShift is how many columns p moves to centre on column col.
+func mouseShift(col int, p engine.Piece, set *engine.PieceSet) int {
+	cells := set[p.Kind][p.Rot]
+	lo, hi := cells[0].X, cells[0].X
+	for _, c := range cells {
+		lo, hi = min(lo, c.X), max(hi, c.X)
+	}
+	return col - (p.X + (lo+hi)/2)
+}
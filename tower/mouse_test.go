@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"tetris/engine"
+)
+
+func TestMouseShift(t *testing.T) {
+	for _, tc := range []struct {
+		p    engine.Piece
+		col  int
+		want int
+	}{
+		{engine.Piece{Kind: 0, Rot: 0, X: 3}, 4, 0},  // a flat I centres on its second cell
+		{engine.Piece{Kind: 0, Rot: 0, X: 3}, 0, -4}, // into the wall, which stops it
+		{engine.Piece{Kind: 0, Rot: 1, X: 3}, 9, 4},  // an upright one on its only column
+	} {
+		if got := mouseShift(tc.col, tc.p, &engine.Shapes); got != tc.want {
+			t.Errorf("%+v to column %d: shift %d, want %d", tc.p, tc.col, got, tc.want)
+		}
+	}
+
+	l := layout{tile: 10, originX: 5}
+	for x, want := range map[int]int{4: -1, 5: 0, 14: 0, 15: 1} {
+		if got := l.column(x); got != want {
+			t.Errorf("x %d is column %d, want %d", x, got, want)
+		}
+	}
+}
+
+func TestMouseColumnFollowsTheFlip(t *testing.T) {
+	for o, want := range map[orientation]int{upright: 2, mirrored: 7, turned: 7} {
+		if got := o.column(2, engine.BoardW); got != want {
+			t.Errorf("orientation %d: the cursor over column 2 points at %d, want %d", o, got, want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"tetris/leaderboard"
+)
+
+// onlineCache is the last rankings fetched of each online board, shown
+// while the server can't be reached, and the results still to be sent.
+type onlineCache struct {
+	Version int
+	Boards  map[string]leaderboard.Rankings
+	Pending []leaderboard.Score `json:",omitempty"`
+}
+
+// onlineMigrations upgrade older cache files; see loadSave.
+var onlineMigrations []saveMigration
+
+// online is the online leaderboard, shared with the requests running in
+// the background.
+var online struct {
+	sync.Mutex
+	client   *leaderboard.Client // nil while off
+	cache    onlineCache
+	fetching map[string]bool // boards with a fetch under way
+	failed   map[string]bool // boards whose last fetch failed
+	flushing bool
+}
+
+func onlineCachePath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "leaderboard.json"), nil
+}
+
+func loadOnline() {
+	online.Lock()
+	defer online.Unlock()
+	online.cache = onlineCache{Boards: map[string]leaderboard.Rankings{}}
+	path, err := onlineCachePath()
+	if err != nil {
+		return
+	}
+	var c onlineCache
+	if err := loadSave(path, &c, onlineMigrations); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("online leaderboard cache unreadable", "path", path, "err", err)
+		}
+		return
+	}
+	if c.Boards == nil {
+		c.Boards = map[string]leaderboard.Rankings{}
+	}
+	online.cache = c
+}
+
+// saveOnline writes the cache; online must be locked.
+func saveOnline() {
+	path, err := onlineCachePath()
+	if err != nil {
+		return
+	}
+	online.cache.Version = len(onlineMigrations)
+	b, err := json.MarshalIndent(online.cache, "", "  ")
+	if err == nil {
+		err = writeSave(path, b)
+	}
+	if err != nil {
+		slog.Error("online leaderboard cache save failed", "err", err)
+	}
+}
+
+// applyOnline connects to the leaderboard server or disconnects to match
+// s, making the player's token the first time. Results held back while
+// offline are sent on connecting.
+func applyOnline(s Settings) {
+	on := s.OnlineScores && s.LeaderboardEndpoint != ""
+	if on && profile.OnlineToken == "" {
+		profile.OnlineToken = leaderboard.NewToken()
+		if err := saveProfile(); err != nil {
+			slog.Error("profile save failed", "err", err)
+		}
+	}
+	online.Lock()
+	online.client = nil
+	if on {
+		online.client = leaderboard.New(s.LeaderboardEndpoint, profile.OnlineToken)
+	}
+	clear(online.failed)
+	online.Unlock()
+	if on {
+		go flushOnline()
+	}
+}
+
+// onlineMode reports whether mode's results go on the online leaderboard.
+func onlineMode(mode string) bool {
+	return mode == "Sprint" || mode == "Ultra" || mode == dailyName
+}
+
+// submitOnline queues the finished game for the online leaderboard and
+// sends it if it can. Only plain runs go online: ones with modifiers,
+// assists or slow mode stay on the local list.
+func (g *Game) submitOnline(board string, e scoreEntry) {
+	if !onlineMode(g.Mode().Name) || len(e.Flags) > 0 {
+		return
+	}
+	online.Lock()
+	on := online.client != nil
+	if on {
+		online.cache.Pending = append(online.cache.Pending, leaderboard.Score{
+			Board: board, Name: profile.Initials, Score: e.Score, Frames: e.Frames, Lines: e.Lines, Level: e.Level, Date: e.Date,
+		})
+		saveOnline()
+	}
+	online.Unlock()
+	if on {
+		go flushOnline()
+	}
+}
+
+// flushOnline sends the queued results in order. Ones the server rejects
+// are dropped; at the first that can't be sent for now, it and the rest
+// stay queued for the next try.
+func flushOnline() {
+	online.Lock()
+	if online.flushing || online.client == nil {
+		online.Unlock()
+		return
+	}
+	online.flushing = true
+	c := online.client
+	online.Unlock()
+	defer func() {
+		online.Lock()
+		online.flushing = false
+		online.Unlock()
+	}()
+	for {
+		// Results finished while sending are picked up on the next pass.
+		online.Lock()
+		pending := slices.Clone(online.cache.Pending)
+		online.Unlock()
+		if len(pending) == 0 {
+			return
+		}
+		err := c.SubmitAll(context.Background(), pending, func(s leaderboard.Score, err error) {
+			if err != nil {
+				slog.Warn("online score rejected", "board", s.Board, "err", err)
+			} else {
+				slog.Info("online score sent", "board", s.Board)
+			}
+			online.Lock()
+			if i := slices.Index(online.cache.Pending, s); i >= 0 {
+				online.cache.Pending = slices.Delete(online.cache.Pending, i, i+1)
+			}
+			saveOnline()
+			online.Unlock()
+		})
+		if err != nil {
+			slog.Debug("online scores not sent", "err", err)
+			return
+		}
+	}
+}
+
+// fetchOnline refreshes board's cached rankings in the background.
+func fetchOnline(board string, friends []string) {
+	online.Lock()
+	defer online.Unlock()
+	c := online.client
+	if c == nil || online.fetching[board] {
+		return
+	}
+	if online.fetching == nil {
+		online.fetching, online.failed = map[string]bool{}, map[string]bool{}
+	}
+	online.fetching[board] = true
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		r, err := c.Fetch(ctx, board, friends)
+		cancel()
+		online.Lock()
+		defer online.Unlock()
+		delete(online.fetching, board)
+		online.failed[board] = err != nil
+		if err != nil {
+			slog.Debug("online rankings not fetched", "board", board, "err", err)
+			return
+		}
+		online.cache.Boards[board] = r
+		saveOnline()
+	}()
+}
+
+// onlineRankings is board's rankings as last fetched, and how they stand:
+// whether a fetch is under way and whether they're out of date because
+// the server couldn't be reached or online scores are off.
+func onlineRankings(board string) (r leaderboard.Rankings, ok, loading, stale bool) {
+	online.Lock()
+	defer online.Unlock()
+	r, ok = online.cache.Boards[board]
+	return r, ok, online.fetching[board], online.client == nil || online.failed[board]
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"tetris/leaderboard"
+)
+
+func TestOnlineQueue(t *testing.T) {
+	tempConfig(t)
+	loadOnline()
+	saved := profile
+	t.Cleanup(func() {
+		applyOnline(Settings{})
+		profile = saved
+	})
+
+	g := newGameSeeded(1, modeByName("Ultra"), Modifiers{})
+	e := scoreEntry{Score: 500, Lines: 12, Level: 2, Date: time.Now().UTC()}
+	g.submitOnline("Ultra", e)
+	if n := len(online.cache.Pending); n != 0 {
+		t.Fatalf("queued %d results with online scores off", n)
+	}
+
+	// Nothing listens here, so the result stays queued.
+	applyOnline(Settings{OnlineScores: true, LeaderboardEndpoint: "http://127.0.0.1:1"})
+	if profile.OnlineToken == "" {
+		t.Error("no token was made")
+	}
+	g.submitOnline("Ultra", e)
+	flagged := e
+	flagged.Flags = []string{"Mirror"}
+	g.submitOnline("Ultra", flagged)
+	newGameSeeded(1, modeByName("Marathon"), Modifiers{}).submitOnline("Marathon", e)
+
+	online.Lock()
+	online.cache.Boards["Ultra"] = leaderboard.Rankings{Global: []leaderboard.Entry{{Rank: 1, Score: 900}}}
+	saveOnline()
+	online.Unlock()
+	loadOnline()
+	if p := online.cache.Pending; len(p) != 1 || p[0].Board != "Ultra" || p[0].Score != 500 {
+		t.Errorf("queued %+v, want the one plain Ultra result", p)
+	}
+	if r := online.cache.Boards["Ultra"]; len(r.Global) != 1 {
+		t.Errorf("cached rankings %+v", r)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"runtime"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// pauseItem is one row of the pause menu.
+type pauseItem struct {
+	label string
+	pick  func(g *Game)
+}
+
+var pauseItems = func() []pauseItem {
+	items := []pauseItem{
+		{"Resume", (*Game).resume},
+		{"Restart", func(g *Game) {
+			if g.rec != nil {
+				g.stopRecording()
+			}
+			g.Reset()
+		}},
+		{"Settings", func(g *Game) { g.openMenu(settingsPage) }},
+		{"High Scores", func(g *Game) { g.openScoreView(g.Mode().Name) }},
+	}
+	switch runtime.GOOS {
+	case "ios", "android", "js":
+		// As on the title screen, the platform closes the app.
+	default:
+		items = append(items, pauseItem{"Quit", func(g *Game) { g.quitConfirm = true }})
+	}
+	return items
+}()
+
+// pause stops play and opens the pause menu on Resume.
+func (g *Game) pause() {
+	for g.menuOpen() {
+		g.closeMenu()
+	}
+	g.setState(statePaused)
+	g.pauseSel = 0
+}
+
+// updatePauseMenu handles input while paused: P, Esc or Start resume, and
+// the arrows, D-pad or a tap pick a row.
+func (g *Game) updatePauseMenu() {
+	if g.pausePressed() || inpututil.IsKeyJustPressed(ebiten.KeyEscape) || g.pad.pausePressed() {
+		g.resume()
+		return
+	}
+	n := len(pauseItems)
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) ||
+		g.pad.justPressed(ebiten.StandardGamepadButtonLeftTop) {
+		g.pauseSel = wrap(g.pauseSel-1, n)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) ||
+		g.pad.justPressed(ebiten.StandardGamepadButtonLeftBottom) {
+		g.pauseSel = wrap(g.pauseSel+1, n)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) ||
+		g.pad.justPressed(ebiten.StandardGamepadButtonRightBottom) {
+		pauseItems[g.pauseSel].pick(g)
+		return
+	}
+	rowH := menuRowH * float32(g.settings.UIScale)
+	top := g.pauseTop()
+	for _, id := range justTouchIDs(nil) {
+		_, y := ebiten.TouchPosition(id)
+		row := int((float32(y) - top) / rowH)
+		if float32(y) < top || row >= n {
+			g.resume()
+			return
+		}
+		g.pauseSel = row
+		pauseItems[row].pick(g)
+		return
+	}
+}
+
+func (g *Game) pauseTop() float32 {
+	_, sh := g.screenSize()
+	return sh/2 - float32(len(pauseItems))*menuRowH*float32(g.settings.UIScale)/2
+}
+
+func (g *Game) drawPauseMenu(screen *ebiten.Image) {
+	pal := g.palette()
+	w := float32(screen.Bounds().Dx())
+	k := float32(g.settings.UIScale)
+	top, rowH := g.pauseTop(), menuRowH*k
+	g.drawCentered(screen, tr("Paused"), w/2, top-rowH, pal.Text)
+	for i, it := range pauseItems {
+		y := top + float32(i)*rowH
+		if i == g.pauseSel {
+			vector.DrawFilledRect(screen, w/2-80*k, y, 160*k, rowH-2, pal.Select, false)
+		}
+		g.drawCentered(screen, tr(it.label), w/2, y+rowH*0.7, pal.Text)
+	}
+	hint := "Arrows pick, Enter selects, P/Esc resumes"
+	if touchScreen() {
+		hint = "Tap an option, or outside to resume"
+	}
+	g.drawCentered(screen, tr(hint), w/2, top+float32(len(pauseItems)+1)*rowH, pal.Dim)
+}
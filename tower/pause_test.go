@@ -0,0 +1,15 @@
+package main
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestPauseQuitOnlyOnDesktop(t *testing.T) {
+	quit := slices.ContainsFunc(pauseItems, func(it pauseItem) bool { return it.label == "Quit" })
+	desktop := !slices.Contains([]string{"ios", "android", "js"}, runtime.GOOS)
+	if quit != desktop {
+		t.Errorf("on %s the pause menu has Quit = %v, want %v", runtime.GOOS, quit, desktop)
+	}
+}
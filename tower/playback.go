@@ -0,0 +1,194 @@
+package main
+
+import (
+	"image/color"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// replayRows is how many replay names the browser shows at once.
+const replayRows = 16
+
+// playbackSpeeds are the frames stepped per update while watching.
+var playbackSpeeds = []int{1, 2, 4, 8}
+
+// replayBrowser lists the saved replays, newest first.
+type replayBrowser struct {
+	names []string // without the .json
+	sel   int
+	err   string // why the last pick didn't play
+}
+
+// playback steps a saved run through its own game, exactly as it was
+// played.
+type playback struct {
+	name   string
+	log    inputLog
+	game   *Game
+	frame  int
+	speed  int // index into playbackSpeeds
+	paused bool
+}
+
+func (g *Game) openReplays() {
+	b := &replayBrowser{}
+	if dir, err := replaysDir(); err == nil {
+		files, err := listSaves(dir)
+		if err != nil {
+			slog.Warn("replays unreadable", "dir", dir, "err", err)
+		}
+		for _, f := range files {
+			if name, ok := strings.CutSuffix(f, ".json"); ok {
+				b.names = append(b.names, name)
+			}
+		}
+	}
+	// Automatic names start with the date, so this is newest first for them.
+	slices.Sort(b.names)
+	slices.Reverse(b.names)
+	g.replays = b
+	g.setState(stateReplays)
+}
+
+// updateReplays picks a replay with the arrows and Enter, or a tap; Esc
+// goes back to the title.
+func (g *Game) updateReplays() {
+	b := g.replays
+	n := len(b.names)
+	pad := g.pad.justPressed
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape) || pad(ebiten.StandardGamepadButtonRightRight):
+		g.setState(stateTitle)
+	case n == 0:
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp) || pad(ebiten.StandardGamepadButtonLeftTop):
+		b.sel = wrap(b.sel-1, n)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown) || pad(ebiten.StandardGamepadButtonLeftBottom):
+		b.sel = wrap(b.sel+1, n)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) || pad(ebiten.StandardGamepadButtonRightBottom):
+		g.watch(b.names[b.sel])
+	}
+	k := float32(g.settings.UIScale)
+	for _, id := range justTouchIDs(nil) {
+		_, y := ebiten.TouchPosition(id)
+		row := b.first() + int((float32(y)-g.replaysTop())/(menuRowH*k))
+		if float32(y) < g.replaysTop() || row >= n {
+			g.setState(stateTitle)
+			return
+		}
+		b.sel = row
+		g.watch(b.names[row])
+		return
+	}
+}
+
+// first is the top row shown, keeping the selection in view.
+func (b *replayBrowser) first() int {
+	return max(0, min(b.sel-replayRows/2, len(b.names)-replayRows))
+}
+
+// watch loads the named replay and starts playing it.
+func (g *Game) watch(name string) {
+	path, err := replayPath(name)
+	var l inputLog
+	if err == nil {
+		err = viewSave(path, &l, inputLogMigrations)
+	}
+	if err != nil {
+		slog.Error("replay load failed", "name", name, "err", err)
+		g.replays.err = trf("Couldn't load %s", name)
+		return
+	}
+	g.replays.err = ""
+	p := &playback{name: name, log: l, game: l.newGame()}
+	p.game.sources = replaySources(&p.log)
+	g.playback = p
+	g.setState(stateReplay)
+}
+
+// updatePlayback steps the replay: Space pauses, left/right change speed,
+// Esc goes back to the list.
+func (g *Game) updatePlayback() {
+	p := g.playback
+	pad := g.pad.justPressed
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape) || pad(ebiten.StandardGamepadButtonRightRight):
+		g.playback = nil
+		g.setState(stateReplays)
+		return
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace) || pad(ebiten.StandardGamepadButtonRightBottom) ||
+		len(justTouchIDs(nil)) > 0:
+		p.paused = !p.paused
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight) || pad(ebiten.StandardGamepadButtonLeftRight):
+		p.speed = min(p.speed+1, len(playbackSpeeds)-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft) || pad(ebiten.StandardGamepadButtonLeftLeft):
+		p.speed = max(p.speed-1, 0)
+	}
+	if p.paused {
+		return
+	}
+	for range playbackSpeeds[p.speed] {
+		if p.frame >= len(p.log.Inputs) {
+			return
+		}
+		p.game.fx.update()
+		p.game.stepPlayers(p.game.pollInputs()...)
+		p.frame++
+	}
+}
+
+func (g *Game) replaysTop() float32 {
+	return 90 * float32(g.settings.UIScale)
+}
+
+func (g *Game) drawReplays(screen *ebiten.Image) {
+	pal := g.palette()
+	w := float32(screen.Bounds().Dx())
+	k := float32(g.settings.UIScale)
+	center := func(s string, y float32, c color.Color) {
+		g.drawCentered(screen, s, w/2, y, c)
+	}
+	b := g.replays
+	top, rowH := g.replaysTop(), menuRowH*k
+	center(tr("Replays"), top-rowH, pal.Text)
+	if len(b.names) == 0 {
+		center(tr("No saved replays"), top+rowH*0.7, pal.Dim)
+	}
+	first := b.first()
+	for i, name := range b.names[first:min(len(b.names), first+replayRows)] {
+		y := top + float32(i)*rowH
+		if first+i == b.sel {
+			vector.DrawFilledRect(screen, 24, y, w-48, rowH-2, pal.Select, false)
+		}
+		g.drawText(screen, name, 36, y+rowH*0.7, pal.Text)
+	}
+	bottom := top + float32(replayRows+1)*rowH
+	if b.err != "" {
+		center(b.err, bottom-rowH, pal.Alert)
+	}
+	center(tr("Enter watches, Esc goes back"), bottom, pal.Dim)
+}
+
+// drawPlayback draws the replay's game as it was played, with the
+// playback state along the bottom.
+func (g *Game) drawPlayback(screen *ebiten.Image) {
+	pal := g.palette()
+	p := g.playback
+	p.game.drawPlayfield(screen)
+	w, h := float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy())
+	k := float32(g.settings.UIScale)
+	vector.DrawFilledRect(screen, 0, h-40*k, w, 40*k, alpha(pal.Shade, 180), false)
+	status := trf("Replay %s  x%d  %s / %s", p.log.Mode, playbackSpeeds[p.speed], raceTime(p.frame), raceTime(len(p.log.Inputs)))
+	switch {
+	case p.frame >= len(p.log.Inputs):
+		status += "  " + tr("(ended)")
+	case p.paused:
+		status += "  " + tr("(paused)")
+	}
+	g.drawText(screen, status, 12*k, h-24*k, pal.Text)
+	g.drawText(screen, tr("Space pause, Left/Right speed, Esc back"), 12*k, h-8*k, pal.Dim)
+}
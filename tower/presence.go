@@ -0,0 +1,12 @@
+package main
+
+import "tetris/engine"
+
+// statusFeed shares the run's score and level as each piece locks, with
+// Settings.ShareStatus on. State changes are shared by setState.
+type statusFeed struct {
+	engine.NopObserver
+	g *Game
+}
+
+func (s statusFeed) OnLock(int, int) { s.g.publishStatus() }
@@ -0,0 +1,139 @@
+package presence
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// Discord's local RPC frames each JSON payload with two little-endian
+// uint32s: the opcode and the payload length.
+const (
+	opHandshake = 0
+	opFrame     = 1
+	opClose     = 2
+	// maxPayload bounds what's read back, against a confused peer.
+	maxPayload = 64 << 10
+)
+
+// discord is a connection to the Discord client's IPC socket.
+type discord struct {
+	conn  io.ReadWriteCloser
+	nonce int
+}
+
+// activity is the Rich Presence card: two lines and an elapsed timer.
+type activity struct {
+	Details    string      `json:"details,omitempty"`
+	State      string      `json:"state,omitempty"`
+	Timestamps *timestamps `json:"timestamps,omitempty"`
+}
+
+type timestamps struct {
+	Start int64 `json:"start"`
+}
+
+// activityFor puts s on the card: the mode, then the score and level, with
+// the time since the run began while it's being played.
+func activityFor(s Status) *activity {
+	if s.Mode == "" {
+		return &activity{Details: "In the menus"}
+	}
+	a := &activity{Details: s.Mode, State: fmt.Sprintf("Score %d, level %d", s.Score, s.Level)}
+	switch s.State {
+	case "playing":
+		if !s.Started.IsZero() {
+			a.Timestamps = &timestamps{Start: s.Started.Unix()}
+		}
+	case "game over":
+		a.State = fmt.Sprintf("Finished with %d, %d lines", s.Score, s.Lines)
+	default:
+		a.Details += " (" + s.State + ")"
+	}
+	return a
+}
+
+// dialDiscord connects to the running Discord client as the application
+// appID and waits for it to be ready.
+func dialDiscord(appID string) (*discord, error) {
+	conn, err := openIPC()
+	if err != nil {
+		return nil, err
+	}
+	d := &discord{conn: conn}
+	if err := d.send(opHandshake, map[string]any{"v": 1, "client_id": appID}); err != nil {
+		d.close()
+		return nil, err
+	}
+	if err := d.reply(); err != nil {
+		d.close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// setActivity shows a on the player's profile, or clears it if a is nil.
+func (d *discord) setActivity(a *activity) error {
+	d.nonce++
+	err := d.send(opFrame, map[string]any{
+		"cmd":   "SET_ACTIVITY",
+		"args":  map[string]any{"pid": os.Getpid(), "activity": a},
+		"nonce": strconv.Itoa(d.nonce),
+	})
+	if err != nil {
+		return err
+	}
+	return d.reply()
+}
+
+func (d *discord) send(op uint32, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b := make([]byte, 8, 8+len(payload))
+	binary.LittleEndian.PutUint32(b, op)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(payload)))
+	_, err = d.conn.Write(append(b, payload...))
+	return err
+}
+
+// reply reads Discord's answer to the last frame sent, failing if it
+// closed the connection or reported an error.
+func (d *discord) reply() error {
+	var hdr [8]byte
+	if _, err := io.ReadFull(d.conn, hdr[:]); err != nil {
+		return err
+	}
+	op, n := binary.LittleEndian.Uint32(hdr[:]), binary.LittleEndian.Uint32(hdr[4:])
+	if n > maxPayload {
+		return fmt.Errorf("discord sent a %d byte frame", n)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(d.conn, payload); err != nil {
+		return err
+	}
+	var msg struct {
+		Evt  string `json:"evt"`
+		Data struct {
+			Message string `json:"message"`
+		} `json:"data"`
+		Message string `json:"message"` // on opClose
+	}
+	json.Unmarshal(payload, &msg)
+	switch {
+	case op == opClose:
+		return errors.New("discord closed the connection: " + msg.Message)
+	case msg.Evt == "ERROR":
+		return errors.New("discord: " + msg.Data.Message)
+	}
+	return nil
+}
+
+func (d *discord) close() {
+	d.conn.Close()
+}
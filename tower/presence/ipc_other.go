@@ -0,0 +1,12 @@
+//go:build !unix && !windows
+
+package presence
+
+import (
+	"errors"
+	"io"
+)
+
+func openIPC() (io.ReadWriteCloser, error) {
+	return nil, errors.New("discord isn't reachable on this platform")
+}
@@ -0,0 +1,31 @@
+//go:build unix
+
+package presence
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// openIPC connects to the first Discord socket found in the usual
+// temporary directories. Discord numbers them from 0 when several
+// clients run at once.
+func openIPC() (io.ReadWriteCloser, error) {
+	var dirs []string
+	for _, env := range []string{"XDG_RUNTIME_DIR", "TMPDIR", "TMP", "TEMP"} {
+		if d := os.Getenv(env); d != "" {
+			dirs = append(dirs, d)
+		}
+	}
+	for _, dir := range append(dirs, "/tmp") {
+		for i := range 10 {
+			if conn, err := net.Dial("unix", filepath.Join(dir, fmt.Sprintf("discord-ipc-%d", i))); err == nil {
+				return conn, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no discord-ipc socket found")
+}
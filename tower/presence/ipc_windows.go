@@ -0,0 +1,18 @@
+package presence
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// openIPC opens the first of Discord's named pipes that's there. Discord
+// numbers them from 0 when several clients run at once.
+func openIPC() (io.ReadWriteCloser, error) {
+	for i := range 10 {
+		if f, err := os.OpenFile(fmt.Sprintf(`\\.\pipe\discord-ipc-%d`, i), os.O_RDWR, 0); err == nil {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("no discord-ipc pipe found")
+}
@@ -0,0 +1,115 @@
+// Package presence shares what the player is doing outside the game: as a
+// JSON status file that stream overlays can poll, and as Discord Rich
+// Presence while Discord is running. Updates are written off the game
+// loop, so a slow disk or an absent Discord never holds up a frame.
+package presence
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// retryInterval is how long to wait before looking for Discord again
+// after it wasn't found or the connection dropped.
+const retryInterval = 15 * time.Second
+
+// Status is what's shared. Mode is empty outside a run.
+type Status struct {
+	Mode    string    `json:"mode,omitempty"`
+	State   string    `json:"state"` // "playing", "paused", "game over", or the screen shown
+	Score   int       `json:"score"`
+	Lines   int       `json:"lines"`
+	Level   int       `json:"level"`
+	Started time.Time `json:"started,omitzero"` // when the run began
+}
+
+// Publisher writes each Status it's given to the status file and, with
+// an application ID, to Discord.
+type Publisher struct {
+	path  string
+	appID string
+	next  chan Status // the latest status not yet written
+	done  chan struct{}
+}
+
+// New starts a publisher writing to the file at path. Discord is left out
+// if appID is empty.
+func New(path, appID string) *Publisher {
+	p := &Publisher{path: path, appID: appID, next: make(chan Status, 1), done: make(chan struct{})}
+	go p.run()
+	return p
+}
+
+// Publish queues s in place of any status not yet written. It never
+// blocks, and must not be called concurrently or after Close.
+func (p *Publisher) Publish(s Status) {
+	select {
+	case <-p.next:
+	default:
+	}
+	p.next <- s
+}
+
+// Close writes the last status, clears the Discord activity and removes
+// the status file, so overlays don't show a finished session as live.
+func (p *Publisher) Close() {
+	close(p.next)
+	<-p.done
+}
+
+func (p *Publisher) run() {
+	defer close(p.done)
+	var (
+		d        *discord
+		lastDial time.Time
+		failing  bool // the file's last write failed, and was logged
+	)
+	for s := range p.next {
+		err := writeStatus(p.path, s)
+		if err != nil && !failing {
+			slog.Warn("status file write failed", "path", p.path, "err", err)
+		}
+		failing = err != nil
+		if p.appID == "" {
+			continue
+		}
+		if d == nil && time.Since(lastDial) >= retryInterval {
+			lastDial = time.Now()
+			if d, err = dialDiscord(p.appID); err != nil {
+				slog.Debug("discord not reachable", "err", err)
+			}
+		}
+		if d != nil {
+			if err := d.setActivity(activityFor(s)); err != nil {
+				slog.Debug("discord connection lost", "err", err)
+				d.close()
+				d = nil
+			}
+		}
+	}
+	if d != nil {
+		d.setActivity(nil)
+		d.close()
+	}
+	os.Remove(p.path)
+}
+
+// writeStatus replaces the file at path with s, through a temporary file
+// so an overlay never reads half of one.
+func writeStatus(path string, s Status) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
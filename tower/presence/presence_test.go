@@ -0,0 +1,75 @@
+package presence
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStatusFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "status.json")
+	p := New(path, "")
+	p.Publish(Status{Mode: "Sprint", State: "playing", Score: 100, Lines: 4, Level: 1})
+	var got Status
+	for deadline := time.Now().Add(5 * time.Second); got.Mode == "" && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		if b, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(b, &got)
+		}
+	}
+	if got.Mode != "Sprint" || got.Score != 100 || got.Lines != 4 {
+		t.Errorf("the status file holds %+v", got)
+	}
+	p.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the status file outlived Close: %v", err)
+	}
+}
+
+func TestDiscordFrames(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	frames := make(chan map[string]any, 2)
+	go func() {
+		for {
+			var hdr [8]byte
+			if _, err := io.ReadFull(server, hdr[:]); err != nil {
+				return
+			}
+			payload := make([]byte, binary.LittleEndian.Uint32(hdr[4:]))
+			io.ReadFull(server, payload)
+			var m map[string]any
+			json.Unmarshal(payload, &m)
+			frames <- m
+			reply := []byte(`{"evt":"READY"}`)
+			out := binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, opFrame), uint32(len(reply)))
+			server.Write(append(out, reply...))
+		}
+	}()
+
+	d := &discord{conn: client}
+	if err := d.send(opHandshake, map[string]any{"v": 1, "client_id": "123"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.reply(); err != nil {
+		t.Fatal(err)
+	}
+	if m := <-frames; m["client_id"] != "123" {
+		t.Errorf("handshake %v", m)
+	}
+	started := time.Unix(1000, 0)
+	if err := d.setActivity(activityFor(Status{Mode: "Marathon", State: "playing", Score: 50, Level: 2, Started: started})); err != nil {
+		t.Fatal(err)
+	}
+	m := <-frames
+	act := m["args"].(map[string]any)["activity"].(map[string]any)
+	if m["cmd"] != "SET_ACTIVITY" || act["details"] != "Marathon" || act["state"] != "Score 50, level 2" ||
+		act["timestamps"].(map[string]any)["start"] != 1000.0 {
+		t.Errorf("activity frame %v", m)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build js || android || ios
+
+package main
+
+const statusShared = false
+
+func applyStatus(Settings) {}
+
+func (g *Game) publishStatus() {}
+
+func shutdownStatus() {}
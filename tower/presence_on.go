@@ -0,0 +1,60 @@
+//go:build !js && !android && !ios
+
+package main
+
+import (
+	"log/slog"
+	"path/filepath"
+
+	"tetris/presence"
+)
+
+// statusShared is whether this build can share status. Phone and browser
+// builds leave the presence package out, having no Discord or overlays to
+// feed.
+const statusShared = true
+
+// statusPublisher is non-nil while Settings.ShareStatus is on.
+var statusPublisher *presence.Publisher
+
+// applyStatus starts or stops sharing status to match s: status.json in
+// the config directory, and Discord too if s.DiscordAppID is set.
+func applyStatus(s Settings) {
+	if s.ShareStatus == (statusPublisher != nil) {
+		return
+	}
+	if !s.ShareStatus {
+		shutdownStatus()
+		return
+	}
+	dir, err := configDir()
+	if err != nil {
+		slog.Error("status sharing unavailable", "err", err)
+		return
+	}
+	path := filepath.Join(dir, "status.json")
+	statusPublisher = presence.New(path, s.DiscordAppID)
+	slog.Info("sharing status", "file", path, "discord", s.DiscordAppID != "")
+}
+
+// publishStatus shares what the player is doing now. Replays being
+// watched and benchmark runs aren't the player's and aren't shared.
+func (g *Game) publishStatus() {
+	if statusPublisher == nil || g.offline || g.bench != nil {
+		return
+	}
+	s := presence.Status{State: g.base().String()}
+	switch g.base() {
+	case statePlaying, statePaused, stateGameOver:
+		s.Mode, s.Score, s.Lines, s.Level, s.Started = g.Mode().Name, g.TotalScore(), g.Lines(), g.Level(), g.startedAt
+	}
+	statusPublisher.Publish(s)
+}
+
+// shutdownStatus clears the shared status on exit or opting out.
+func shutdownStatus() {
+	if statusPublisher != nil {
+		statusPublisher.Close()
+		statusPublisher = nil
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+import "log/slog"
+
+// countPrestige adds an Endless rollover to the profile's total.
+func (g *Game) countPrestige() {
+	if g.offline {
+		return
+	}
+	profile.TotalPrestiges++
+	if err := saveProfile(); err != nil {
+		slog.Error("profile save failed", "err", err)
+	}
+	publishHistory()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+)
+
+// playerProfile is the player's long-term progress.
+type playerProfile struct {
+	Version        int
+	TotalPrestiges int
+	// Name goes on result cards; empty leaves it off.
+	Name string `json:",omitempty"`
+	// Initials fills in the high score name entry.
+	Initials string `json:",omitempty"`
+	// SolvedPuzzles names the puzzles solved so far.
+	SolvedPuzzles []string `json:",omitempty"`
+	// OnlineToken is the anonymous token the online leaderboard knows the
+	// player by, made the first time it's turned on.
+	OnlineToken string `json:",omitempty"`
+}
+
+// profileMigrations upgrade older profile files; see loadSave.
+var profileMigrations []saveMigration
+
+var (
+	profile         playerProfile
+	profileReadOnly bool
+)
+
+func profilePath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "profile.json"), nil
+}
+
+func loadProfile() {
+	path, err := profilePath()
+	if err != nil {
+		return
+	}
+	if err := loadSave(path, &profile, profileMigrations); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("profile unreadable, not saving progress", "path", path, "err", err)
+		profileReadOnly = true
+		profile = playerProfile{}
+	}
+}
+
+func saveProfile() error {
+	if profileReadOnly {
+		return nil
+	}
+	path, err := profilePath()
+	if err != nil {
+		return err
+	}
+	profile.Version = len(profileMigrations)
+	b, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeSave(path, b)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"tetris/engine"
+)
+
+// puzzlePrefix starts a puzzle's mode name, so logs and links find it.
+const puzzlePrefix = "Puzzle: "
+
+// puzzleMode is the mode that plays the named puzzle. Hold is off: every
+// piece dealt is one to place.
+func puzzleMode(name string) engine.Mode {
+	return engine.Mode{Name: puzzlePrefix + name, Width: engine.BoardW, Puzzle: name, Rules: engine.Ruleset{NoHold: true}}
+}
+
+// puzzleByModeName reads a mode name made by puzzleMode back.
+func puzzleByModeName(mode string) (engine.Mode, bool) {
+	name, ok := strings.CutPrefix(mode, puzzlePrefix)
+	if !ok || !slices.Contains(engine.Puzzles(), name) {
+		return engine.Mode{}, false
+	}
+	return puzzleMode(name), true
+}
+
+var puzzlePage = &menuPage{title: "Puzzles", items: puzzleItems()}
+
+// puzzleItems lists every puzzle but the editor's, marking those already
+// solved.
+func puzzleItems() []settingItem {
+	var items []settingItem
+	for _, name := range engine.Puzzles() {
+		if name == editorPuzzle {
+			continue
+		}
+		items = append(items, settingItem{
+			label: name,
+			value: func(g *Game) string {
+				if slices.Contains(profile.SolvedPuzzles, name) {
+					return "Solved"
+				}
+				return ""
+			},
+			adjust: func(g *Game, dir int) { g.startMode(puzzleMode(name), Modifiers{}) },
+		})
+	}
+	return items
+}
+
+// markSolved records a solved puzzle in the profile.
+func (g *Game) markSolved() {
+	name := g.Mode().Puzzle
+	if g.offline || name == "" || name == editorPuzzle || !g.Won() || slices.Contains(profile.SolvedPuzzles, name) {
+		return
+	}
+	profile.SolvedPuzzles = append(profile.SolvedPuzzles, name)
+	if err := saveProfile(); err != nil {
+		slog.Error("profile save failed", "err", err)
+	}
+}
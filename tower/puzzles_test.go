@@ -0,0 +1,41 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPuzzleModeNames(t *testing.T) {
+	m := modeByName(puzzlePrefix + "Tetris Well")
+	if m.Puzzle != "Tetris Well" || !m.Rules.NoHold {
+		t.Errorf("mode = %+v, want the Tetris Well puzzle without hold", m)
+	}
+	if m := modeByName(puzzlePrefix + "Nope"); m.Puzzle != "" {
+		t.Errorf("an unknown puzzle found %+v", m)
+	}
+	if len(puzzlePage.items) == 0 {
+		t.Error("the Puzzles page is empty")
+	}
+}
+
+func TestSolvingMarksThePuzzle(t *testing.T) {
+	tempConfig(t)
+	saved := profile
+	t.Cleanup(func() { profile = saved })
+	profile.SolvedPuzzles = nil
+
+	g := newGameSeeded(1, puzzleMode("Tetris Well"), Modifiers{})
+	// The I turns upright in column 5 and moves over to the well in 9.
+	for _, in := range []frameInput{{rotCW: true}, {shift: 4}, {hardDrop: true}} {
+		g.stepPlayers(in)
+	}
+	if !g.GameOver() || !g.Won() {
+		t.Fatalf("the drop didn't solve the puzzle: lines %d", g.Lines())
+	}
+	if !slices.Equal(profile.SolvedPuzzles, []string{"Tetris Well"}) {
+		t.Errorf("solved puzzles %v", profile.SolvedPuzzles)
+	}
+	if v := puzzlePage.items[0].value(g); v != "Solved" {
+		t.Errorf("the Puzzles page shows %q for it", v)
+	}
+}
@@ -0,0 +1,382 @@
+// Package qr encodes short byte strings as QR codes, versions 1 to 10 at
+// error correction level M, which is plenty for a seed or a URL.
+package qr
+
+import "errors"
+
+// ErrTooLong is returned for data that doesn't fit in version 10.
+var ErrTooLong = errors.New("qr: data too long")
+
+// Code is an encoded symbol, Size modules on a side, without quiet zone.
+type Code struct {
+	Size    int
+	modules []bool
+}
+
+// Black reports whether the module at (x, y) is dark.
+func (c *Code) Black(x, y int) bool {
+	return c.modules[y*c.Size+x]
+}
+
+// block layout per version at level M: EC codewords per block, then the
+// count and data length of the two block groups.
+var versions = [...]struct {
+	ec, n1, d1, n2, d2 int
+	align              []int
+}{
+	{10, 1, 16, 0, 0, nil},
+	{16, 1, 28, 0, 0, []int{6, 18}},
+	{26, 1, 44, 0, 0, []int{6, 22}},
+	{18, 2, 32, 0, 0, []int{6, 26}},
+	{24, 2, 43, 0, 0, []int{6, 30}},
+	{16, 4, 27, 0, 0, []int{6, 34}},
+	{18, 4, 31, 0, 0, []int{6, 22, 38}},
+	{22, 2, 38, 2, 39, []int{6, 24, 42}},
+	{22, 3, 36, 2, 37, []int{6, 26, 46}},
+	{26, 4, 43, 1, 44, []int{6, 28, 50}},
+}
+
+// Encode encodes data in byte mode in the smallest version that holds it.
+func Encode(data []byte) (*Code, error) {
+	for v := 1; v <= len(versions); v++ {
+		vi := versions[v-1]
+		capacity := vi.n1*vi.d1 + vi.n2*vi.d2
+		countBits := 8
+		if v >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) > 8*capacity {
+			continue
+		}
+		var bb bitBuffer
+		bb.put(0b0100, 4)
+		bb.put(len(data), countBits)
+		for _, b := range data {
+			bb.put(int(b), 8)
+		}
+		bb.put(0, min(4, 8*capacity-len(bb)))
+		bb.put(0, (8-len(bb)%8)%8)
+		cw := bb.bytes()
+		for pad := 0xEC; len(cw) < capacity; pad ^= 0xEC ^ 0x11 {
+			cw = append(cw, byte(pad))
+		}
+		return build(v, interleave(v, cw)), nil
+	}
+	return nil, ErrTooLong
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) put(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>i&1 != 0)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return out
+}
+
+// interleave splits the data codewords into blocks, adds each block's
+// error correction, and interleaves the lot as the symbol stores it.
+func interleave(v int, data []byte) []byte {
+	vi := versions[v-1]
+	var blocks, ecs [][]byte
+	div := rsDivisor(vi.ec)
+	for i := 0; i < vi.n1+vi.n2; i++ {
+		n := vi.d1
+		if i >= vi.n1 {
+			n = vi.d2
+		}
+		blocks = append(blocks, data[:n])
+		ecs = append(ecs, rsRemainder(data[:n], div))
+		data = data[n:]
+	}
+	var out []byte
+	for i := 0; i < max(vi.d1, vi.d2); i++ {
+		for _, b := range blocks {
+			if i < len(b) {
+				out = append(out, b[i])
+			}
+		}
+	}
+	for i := 0; i < vi.ec; i++ {
+		for _, e := range ecs {
+			out = append(out, e[i])
+		}
+	}
+	return out
+}
+
+// gfMul multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMul(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+func rsDivisor(degree int) []byte {
+	div := make([]byte, degree)
+	div[degree-1] = 1
+	root := byte(1)
+	for range degree {
+		for j := range div {
+			div[j] = gfMul(div[j], root)
+			if j+1 < len(div) {
+				div[j] ^= div[j+1]
+			}
+		}
+		root = gfMul(root, 2)
+	}
+	return div
+}
+
+func rsRemainder(data, div []byte) []byte {
+	rem := make([]byte, len(div))
+	for _, b := range data {
+		factor := b ^ rem[0]
+		copy(rem, rem[1:])
+		rem[len(rem)-1] = 0
+		for i, c := range div {
+			rem[i] ^= gfMul(c, factor)
+		}
+	}
+	return rem
+}
+
+// symbol is a Code under construction, tracking which modules are part of
+// the fixed patterns rather than data.
+type symbol struct {
+	Code
+	fixed []bool
+}
+
+func (s *symbol) set(x, y int, dark bool) {
+	s.modules[y*s.Size+x] = dark
+	s.fixed[y*s.Size+x] = true
+}
+
+func build(v int, codewords []byte) *Code {
+	size := 17 + 4*v
+	s := &symbol{Code: Code{Size: size, modules: make([]bool, size*size)}, fixed: make([]bool, size*size)}
+	for i := range size {
+		s.set(6, i, i%2 == 0)
+		s.set(i, 6, i%2 == 0)
+	}
+	for _, c := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := c[0]+dx, c[1]+dy
+				if x >= 0 && x < size && y >= 0 && y < size {
+					d := max(abs(dx), abs(dy))
+					s.set(x, y, d != 2 && d != 4)
+				}
+			}
+		}
+	}
+	align := versions[v-1].align
+	for i, ay := range align {
+		for j, ax := range align {
+			if i == 0 && j == 0 || i == 0 && j == len(align)-1 || i == len(align)-1 && j == 0 {
+				continue // under a finder
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					s.set(ax+dx, ay+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+	s.drawFormat(0) // reserves the format areas
+	if v >= 7 {
+		rem := v
+		for range 12 {
+			rem = rem<<1 ^ (rem>>11)*0x1F25
+		}
+		bits := v<<12 | rem
+		for i := range 18 {
+			dark := bits>>i&1 != 0
+			a, b := size-11+i%3, i/3
+			s.set(a, b, dark)
+			s.set(b, a, dark)
+		}
+	}
+
+	i := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := range size {
+			for j := range 2 {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = size - 1 - vert
+				}
+				if !s.fixed[y*size+x] && i < len(codewords)*8 {
+					s.modules[y*size+x] = codewords[i/8]>>(7-i%8)&1 != 0
+					i++
+				}
+			}
+		}
+	}
+
+	best, bestPenalty := -1, 0
+	for mask := range 8 {
+		s.applyMask(mask)
+		s.drawFormat(mask)
+		if p := s.penalty(); best < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		s.applyMask(mask)
+	}
+	s.applyMask(best)
+	s.drawFormat(best)
+	return &s.Code
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// drawFormat writes both copies of the format bits for level M and mask.
+func (s *symbol) drawFormat(mask int) {
+	data := 0b00<<3 | mask // level M
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+	size := s.Size
+	for i := range 6 {
+		s.set(8, i, bit(i))
+	}
+	s.set(8, 7, bit(6))
+	s.set(8, 8, bit(7))
+	s.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		s.set(14-i, 8, bit(i))
+	}
+	for i := range 8 {
+		s.set(size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		s.set(8, size-15+i, bit(i))
+	}
+	s.set(8, size-8, true)
+}
+
+func (s *symbol) applyMask(mask int) {
+	for y := range s.Size {
+		for x := range s.Size {
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip && !s.fixed[y*s.Size+x] {
+				s.modules[y*s.Size+x] = !s.modules[y*s.Size+x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the symbol is to scan, per the standard's four
+// rules: long runs, 2x2 blocks, finder-like patterns, and dark balance.
+func (s *symbol) penalty() int {
+	n := s.Size
+	p, dark := 0, 0
+	row, col := make([]bool, n), make([]bool, n)
+	for i := range n {
+		for j := range n {
+			row[j], col[j] = s.Black(j, i), s.Black(i, j)
+		}
+		p += linePenalty(row) + linePenalty(col)
+	}
+	for y := range n {
+		for x := range n {
+			c := s.Black(x, y)
+			if c {
+				dark++
+			}
+			if x+1 < n && y+1 < n && c == s.Black(x+1, y) && c == s.Black(x, y+1) && c == s.Black(x+1, y+1) {
+				p += 3
+			}
+		}
+	}
+	total := n * n
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return p + max(0, k)*10
+}
+
+var finderLike = []bool{true, false, true, true, true, false, true}
+
+// linePenalty scores one row or column for runs and finder-like patterns.
+func linePenalty(line []bool) int {
+	p, run := 0, 0
+	for j := range line {
+		if j > 0 && line[j] == line[j-1] {
+			run++
+		} else {
+			run = 1
+		}
+		if run == 5 {
+			p += 3
+		} else if run > 5 {
+			p++
+		}
+		if j+len(finderLike) <= len(line) && matches(line[j:], finderLike) &&
+			(allLight(line, j-4, j) || allLight(line, j+7, j+11)) {
+			p += 40
+		}
+	}
+	return p
+}
+
+func matches(line, want []bool) bool {
+	for k, w := range want {
+		if line[k] != w {
+			return false
+		}
+	}
+	return true
+}
+
+// allLight reports whether line[from:to] is light, counting modules past
+// either end as light.
+func allLight(line []bool, from, to int) bool {
+	for k := max(0, from); k < min(to, len(line)); k++ {
+		if line[k] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// activeRun reports whether quitting now would throw away a run in
+// progress.
+func (g *Game) activeRun() bool {
+	return !g.GameOver() && g.rec != nil && g.bench == nil
+}
+
+// updateQuit asks before closing the window on a run in progress. It
+// returns ebiten.Termination once quitting is confirmed.
+func (g *Game) updateQuit() error {
+	if ebiten.IsWindowBeingClosed() {
+		if !g.activeRun() {
+			return ebiten.Termination
+		}
+		g.pause()
+		g.quitConfirm = true
+	}
+	if !g.quitConfirm {
+		return nil
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyY) || inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		return g.quit()
+	case inpututil.IsKeyJustPressed(ebiten.KeyN) || inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		g.quitConfirm = false
+	}
+	return nil
+}
+
+// quit autosaves a run in progress and ends the game loop.
+func (g *Game) quit() error {
+	if g.activeRun() {
+		g.rec.log.Assisted, g.rec.log.CPU = g.assisted, g.cpuPlayed
+		if err := saveAutosave(g); err != nil {
+			slog.Error("autosave failed", "err", err)
+		}
+	}
+	return ebiten.Termination
+}
+
+func (g *Game) drawQuitConfirm(screen *ebiten.Image) {
+	pal := g.palette()
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	vector.DrawFilledRect(screen, 0, 0, float32(w), float32(h), alpha(pal.Shade, 200), false)
+	for i, s := range []string{"Quit this run?", "Continue it from the title screen.", "Y/Enter quit, N/Esc keep playing"} {
+		g.drawCentered(screen, tr(s), float32(w)/2, float32(h/2-20+i*18), pal.Text)
+	}
+}
+
+func autosavePath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "autosave.json"), nil
+}
+
+// autosave is a quit run: its input log, so the replay and high score
+// records carry on, and the engine's save of where it stopped. Autosaves
+// from before the engine could save, or whose save this build can't read,
+// are resumed by replaying the log instead.
+type autosave struct {
+	inputLog
+	State json.RawMessage `json:",omitempty"`
+}
+
+// saveAutosave keeps g's run to continue from the title screen.
+func saveAutosave(g *Game) error {
+	path, err := autosavePath()
+	if err != nil {
+		return err
+	}
+	a := autosave{inputLog: g.rec.log}
+	if a.State, err = g.Save(); err != nil {
+		slog.Info("autosaving the input log only", "mode", a.Mode, "err", err)
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	return writeSave(path, b)
+}
+
+// hasAutosave reports whether there's a quit run to continue.
+func hasAutosave() bool {
+	path, err := autosavePath()
+	if err != nil {
+		return false
+	}
+	_, err = readSave(path)
+	return err == nil
+}
+
+// continueRun picks up the autosaved run in place of g and removes the
+// file, so the run resumes only once. The game comes back paused; if
+// there's nothing to continue, g stays on the title screen.
+func (g *Game) continueRun() {
+	g.canContinue = false
+	path, err := autosavePath()
+	if err != nil {
+		return
+	}
+	var a autosave
+	if err := loadSave(path, &a, inputLogMigrations); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("autosave unreadable, starting fresh", "path", path, "err", err)
+		}
+		return
+	}
+	if err := removeSave(path); err != nil {
+		slog.Warn("autosave not removed", "path", path, "err", err)
+	}
+	settings, pad, titleMode := g.settings, g.pad, g.titleMode
+	l := a.inputLog
+	g.init(l.Seed, modeByName(l.Mode), l.Modifiers)
+	g.settings, g.assisted, g.cpuPlayed = l.Settings, l.Assisted, l.CPU
+	g.SoftDropFrames = l.Settings.SoftDropFrames
+	restored := false
+	if a.State != nil {
+		if err := g.Restore(a.State); err != nil {
+			slog.Warn("autosave state unreadable, replaying its inputs", "err", err)
+		} else {
+			restored = true
+		}
+	}
+	if !restored {
+		g.offline = true
+		for i := range l.Inputs {
+			l.step(g, i)
+		}
+		g.offline = false
+	}
+	g.settings, g.pad = settings, pad
+	if g.GameOver() {
+		g.state, g.titleMode = stateTitle, titleMode
+		return
+	}
+	g.state = statePaused
+	g.rec = &recorder{log: l}
+	slog.Info("continued autosaved run", "mode", l.Mode, "frames", len(l.Inputs), "replayed", !restored)
+}
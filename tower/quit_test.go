@@ -0,0 +1,40 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestContinueKeepsFlags(t *testing.T) {
+	tempConfig(t)
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.rec = newRecorder("", g)
+	for range 10 {
+		g.stepPlayers(frameInput{})
+		g.rec.frame(g.Hash(), frameInput{})
+	}
+	g.assisted, g.cpuPlayed = true, true
+	g.quit()
+	if !hasAutosave() {
+		t.Fatal("the quit run wasn't autosaved")
+	}
+
+	r := newGameSeeded(2, modes[0], Modifiers{})
+	r.state, r.canContinue = stateTitle, true
+	r.continueRun()
+	if r.state != statePaused {
+		t.Fatal("the quit run didn't continue")
+	}
+	if !r.assisted || !slices.Contains(r.scoreFlags(), assistedFlag) {
+		t.Error("the continued run lost its Assisted flag")
+	}
+	if !r.cpuPlayed {
+		t.Error("the continued run forgot the CPU played it")
+	}
+	if r.Hash() != g.Hash() {
+		t.Error("the continued run isn't where it was quit")
+	}
+	if hasAutosave() {
+		t.Error("the autosave is still there to continue twice")
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// replayRender plays an input log through the normal renderer and writes
+// every frame to out as raw RGBA, logicalW x logicalH, for
+// cmd/render-replay to encode.
+type replayRender struct {
+	g   *Game
+	log inputLog
+	i   int
+	out *bufio.Writer
+	buf []byte
+	err error
+}
+
+func (r *replayRender) Update() error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.i >= len(r.log.Inputs) {
+		return ebiten.Termination
+	}
+	return nil
+}
+
+// Draw steps the simulation as well, so exactly one frame is written per
+// recorded input however the loop schedules Update and Draw.
+func (r *replayRender) Draw(screen *ebiten.Image) {
+	if r.i >= len(r.log.Inputs) || r.err != nil {
+		return
+	}
+	r.g.fx.update()
+	r.log.step(r.g, r.i)
+	r.i++
+	r.g.Draw(screen)
+	if r.buf == nil {
+		r.buf = make([]byte, 4*logicalW*logicalH)
+	}
+	screen.ReadPixels(r.buf)
+	_, r.err = r.out.Write(r.buf)
+}
+
+func (r *replayRender) Layout(ow, oh int) (int, int) {
+	return logicalW, logicalH
+}
+
+// renderReplay writes the frames of the log at path to stdout.
+func renderReplay(path string) error {
+	var l inputLog
+	if err := viewSave(path, &l, inputLogMigrations); err != nil {
+		return err
+	}
+	r := &replayRender{g: l.newGame(), log: l, out: bufio.NewWriterSize(os.Stdout, 1<<20)}
+	ebiten.SetVsyncEnabled(false)
+	ebiten.SetTPS(ebiten.SyncWithFPS)
+	ebiten.SetRunnableOnUnfocused(true)
+	if err := ebiten.RunGame(r); err != nil {
+		return err
+	}
+	return r.out.Flush()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// ReplaySave decides what happens to a run's input log when it ends.
+type ReplaySave int
+
+const (
+	// ReplayAsk shows a save prompt on the game over screen.
+	ReplayAsk ReplaySave = iota
+	// ReplayAlways saves every run under an automatic name.
+	ReplayAlways
+	// ReplayNever drops every run.
+	ReplayNever
+)
+
+func (r ReplaySave) String() string {
+	switch r {
+	case ReplayAlways:
+		return "Always"
+	case ReplayNever:
+		return "Never"
+	}
+	return "Ask"
+}
+
+// maxReplayName caps the name typed into the save prompt.
+const maxReplayName = 32
+
+// replayPrompt is the game over screen's "Save replay?" field.
+type replayPrompt struct {
+	log       inputLog
+	name      string
+	replacing string // a taken name Enter was pressed on; Enter again replaces it
+}
+
+func replaysDir() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "replays"), nil
+}
+
+// replayPath is where the replay called name is kept.
+func replayPath(name string) (string, error) {
+	dir, err := replaysDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+func replayExists(name string) bool {
+	path, err := replayPath(name)
+	return err == nil && saveExists(path)
+}
+
+// freeReplayName is name, or name with a number after it if that's taken.
+func freeReplayName(name string) string {
+	free := name
+	for i := 2; replayExists(free); i++ {
+		free = fmt.Sprintf("%s-%d", name, i)
+	}
+	return free
+}
+
+// autoReplayName names a replay after when and what was played.
+func autoReplayName(mode string) string {
+	return time.Now().Format("2006-01-02_150405") + "-" + strings.ReplaceAll(strings.ToLower(mode), " ", "-")
+}
+
+// finishRecording ends the run's input log: -record logs are always
+// written, others go by Settings.ReplaySave.
+func (g *Game) finishRecording() {
+	if g.rec.path != "" {
+		g.stopRecording()
+		return
+	}
+	switch g.settings.ReplaySave {
+	case ReplayAsk:
+		g.prompt = &replayPrompt{log: g.rec.log, name: autoReplayName(g.Mode().Name)}
+	case ReplayAlways:
+		saveReplay(g.rec.log, freeReplayName(autoReplayName(g.Mode().Name)))
+	}
+	g.rec = nil
+}
+
+// saveReplay writes l as name, replacing any replay already called that.
+func saveReplay(l inputLog, name string) {
+	path, err := replayPath(name)
+	if err != nil {
+		slog.Error("replay save failed", "err", err)
+		return
+	}
+	r := recorder{path: path, log: l}
+	if err := r.save(); err != nil {
+		slog.Error("replay save failed", "err", err)
+	}
+}
+
+// updateReplayPrompt edits the replay name: Enter or a tap saves, Esc
+// skips.
+func (g *Game) updateReplayPrompt() {
+	p := g.prompt
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if len(p.name) < maxReplayName && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			p.name += string(r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && p.name != "" {
+		p.name = p.name[:len(p.name)-1]
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) || len(justTouchIDs(nil)) > 0:
+		if p.name == "" {
+			p.name = autoReplayName(g.Mode().Name)
+		}
+		if replayExists(p.name) && p.replacing != p.name {
+			p.replacing = p.name
+			return
+		}
+		saveReplay(p.log, p.name)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+	default:
+		return
+	}
+	g.prompt = nil
+}
+
+func (g *Game) drawReplayPrompt(screen *ebiten.Image, y float32) {
+	pal := g.palette()
+	w := float32(screen.Bounds().Dx())
+	k := float32(g.settings.UIScale)
+	lines := []string{trf("Save replay? %s_", g.prompt.name), tr("Enter saves, Esc skips")}
+	if g.prompt.name != "" && g.prompt.name == g.prompt.replacing {
+		lines[1] = tr("That name is taken: Enter replaces it, Esc skips")
+	}
+	for i, s := range lines {
+		g.drawCentered(screen, s, w/2, y+float32(i)*16*k, pal.Text)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// tempConfig points the config directory at a fresh temporary one.
+func tempConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+}
+
+func TestReplayNames(t *testing.T) {
+	tempConfig(t)
+	l := inputLog{Mode: "Marathon", Inputs: []int{0}, Hashes: []string{"0"}}
+	saveReplay(l, "run")
+	if !replayExists("run") || replayExists("other") {
+		t.Fatal("replayExists doesn't match what was saved")
+	}
+	if got := freeReplayName("run"); got != "run-2" {
+		t.Errorf("freeReplayName = %q, want run-2", got)
+	}
+	saveReplay(l, "run-2")
+	if got := freeReplayName("run"); got != "run-3" {
+		t.Errorf("freeReplayName = %q, want run-3", got)
+	}
+
+	g := &Game{}
+	g.openReplays()
+	if !slices.Equal(g.replays.names, []string{"run-2", "run"}) {
+		t.Errorf("browser lists %q, want newest name first", g.replays.names)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// restartHoldFrames is how long the quick-restart key must be held, so a
+// stray press can't throw away a run.
+const restartHoldFrames = 30
+
+// restartKeys are the choices for Settings.RestartKey; "" turns it off.
+var restartKeys = []string{"R", "T", "F5", ""}
+
+// updateQuickRestart starts the mode over with a fresh seed once the
+// restart key has been held long enough. It reports whether it did.
+func (g *Game) updateQuickRestart() bool {
+	var k ebiten.Key
+	if g.settings.RestartKey == "" || g.prompt != nil || g.naming != nil || k.UnmarshalText([]byte(g.settings.RestartKey)) != nil || !ebiten.IsKeyPressed(k) {
+		g.restartHold = 0
+		return false
+	}
+	if g.restartHold < 0 {
+		// Still held from the last restart.
+		return false
+	}
+	g.restartHold++
+	if g.restartHold < restartHoldFrames {
+		return false
+	}
+	if g.rec != nil {
+		g.stopRecording()
+	}
+	g.Reset()
+	g.restartHold = -1
+	return true
+}
+
+// drawRestartHold fills a bar over the board while the restart key is held.
+func (g *Game) drawRestartHold(screen *ebiten.Image, l layout) {
+	pal := g.palette()
+	if g.restartHold <= 0 {
+		return
+	}
+	frac := float32(g.restartHold) / restartHoldFrames
+	vector.DrawFilledRect(screen, l.originX, l.originY-6, l.boardPxW*frac, 4, pal.Alert, false)
+}
+
+func cycleRestartKey(key string, dir int) string {
+	cur := 0
+	for i, k := range restartKeys {
+		if k == key {
+			cur = i
+		}
+	}
+	return restartKeys[wrap(cur+dir, len(restartKeys))]
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// Save files (settings, profiles, high scores, online settings and the
+// input logs of runs and replays) carry a "Version" field. Each kind keeps a list of migrations,
+// where migrations[i] upgrades a version i document to version i+1; the
+// current version is len(migrations). Files without a version are version 0.
+type saveMigration func(doc map[string]any) error
+
+// errSaveTooNew means a file was written by a newer build. Callers must not
+// overwrite such a file, or the newer build would lose its data.
+type errSaveTooNew struct {
+	path          string
+	version, have int
+}
+
+func (e errSaveTooNew) Error() string {
+	return fmt.Sprintf("%s is version %d, this build reads up to %d", e.path, e.version, e.have)
+}
+
+// loadSave decodes the file at path into out, migrating it forward first.
+// When a migration runs, the original is kept beside it as
+// <path>.v<N>.bak so a bad migration can't destroy anything.
+func loadSave(path string, out any, migrations []saveMigration) error {
+	return decodeSave(path, out, migrations, true)
+}
+
+// viewSave is loadSave for files that are only read, such as replays
+// being watched or verified. Nothing is written back over them, so no
+// backup is kept and the directory they're in is left as it was.
+func viewSave(path string, out any, migrations []saveMigration) error {
+	return decodeSave(path, out, migrations, false)
+}
+
+func decodeSave(path string, out any, migrations []saveMigration, backup bool) error {
+	b, err := readSave(path)
+	if err != nil {
+		return err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+	v := 0
+	if f, ok := doc["Version"].(float64); ok {
+		v = int(f)
+	}
+	if v > len(migrations) {
+		return errSaveTooNew{path, v, len(migrations)}
+	}
+	if v < len(migrations) {
+		kept := ""
+		if backup {
+			kept = fmt.Sprintf("%s.v%d.bak", path, v)
+			if err := writeSave(kept, b); err != nil {
+				return fmt.Errorf("backing up before migration: %w", err)
+			}
+		}
+		for i := v; i < len(migrations); i++ {
+			if err := migrations[i](doc); err != nil {
+				return fmt.Errorf("migrating to version %d: %w", i+1, err)
+			}
+		}
+		doc["Version"] = len(migrations)
+		slog.Info("save migrated", "path", path, "from", v, "to", len(migrations), "backup", kept)
+		if b, err = json.Marshal(doc); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(b, out)
+}
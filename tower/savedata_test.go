@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// testMigrations renames A to B, then doubles B.
+var testMigrations = []saveMigration{
+	func(doc map[string]any) error {
+		doc["B"] = doc["A"]
+		delete(doc, "A")
+		return nil
+	},
+	func(doc map[string]any) error {
+		f, ok := doc["B"].(float64)
+		if !ok {
+			return errors.New("B isn't a number")
+		}
+		doc["B"] = f * 2
+		return nil
+	},
+}
+
+type testDoc struct {
+	Version int
+	B       int
+}
+
+func writeTestSave(t *testing.T, doc string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := writeSave(path, []byte(doc)); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSaveMigrations(t *testing.T) {
+	for _, tc := range []struct {
+		doc    string
+		want   int
+		backup string // the backup expected beside the file, if any
+	}{
+		{`{"A":3}`, 6, ".v0.bak"},
+		{`{"Version":1,"B":3}`, 6, ".v1.bak"},
+		{`{"Version":2,"B":3}`, 3, ""},
+	} {
+		tempConfig(t)
+		path := writeTestSave(t, tc.doc)
+		var d testDoc
+		if err := loadSave(path, &d, testMigrations); err != nil {
+			t.Errorf("%s: %v", tc.doc, err)
+			continue
+		}
+		if d.Version != 2 || d.B != tc.want {
+			t.Errorf("%s loaded as %+v, want version 2 with B %d", tc.doc, d, tc.want)
+		}
+		for _, v := range []string{".v0.bak", ".v1.bak"} {
+			if saveExists(path+v) != (v == tc.backup) {
+				t.Errorf("%s: backup %s exists %v, want %v", tc.doc, v, saveExists(path+v), v == tc.backup)
+			}
+		}
+		if tc.backup == "" {
+			continue
+		}
+		b, err := readSave(path + tc.backup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tc.doc {
+			t.Errorf("backup holds %s, want the original %s", b, tc.doc)
+		}
+	}
+}
+
+func TestSaveMigrationFails(t *testing.T) {
+	tempConfig(t)
+	path := writeTestSave(t, `{"Version":1,"B":"three"}`)
+	var d testDoc
+	if err := loadSave(path, &d, testMigrations); err == nil {
+		t.Error("a failed migration loaded")
+	}
+	if !saveExists(path + ".v1.bak") {
+		t.Error("no backup was kept before the failed migration")
+	}
+}
+
+func TestSaveTooNew(t *testing.T) {
+	tempConfig(t)
+	path := writeTestSave(t, `{"Version":3,"B":3}`)
+	var d testDoc
+	err := loadSave(path, &d, testMigrations)
+	var tooNew errSaveTooNew
+	if !errors.As(err, &tooNew) || tooNew.version != 3 || tooNew.have != 2 {
+		t.Errorf("err = %v, want version 3 too new for 2", err)
+	}
+	if saveExists(path + ".v3.bak") {
+		t.Error("a file too new to read was backed up")
+	}
+}
+
+func TestViewSaveKeepsNoBackup(t *testing.T) {
+	tempConfig(t)
+	path := writeTestSave(t, `{"A":3}`)
+	var d testDoc
+	if err := viewSave(path, &d, testMigrations); err != nil {
+		t.Fatal(err)
+	}
+	if d.B != 6 {
+		t.Errorf("viewed as %+v, want it migrated to B 6", d)
+	}
+	if saveExists(path + ".v0.bak") {
+		t.Error("viewing a file backed it up")
+	}
+	b, err := readSave(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(b, &doc); err != nil || doc["Version"] != nil {
+		t.Errorf("viewing rewrote the file as %s", b)
+	}
+}
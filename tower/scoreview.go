@@ -0,0 +1,202 @@
+package main
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"tetris/leaderboard"
+)
+
+// scoreView is the high score screen, one mode's full leaderboard at a
+// time.
+type scoreView struct {
+	mode int      // index into modes
+	tab  scoreTab // always scoreLocal for modes that aren't online
+}
+
+// scoreTab picks one of an online mode's leaderboards.
+type scoreTab int
+
+const (
+	scoreLocal scoreTab = iota
+	scoreWorld
+	scoreFriends
+)
+
+var scoreTabNames = []string{"Local", "World", "Friends"}
+
+// board is the leaderboard the view's mode keeps: today's for the Daily
+// Challenge.
+func (v *scoreView) board() string {
+	if m := modes[v.mode]; m.Name != dailyName {
+		return m.Name
+	}
+	return dailyBoard(time.Now())
+}
+
+// openScoreView shows the leaderboard of the named mode.
+func (g *Game) openScoreView(mode string) {
+	v := &scoreView{}
+	for i, m := range modes {
+		if m.Name == mode {
+			v.mode = i
+		}
+	}
+	g.scoreView = v
+	g.setState(stateHighScores)
+}
+
+// updateScoreView pages through the modes with left/right, and an online
+// mode's local, world and friends rankings with up/down or Tab; Esc, Enter
+// or a tap goes back. On touch, a tap on the tabs switches instead.
+func (g *Game) updateScoreView() {
+	v := g.scoreView
+	tabs := 1
+	if onlineMode(modes[v.mode].Name) {
+		tabs = len(scoreTabNames)
+	}
+	k := float32(g.settings.UIScale)
+	tapped := false
+	for _, id := range justTouchIDs(nil) {
+		if _, y := ebiten.TouchPosition(id); float32(y) < scoreTabsY*k+menuRowH*k && tabs > 1 {
+			v.tab = scoreTab(wrap(int(v.tab)+1, tabs))
+			g.refreshScoreView()
+			return
+		}
+		tapped = true
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) ||
+		g.pad.justPressed(ebiten.StandardGamepadButtonLeftLeft):
+		v.mode = wrap(v.mode-1, len(modes))
+		g.refreshScoreView()
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) ||
+		g.pad.justPressed(ebiten.StandardGamepadButtonLeftRight):
+		v.mode = wrap(v.mode+1, len(modes))
+		g.refreshScoreView()
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) ||
+		g.pad.justPressed(ebiten.StandardGamepadButtonLeftTop):
+		v.tab = scoreTab(wrap(int(v.tab)-1, tabs))
+		g.refreshScoreView()
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) ||
+		inpututil.IsKeyJustPressed(ebiten.KeyTab) || g.pad.justPressed(ebiten.StandardGamepadButtonLeftBottom):
+		v.tab = scoreTab(wrap(int(v.tab)+1, tabs))
+		g.refreshScoreView()
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) ||
+		g.pad.justPressed(ebiten.StandardGamepadButtonRightRight) || tapped:
+		g.closeOverlay()
+	}
+}
+
+// refreshScoreView keeps the tab to the local list for modes that aren't
+// online, and fetches the rankings an online tab shows.
+func (g *Game) refreshScoreView() {
+	v := g.scoreView
+	if !onlineMode(modes[v.mode].Name) {
+		v.tab = scoreLocal
+	}
+	if v.tab != scoreLocal {
+		fetchOnline(v.board(), g.settings.Friends)
+	}
+}
+
+// scoreTabsY is the baseline of the tabs, before the UI scale.
+const scoreTabsY = 100
+
+func (g *Game) drawScoreView(screen *ebiten.Image) {
+	pal := g.palette()
+	w, h := float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy())
+	vector.DrawFilledRect(screen, 0, 0, w, h, alpha(pal.Shade, 220), false)
+	k := float32(g.settings.UIScale)
+	center := func(s string, y float32, c color.Color) {
+		g.drawCentered(screen, s, w/2, y, c)
+	}
+	v := g.scoreView
+	board := v.board()
+	y := 80 * k
+	center("< "+trf("%s High Scores", board)+" >", y, pal.Text)
+	online := onlineMode(modes[v.mode].Name)
+	if online {
+		for i, name := range scoreTabNames {
+			c := pal.Dim
+			if scoreTab(i) == v.tab {
+				c = pal.Accent
+			}
+			g.drawCentered(screen, tr(name), w/2+float32(i-1)*100*k, scoreTabsY*k, c)
+		}
+	}
+	y += 32 * k
+	if online {
+		y += 20 * k
+	}
+	foot := tr("* modifiers or assists")
+	if v.tab == scoreLocal {
+		list := scores.Boards[board]
+		if len(list) == 0 {
+			center(tr("No scores yet"), y, pal.Dim)
+		}
+		for i, e := range list {
+			s := trf("%2d. %-3s %7s %4d lines  L%-2d %s", i+1, e.Name, e.result(), e.Lines, e.Level, e.Date.Local().Format("2006-01-02"))
+			if len(e.Flags) > 0 {
+				s += " *"
+			}
+			center(s, y+float32(i)*18*k, pal.Text)
+		}
+	} else {
+		foot = g.drawRankings(screen, board, y)
+	}
+	hint := "Left/Right change mode, Esc goes back"
+	switch {
+	case touchScreen() && online:
+		hint = "Tap the tabs to switch, elsewhere to go back"
+	case touchScreen():
+		hint = "Tap to go back"
+	case online:
+		hint = "Left/Right mode, Up/Down tab, Esc back"
+	}
+	center(foot, y+float32(maxScores)*18*k+8*k, pal.Dim)
+	center(tr(hint), y+float32(maxScores+1)*18*k+8*k, pal.Dim)
+}
+
+// drawRankings lists the world or friends rankings of board from y down,
+// and returns the line to go under them: how old they are, or the
+// player's friend code.
+func (g *Game) drawRankings(screen *ebiten.Image, board string, y float32) string {
+	pal := g.palette()
+	w := float32(screen.Bounds().Dx())
+	k := float32(g.settings.UIScale)
+	r, ok, loading, stale := onlineRankings(board)
+	list := r.Global
+	if g.scoreView.tab == scoreFriends {
+		list = r.Friends
+	}
+	switch {
+	case !g.settings.OnlineScores || g.settings.LeaderboardEndpoint == "":
+		g.drawCentered(screen, tr("Turn on Online Scores in Settings"), w/2, y, pal.Dim)
+	case !ok && loading:
+		g.drawCentered(screen, tr("Loading..."), w/2, y, pal.Dim)
+	case !ok:
+		g.drawCentered(screen, tr("Couldn't reach the leaderboard"), w/2, y, pal.Dim)
+	case len(list) == 0:
+		g.drawCentered(screen, tr("No scores yet"), w/2, y, pal.Dim)
+	}
+	for i, e := range list[:min(len(list), maxScores)] {
+		s := trf("%2d. %-3s %7s %4d lines  %s", e.Rank, e.Name, scoreEntry{Score: e.Score, Frames: e.Frames}.result(), e.Lines, e.Date.Local().Format("2006-01-02"))
+		c := pal.Text
+		if e.Mine {
+			c = pal.Accent
+		}
+		g.drawCentered(screen, s, w/2, y+float32(i)*18*k, c)
+	}
+	switch {
+	case ok && stale:
+		return trf("Offline, as of %s", r.Fetched.Local().Format("2006-01-02 15:04"))
+	case g.scoreView.tab == scoreFriends && profile.OnlineToken != "":
+		return trf("Your friend code: %s", leaderboard.FriendCode(profile.OnlineToken))
+	}
+	return ""
+}
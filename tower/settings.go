@@ -0,0 +1,164 @@
+package main
+
+// Quality selects how much rendering work goes into effects.
+type Quality int
+
+const (
+	// QualityLow renders plain rectangles only.
+	QualityLow Quality = iota
+	// QualityHigh adds Kage shader effects.
+	QualityHigh
+)
+
+func (q Quality) String() string {
+	if q == QualityHigh {
+		return "High"
+	}
+	return "Low"
+}
+
+// Settings holds player-tunable options. They survive Reset.
+type Settings struct {
+	// Version is the settings file schema; see settingsMigrations.
+	Version int
+
+	Quality Quality
+	// Tweens animates the piece easing into moves and rotations. Off is
+	// better for competitive play, where the drawn piece must match logic.
+	Tweens bool
+	// ThemeName is the selected theme, by name so the choice survives mods
+	// being added or removed.
+	ThemeName string
+	// Fullscreen opens the game fullscreen; F11 toggles it.
+	Fullscreen bool
+	// ReduceMotion disables camera zoom and slow motion.
+	ReduceMotion bool
+	// Juice adds hard drop trails, a shake on Tetrises (unless
+	// ReduceMotion) and a border flash on level ups.
+	Juice bool
+	// Ghost shows where a hard drop would land the piece.
+	Ghost bool
+	// PieceMarks overlays a pattern or letter on each piece kind, so they
+	// can be told apart without colour.
+	PieceMarks PieceMarks
+	// StartLevel is the level new games begin at, 0..maxStartLevel.
+	StartLevel int
+	// ClassicGravity names the modes played with the NES gravity table.
+	ClassicGravity map[string]bool
+	// ShowStats puts the live stats panel in place of the controls help.
+	ShowStats bool
+	// Background is a file in backgroundDir; empty uses the theme's.
+	Background string
+	// UIScale sizes HUD text, panels, and touch buttons, independent of
+	// the board.
+	UIScale float64
+	// Language is the code of the language the game's text is shown in.
+	Language string
+
+	// DAS is the frames a direction must be held before it auto-repeats,
+	// and ARR the frames between repeats (0 moves straight to the wall).
+	DAS, ARR int
+	// SoftDropFrames is the frames per row while soft dropping; 0 drops
+	// straight to the floor without locking.
+	SoftDropFrames int
+	// ShiftPriority resolves left and right being held together.
+	ShiftPriority ShiftPriority
+	// Gestures maps playfield touch gestures to actions.
+	Gestures map[Gesture]Action
+
+	// TiltControls moves the piece by tilting the device, with any tap on
+	// the playfield rotating. Experimental; needs a TiltSource.
+	TiltControls    bool
+	TiltSensitivity int     // 1..10
+	TiltZero        float64 // calibrated neutral roll, radians
+
+	// LogToFile keeps a rotating log under the config directory, for
+	// attaching to bug reports.
+	LogToFile bool
+
+	// Telemetry opts in to sending anonymous aggregates (runs per mode,
+	// run length, crash-free sessions) to TelemetryEndpoint. Off by default,
+	// and nothing is sent without an endpoint.
+	Telemetry         bool
+	TelemetryEndpoint string
+
+	// OnlineScores submits Sprint, Ultra and Daily Challenge results to
+	// the leaderboard server at LeaderboardEndpoint and shows its
+	// rankings, the friends ones among the players whose friend codes are
+	// in Friends. Off by default, and nothing is sent without an endpoint.
+	OnlineScores        bool
+	LeaderboardEndpoint string
+	Friends             []string `json:",omitempty"`
+
+	// CheckUpdates looks for a newer release on launch.
+	CheckUpdates bool
+
+	// RestartKey, held for restartHoldFrames, starts the mode over; empty
+	// turns quick restart off.
+	RestartKey string
+	// ReplaySave keeps, drops, or asks about each finished run's replay.
+	ReplaySave ReplaySave
+	// RecordClips keeps the last ten seconds of each run, saved as a GIF
+	// in clips/ when the game ends.
+	RecordClips bool
+	// ShareStatus keeps status.json in the config directory up to date
+	// with the mode, score and level, for stream overlays, and shows them
+	// on Discord if DiscordAppID names a Discord application.
+	ShareStatus  bool
+	DiscordAppID string `json:",omitempty"`
+
+	// KeyPreset and TouchLayout can put every control under one hand.
+	KeyPreset   KeyPreset
+	TouchLayout TouchLayout
+	// MouseControl steers the piece with the mouse over the board.
+	MouseControl bool
+	// TouchButtons are the Custom touch layout's Left, Right, Rotate and
+	// Drop buttons, as fractions of the screen so they fit any size.
+	TouchButtons []touchPos `json:",omitempty"`
+	// TouchLeftHanded mirrors the Buttons and One Thumb layouts.
+	TouchLeftHanded bool
+	// TouchOpacity scales how strongly the touch buttons are drawn, from
+	// minTouchOpacity to 1.
+	TouchOpacity float64
+	// Keys is the Custom keyboard layout, filled in from the layout in use
+	// the first time a key is rebound.
+	Keys KeyMap `json:",omitempty"`
+	// GameSpeed below 1 slows all gameplay timing, flagging scores as
+	// assisted; see gameSpeeds.
+	GameSpeed float64
+
+	// SFXVolume and MusicVolume run from 0 to 1; Mute silences both.
+	SFXVolume, MusicVolume float64
+	Mute                   bool
+}
+
+const (
+	minUIScale  = 0.75
+	maxUIScale  = 2.0
+	uiScaleStep = 0.25
+)
+
+func DefaultSettings() Settings {
+	return Settings{
+		Quality: QualityHigh,
+		Tweens:  true,
+		Ghost:   true,
+		Juice:   true,
+		UIScale: 1,
+		DAS:     10,
+		ARR:     2,
+
+		SoftDropFrames: 1,
+
+		Gestures:     defaultGestureMap(),
+		TouchLayout:  TouchDrag,
+		TouchOpacity: 1,
+
+		TiltSensitivity: 5,
+		CheckUpdates:    true,
+		RestartKey:      "R",
+		GameSpeed:       1,
+		SFXVolume:       0.7,
+		MusicVolume:     0.5,
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	_ "embed"
+	"log/slog"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var (
+	//go:embed shaders/background.kage
+	backgroundKage []byte
+	//go:embed shaders/danger.kage
+	dangerKage []byte
+	//go:embed shaders/dissolve.kage
+	dissolveKage []byte
+)
+
+// shaderSet holds the compiled Kage programs. A nil field means the shader
+// failed to compile and the plain rendering path is used instead.
+type shaderSet struct {
+	background *ebiten.Shader
+	danger     *ebiten.Shader
+	dissolve   *ebiten.Shader
+}
+
+var (
+	shadersOnce sync.Once
+	shaders     shaderSet
+)
+
+func loadShaders() *shaderSet {
+	shadersOnce.Do(func() {
+		shaders.background = compileShader("background", backgroundKage)
+		shaders.danger = compileShader("danger", dangerKage)
+		shaders.dissolve = compileShader("dissolve", dissolveKage)
+	})
+	return &shaders
+}
+
+func compileShader(name string, src []byte) *ebiten.Shader {
+	s, err := ebiten.NewShader(src)
+	if err != nil {
+		slog.Warn("shader compile failed, using plain rendering", "shader", name, "err", err)
+		return nil
+	}
+	return s
+}
+
+const (
+	dangerRows = 6 // stack height (from the top) where the glow starts
+	dangerPad  = 12
+)
+
+// dangerLevel reports 0..1 for how close the stack is to the top.
+func (g *Game) dangerLevel() float32 {
+	for y := 0; y < dangerRows; y++ {
+		for x := 0; x < g.Width(); x++ {
+			if g.Cell(x, y) != 0 {
+				return float32(dangerRows-y) / dangerRows
+			}
+		}
+	}
+	return 0
+}
+
+func (g *Game) drawBackground(screen *ebiten.Image) {
+	th := g.theme()
+	if g.drawUserBackground(screen) {
+		return
+	}
+	sh := loadShaders()
+	if !th.Animated || g.settings.Quality < QualityHigh || sh.background == nil {
+		screen.Fill(th.Palette.Back)
+		return
+	}
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	op := &ebiten.DrawRectShaderOptions{}
+	op.Uniforms = map[string]any{
+		"Time": g.fx.time(),
+		"Size": []float32{float32(w), float32(h)},
+	}
+	screen.DrawRectShader(w, h, sh.background, op)
+}
+
+func (g *Game) drawDangerGlow(screen *ebiten.Image, originX, originY, boardPxW, boardPxH float32) {
+	sh := loadShaders()
+	level := g.dangerLevel()
+	if g.settings.Quality < QualityHigh || sh.danger == nil || level == 0 {
+		return
+	}
+	op := &ebiten.DrawRectShaderOptions{}
+	op.GeoM.Translate(float64(originX-dangerPad), float64(originY-dangerPad))
+	op.Uniforms = map[string]any{
+		"Board":     []float32{dangerPad, dangerPad, boardPxW, boardPxH},
+		"Pad":       float32(dangerPad),
+		"Intensity": level,
+		"Time":      g.fx.time(),
+	}
+	screen.DrawRectShader(int(boardPxW+2*dangerPad), int(boardPxH+2*dangerPad), sh.danger, op)
+}
+
+// drawDissolve burns the cleared rows away with the dissolve shader,
+// reporting false if it can't on this quality.
+func (g *Game) drawDissolve(screen *ebiten.Image, originX, originY, tile, progress float32) bool {
+	pal := g.palette()
+	sh := loadShaders()
+	if g.settings.Quality < QualityHigh || sh.dissolve == nil {
+		return false
+	}
+	size := int(tile - 2)
+	for _, r := range g.fx.cleared {
+		for x, k := range r.Cells {
+			if k == 0 {
+				continue
+			}
+			op := &ebiten.DrawRectShaderOptions{}
+			op.GeoM.Translate(float64(originX+float32(x)*tile+1), float64(originY+float32(r.Y)*tile+1))
+			op.ColorScale.ScaleWithColor(pal.Pieces[k-1])
+			op.Uniforms = map[string]any{"Progress": progress}
+			screen.DrawRectShader(size, size, sh.dissolve, op)
+		}
+	}
+	return true
+}
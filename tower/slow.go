@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// gameSpeeds are the Game Speed choices; below 1 is slow mode.
+var gameSpeeds = []float64{1, 0.75, 0.5}
+
+// assistedFlag marks leaderboard entries with any slow-mode play.
+const assistedFlag = "Assisted"
+
+func speedLabel(s float64) string {
+	return fmt.Sprintf("%d%%", int(s*100+0.5))
+}
+
+func (s Settings) speed() float64 {
+	if s.GameSpeed <= 0 || s.GameSpeed > 1 {
+		return 1
+	}
+	return s.GameSpeed
+}
+
+// handling is the settings the shifter runs on: slow mode stretches DAS
+// and ARR with everything else.
+func (g *Game) handling() Settings {
+	s := g.settings
+	if sp := s.speed(); sp < 1 {
+		s.DAS = int(math.Round(float64(s.DAS) / sp))
+		s.ARR = int(math.Round(float64(s.ARR) / sp))
+	}
+	return s
+}
+
+// slowStep decides whether the game steps this frame. Slow mode steps on
+// only a share of frames, so gravity, soft drop, spawn delay and every
+// mode's timers slow down together; presses from skipped frames are held
+// back and merged into ins for the next step, which takes the buttons down
+// as of the latest frame.
+func (g *Game) slowStep(ins []frameInput) bool {
+	sp := g.settings.speed()
+	if sp < 1 {
+		g.assisted = true
+	}
+	if len(g.heldBack) != len(ins) {
+		g.heldBack = make([]frameInput, len(ins))
+	}
+	for i, in := range ins {
+		h := &g.heldBack[i]
+		h.merge(in)
+		h.shift += in.shift
+		h.undo = h.undo || in.undo
+		h.softDrop = in.softDrop
+		h.heldCW, h.heldCCW, h.heldHold = in.heldCW, in.heldCCW, in.heldHold
+	}
+	g.slowAcc += sp
+	if g.slowAcc < 1 {
+		return false
+	}
+	g.slowAcc--
+	copy(ins, g.heldBack)
+	clear(g.heldBack)
+	return true
+}
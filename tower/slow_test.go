@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestHeldButtonsReachTheLog(t *testing.T) {
+	for _, speed := range gameSpeeds {
+		g := newGameSeeded(1, modes[0], Modifiers{})
+		g.settings.GameSpeed = speed
+		acts := make(chan []Action, 1)
+		g.sources = [][]InputSource{{remoteSource{acts}}}
+		// Hold the buttons until a step goes through.
+		for g.rec == nil || len(g.rec.log.Inputs) == 0 {
+			acts <- []Action{ActHeldRotateCW, ActHeldHold}
+			g.updatePlaying()
+		}
+		in := unpackInput(g.rec.log.Inputs[0])
+		if !in.heldCW || !in.heldHold || in.heldCCW {
+			t.Errorf("at %s, stepped with %+v, want rotate and hold down", speedLabel(speed), in)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package sound
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+const (
+	// baseTempo is the background track's beats per minute at level 0.
+	baseTempo     = 120
+	tempoPerLevel = 6
+	maxTempo      = 220
+)
+
+// melody is the looping background line, one entry per eighth note; 0
+// rests.
+var melody = []float64{
+	330, 0, 247, 262, 294, 0, 262, 247,
+	220, 0, 220, 262, 330, 0, 294, 262,
+	247, 0, 247, 262, 294, 0, 330, 0,
+	262, 0, 220, 0, 220, 0, 0, 0,
+}
+
+// bass sounds one root per bar of eight eighths.
+var bass = []float64{110, 104, 110, 82}
+
+// song streams the background track forever. The audio goroutine reads it
+// while the game changes its tempo, hence the atomic.
+type song struct {
+	tempo atomic.Uint64 // math.Float64bits of beats per minute
+	pos   float64       // in eighth notes
+	phase [2]float64    // per voice
+	frame [4]byte       // a sample frame left over from the last Read
+	spare int
+}
+
+func newSong() *song {
+	s := &song{}
+	s.setTempo(baseTempo)
+	return s
+}
+
+func (s *song) setTempo(bpm float64) {
+	s.tempo.Store(math.Float64bits(bpm))
+}
+
+// Read fills b with 16-bit stereo samples.
+func (s *song) Read(b []byte) (int, error) {
+	n := 0
+	for n < len(b) && s.spare > 0 {
+		b[n] = s.frame[4-s.spare]
+		n++
+		s.spare--
+	}
+	step := math.Float64frombits(s.tempo.Load()) / 60 * 2 / sampleRate
+	for n < len(b) {
+		v := int16(s.sample() * math.MaxInt16)
+		s.frame = [4]byte{byte(v), byte(v >> 8), byte(v), byte(v >> 8)}
+		s.pos = math.Mod(s.pos+step, float64(len(melody)))
+		c := copy(b[n:], s.frame[:])
+		n += c
+		s.spare = 4 - c
+	}
+	return n, nil
+}
+
+// sample is the next mono sample: a soft square lead over a triangle bass.
+func (s *song) sample() float64 {
+	i := int(s.pos)
+	frac := s.pos - float64(i)
+	var out float64
+	if f := melody[i]; f != 0 {
+		s.phase[0] = math.Mod(s.phase[0]+f/sampleRate, 1)
+		env := 1 - frac*0.7
+		out += 0.12 * env * square(s.phase[0])
+	}
+	f := bass[i/8%len(bass)]
+	s.phase[1] = math.Mod(s.phase[1]+f/sampleRate, 1)
+	out += 0.18 * triangle(s.phase[1])
+	return out
+}
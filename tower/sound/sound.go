@@ -0,0 +1,171 @@
+// Package sound plays the game's sound effects and music. Everything is
+// synthesized when the player is made, so the build carries no audio files.
+package sound
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+const sampleRate = 44100
+
+// Effect is a sound effect.
+type Effect int
+
+const (
+	Move Effect = iota
+	Rotate
+	Lock
+	LineClear
+	Tetris
+	LevelUp
+	GameOver
+	numEffects
+)
+
+// Player owns the audio context. A nil *Player is silent, for headless
+// runs.
+type Player struct {
+	ctx     *audio.Context
+	effects [numEffects][]byte
+	song    *song
+	music   *audio.Player
+
+	sfxVolume, musicVolume float64
+	muted                  bool
+}
+
+// New starts the audio context and renders every effect. Only one may be
+// made per process.
+func New() *Player {
+	p := &Player{ctx: audio.NewContext(sampleRate), song: newSong(), sfxVolume: 1, musicVolume: 1}
+	for e := range p.effects {
+		p.effects[e] = pcm(render(Effect(e)))
+	}
+	music, err := p.ctx.NewPlayer(p.song)
+	if err != nil {
+		slog.Error("music player failed", "err", err)
+	} else {
+		p.music = music
+	}
+	return p
+}
+
+// SetVolumes sets the effect and music volumes, 0 to 1.
+func (p *Player) SetVolumes(sfx, music float64, muted bool) {
+	if p == nil {
+		return
+	}
+	p.sfxVolume, p.musicVolume, p.muted = sfx, music, muted
+	if p.music != nil {
+		p.music.SetVolume(p.volume(music))
+	}
+}
+
+func (p *Player) volume(v float64) float64 {
+	if p.muted {
+		return 0
+	}
+	return v
+}
+
+// Play starts e over anything already playing.
+func (p *Player) Play(e Effect) {
+	if p == nil || p.muted || p.sfxVolume == 0 {
+		return
+	}
+	pl := p.ctx.NewPlayerFromBytes(p.effects[e])
+	pl.SetVolume(p.sfxVolume)
+	pl.Play()
+}
+
+// SetMusic plays or pauses the background track, picking up where it
+// stopped.
+func (p *Player) SetMusic(on bool) {
+	if p == nil || p.music == nil || on == p.music.IsPlaying() {
+		return
+	}
+	if on {
+		p.music.Play()
+	} else {
+		p.music.Pause()
+	}
+}
+
+// SetLevel speeds the background track up for level.
+func (p *Player) SetLevel(level int) {
+	if p == nil {
+		return
+	}
+	p.song.setTempo(min(baseTempo+tempoPerLevel*float64(level), maxTempo))
+}
+
+// note is a tone of freq Hz, or a rest at 0, lasting dur seconds.
+type note struct {
+	freq, dur float64
+}
+
+// render synthesizes e as mono samples in [-1, 1].
+func render(e Effect) []float64 {
+	switch e {
+	case Move:
+		return tone([]note{{660, 0.03}}, square, 0.25)
+	case Rotate:
+		return tone([]note{{880, 0.04}}, square, 0.25)
+	case Lock:
+		return tone([]note{{110, 0.08}}, triangle, 0.8)
+	case LineClear:
+		return tone([]note{{523, 0.06}, {659, 0.06}, {784, 0.1}}, square, 0.35)
+	case Tetris:
+		return tone([]note{{523, 0.06}, {659, 0.06}, {784, 0.06}, {1047, 0.25}}, square, 0.4)
+	case LevelUp:
+		return tone([]note{{440, 0.08}, {554, 0.08}, {659, 0.08}, {880, 0.2}}, triangle, 0.6)
+	case GameOver:
+		return tone([]note{{392, 0.15}, {330, 0.15}, {262, 0.15}, {196, 0.4}}, triangle, 0.6)
+	}
+	return nil
+}
+
+type wave func(phase float64) float64
+
+func square(phase float64) float64 {
+	if phase < 0.5 {
+		return 1
+	}
+	return -1
+}
+
+func triangle(phase float64) float64 {
+	return 4*math.Abs(phase-0.5) - 1
+}
+
+// tone plays notes back to back at gain, each with a short attack and a
+// linear decay so they don't click.
+func tone(notes []note, w wave, gain float64) []float64 {
+	var out []float64
+	for _, n := range notes {
+		count := int(n.dur * sampleRate)
+		for i := range count {
+			env := min(float64(i)/(0.004*sampleRate), 1) * (1 - float64(i)/float64(count))
+			phase := math.Mod(n.freq*float64(i)/sampleRate, 1)
+			out = append(out, gain*env*w(phase))
+		}
+	}
+	return out
+}
+
+// pcm converts mono samples to the 16-bit little-endian stereo the audio
+// context plays.
+func pcm(samples []float64) []byte {
+	b := make([]byte, 4*len(samples))
+	for i, s := range samples {
+		v := int16(max(-1, min(1, s)) * math.MaxInt16)
+		for c := range 2 {
+			b[4*i+2*c] = byte(v)
+			b[4*i+2*c+1] = byte(v >> 8)
+		}
+	}
+	return b
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// InputSource is somewhere a player's actions come from: a device, the
+// CPU, a replay or a player across the network. Poll is called once for
+// each frame the player plays, and a player may have several sources at
+// once, such as the keyboard and a gamepad.
+type InputSource interface {
+	Poll() []Action
+}
+
+// keyboardSource is a layout of keys on the keyboard.
+type keyboardSource struct {
+	keys func() KeyMap
+}
+
+func (k keyboardSource) Poll() []Action {
+	in, left, right := k.keys().read()
+	return appendActions(nil, in, left, right)
+}
+
+// padSource is the gamepad in use.
+type padSource struct {
+	pad *padReader
+}
+
+func (p padSource) Poll() []Action {
+	in, left, right := p.pad.read()
+	return appendActions(nil, in, left, right)
+}
+
+// pauseSource is the solo layout's pause key and the gamepad's Start, for
+// players whose other sources can't pause.
+type pauseSource struct {
+	g *Game
+}
+
+func (p pauseSource) Poll() []Action {
+	if p.g.pausePressed() || p.g.pad.pausePressed() {
+		return []Action{ActPause}
+	}
+	return nil
+}
+
+// touchSource is the touch controls: the button bar or drag pad, the
+// playfield's gestures and tilt. A tap on the settings button opens the
+// menu instead.
+type touchSource struct {
+	g          *Game
+	just, down []ebiten.TouchID // reused each frame
+}
+
+// touchIDs is the touches down now, and justTouchIDs those that began this
+// frame, written over dst. Callers polling every frame keep dst to save
+// allocating; others can pass nil.
+func touchIDs(dst []ebiten.TouchID) []ebiten.TouchID {
+	return ebiten.AppendTouchIDs(dst[:0])
+}
+
+func justTouchIDs(dst []ebiten.TouchID) []ebiten.TouchID {
+	return inpututil.AppendJustPressedTouchIDs(dst[:0])
+}
+
+func (t *touchSource) Poll() []Action {
+	g := t.g
+	if !touchScreen() {
+		return nil
+	}
+	// Touch positions come in the screen's coordinates, the same the
+	// buttons are laid out in.
+	w, h := g.screenSize()
+	ctrlH := int(g.touchBarHeight())
+	btnY := int(h) - ctrlH
+	buttons := g.touchRects(w, h)
+
+	t.just, t.down = justTouchIDs(t.just), touchIDs(t.down)
+	justIDs, downIDs := t.just, t.down
+
+	var acts []Action
+	l := g.layout()
+	var field []ebiten.TouchID // touches that start on the playfield
+	for _, id := range justIDs {
+		x, y := ebiten.TouchPosition(id)
+		switch {
+		case l.settingsButton().contains(x, y):
+			g.openMenu(settingsPage)
+			return nil
+		case l.holdBox().contains(x, y):
+			acts = append(acts, ActHold)
+		case y < btnY && !onButton(buttons, x, y):
+			field = append(field, id)
+		}
+	}
+	tilt := frameInput{shift: g.tilt.update(g.settings)}
+	if g.settings.TouchLayout == TouchDrag {
+		drag := g.drag.update(field, l.tile)
+		if g.orientation().reversed() {
+			drag.shift = -drag.shift // dragging follows the board as drawn
+		}
+		acts = appendActions(acts, drag, false, false)
+		return appendActions(acts, tilt, false, false)
+	}
+	if ge, ok := g.gestures.update(field, int(w)); ok {
+		if g.settings.TiltControls && (ge == TapLeft || ge == TapRight) {
+			acts = append(acts, ActRotateCW)
+		} else {
+			acts = append(acts, g.settings.Gestures[ge])
+		}
+	}
+	acts = appendActions(acts, tilt, false, false)
+
+	justPressIn := func(b int) bool {
+		for _, id := range justIDs {
+			if buttons[b].contains(ebiten.TouchPosition(id)) {
+				return true
+			}
+		}
+		return false
+	}
+	pressIn := func(b int) bool {
+		for _, id := range downIDs {
+			if buttons[b].contains(ebiten.TouchPosition(id)) {
+				return true
+			}
+		}
+		return false
+	}
+	left, right := pressIn(btnLeft), pressIn(btnRight)
+	return appendActions(acts, frameInput{
+		rotCW:    justPressIn(btnRotate),
+		heldCW:   pressIn(btnRotate),
+		hardDrop: justPressIn(btnDrop),
+		// Soft drop while a move button is held
+		softDrop: left || right,
+	}, left, right)
+}
+
+// onButton reports whether (x, y) is on one of the buttons.
+func onButton(buttons [4]rect, x, y int) bool {
+	for _, b := range buttons {
+		if b.contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// cpuSource is the CPU playing g.
+type cpuSource struct {
+	g *Game
+	d *botDriver
+}
+
+func (c cpuSource) Poll() []Action {
+	return appendActions(nil, c.d.input(c.g), false, false)
+}
+
+// replaySource plays back one player's recorded inputs, a frame a Poll.
+type replaySource struct {
+	log    *inputLog
+	player int
+	frame  int
+}
+
+func (r *replaySource) Poll() []Action {
+	ins := r.log.Inputs
+	if r.player == 1 {
+		ins = r.log.Inputs2
+	}
+	if r.frame >= len(ins) {
+		return nil
+	}
+	r.frame++
+	return appendActions(nil, unpackInput(ins[r.frame-1]), false, false)
+}
+
+// replaySources play back each player in l.
+func replaySources(l *inputLog) [][]InputSource {
+	srcs := [][]InputSource{{&replaySource{log: l}}}
+	if len(l.Inputs2) > 0 {
+		srcs = append(srcs, []InputSource{&replaySource{log: l, player: 1}})
+	}
+	return srcs
+}
+
+// remoteSource is a player across the network: the connection sends each
+// frame's actions down in, and a frame whose actions haven't arrived yet
+// plays as nothing.
+type remoteSource struct {
+	in <-chan []Action
+}
+
+func (r remoteSource) Poll() []Action {
+	select {
+	case acts := <-r.in:
+		return acts
+	default:
+		return nil
+	}
+}
+
+// playerSources are where each player's input comes from in the game as it
+// stands: the CPU, a half of the keyboard each in co-op and versus, or
+// otherwise every device the player might pick up. A player facing the
+// CPU in versus has every device too.
+func (g *Game) playerSources() [][]InputSource {
+	one := []InputSource{
+		keyboardSource{func() KeyMap { return g.keyPreset().keys }},
+		padSource{&g.pad},
+		&touchSource{g: g},
+		&mouseSource{g: g},
+	}
+	if g.cpu != nil {
+		one = []InputSource{cpuSource{g, g.cpu}, pauseSource{g}}
+	}
+	switch {
+	case g.rival != nil && g.rivalCPU != nil:
+		return [][]InputSource{one, {cpuSource{g.rival, g.rivalCPU}}}
+	case g.cpu != nil:
+		return [][]InputSource{one}
+	case g.Mode().Coop || g.Mode().Versus:
+		return [][]InputSource{
+			{keyboardSource{func() KeyMap { return coopKeys[0] }}, pauseSource{g}},
+			{keyboardSource{func() KeyMap { return coopKeys[1] }}},
+		}
+	}
+	return [][]InputSource{one}
+}
+
+// pollInputs polls every player's sources for the frame's input, running
+// their held directions through the player's shifter.
+func (g *Game) pollInputs() []frameInput {
+	ins := make([]frameInput, len(g.sources))
+	for i, srcs := range g.sources {
+		var left, right bool
+		for _, src := range srcs {
+			for _, a := range src.Poll() {
+				switch a {
+				case ActHeldLeft:
+					left = true
+				case ActHeldRight:
+					right = true
+				default:
+					a.apply(&ins[i])
+				}
+			}
+		}
+		ins[i].shift += g.shifters[i].update(left, right, g.handling())
+	}
+	return ins
+}
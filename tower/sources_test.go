@@ -0,0 +1,51 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReplaySourcePlaysBackTheLog(t *testing.T) {
+	ins := []frameInput{
+		{shift: -1, rotCW: true},
+		{shift: 3, softDrop: true, hold: true},
+		{shift: -40, hardDrop: true},
+		{rotCCW: true, undo: true},
+		{},
+	}
+	l := &inputLog{}
+	for _, in := range ins {
+		l.Inputs = append(l.Inputs, in.pack())
+		l.Inputs2 = append(l.Inputs2, in.pack())
+	}
+	g := newGameSeeded(1, modeByName("Co-op"), Modifiers{})
+	g.sources = replaySources(l)
+	for i, want := range ins {
+		got := g.pollInputs()
+		if len(got) != 2 || got[0] != want || got[1] != want {
+			t.Fatalf("frame %d: polled %+v, want %+v for both players", i, got, want)
+		}
+	}
+	if got := g.pollInputs(); got[0] != (frameInput{}) {
+		t.Errorf("past the end of the log: %+v, want nothing", got[0])
+	}
+}
+
+func TestHeldDirectionsGoThroughTheShifter(t *testing.T) {
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.settings.DAS, g.settings.ARR = 3, 1
+	acts := make(chan []Action, 8)
+	g.sources = [][]InputSource{{remoteSource{acts}}}
+	var shifts []int
+	for range 5 {
+		acts <- []Action{ActHeldRight}
+		shifts = append(shifts, g.pollInputs()[0].shift)
+	}
+	if want := []int{1, 0, 0, 1, 1}; !slices.Equal(shifts, want) {
+		t.Errorf("holding right shifted %v, want %v", shifts, want)
+	}
+	// Nothing has arrived, so right is let go.
+	if in := g.pollInputs()[0]; in.shift != 0 {
+		t.Errorf("with no actions, shifted %d", in.shift)
+	}
+}
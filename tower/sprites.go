@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"image"
+	"image/color"
+	"image/png"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// blocksPNG is the block atlas: a row of white tiles, atlasTileSize
+// square, in blockTile order. Drawing tints them to the cell's colour.
+//
+//go:embed sprites/blocks.png
+var blocksPNG []byte
+
+const atlasTileSize = 16
+
+// blockTile is a tile in the block atlas.
+type blockTile int
+
+const (
+	tileFlat blockTile = iota
+	tileBeveled
+	tileGhost
+	tileGarbage
+	numTiles
+)
+
+// tile is the atlas tile a style draws pieces with.
+func (s BlockStyle) tile() blockTile {
+	if s == BlockBeveled {
+		return tileBeveled
+	}
+	return tileFlat
+}
+
+// blockAtlas cuts the atlas into its tiles. They all share one source
+// image, so Ebitengine batches a board's worth of cells into a draw call.
+var blockAtlas = sync.OnceValue(func() [numTiles]*ebiten.Image {
+	img, err := png.Decode(bytes.NewReader(blocksPNG))
+	if err != nil {
+		panic(err) // embedded, so only a bad build gets here
+	}
+	src := ebiten.NewImageFromImage(img)
+	var tiles [numTiles]*ebiten.Image
+	for i := range tiles {
+		r := image.Rect(i*atlasTileSize, 0, (i+1)*atlasTileSize, atlasTileSize)
+		tiles[i] = src.SubImage(r).(*ebiten.Image)
+	}
+	return tiles
+})
+
+// tileOp is drawTile's options, reused from cell to cell since a board
+// draws hundreds of them a frame.
+var tileOp ebiten.DrawImageOptions
+
+// drawTile draws atlas tile t tinted c into the size-pixel cell at
+// (px, py), leaving a pixel's gap around it.
+func drawTile(screen *ebiten.Image, px, py, size float32, c color.RGBA, t blockTile) {
+	op := &tileOp
+	op.GeoM.Reset()
+	op.ColorScale.Reset()
+	s := float64(size-2) / atlasTileSize
+	op.GeoM.Scale(s, s)
+	op.GeoM.Translate(float64(px+1), float64(py+1))
+	op.ColorScale.ScaleWithColor(color.RGBA{c.R, c.G, c.B, 255})
+	op.ColorScale.ScaleAlpha(float32(c.A) / 255)
+	screen.DrawImage(blockAtlas()[t], op)
+}
+
+type gridKey struct {
+	theme         string
+	tile          float32
+	width, height int
+}
+
+// drawGrid draws the board's frame and empty cells with the top-left cell
+// at (originX, originY). They only change with the theme and layout, so
+// they are rendered once into fx.grid and drawn from there.
+func (g *Game) drawGrid(screen *ebiten.Image, originX, originY, tile float32, th Theme) {
+	k := gridKey{th.Name, tile, g.Width(), g.Height()}
+	if g.fx.grid == nil || g.fx.gridKey != k {
+		g.fx.grid, g.fx.gridKey = renderGrid(th, tile, g.Width(), g.Height()), k
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(originX-2), float64(originY-2))
+	screen.DrawImage(g.fx.grid, op)
+}
+
+func renderGrid(th Theme, tile float32, width, height int) *ebiten.Image {
+	pal := th.Palette
+	w, h := tile*float32(width), tile*float32(height)
+	img := ebiten.NewImage(int(w+4+0.5), int(h+4+0.5))
+	img.Fill(pal.Frame)
+	switch th.Grid {
+	case GridCells:
+		for y := range height {
+			for x := range width {
+				drawTile(img, 2+float32(x)*tile, 2+float32(y)*tile, tile, pal.Cell, tileFlat)
+			}
+		}
+	case GridLines:
+		vector.DrawFilledRect(img, 2, 2, w, h, pal.Cell, false)
+		for x := 1; x < width; x++ {
+			vector.StrokeLine(img, 2+float32(x)*tile, 2, 2+float32(x)*tile, 2+h, 1, pal.Frame, false)
+		}
+		for y := 1; y < height; y++ {
+			vector.StrokeLine(img, 2, 2+float32(y)*tile, 2+w, 2+float32(y)*tile, 1, pal.Frame, false)
+		}
+	case GridNone:
+		vector.DrawFilledRect(img, 2, 2, w, h, pal.Cell, false)
+	}
+	return img
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestBlockAtlas(t *testing.T) {
+	img, err := png.Decode(bytes.NewReader(blocksPNG))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != int(numTiles)*atlasTileSize || b.Dy() != atlasTileSize {
+		t.Fatalf("atlas is %dx%d, want %d tiles of %d", b.Dx(), b.Dy(), numTiles, atlasTileSize)
+	}
+	// The ghost's face lets the board show through; the other tiles are
+	// solid.
+	mid := atlasTileSize / 2
+	for i := range int(numTiles) {
+		_, _, _, a := img.At(i*atlasTileSize+mid, mid).RGBA()
+		if ghost := blockTile(i) == tileGhost; ghost != (a < 0xffff) {
+			t.Errorf("tile %d: alpha %#x mid-face", i, a)
+		}
+	}
+}
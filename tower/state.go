@@ -0,0 +1,55 @@
+package main
+
+import "log/slog"
+
+// appState is the screen the game is on.
+type appState int
+
+const (
+	stateTitle appState = iota
+	statePlaying
+	statePaused
+	stateGameOver
+	stateReplays // the saved replay list
+	stateReplay  // watching one
+	stateEditor  // the board editor
+	// stateSettings, stateHighScores and stateSummary open over another state and go
+	// back to it when closed.
+	stateSettings
+	stateHighScores
+	stateSummary // the finished game's stats
+)
+
+func (s appState) String() string {
+	return [...]string{"title", "playing", "paused", "game over", "replays", "replay", "editor", "settings", "high scores", "summary"}[s]
+}
+
+func (s appState) overlay() bool {
+	return s == stateSettings || s == stateHighScores || s == stateSummary
+}
+
+// setState moves to s, remembering what an overlay opened over.
+func (g *Game) setState(s appState) {
+	if s == g.state {
+		return
+	}
+	if s.overlay() && !g.state.overlay() {
+		g.under = g.state
+	}
+	slog.Debug("state changed", "from", g.state, "to", s)
+	g.state = s
+	g.publishStatus()
+}
+
+// closeOverlay goes back to the state under an overlay.
+func (g *Game) closeOverlay() {
+	g.setState(g.under)
+}
+
+// base is the state being shown under any overlay.
+func (g *Game) base() appState {
+	if g.state.overlay() {
+		return g.under
+	}
+	return g.state
+}
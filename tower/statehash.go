@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// packShift is where pack puts the shift, above the flag bits.
+const packShift = 10
+
+// pack encodes f as one integer for input logs: shift in the high bits,
+// one flag bit per press below.
+func (f frameInput) pack() int {
+	v := f.shift << packShift
+	for i, b := range []bool{f.rotCW, f.rotCCW, f.hardDrop, f.hold, f.softDrop, f.pause, f.undo, f.heldCW, f.heldCCW, f.heldHold} {
+		v |= b2i(b) << i
+	}
+	return v
+}
+
+func unpackInput(v int) frameInput {
+	return frameInput{
+		shift:    v >> packShift,
+		rotCW:    v&1 != 0,
+		rotCCW:   v&2 != 0,
+		hardDrop: v&4 != 0,
+		hold:     v&8 != 0,
+		softDrop: v&16 != 0,
+		pause:    v&32 != 0,
+		undo:     v&64 != 0,
+		heldCW:   v&128 != 0,
+		heldCCW:  v&256 != 0,
+		heldHold: v&512 != 0,
+	}
+}
+
+// inputLog is a recorded run: the mode, seed and settings it started from,
+// the input fed to each step, and the state hash after it.
+type inputLog struct {
+	Version   int
+	Mode      string
+	Modifiers Modifiers
+	Seed      int64
+	Settings  Settings
+	Inputs    []int
+	Inputs2   []int    `json:",omitempty"` // the co-op partner's
+	Hashes    []string // hex, since JSON numbers can't hold a uint64
+	// Assisted is set once any of the run was played in slow mode, so a
+	// resumed run keeps its leaderboard flag.
+	Assisted bool `json:",omitempty"`
+	// CPU is set once the CPU played any of the run.
+	CPU bool `json:",omitempty"`
+}
+
+// step advances g by recorded frame i.
+func (l *inputLog) step(g *Game, i int) {
+	ins := []frameInput{unpackInput(l.Inputs[i])}
+	if i < len(l.Inputs2) {
+		ins = append(ins, unpackInput(l.Inputs2[i]))
+	}
+	g.stepPlayers(ins...)
+}
+
+// newGame starts the game the log was recorded from.
+func (l *inputLog) newGame() *Game {
+	g := newGameSeeded(l.Seed, modeByName(l.Mode), l.Modifiers)
+	g.settings, g.assisted, g.cpuPlayed = l.Settings, l.Assisted, l.CPU
+	g.SoftDropFrames = l.Settings.SoftDropFrames
+	g.offline = true
+	return g
+}
+
+// inputLogMigrations upgrade older input logs; see loadSave.
+var inputLogMigrations = []saveMigration{
+	// 0 -> 1: a seventh flag bit (undo) moved the shift up by one.
+	func(m map[string]any) error {
+		for _, key := range []string{"Inputs", "Inputs2"} {
+			ins, _ := m[key].([]any)
+			for i, v := range ins {
+				n, ok := v.(float64)
+				if !ok {
+					return fmt.Errorf("%s[%d]: not a number", key, i)
+				}
+				old := int(n)
+				ins[i] = old>>6<<7 | old&63
+			}
+		}
+		return nil
+	},
+	// 1 -> 2: three held-button bits moved the shift up by three.
+	func(m map[string]any) error {
+		for _, key := range []string{"Inputs", "Inputs2"} {
+			ins, _ := m[key].([]any)
+			for i, v := range ins {
+				n, ok := v.(float64)
+				if !ok {
+					return fmt.Errorf("%s[%d]: not a number", key, i)
+				}
+				old := int(n)
+				ins[i] = old>>7<<packShift | old&127
+			}
+		}
+		return nil
+	},
+}
+
+// recorder appends every stepped frame of one run to an input log.
+type recorder struct {
+	path string
+	log  inputLog
+}
+
+// newRecorder starts logging g's run. The engine takes its soft drop speed
+// from the settings now and keeps it for the run, since the log only
+// records the settings it started with.
+func newRecorder(path string, g *Game) *recorder {
+	g.SoftDropFrames = g.settings.SoftDropFrames
+	return &recorder{path: path, log: inputLog{
+		Version:   len(inputLogMigrations),
+		Mode:      g.Mode().Name,
+		Modifiers: g.modifiers,
+		Seed:      g.seed,
+		Settings:  g.settings,
+	}}
+}
+
+func (r *recorder) frame(hash uint64, ins ...frameInput) {
+	r.log.Inputs = append(r.log.Inputs, ins[0].pack())
+	if len(ins) > 1 {
+		r.log.Inputs2 = append(r.log.Inputs2, ins[1].pack())
+	}
+	r.log.Hashes = append(r.log.Hashes, strconv.FormatUint(hash, 16))
+}
+
+func (r *recorder) save() error {
+	b, err := json.Marshal(r.log)
+	if err != nil {
+		return err
+	}
+	if err := writeSave(r.path, b); err != nil {
+		return err
+	}
+	slog.Info("input log saved", "path", r.path, "frames", len(r.log.Inputs))
+	return nil
+}
+
+// verifyInputLog replays a recorded log headlessly and reports the first
+// frame whose state hash differs from the recording.
+func verifyInputLog(path string) error {
+	var l inputLog
+	if err := viewSave(path, &l, inputLogMigrations); err != nil {
+		return err
+	}
+	if len(l.Inputs) != len(l.Hashes) {
+		return errors.New("inputs and hashes differ in length")
+	}
+	g := l.newGame()
+	for i := range l.Inputs {
+		l.step(g, i)
+		if got := strconv.FormatUint(g.Hash(), 16); got != l.Hashes[i] {
+			return fmt.Errorf("frame %d: state hash %s, recorded %s", i, got, l.Hashes[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSoftDropChangeMidRunReplays(t *testing.T) {
+	tempConfig(t)
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.settings.SoftDropFrames = 3
+	path := filepath.Join(t.TempDir(), "run.json")
+	g.rec = newRecorder(path, g)
+	down := frameInput{softDrop: true}
+	for i := range 120 {
+		if i == 40 {
+			// Retuned from the pause menu partway through.
+			g.settings.SoftDropFrames = 0
+		}
+		g.stepPlayers(down)
+		g.rec.frame(g.Hash(), down)
+	}
+	if g.SoftDropFrames != 3 {
+		t.Errorf("the engine soft drops at %d frames/row, want the run's 3", g.SoftDropFrames)
+	}
+	if err := g.rec.save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyInputLog(path); err != nil {
+		t.Errorf("the run doesn't verify: %v", err)
+	}
+}
+
+func TestPackHeldButtons(t *testing.T) {
+	f := frameInput{shift: -1, rotCW: true, heldCCW: true, heldHold: true}
+	if got := unpackInput(f.pack()); got != f {
+		t.Errorf("round trip gave %+v, want %+v", got, f)
+	}
+	// A version 1 log: shift 1 over seven flag bits, with hard drop.
+	m := map[string]any{"Inputs": []any{float64(1<<7 | 4)}}
+	if err := inputLogMigrations[1](m); err != nil {
+		t.Fatal(err)
+	}
+	got := unpackInput(int(m["Inputs"].([]any)[0].(int)))
+	if want := (frameInput{shift: 1, hardDrop: true}); got != want {
+		t.Errorf("migrated to %+v, want %+v", got, want)
+	}
+}
+
+func TestVerifyingLeavesOldLogsAlone(t *testing.T) {
+	tempConfig(t)
+	path := filepath.Join(t.TempDir(), "old.json")
+	if err := writeSave(path, []byte(`{"Version":1,"Mode":"Marathon","Seed":1,"Inputs":[0],"Hashes":["0"]}`)); err != nil {
+		t.Fatal(err)
+	}
+	verifyInputLog(path)
+	if saveExists(path + ".v1.bak") {
+		t.Error("verifying an old log backed it up beside itself")
+	}
+}
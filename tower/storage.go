@@ -0,0 +1,58 @@
+//go:build !js
+
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// configDir is where settings and other player data live.
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tower"), nil
+}
+
+// readSave and writeSave store the save files (settings, profile, high
+// scores, the autosave and replays). Browser builds keep them in
+// localStorage instead.
+func readSave(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func writeSave(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+func removeSave(path string) error {
+	return os.Remove(path)
+}
+
+// saveExists reports whether a save file is at path.
+func saveExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// listSaves names the save files in dir. A missing dir has none.
+func listSaves(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, err
+}
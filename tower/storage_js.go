@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+	"strings"
+	"syscall/js"
+)
+
+// configDir names the localStorage keys; a browser has no config directory.
+func configDir() (string, error) {
+	return "tower", nil
+}
+
+func localStorage() (js.Value, error) {
+	s := js.Global().Get("localStorage")
+	if !s.Truthy() {
+		return js.Value{}, errors.New("localStorage unavailable")
+	}
+	return s, nil
+}
+
+// readSave and writeSave keep each save file as a localStorage item keyed
+// by its path.
+func readSave(path string) ([]byte, error) {
+	s, err := localStorage()
+	if err != nil {
+		return nil, err
+	}
+	v := s.Call("getItem", path)
+	if v.IsNull() {
+		return nil, fs.ErrNotExist
+	}
+	return []byte(v.String()), nil
+}
+
+func writeSave(path string, b []byte) (err error) {
+	s, err := localStorage()
+	if err != nil {
+		return err
+	}
+	// setItem throws once the quota is used up.
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("localStorage full")
+		}
+	}()
+	s.Call("setItem", path, string(b))
+	return nil
+}
+
+func removeSave(path string) error {
+	s, err := localStorage()
+	if err != nil {
+		return err
+	}
+	s.Call("removeItem", path)
+	return nil
+}
+
+func saveExists(path string) bool {
+	s, err := localStorage()
+	return err == nil && !s.Call("getItem", path).IsNull()
+}
+
+// listSaves names the items keyed dir/<name>, as if dir were a folder.
+func listSaves(dir string) ([]string, error) {
+	s, err := localStorage()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for i := range s.Get("length").Int() {
+		name, ok := strings.CutPrefix(s.Call("key", i).String(), dir+"/")
+		if ok && name != "" && !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
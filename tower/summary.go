@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"tetris/engine"
+)
+
+// drawStatsPanel is the compact live stats in the side panel.
+func (g *Game) drawStatsPanel(screen *ebiten.Image, x, y float32) {
+	pal := g.palette()
+	k := float32(g.settings.UIScale)
+	s := g.Stats()
+	g.drawText(screen, tr("Stats:"), x, y, pal.Text)
+	for i, line := range []string{
+		trf("PPS %.2f", s.PPS()),
+		trf("APM %.1f", s.APM()),
+		trf("Tetris %d%%", int(s.TetrisRate()*100+0.5)),
+		trf("Finesse %d", s.FinesseFaults),
+	} {
+		g.drawText(screen, line, x, y+float32(16+16*i)*k, pal.Dim)
+	}
+}
+
+// summaryItems are the summary's choices: play the mode again, or go back
+// to the title screen.
+var summaryItems = []string{"Retry", "Menu"}
+
+// updateSummary moves between Retry and Menu and picks one on Enter or a
+// tap. Esc, S or a tap elsewhere goes back to the game over screen.
+func (g *Game) updateSummary() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		g.summarySel = 1 - g.summarySel
+	}
+	pick := -1
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) ||
+		g.pad.justPressed(ebiten.StandardGamepadButtonRightBottom):
+		pick = g.summarySel
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyS) ||
+		g.pad.justPressed(ebiten.StandardGamepadButtonRightRight):
+		g.closeOverlay()
+		return
+	}
+	k := float32(g.settings.UIScale)
+	top := g.summaryMenuTop()
+	for _, id := range justTouchIDs(nil) {
+		_, y := ebiten.TouchPosition(id)
+		row := int((float32(y) - top + 14*k) / (summaryRowH * k))
+		if float32(y) < top-14*k || row >= len(summaryItems) {
+			g.closeOverlay()
+			return
+		}
+		pick = row
+	}
+	switch pick {
+	case 0:
+		g.Reset()
+	case 1:
+		g.setState(stateTitle)
+	}
+}
+
+// summaryRowH is the height of a Retry or Menu row, before the UI scale.
+const summaryRowH = 20
+
+// summaryLines are the finished game's totals, one per row.
+func summaryLines(score int, s engine.Stats) []string {
+	return []string{
+		trf("Score      %d", score),
+		trf("Time       %s", raceTime(s.Frames)),
+		trf("Pieces     %d", s.Placed),
+		trf("Lines      %d", s.Lines),
+		trf("PPS        %.2f", s.PPS()),
+		trf("Attack     %d (%.1f APM)", s.Attack, s.APM()),
+		trf("Tetrises   %d (%d%% of lines)", s.Tetrises, int(s.TetrisRate()*100+0.5)),
+		trf("Max Combo  %d", s.MaxCombo),
+		trf("Finesse    %d faults", s.FinesseFaults),
+	}
+}
+
+func (g *Game) drawSummary(screen *ebiten.Image) {
+	pal := g.palette()
+	w, h := float32(screen.Bounds().Dx()), float32(screen.Bounds().Dy())
+	vector.DrawFilledRect(screen, 0, 0, w, h, alpha(pal.Shade, 220), false)
+	k := float32(g.settings.UIScale)
+	center := func(s string, y float32, c color.Color) {
+		g.drawCentered(screen, s, w/2, y, c)
+	}
+	s := g.Stats()
+	y := 80 * k
+	center(trf("%s Summary", g.Mode().Name), y, pal.Text)
+	y += 32 * k
+	left := w/2 - 110*k
+	for _, line := range summaryLines(g.Score(), s) {
+		g.drawText(screen, line, left, y, pal.Text)
+		y += 18 * k
+	}
+
+	// Pieces by kind, as bars against the most placed.
+	y += 14 * k
+	most := 1
+	for _, n := range s.Kinds {
+		most = max(most, n)
+	}
+	for i, n := range s.Kinds {
+		g.drawText(screen, pieceKinds[i:i+1], left, y, pal.Text)
+		bar := 150 * k * float32(n) / float32(most)
+		vector.DrawFilledRect(screen, left+16*k, y-10*k, bar, 10*k, pal.Pieces[i], false)
+		g.drawText(screen, fmt.Sprint(n), left+174*k, y, pal.Dim)
+		y += 16 * k
+	}
+
+	y = g.summaryMenuTop()
+	for i, item := range summaryItems {
+		c := pal.Dim
+		item = tr(item)
+		if i == g.summarySel {
+			c = pal.Accent
+			item = "> " + item + " <"
+		}
+		center(item, y, c)
+		y += summaryRowH * k
+	}
+	hint := "Esc goes back"
+	if touchScreen() {
+		hint = "Tap elsewhere to go back"
+	}
+	center(tr(hint), y+8*k, pal.Dim)
+}
+
+// summaryMenuTop is the baseline of the Retry row, under the totals and
+// the piece bars.
+func (g *Game) summaryMenuTop() float32 {
+	k := float32(g.settings.UIScale)
+	rows := len(summaryLines(0, engine.Stats{}))
+	return (80 + 32 + 18*float32(rows) + 14 + 16*7 + 20) * k
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummaryOverGameOver(t *testing.T) {
+	tempConfig(t)
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	for i := 0; g.Stats().Placed < 3 && i < 600; i++ {
+		g.stepPlayers(frameInput{hardDrop: true})
+	}
+	g.setState(stateGameOver)
+	g.setState(stateSummary)
+	if g.base() != stateGameOver {
+		t.Errorf("the summary is over %v, want the game over screen", g.base())
+	}
+	lines := summaryLines(g.Score(), g.Stats())
+	if !strings.HasSuffix(lines[2], " 3") {
+		t.Errorf("pieces line %q, want 3 placed", lines[2])
+	}
+	g.closeOverlay()
+	if g.state != stateGameOver {
+		t.Errorf("closing the summary went to %v", g.state)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tetris/telemetry"
+)
+
+const telemetryInterval = 5 * time.Minute
+
+// telemetryClient is non-nil only while the player has opted in.
+var (
+	telemetryClient *telemetry.Client
+	stopTelemetry   = func() {}
+	// previousCrashed is whether the last session left its marker behind.
+	previousCrashed bool
+)
+
+func sessionMarker() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "session.lock"), nil
+}
+
+// beginSession notes whether the previous run exited cleanly and leaves a
+// marker that endSession removes.
+func beginSession() {
+	p, err := sessionMarker()
+	if err != nil {
+		return
+	}
+	_, err = os.Stat(p)
+	previousCrashed = err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Debug("session marker", "err", err)
+	}
+	os.MkdirAll(filepath.Dir(p), 0o755)
+	if err := os.WriteFile(p, []byte(time.Now().UTC().Format(time.RFC3339)), 0o644); err != nil {
+		slog.Debug("session marker", "err", err)
+	}
+}
+
+func endSession() {
+	if p, err := sessionMarker(); err == nil {
+		os.Remove(p)
+	}
+}
+
+// applyTelemetry starts or stops uploads to match s. Nothing is sent unless
+// s.Telemetry is on and an endpoint is configured.
+func applyTelemetry(s Settings) {
+	on := s.Telemetry && s.TelemetryEndpoint != ""
+	if on == (telemetryClient != nil) {
+		return
+	}
+	if !on {
+		stopTelemetry()
+		return
+	}
+	c := telemetry.New(s.TelemetryEndpoint)
+	c.SessionStarted(previousCrashed)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx, telemetryInterval)
+		close(done)
+	}()
+	telemetryClient = c
+	stopTelemetry = func() {
+		// Opting out drops what was not yet sent.
+		c.Discard()
+		cancel()
+		<-done
+		telemetryClient = nil
+		stopTelemetry = func() {}
+	}
+	slog.Info("telemetry enabled", "endpoint", s.TelemetryEndpoint)
+}
+
+// shutdownTelemetry sends any pending batch on a clean exit.
+func shutdownTelemetry() {
+	c := telemetryClient
+	if c == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	c.Flush(ctx)
+	stopTelemetry()
+}
+
+func recordRun(mode string, d time.Duration) {
+	if telemetryClient != nil {
+		telemetryClient.RunFinished(mode, d)
+	}
+}
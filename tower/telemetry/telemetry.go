@@ -0,0 +1,143 @@
+// Package telemetry batches anonymous gameplay aggregates and posts them to
+// a configurable endpoint. It records counts and totals only: no player
+// names, scores tied to a person, device identifiers, or IP-derived data
+// are collected by this package.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Batch is one upload: aggregates since the previous successful flush.
+type Batch struct {
+	Version         int            `json:"version"`
+	Sessions        int            `json:"sessions"`
+	CrashedSessions int            `json:"crashed_sessions"`
+	RunsByMode      map[string]int `json:"runs_by_mode"`
+	RunSeconds      float64        `json:"run_seconds"` // summed over runs
+}
+
+func (b *Batch) empty() bool {
+	return b.Sessions == 0 && b.CrashedSessions == 0 && len(b.RunsByMode) == 0
+}
+
+func (b *Batch) merge(o Batch) {
+	b.Sessions += o.Sessions
+	b.CrashedSessions += o.CrashedSessions
+	b.RunSeconds += o.RunSeconds
+	for m, n := range o.RunsByMode {
+		if b.RunsByMode == nil {
+			b.RunsByMode = map[string]int{}
+		}
+		b.RunsByMode[m] += n
+	}
+}
+
+// Client accumulates a Batch and sends it. It is safe for concurrent use.
+type Client struct {
+	endpoint string
+	http     *http.Client
+
+	mu      sync.Mutex
+	pending Batch
+}
+
+// New returns a client posting to endpoint. Nothing is sent until Flush.
+func New(endpoint string) *Client {
+	return &Client{endpoint: endpoint, http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// SessionStarted counts a launch; crashedBefore reports that the previous
+// session did not shut down cleanly.
+func (c *Client) SessionStarted(crashedBefore bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending.Sessions++
+	if crashedBefore {
+		c.pending.CrashedSessions++
+	}
+}
+
+// RunFinished counts one finished game of mode lasting d.
+func (c *Client) RunFinished(mode string, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending.merge(Batch{RunsByMode: map[string]int{mode: 1}, RunSeconds: d.Seconds()})
+}
+
+// Discard drops anything not yet sent, for when the player opts out.
+func (c *Client) Discard() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = Batch{}
+}
+
+// Flush posts the pending batch. On failure the batch is kept and retried by
+// the next Flush.
+func (c *Client) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	b := c.pending
+	c.pending = Batch{}
+	c.mu.Unlock()
+	if b.empty() || c.endpoint == "" {
+		return nil
+	}
+	b.Version = 1
+	if err := c.post(ctx, b); err != nil {
+		c.mu.Lock()
+		c.pending.merge(b)
+		c.mu.Unlock()
+		return err
+	}
+	slog.Debug("telemetry sent", "sessions", b.Sessions, "runs", len(b.RunsByMode))
+	return nil
+}
+
+func (c *Client) post(ctx context.Context, b Batch) error {
+	body, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("telemetry: %s", resp.Status)
+	}
+	return nil
+}
+
+// Run flushes every interval until ctx is done, then makes one last attempt.
+func (c *Client) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			final, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			if err := c.Flush(final); err != nil {
+				slog.Debug("telemetry final flush failed", "err", err)
+			}
+			cancel()
+			return
+		case <-t.C:
+			if err := c.Flush(ctx); err != nil {
+				slog.Debug("telemetry flush failed", "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// server fails the first fails posts, then records the batches it takes.
+func server(t *testing.T, fails int) (*httptest.Server, *[]Batch) {
+	var got []Batch
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fails > 0 {
+			fails--
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		var b Batch
+		if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+			t.Error(err)
+		}
+		got = append(got, b)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func TestFlushRetriesAFailedBatch(t *testing.T) {
+	srv, got := server(t, 1)
+	c := New(srv.URL)
+	c.SessionStarted(true)
+	c.RunFinished("Marathon", 90*time.Second)
+	if err := c.Flush(context.Background()); err == nil {
+		t.Fatal("a failed post flushed without an error")
+	}
+	// Play goes on while the batch waits.
+	c.RunFinished("Marathon", 30*time.Second)
+	c.RunFinished("Sprint", time.Minute)
+	if err := c.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(*got) != 1 {
+		t.Fatalf("%d batches arrived, want the one", len(*got))
+	}
+	b := (*got)[0]
+	if b.Version != 1 || b.Sessions != 1 || b.CrashedSessions != 1 || b.RunSeconds != 180 ||
+		b.RunsByMode["Marathon"] != 2 || b.RunsByMode["Sprint"] != 1 {
+		t.Errorf("sent %+v, want the failed batch and the later runs together", b)
+	}
+
+	if err := c.Flush(context.Background()); err != nil || len(*got) != 1 {
+		t.Errorf("flushing with nothing pending: %v, %d batches; want nothing sent", err, len(*got))
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	srv, got := server(t, 0)
+	c := New(srv.URL)
+	c.SessionStarted(false)
+	c.Discard()
+	if err := c.Flush(context.Background()); err != nil || len(*got) != 0 {
+		t.Errorf("after Discard: %v, %d batches sent", err, len(*got))
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import "image/color"
+
+// BlockStyle selects how a single mino is drawn.
+type BlockStyle int
+
+const (
+	BlockFlat BlockStyle = iota
+	BlockBeveled
+)
+
+// GridStyle selects how the empty board is drawn.
+type GridStyle int
+
+const (
+	GridCells GridStyle = iota // each empty cell a faint square
+	GridLines                  // lines between the cells
+	GridNone                   // plain
+)
+
+// Palette is every colour a theme draws with.
+type Palette struct {
+	Pieces [9]color.RGBA // by kind I, O, T, S, Z, J, L, then garbage and gem
+	Back   color.RGBA    // behind everything
+	Frame  color.RGBA    // the board's border, and grid lines
+	Cell   color.RGBA    // an empty cell
+	Text   color.RGBA
+	Dim    color.RGBA // hints and secondary text
+	Accent color.RGBA // combos, new high scores
+	Info   color.RGBA // back-to-back, the CPU label, the update banner
+	Alert  color.RGBA // incoming garbage, boss health, errors
+	Select color.RGBA // the highlighted menu row
+	Shade  color.RGBA // the overlays over a paused or finished game
+}
+
+// Theme groups the visual choices that go together.
+type Theme struct {
+	Name    string
+	Block   BlockStyle
+	Grid    GridStyle
+	Palette Palette
+	// Animated draws the shader background behind the board at high
+	// quality; otherwise the background is Palette.Back.
+	Animated bool
+	// Marks are Settings.PieceMarks, carried with the theme to everything
+	// that draws pieces. Themes don't set them.
+	Marks PieceMarks
+
+	// Background is a file in backgroundDir drawn behind the playfield,
+	// unless the player picked one in settings.
+	Background     string
+	BackgroundDim  float64 // 0 keeps the image as is, 1 is black
+	BackgroundBlur int     // halvings in the blur pass, 0 for none
+}
+
+var darkPalette = Palette{
+	Pieces: [9]color.RGBA{
+		{0, 255, 255, 255},   // I
+		{255, 255, 0, 255},   // O
+		{160, 0, 240, 255},   // T
+		{0, 200, 0, 255},     // S
+		{220, 0, 0, 255},     // Z
+		{0, 80, 220, 255},    // J
+		{255, 140, 0, 255},   // L
+		{110, 110, 120, 255}, // garbage
+		{255, 215, 0, 255},   // gem
+	},
+	Back:   color.RGBA{18, 18, 24, 255},
+	Frame:  color.RGBA{40, 40, 55, 255},
+	Cell:   color.RGBA{30, 30, 44, 255},
+	Text:   color.RGBA{255, 255, 255, 255},
+	Dim:    color.RGBA{200, 200, 200, 255},
+	Accent: color.RGBA{255, 220, 100, 255},
+	Info:   color.RGBA{120, 220, 255, 255},
+	Alert:  color.RGBA{255, 110, 70, 255},
+	Select: color.RGBA{255, 255, 255, 40},
+	Shade:  color.RGBA{0, 0, 0, 255},
+}
+
+var builtinThemes = []Theme{
+	{Name: "Dark", Block: BlockFlat, Palette: darkPalette, Animated: true, BackgroundDim: 0.6, BackgroundBlur: 3},
+	{Name: "Classic", Block: BlockBeveled, Animated: true, BackgroundDim: 0.5, BackgroundBlur: 2, Palette: Palette{
+		Pieces: darkPalette.Pieces,
+		Back:   color.RGBA{12, 14, 44, 255},
+		Frame:  color.RGBA{90, 90, 140, 255},
+		Cell:   color.RGBA{22, 24, 64, 255},
+		Text:   color.RGBA{255, 255, 255, 255},
+		Dim:    color.RGBA{190, 190, 220, 255},
+		Accent: color.RGBA{255, 220, 100, 255},
+		Info:   color.RGBA{120, 220, 255, 255},
+		Alert:  color.RGBA{255, 110, 70, 255},
+		Select: color.RGBA{255, 255, 255, 48},
+		Shade:  color.RGBA{0, 0, 20, 255},
+	}},
+	{Name: "High Contrast", Block: BlockFlat, Grid: GridNone, BackgroundDim: 0.85, Palette: Palette{
+		Pieces: [9]color.RGBA{
+			{0, 255, 255, 255},
+			{255, 255, 0, 255},
+			{255, 0, 255, 255},
+			{0, 255, 0, 255},
+			{255, 40, 40, 255},
+			{80, 140, 255, 255},
+			{255, 160, 0, 255},
+			{170, 170, 170, 255},
+			{255, 255, 255, 255},
+		},
+		Back:   color.RGBA{0, 0, 0, 255},
+		Frame:  color.RGBA{255, 255, 255, 255},
+		Cell:   color.RGBA{0, 0, 0, 255},
+		Text:   color.RGBA{255, 255, 255, 255},
+		Dim:    color.RGBA{235, 235, 235, 255},
+		Accent: color.RGBA{255, 255, 0, 255},
+		Info:   color.RGBA{0, 255, 255, 255},
+		Alert:  color.RGBA{255, 90, 0, 255},
+		Select: color.RGBA{255, 255, 255, 96},
+		Shade:  color.RGBA{0, 0, 0, 255},
+	}},
+	{Name: "Colorblind", Block: BlockFlat, Grid: GridLines, BackgroundDim: 0.85, Palette: Palette{
+		// Okabe and Ito's palette, told apart with any colour vision
+		// deficiency, on black.
+		Pieces: [9]color.RGBA{
+			{86, 180, 233, 255},  // sky blue
+			{240, 228, 66, 255},  // yellow
+			{204, 121, 167, 255}, // reddish purple
+			{0, 158, 115, 255},   // bluish green
+			{213, 94, 0, 255},    // vermilion
+			{0, 114, 178, 255},   // blue
+			{230, 159, 0, 255},   // orange
+			{150, 150, 150, 255},
+			{255, 255, 255, 255},
+		},
+		Back:   color.RGBA{0, 0, 0, 255},
+		Frame:  color.RGBA{200, 200, 200, 255},
+		Cell:   color.RGBA{16, 16, 16, 255},
+		Text:   color.RGBA{255, 255, 255, 255},
+		Dim:    color.RGBA{220, 220, 220, 255},
+		Accent: color.RGBA{240, 228, 66, 255},
+		Info:   color.RGBA{86, 180, 233, 255},
+		Alert:  color.RGBA{213, 94, 0, 255},
+		Select: color.RGBA{255, 255, 255, 96},
+		Shade:  color.RGBA{0, 0, 0, 255},
+	}},
+	{Name: "Retro", Block: BlockBeveled, Grid: GridLines, BackgroundDim: 0.8, Palette: Palette{
+		// Four greens, like an old handheld's screen.
+		Pieces: [9]color.RGBA{
+			{155, 188, 15, 255},
+			{139, 172, 15, 255},
+			{155, 188, 15, 255},
+			{139, 172, 15, 255},
+			{155, 188, 15, 255},
+			{139, 172, 15, 255},
+			{155, 188, 15, 255},
+			{88, 125, 30, 255},
+			{200, 220, 120, 255},
+		},
+		Back:   color.RGBA{15, 56, 15, 255},
+		Frame:  color.RGBA{48, 98, 48, 255},
+		Cell:   color.RGBA{15, 56, 15, 255},
+		Text:   color.RGBA{155, 188, 15, 255},
+		Dim:    color.RGBA{139, 172, 15, 255},
+		Accent: color.RGBA{200, 220, 120, 255},
+		Info:   color.RGBA{155, 188, 15, 255},
+		Alert:  color.RGBA{220, 240, 160, 255},
+		Select: color.RGBA{155, 188, 15, 64},
+		Shade:  color.RGBA{15, 56, 15, 255},
+	}},
+}
+
+// themes lists the built-in themes followed by any loaded from mods.
+func themes() []Theme {
+	return append(builtinThemes[:len(builtinThemes):len(builtinThemes)], modThemes...)
+}
+
+// theme returns the selected theme, falling back to the first one when the
+// saved name is gone (for example, its mod was removed).
+func (g *Game) theme() Theme {
+	all := themes()
+	th := all[0]
+	for _, t := range all {
+		if t.Name == g.settings.ThemeName {
+			th = t
+		}
+	}
+	th.Marks = g.settings.PieceMarks
+	return th
+}
+
+// palette is the selected theme's colours.
+func (g *Game) palette() Palette {
+	return g.theme().Palette
+}
+
+// alpha is c at opacity a.
+func alpha(c color.RGBA, a uint8) color.RGBA {
+	c.A = a
+	return c
+}
+
+func (g *Game) cycleTheme(dir int) {
+	all := themes()
+	cur := 0
+	for i, t := range all {
+		if t.Name == g.settings.ThemeName {
+			cur = i
+		}
+	}
+	g.settings.ThemeName = all[wrap(cur+dir, len(all))].Name
+}
+
+// shade scales a color's RGB by f, clamping to the valid range.
+func shade(c color.RGBA, f float32) color.RGBA {
+	ch := func(v uint8) uint8 {
+		x := float32(v) * f
+		if x > 255 {
+			x = 255
+		}
+		return uint8(x)
+	}
+	return color.RGBA{ch(c.R), ch(c.G), ch(c.B), c.A}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"image/color"
+	"testing"
+)
+
+func TestBuiltinThemes(t *testing.T) {
+	seen := map[string]bool{}
+	for _, th := range builtinThemes {
+		if seen[th.Name] {
+			t.Errorf("two themes named %q", th.Name)
+		}
+		seen[th.Name] = true
+		if th.Palette.Text == th.Palette.Back || th.Palette.Cell.A == 0 {
+			t.Errorf("%s: text on the background or empty cells won't show", th.Name)
+		}
+	}
+	for _, name := range []string{"Dark", "Classic", "High Contrast", "Colorblind", "Retro"} {
+		if !seen[name] {
+			t.Errorf("no %s theme", name)
+		}
+	}
+}
+
+func TestThemeCarriesMarks(t *testing.T) {
+	tempConfig(t)
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.settings.ThemeName = "Colorblind"
+	g.settings.PieceMarks = MarksLetters
+	if th := g.theme(); th.Name != "Colorblind" || th.Marks != MarksLetters {
+		t.Errorf("theme() = %s with marks %v, want Colorblind with Letters", th.Name, th.Marks)
+	}
+}
+
+func TestThemeJSONPalette(t *testing.T) {
+	var tj themeJSON
+	err := json.Unmarshal([]byte(`{"grid": "lines", "animated": false,
+		"palette": {"pieces": ["#ff0000", "#00ff0080"], "back": "#102030"}}`), &tj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	th, err := tj.toTheme("Custom", "mods/custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := th.Palette
+	if p.Pieces[0] != (color.RGBA{255, 0, 0, 255}) || p.Pieces[1] != (color.RGBA{0, 255, 0, 128}) || p.Back != (color.RGBA{16, 32, 48, 255}) {
+		t.Errorf("palette = %+v", p)
+	}
+	if p.Pieces[2] != darkPalette.Pieces[2] || p.Text != darkPalette.Text {
+		t.Error("colours left out didn't keep the Dark theme's")
+	}
+	if th.Grid != GridLines || th.Animated {
+		t.Errorf("grid %d, animated %v; want lines, off", th.Grid, th.Animated)
+	}
+
+	if th, err := (themeJSON{}).toTheme("Plain", ""); err != nil || !th.Animated || th.Palette != darkPalette {
+		t.Errorf("a theme with no palette: %+v, %v", th, err)
+	}
+	for _, bad := range []themeJSON{
+		{Grid: "dots"},
+		{Palette: &paletteJSON{Back: "102030"}},
+		{Palette: &paletteJSON{Text: "#12345"}},
+		{Palette: &paletteJSON{Pieces: make([]string, 10)}},
+	} {
+		if _, err := bad.toTheme("Bad", ""); err == nil {
+			t.Errorf("%+v loaded", bad)
+		}
+	}
+}
+
+func TestModernThemeBecomesDark(t *testing.T) {
+	doc := map[string]any{"ThemeName": "Modern"}
+	for _, m := range settingsMigrations[1:] {
+		if err := m(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if doc["ThemeName"] != "Dark" {
+		t.Errorf("ThemeName = %v, want Dark", doc["ThemeName"])
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import "math"
+
+// TiltSource reports the device's left/right roll in radians, positive when
+// tilted right. Ebitengine has no sensor API, so platform glue provides
+// one: the browser's orientation events (tilt_js.go), or an ebitenmobile
+// host app setting tiltSource. ok is false without a reading.
+type TiltSource interface {
+	Roll() (radians float64, ok bool)
+}
+
+// tiltPermitter is a TiltSource that needs the player's leave before it
+// reads anything. Permit asks, from inside a tap or key press.
+type tiltPermitter interface {
+	Permit()
+}
+
+// tiltSource is nil on platforms without an accelerometer hookup, which
+// leaves tilt controls unavailable.
+var tiltSource TiltSource
+
+const tiltDeadZone = 0.08 // radians around the calibrated zero that do nothing
+
+func tiltAvailable() bool {
+	if tiltSource == nil {
+		return false
+	}
+	_, ok := tiltSource.Roll()
+	return ok
+}
+
+// tiltShifter turns roll past the dead zone into horizontal moves: the
+// further the tilt, the faster the piece walks.
+type tiltShifter struct {
+	charge float64
+}
+
+func (t *tiltShifter) update(st Settings) int {
+	if !st.TiltControls || tiltSource == nil {
+		t.charge = 0
+		return 0
+	}
+	roll, ok := tiltSource.Roll()
+	if !ok {
+		return 0
+	}
+	a := roll - st.TiltZero
+	if math.Abs(a) < tiltDeadZone {
+		t.charge = 0
+		return 0
+	}
+	t.charge += (math.Abs(a) - tiltDeadZone) * float64(st.TiltSensitivity) * 0.25
+	if t.charge < 1 {
+		return 0
+	}
+	t.charge--
+	if a < 0 {
+		return -1
+	}
+	return 1
+}
+
+// calibrateTilt takes the current roll as neutral.
+func (g *Game) calibrateTilt() {
+	if tiltSource == nil {
+		return
+	}
+	if roll, ok := tiltSource.Roll(); ok {
+		g.settings.TiltZero = roll
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"syscall/js"
+)
+
+// deviceTilt is the browser's deviceorientation events, for phones and
+// tablets playing in a browser.
+type deviceTilt struct {
+	mu   sync.Mutex
+	roll float64
+	ok   bool // an event with a reading has come in
+}
+
+func init() {
+	w := js.Global()
+	if w.Get("DeviceOrientationEvent").Type() != js.TypeFunction {
+		return
+	}
+	t := &deviceTilt{}
+	w.Call("addEventListener", "deviceorientation", js.FuncOf(func(this js.Value, args []js.Value) any {
+		t.update(args[0])
+		return nil
+	}))
+	tiltSource = t
+}
+
+// update takes the roll from an event. gamma is the left/right tilt of
+// the device held upright and beta its front/back tilt, which is the roll
+// when the screen is turned to landscape.
+func (t *deviceTilt) update(e js.Value) {
+	beta, gamma := e.Get("beta"), e.Get("gamma")
+	if beta.Type() != js.TypeNumber || gamma.Type() != js.TypeNumber {
+		return
+	}
+	deg := gamma.Float()
+	switch screenAngle() {
+	case 90:
+		deg = beta.Float()
+	case 270, -90:
+		deg = -beta.Float()
+	}
+	t.mu.Lock()
+	t.roll, t.ok = deg*math.Pi/180, true
+	t.mu.Unlock()
+}
+
+func (t *deviceTilt) Roll() (float64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.roll, t.ok
+}
+
+// Permit asks for the sensor where the browser wants leave first, as iOS
+// Safari does. It must run inside a tap or key press.
+func (t *deviceTilt) Permit() {
+	ev := js.Global().Get("DeviceOrientationEvent")
+	if ev.Get("requestPermission").Type() == js.TypeFunction {
+		ev.Call("requestPermission")
+	}
+}
+
+// screenAngle is how far the screen is turned from upright, in degrees.
+func screenAngle() int {
+	if s := js.Global().Get("screen"); s.Truthy() && s.Get("orientation").Truthy() {
+		if a := s.Get("orientation").Get("angle"); a.Type() == js.TypeNumber {
+			return a.Int()
+		}
+	}
+	if a := js.Global().Get("orientation"); a.Type() == js.TypeNumber {
+		return a.Int()
+	}
+	return 0
+}
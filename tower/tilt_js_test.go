@@ -0,0 +1,22 @@
+package main
+
+import (
+	"math"
+	"syscall/js"
+	"testing"
+)
+
+func TestDeviceTilt(t *testing.T) {
+	var d deviceTilt
+	if _, ok := d.Roll(); ok {
+		t.Error("a reading before any event")
+	}
+	d.update(js.ValueOf(map[string]any{"beta": nil, "gamma": nil}))
+	if _, ok := d.Roll(); ok {
+		t.Error("an event without angles gave a reading")
+	}
+	d.update(js.ValueOf(map[string]any{"beta": 10, "gamma": -30}))
+	if roll, ok := d.Roll(); !ok || math.Abs(roll+math.Pi/6) > 1e-9 {
+		t.Errorf("roll %v, %v; want -30° upright", roll, ok)
+	}
+}
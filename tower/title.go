@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// titleItems are the title screen's rows. They work like settings rows:
+// left/right adjust, Enter or a tap picks.
+var titleItems = func() []settingItem {
+	items := []settingItem{
+		{
+			label:  "Start",
+			value:  func(g *Game) string { return "" },
+			adjust: func(g *Game, dir int) { g.startMode(modes[g.titleMode], Modifiers{}) },
+		},
+		{
+			label:  "Mode",
+			value:  func(g *Game) string { return modes[g.titleMode].Name },
+			adjust: func(g *Game, dir int) { g.titleMode = wrap(g.titleMode+dir, len(modes)) },
+		},
+		{
+			label: "Level",
+			value: func(g *Game) string { return fmt.Sprint(g.settings.StartLevel) },
+			adjust: func(g *Game, dir int) {
+				g.settings.StartLevel = wrap(g.settings.StartLevel+dir, maxStartLevel+1)
+				g.storeSettings()
+			},
+		},
+		{
+			label: "Gravity",
+			value: func(g *Game) string { return gravityName(g.settings.ClassicGravity[modes[g.titleMode].Name]) },
+			adjust: func(g *Game, dir int) {
+				g.toggleClassicGravity(modes[g.titleMode].Name)
+				g.storeSettings()
+			},
+		},
+		{
+			label: "Demo",
+			value: func(g *Game) string {
+				if !demoable(modes[g.titleMode]) {
+					return "Unavailable"
+				}
+				return ""
+			},
+			adjust: func(g *Game, dir int) { g.startDemo(modes[g.titleMode]) },
+		},
+		{
+			label:  "Versus CPU",
+			value:  func(g *Game) string { return "" },
+			adjust: func(g *Game, dir int) { g.startVersusCPU() },
+		},
+		subPage("Puzzles", puzzlePage),
+		subPage("Custom Game", customGamePage),
+		{
+			label:  "Board Editor",
+			value:  func(g *Game) string { return "" },
+			adjust: func(g *Game, dir int) { g.openEditor() },
+		},
+		{
+			label:  "High Scores",
+			value:  func(g *Game) string { return "" },
+			adjust: func(g *Game, dir int) { g.openScoreView(modes[g.titleMode].Name) },
+		},
+		{
+			label:  "Replays",
+			value:  func(g *Game) string { return "" },
+			adjust: func(g *Game, dir int) { g.openReplays() },
+		},
+		subPage("Settings", settingsPage),
+	}
+	switch runtime.GOOS {
+	case "ios", "android", "js":
+		// The platform closes the app.
+	default:
+		items = append(items, settingItem{
+			label:  "Quit",
+			value:  func(g *Game) string { return "" },
+			adjust: func(g *Game, dir int) { g.quitting = true },
+		})
+	}
+	return items
+}()
+
+// continueItem leads the title screen while there's a quit run to pick up.
+var continueItem = settingItem{
+	label:  "Continue",
+	value:  func(g *Game) string { return "" },
+	adjust: func(g *Game, dir int) { g.continueRun() },
+}
+
+// titleRows are the title screen's rows as things stand.
+func (g *Game) titleRows() []settingItem {
+	if !g.canContinue {
+		return titleItems
+	}
+	return append([]settingItem{continueItem}, titleItems...)
+}
+
+// updateTitle moves through the title menu with the keyboard, D-pad or
+// touch.
+func (g *Game) updateTitle() {
+	items := g.titleRows()
+	n := len(items)
+	pad := g.pad.justPressed
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp) || inpututil.IsKeyJustPressed(ebiten.KeyW) ||
+		pad(ebiten.StandardGamepadButtonLeftTop):
+		g.titleSel = wrap(g.titleSel-1, n)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown) || inpututil.IsKeyJustPressed(ebiten.KeyS) ||
+		pad(ebiten.StandardGamepadButtonLeftBottom):
+		g.titleSel = wrap(g.titleSel+1, n)
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft) || inpututil.IsKeyJustPressed(ebiten.KeyA) ||
+		pad(ebiten.StandardGamepadButtonLeftLeft):
+		items[g.titleSel].adjust(g, -1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight) || inpututil.IsKeyJustPressed(ebiten.KeyD) ||
+		inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeySpace) ||
+		pad(ebiten.StandardGamepadButtonLeftRight) || pad(ebiten.StandardGamepadButtonRightBottom) ||
+		pad(ebiten.StandardGamepadButtonCenterRight):
+		items[g.titleSel].adjust(g, 1)
+	}
+	if g.state != stateTitle {
+		return
+	}
+	k := float32(g.settings.UIScale)
+	top, rowH := g.titleTop(), menuRowH*k
+	for _, id := range justTouchIDs(nil) {
+		x, y := ebiten.TouchPosition(id)
+		row := int((float32(y) - top) / rowH)
+		if float32(y) < top || row >= n {
+			continue
+		}
+		g.titleSel = row
+		if sw, _ := g.screenSize(); float32(x) < sw/2 {
+			items[row].adjust(g, -1)
+		} else {
+			items[row].adjust(g, 1)
+		}
+		return
+	}
+}
+
+func (g *Game) titleTop() float32 {
+	_, sh := g.screenSize()
+	return sh/2 - 40
+}
+
+// toggleClassicGravity switches the named mode between the standard and
+// NES gravity tables.
+func (g *Game) toggleClassicGravity(mode string) {
+	if g.settings.ClassicGravity[mode] {
+		delete(g.settings.ClassicGravity, mode)
+		return
+	}
+	if g.settings.ClassicGravity == nil {
+		g.settings.ClassicGravity = map[string]bool{}
+	}
+	g.settings.ClassicGravity[mode] = true
+}
+
+func (g *Game) drawTitle(screen *ebiten.Image) {
+	pal := g.palette()
+	w := float32(screen.Bounds().Dx())
+	const name = "TETRIS"
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(5, 5)
+	op.GeoM.Translate(float64(w)/2-float64(len(name))*7*5/2, float64(screen.Bounds().Dy())/4)
+	text.DrawWithOptions(screen, name, textFace, op)
+
+	k := float32(g.settings.UIScale)
+	top, rowH := g.titleTop(), menuRowH*k
+	items := g.titleRows()
+	for i, it := range items {
+		y := top + float32(i)*rowH
+		if i == g.titleSel {
+			vector.DrawFilledRect(screen, w/2-110*k, y, 220*k, rowH-2, pal.Select, false)
+		}
+		s := tr(it.label)
+		if v := it.value(g); v != "" {
+			s += ": < " + tr(v) + " >"
+		}
+		g.drawCentered(screen, s, w/2, y+rowH*0.7, pal.Text)
+	}
+	hint := "Arrows choose, Enter starts"
+	if touchScreen() {
+		hint = "Tap left/right half to change"
+	}
+	g.drawCentered(screen, tr(hint), w/2, top+float32(len(items)+1)*rowH, pal.Dim)
+}
@@ -0,0 +1,11 @@
+//go:build !js
+
+package main
+
+import "runtime"
+
+// touchScreen reports whether to show the touch controls: on phones and
+// tablets, which have no keyboard to fall back on.
+func touchScreen() bool {
+	return runtime.GOOS == "ios" || runtime.GOOS == "android"
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"sync"
+	"syscall/js"
+)
+
+// touchScreen reports whether the browser's main pointer is a finger, so
+// a phone gets the touch controls and a desktop browser the keyboard ones.
+var touchScreen = sync.OnceValue(func() bool {
+	w := js.Global()
+	if mm := w.Get("matchMedia"); mm.Type() == js.TypeFunction {
+		return w.Call("matchMedia", "(pointer: coarse)").Get("matches").Truthy()
+	}
+	if nav := w.Get("navigator"); nav.Truthy() {
+		n := nav.Get("maxTouchPoints")
+		return n.Type() == js.TypeNumber && n.Int() > 0
+	}
+	return false
+})
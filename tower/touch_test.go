@@ -0,0 +1,42 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestTouchScreen(t *testing.T) {
+	// Tests run natively or under Node, neither of which has a finger for
+	// a pointer.
+	if runtime.GOOS == "ios" || runtime.GOOS == "android" {
+		t.Skip("touch is the only input here")
+	}
+	if touchScreen() {
+		t.Error("touch controls on without a touch screen")
+	}
+}
+
+func TestTouchRects(t *testing.T) {
+	tempConfig(t)
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	g.settings.TouchLayout = TouchOneThumb
+	right := g.touchRects(480, 640)
+	g.settings.TouchLeftHanded = true
+	left := g.touchRects(480, 640)
+	for i := range left {
+		if left[i].x != 480-right[i].x-right[i].w || left[i].y != right[i].y {
+			t.Errorf("button %d: left-handed %+v doesn't mirror %+v", i, left[i], right[i])
+		}
+	}
+
+	// An edited layout is kept as fractions of the screen.
+	g.editTouchButtons()
+	g.touchEdit.rects[btnDrop] = rect{240, 160, 120, 80}
+	g.finishTouchEdit()
+	if g.settings.TouchLayout != TouchCustom || g.touchEdit != nil {
+		t.Fatalf("after editing, layout %v", g.settings.TouchLayout)
+	}
+	if got := g.touchRects(960, 1280)[btnDrop]; got != (rect{480, 320, 240, 160}) {
+		t.Errorf("the moved Drop button is at %+v on a screen twice the size", got)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// minTouchOpacity keeps the buttons from being turned all the way off.
+	minTouchOpacity  = 0.25
+	touchOpacityStep = 0.25
+	// minTouchButton is the smallest a button can be resized to, in
+	// pixels on each side.
+	minTouchButton = 40
+	// touchHandle is the side of the square at a button's bottom-right
+	// corner that resizes it.
+	touchHandle = 24
+)
+
+// touchPos is a touch button's rectangle as fractions of the screen.
+type touchPos struct {
+	X, Y, W, H float64
+}
+
+// touchEditor drags the touch buttons around and resizes them, opened
+// from Settings > Accessibility > Edit Touch Buttons.
+type touchEditor struct {
+	rects  [4]rect
+	drag   int // the button being dragged, -1 for none
+	resize bool
+	id     ebiten.TouchID
+	lastX  int
+	lastY  int
+}
+
+// editTouchButtons opens the editor on the buttons as they are now laid
+// out.
+func (g *Game) editTouchButtons() {
+	w, h := g.screenSize()
+	g.touchEdit = &touchEditor{rects: g.touchRects(w, h), drag: -1}
+}
+
+// touchEditButtons are the editor's Done and Reset buttons, across the top.
+func (g *Game) touchEditButtons() (done, reset rect) {
+	w, _ := g.screenSize()
+	k := float32(g.settings.UIScale)
+	bw, bh := 90*k, 32*k
+	return rect{w/2 - bw - 8, 40 * k, bw, bh}, rect{w/2 + 8, 40 * k, bw, bh}
+}
+
+// updateTouchEdit follows a finger dragging a button or its corner, and
+// saves the layout as Custom on Done (or Esc).
+func (g *Game) updateTouchEdit() {
+	e := g.touchEdit
+	w, h := g.screenSize()
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		g.finishTouchEdit()
+		return
+	}
+	done, reset := g.touchEditButtons()
+	for _, id := range justTouchIDs(nil) {
+		x, y := ebiten.TouchPosition(id)
+		switch {
+		case done.contains(x, y):
+			g.finishTouchEdit()
+			return
+		case reset.contains(x, y):
+			e.rects = touchButtons(TouchStandard, w, h, touchBarH*float32(g.settings.UIScale))
+			continue
+		}
+		for i, r := range e.rects {
+			if r.contains(x, y) {
+				handle := rect{r.x + r.w - touchHandle, r.y + r.h - touchHandle, touchHandle, touchHandle}
+				e.drag, e.resize, e.id = i, handle.contains(x, y), id
+				e.lastX, e.lastY = x, y
+			}
+		}
+	}
+	if e.drag < 0 {
+		return
+	}
+	if inpututil.IsTouchJustReleased(e.id) {
+		e.drag = -1
+		return
+	}
+	x, y := ebiten.TouchPosition(e.id)
+	dx, dy := float32(x-e.lastX), float32(y-e.lastY)
+	e.lastX, e.lastY = x, y
+	r := &e.rects[e.drag]
+	if e.resize {
+		r.w = max(minTouchButton, min(w-r.x, r.w+dx))
+		r.h = max(minTouchButton, min(h-r.y, r.h+dy))
+		return
+	}
+	r.x = max(0, min(w-r.w, r.x+dx))
+	r.y = max(0, min(h-r.h, r.y+dy))
+}
+
+// finishTouchEdit keeps the edited layout as Custom and goes back to the
+// menu.
+func (g *Game) finishTouchEdit() {
+	w, h := g.screenSize()
+	g.settings.TouchButtons = g.settings.TouchButtons[:0]
+	for _, r := range g.touchEdit.rects {
+		g.settings.TouchButtons = append(g.settings.TouchButtons, touchPos{
+			X: float64(r.x / w), Y: float64(r.y / h), W: float64(r.w / w), H: float64(r.h / h),
+		})
+	}
+	g.settings.TouchLayout = TouchCustom
+	g.touchEdit = nil
+	g.storeSettings()
+}
+
+func (g *Game) drawTouchEdit(screen *ebiten.Image) {
+	pal := g.palette()
+	e := g.touchEdit
+	w, h := g.screenSize()
+	k := float32(g.settings.UIScale)
+	vector.DrawFilledRect(screen, 0, 0, w, h, alpha(pal.Shade, 200), false)
+	g.drawCentered(screen, tr("Drag a button to move it, its corner to resize it"), w/2, 24*k, pal.Text)
+	done, reset := g.touchEditButtons()
+	for _, b := range []struct {
+		r     rect
+		label string
+	}{{done, "Done"}, {reset, "Reset"}} {
+		vector.StrokeRect(screen, b.r.x, b.r.y, b.r.w, b.r.h, 2, pal.Accent, false)
+		g.drawCentered(screen, tr(b.label), b.r.x+b.r.w/2, b.r.y+b.r.h/2+4*k, pal.Accent)
+	}
+	o := g.settings.TouchOpacity
+	for i, r := range e.rects {
+		c := alpha(pal.Text, uint8(40*o))
+		if i == e.drag {
+			c = alpha(pal.Accent, uint8(80*o))
+		}
+		vector.DrawFilledRect(screen, r.x, r.y, r.w, r.h, c, false)
+		vector.StrokeRect(screen, r.x, r.y, r.w, r.h, 1, pal.Dim, false)
+		vector.DrawFilledRect(screen, r.x+r.w-touchHandle, r.y+r.h-touchHandle, touchHandle, touchHandle, alpha(pal.Dim, 120), false)
+		g.drawCentered(screen, tr(touchLabels[i]), r.x+r.w/2, r.y+r.h/2, pal.Text)
+	}
+}
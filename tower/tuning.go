@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"tetris/engine"
+)
+
+// handlingPage tunes DAS/ARR/soft drop next to a live test board. Arrow
+// keys drive the test piece, so the page uses its own keys for the menu.
+var handlingPage = &menuPage{title: "Handling", live: true, items: []settingItem{
+	{
+		label:  "DAS",
+		value:  func(g *Game) string { return fmt.Sprintf("%d f", g.settings.DAS) },
+		adjust: func(g *Game, dir int) { g.settings.DAS = max(1, min(30, g.settings.DAS+dir)) },
+	},
+	{
+		label:  "ARR",
+		value:  func(g *Game) string { return fmt.Sprintf("%d f", g.settings.ARR) },
+		adjust: func(g *Game, dir int) { g.settings.ARR = max(0, min(10, g.settings.ARR+dir)) },
+	},
+	{
+		label: "Soft Drop",
+		value: func(g *Game) string {
+			if g.settings.SoftDropFrames == 0 {
+				return "Instant"
+			}
+			return fmt.Sprintf("%d f/row", g.settings.SoftDropFrames)
+		},
+		adjust: func(g *Game, dir int) {
+			g.settings.SoftDropFrames = max(0, min(10, g.settings.SoftDropFrames+dir))
+		},
+	},
+	{
+		label: "Left+Right",
+		value: func(g *Game) string { return g.settings.ShiftPriority.String() },
+		adjust: func(g *Game, dir int) {
+			g.settings.ShiftPriority = ShiftPriority(wrap(int(g.settings.ShiftPriority)+dir, int(Neutral)+1))
+		},
+	},
+}}
+
+const (
+	tunerW = engine.BoardW
+	tunerH = 8
+)
+
+// tuner is a tiny board where a single T piece moves with the current
+// handling settings. It has no gravity or stack; the piece wraps back to
+// the top when it reaches the floor.
+type tuner struct {
+	x, y    int
+	shifter shifter
+	soft    int // frames since the last soft-drop step
+}
+
+func newTuner() *tuner {
+	return &tuner{x: 3}
+}
+
+func (t *tuner) update(st Settings) {
+	keys := st.keyPreset().keys
+	left, right := anyPressed(keys[BindLeft]), anyPressed(keys[BindRight])
+	t.x = max(0, min(tunerW-3, t.x+t.shifter.update(left, right, st)))
+
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		t.y = tunerH - 2
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyDown) || ebiten.IsKeyPressed(ebiten.KeyS) {
+		t.soft++
+		if st.SoftDropFrames == 0 {
+			t.y = tunerH - 2
+		} else if t.soft >= st.SoftDropFrames {
+			t.soft = 0
+			t.y++
+		}
+	} else {
+		t.soft = 0
+	}
+	if t.y > tunerH-2 {
+		t.y = 0
+	}
+}
+
+func (t *tuner) draw(screen *ebiten.Image, ox, oy, tile float32, pal Palette) {
+	vector.DrawFilledRect(screen, ox-2, oy-2, tile*tunerW+4, tile*tunerH+4, pal.Frame, false)
+	for y := 0; y < tunerH; y++ {
+		for x := 0; x < tunerW; x++ {
+			drawCell(screen, ox, oy, tile, x, y, pal.Cell, BlockFlat)
+		}
+	}
+	for _, p := range engine.Shapes[2][0] {
+		drawCell(screen, ox, oy, tile, t.x+p.X, t.y+p.Y, pal.Pieces[2], BlockFlat)
+	}
+}
+
+// updateLiveMenu handles a live page: Up/Tab pick a row, -/+ adjust, and
+// everything else goes to the test board.
+func (g *Game) updateLiveMenu() {
+	items := g.page().items
+	n := len(items)
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		g.menuSel = wrap(g.menuSel-1, n)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		g.menuSel = wrap(g.menuSel+1, n)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) {
+		items[g.menuSel].adjust(g, -1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) {
+		items[g.menuSel].adjust(g, 1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.closeMenu()
+		return
+	}
+	if g.tuner == nil {
+		g.tuner = newTuner()
+	}
+	g.tuner.update(g.settings)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"math"
+
+	"tetris/engine"
+)
+
+const tweenFrames = 6
+
+// tween eases the active piece from where it was drawn toward where it now
+// is after a move or rotation. It is rendering-only state.
+type tween struct {
+	dx    float32 // starting horizontal offset in cells
+	angle float32 // starting rotation offset in radians
+	age   float32
+}
+
+// residual is the offset still to be shown this frame.
+func (t *tween) residual() (dx, angle float32) {
+	if t.age >= tweenFrames {
+		return 0, 0
+	}
+	p := 1 - t.age/tweenFrames
+	k := p * p * p // ease-out cubic, remaining share
+	return t.dx * k, t.angle * k
+}
+
+// push starts a new tween from the current visual position, so rapid inputs
+// chain smoothly instead of snapping back.
+func (t *tween) push(dx float32, rotDir int) {
+	cdx, cang := t.residual()
+	t.dx = cdx - dx
+	t.angle = cang - float32(rotDir)*math.Pi/2
+	t.age = 0
+}
+
+func (t *tween) update(dt float32) {
+	if t.age < tweenFrames {
+		t.age += dt
+	}
+}
+
+// piecePivot is the rotation center of a kind within its 4x4 box.
+func piecePivot(kind int) (float32, float32) {
+	if kind == 0 || kind == 1 { // I, O
+		return 2, 2
+	}
+	return 1.5, 1.5
+}
+
+// tweenedCell returns the drawn position (in cells, top-left of the box) of
+// shape cell p of piece cur for tween t.
+func (g *Game) tweenedCell(p engine.Point, cur engine.Piece, t tween) (float32, float32) {
+	dx, angle := t.residual()
+	if !g.settings.Tweens {
+		dx, angle = 0, 0
+	}
+	cx, cy := float32(p.X)+0.5, float32(p.Y)+0.5
+	if angle != 0 {
+		px, py := piecePivot(cur.Kind)
+		s, c := math.Sincos(float64(angle))
+		rx, ry := cx-px, cy-py
+		cx = px + rx*float32(c) - ry*float32(s)
+		cy = py + rx*float32(s) + ry*float32(c)
+	}
+	return float32(cur.X) + cx - 0.5 + dx, float32(cur.Y) + cy - 0.5
+}
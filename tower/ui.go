@@ -0,0 +1,108 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+)
+
+const (
+	rightPanelW = 150
+	margin      = 16
+	touchBarH   = 160
+)
+
+// layout is where the board and panels go on the logical screen.
+type layout struct {
+	tile               float32
+	originX, originY   float32
+	boardPxW, boardPxH float32
+	panelX             float32
+	uiScale            float32
+}
+
+type rect struct {
+	x, y, w, h float32
+}
+
+func (r rect) contains(x, y int) bool {
+	fx, fy := float32(x), float32(y)
+	return fx >= r.x && fx < r.x+r.w && fy >= r.y && fy < r.y+r.h
+}
+
+// layout computes the board geometry. The board fills what the fixed-width
+// panel and touch bar leave over, so the UI scale does not resize it, and
+// the board and panel are centred in whatever room is spare. Tiles are
+// whole pixels so cells stay crisp at any window size.
+func (g *Game) layout() layout {
+	ctrlH := float32(0)
+	if touchScreen() {
+		ctrlH = g.touchBarHeight()
+	}
+	sw, sh := g.screenSize()
+	playWidth := sw - rightPanelW - margin*3
+	playHeight := sh - margin*2 - ctrlH
+	w, h := float32(g.Width()), float32(g.Height())
+	tile := max(1, floorF(minF(playWidth/w, playHeight/h)))
+	originX := margin + floorF((playWidth-tile*w)/2)
+	originY := margin + floorF((playHeight-tile*h)/2)
+	return layout{
+		tile:     tile,
+		originX:  originX,
+		originY:  originY,
+		boardPxW: tile * w,
+		boardPxH: tile * h,
+		panelX:   originX + tile*w + margin,
+		uiScale:  float32(g.settings.UIScale),
+	}
+}
+
+func floorF(f float32) float32 {
+	return float32(math.Floor(float64(f)))
+}
+
+// board is the playfield's area on screen.
+func (l layout) board() rect {
+	return rect{x: l.originX, y: l.originY, w: l.boardPxW, h: l.boardPxH}
+}
+
+// column is the board column under screen x, off either side past the
+// walls.
+func (l layout) column(x int) int {
+	return int(floorF((float32(x) - l.originX) / l.tile))
+}
+
+// row is the board row under screen y, off the top or bottom past it.
+func (l layout) row(y int) int {
+	return int(floorF((float32(y) - l.originY) / l.tile))
+}
+
+// settingsButton is the touch target that opens the settings menu.
+func (l layout) settingsButton() rect {
+	return rect{x: l.panelX, y: l.originY + 240*l.uiScale, w: 80 * l.uiScale, h: 24 * l.uiScale}
+}
+
+// holdBox is the hold preview, which doubles as the hold button on touch.
+func (l layout) holdBox() rect {
+	return rect{x: l.panelX, y: l.originY + 78*l.uiScale, w: l.tile*0.7*4 + 16, h: 78 * l.uiScale}
+}
+
+func (g *Game) touchBarHeight() float32 {
+	if g.settings.TouchLayout == TouchDrag {
+		return 0
+	}
+	return touchBarH * float32(g.settings.UIScale)
+}
+
+// drawText draws HUD text with its baseline at (x, y), scaled by the UI
+// scale setting.
+func (g *Game) drawText(screen *ebiten.Image, s string, x, y float32, clr color.Color) {
+	k := g.settings.UIScale
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(k, k)
+	op.GeoM.Translate(float64(x), float64(y))
+	op.ColorScale.ScaleWithColor(clr)
+	text.DrawWithOptions(screen, s, textFace, op)
+}
@@ -0,0 +1,97 @@
+// Package update checks GitHub for a newer release of the game.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// LatestURL is the GitHub API endpoint for the newest published release.
+const LatestURL = "https://api.github.com/repos/kyleparisi/ai-playground/releases/latest"
+
+// Release is the subset of the GitHub release payload the game shows.
+type Release struct {
+	Tag  string `json:"tag_name"`
+	Name string `json:"name"`
+	Body string `json:"body"`
+	URL  string `json:"html_url"`
+}
+
+// Summary is the first non-empty line of the changelog, without Markdown
+// list or heading markers.
+func (r Release) Summary() string {
+	for _, line := range strings.Split(r.Body, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#*- "))
+		if line != "" {
+			return line
+		}
+	}
+	return r.Name
+}
+
+// Check fetches the latest release and returns it if it is newer than
+// current. It returns nil, nil when current is up to date or is not a
+// version (such as "dev").
+func Check(ctx context.Context, client *http.Client, url, current string) (*Release, error) {
+	cur, ok := parse(current)
+	if !ok {
+		return nil, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("update: %s", resp.Status)
+	}
+	var r Release
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, err
+	}
+	latest, ok := parse(r.Tag)
+	if !ok || !newer(latest, cur) {
+		return nil, nil
+	}
+	return &r, nil
+}
+
+// parse reads "v1.2.3" or "1.2" into major, minor, patch. Pre-release and
+// build suffixes are ignored.
+func parse(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
+
+func newer(a, b [3]int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package update
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want [3]int
+		ok   bool
+	}{
+		{"v1.2.3", [3]int{1, 2, 3}, true},
+		{"1.2", [3]int{1, 2, 0}, true},
+		{" v4 ", [3]int{4, 0, 0}, true},
+		{"v1.2.3-rc.1", [3]int{1, 2, 3}, true},
+		{"1.0.0+build.5", [3]int{1, 0, 0}, true},
+		{"dev", [3]int{}, false},
+		{"", [3]int{}, false},
+		{"v1..2", [3]int{}, false},
+		{"1.2.", [3]int{}, false},
+		{"1.2.3.4", [3]int{}, false},
+		{"1.-2", [3]int{}, false},
+	} {
+		got, ok := parse(tc.in)
+		if ok != tc.ok || ok && got != tc.want {
+			t.Errorf("parse(%q) = %v, %v; want %v, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestNewer(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{"1.2.4", "1.2.3", true},
+		{"1.3", "1.2.9", true},
+		{"2", "1.99.99", true},
+		{"1.2.3", "1.2.3", false},
+		{"1.2", "1.2.0", false},
+		{"1.2.3", "1.2.4", false},
+		// A pre-release counts as its release.
+		{"1.2.3", "1.2.3-beta", false},
+	} {
+		a, _ := parse(tc.a)
+		b, _ := parse(tc.b)
+		if got := newer(a, b); got != tc.want {
+			t.Errorf("newer(%s, %s) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	for _, tc := range []struct {
+		body, want string
+	}{
+		{"## What's new\n- Faster", "What's new"},
+		{"\n\n  * Boss Battle mode\n* More", "Boss Battle mode"},
+		{"- fixed the -1 bug", "fixed the -1 bug"},
+		{"", "v1.2.0"},
+		{"#\n-\n", "v1.2.0"},
+	} {
+		r := Release{Name: "v1.2.0", Body: tc.body}
+		if got := r.Summary(); got != tc.want {
+			t.Errorf("Summary of %q = %q, want %q", tc.body, got, tc.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tag_name":"v1.3.0","name":"v1.3.0","html_url":"https://example.com"}`))
+	}))
+	defer srv.Close()
+	for _, tc := range []struct {
+		current string
+		want    bool
+	}{
+		{"v1.2.9", true},
+		{"v1.3.0", false},
+		{"v2.0.0", false},
+		{"dev", false},
+	} {
+		r, err := Check(context.Background(), srv.Client(), srv.URL, tc.current)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (r != nil) != tc.want {
+			t.Errorf("from %s: got %v, want an update %v", tc.current, r, tc.want)
+		}
+	}
+}
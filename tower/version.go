@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"tetris/update"
+)
+
+// version is the release this build came from, set at link time with
+// -ldflags "-X main.version=v1.2.3". Development builds skip update checks.
+var version = "dev"
+
+// availableUpdate holds a newer release once the background check finds one.
+var availableUpdate atomic.Pointer[update.Release]
+
+const updateBannerFrames = 10 * 60
+
+// bannerAge counts frames the banner has been up, across restarts.
+var bannerAge int
+
+// checkForUpdate queries GitHub in the background. It never blocks startup
+// and failures are only logged.
+func checkForUpdate(s Settings) {
+	if !s.CheckUpdates {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		rel, err := update.Check(ctx, http.DefaultClient, update.LatestURL, version)
+		if err != nil {
+			slog.Debug("update check failed", "err", err)
+			return
+		}
+		if rel != nil {
+			slog.Info("update available", "current", version, "latest", rel.Tag)
+			availableUpdate.Store(rel)
+		}
+	}()
+}
+
+// drawUpdateBanner shows a strip across the top for a while after an update
+// is found. The banner never takes input focus.
+func (g *Game) drawUpdateBanner(screen *ebiten.Image) {
+	rel := availableUpdate.Load()
+	if rel == nil || bannerAge >= updateBannerFrames {
+		return
+	}
+	w := float32(screen.Bounds().Dx())
+	k := float32(g.settings.UIScale)
+	h := 34 * k
+	pal := g.palette()
+	vector.DrawFilledRect(screen, 0, 0, w, h, alpha(shade(pal.Info, 0.6), 230), false)
+	g.drawText(screen, trf("New version %s available", rel.Tag), 8, 14*k, pal.Text)
+	summary := rel.Summary()
+	if n := int((w - 16) / (7 * k)); len(summary) > n && n > 3 {
+		summary = summary[:n-3] + "..."
+	}
+	g.drawText(screen, summary, 8, 28*k, pal.Dim)
+}
+
+func updateBanner() {
+	if availableUpdate.Load() != nil && bannerAge < updateBannerFrames {
+		bannerAge++
+	}
+}
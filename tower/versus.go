@@ -0,0 +1,80 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// versusPanelW is the strip beside each versus board for its next and
+// hold pieces.
+const versusPanelW = 56
+
+// attachRival wraps the versus rival's game, if there is one, so its board
+// animates, sounds and draws like the first player's. Scores and records
+// stay with the first player's game.
+func (g *Game) attachRival() {
+	r := g.Rival()
+	if r == nil {
+		g.rival = nil
+		return
+	}
+	g.rival = &Game{Game: r, modifiers: g.modifiers, state: statePlaying, settings: g.settings}
+	h := g.rival.hooks()
+	h.Prestige, h.GameOver = nil, nil
+	r.Hooks = h
+	r.Subscribe(sfx{g: g.rival, rival: true})
+}
+
+// versusLayout places player i's board in their half of the screen.
+func (g *Game) versusLayout(i int) layout {
+	sw, sh := g.screenSize()
+	half := sw / 2
+	w := float32(g.Width())
+	tile := max(1, floorF(minF((half-margin*2-versusPanelW)/w, (sh-margin*2-80)/float32(g.Height()))))
+	originX := float32(i)*half + margin
+	return layout{
+		tile:     tile,
+		originX:  originX,
+		originY:  margin + 24,
+		boardPxW: tile * w,
+		boardPxH: tile * float32(g.Height()),
+		panelX:   originX + tile*w + 8,
+		uiScale:  float32(g.settings.UIScale),
+	}
+}
+
+var versusLabels = [2]struct{ name, keys string }{
+	{"Player 1", "A/D/S, W/Q turn, E hold, Space"},
+	{"Player 2", "←/→/↓, ↑// turn, RShift, Enter"},
+}
+
+// drawVersus draws both boards side by side, each with its pieces, score
+// and keys.
+func (g *Game) drawVersus(screen *ebiten.Image) {
+	pal := g.palette()
+	g.rival.settings = g.settings
+	k := float32(g.settings.UIScale)
+	grey := pal.Dim
+	for i, v := range []*Game{g, g.rival} {
+		l := g.versusLayout(i)
+		th := v.theme()
+		v.drawBoard(screen, l.originX, l.originY, l)
+		label := versusLabels[i]
+		switch {
+		case i == 0 && g.cpu != nil:
+			label.name, label.keys = "CPU", "F3 takes over"
+		case i == 1 && g.rivalCPU != nil:
+			label.name, label.keys = "CPU", "F3 for two players"
+		}
+		g.drawText(screen, tr(label.name), l.originX, l.originY-10*k, pal.Text)
+
+		queue := v.Queue()
+		g.drawText(screen, tr("Next"), l.panelX, l.originY+10*k, pal.Text)
+		drawNext(screen, v.PieceSet(), l.panelX-8, l.originY+12*k, l.tile*0.8, queue[0], pal.Pieces[queue[0]], th)
+		g.drawText(screen, tr("Hold"), l.panelX, l.originY+70*k, pal.Text)
+		drawHold(screen, v.PieceSet(), l.panelX-8, l.originY+72*k, l.tile*0.8, v.Player(0), th)
+
+		below := l.originY + l.boardPxH + 18*k
+		g.drawText(screen, trf("Score %d  Lines %d", v.Score(), v.Lines()), l.originX, below, pal.Text)
+		g.drawText(screen, tr(label.keys), l.originX, below+16*k, grey)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestVersusRivalFollowsRestarts(t *testing.T) {
+	tempConfig(t)
+	g := newGameSeeded(1, modeByName("Versus"), Modifiers{})
+	first := g.rival
+	if first == nil || first.Game != g.Rival() {
+		t.Fatal("versus has no view of player 2's board")
+	}
+	g.Reset()
+	if g.rival == nil || g.rival == first || g.rival.Game != g.Rival() {
+		t.Fatal("after a restart the view still shows the old board")
+	}
+	g.stepPlayers(frameInput{}, frameInput{hardDrop: true})
+	if g.Rival().Board().Empty() || !g.Board().Empty() {
+		t.Error("player 2's hard drop didn't land on player 2's board")
+	}
+
+	solo := newGameSeeded(1, modes[0], Modifiers{})
+	if solo.rival != nil || solo.Rival() != nil {
+		t.Error("a solo game has a rival")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// The window can be dragged down to minWindowW x minWindowH; the layout
+// squeezes the board to fit rather than cropping it.
+const (
+	minWindowW = 320
+	minWindowH = 400
+)
+
+// Layout takes the window's size for the screen's, so the board is drawn
+// at whatever size the window is rather than stretched from a fixed one.
+func (g *Game) Layout(ow, oh int) (int, int) {
+	g.screenW, g.screenH = max(ow, 1), max(oh, 1)
+	return g.screenW, g.screenH
+}
+
+// screenSize is the screen as of the last Layout. Before the first, as when
+// rendering a replay, it's logicalW x logicalH.
+func (g *Game) screenSize() (float32, float32) {
+	if g.screenW == 0 {
+		return logicalW, logicalH
+	}
+	return float32(g.screenW), float32(g.screenH)
+}
+
+// setupWindow opens the window at logicalW x logicalH, resizable, and in
+// fullscreen if it was last time.
+func setupWindow(s Settings) {
+	ebiten.SetWindowSize(logicalW, logicalH)
+	ebiten.SetWindowSizeLimits(minWindowW, minWindowH, -1, -1)
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetFullscreen(s.Fullscreen)
+}
+
+// setFullscreen switches fullscreen on or off and remembers it.
+func (g *Game) setFullscreen(on bool) {
+	g.settings.Fullscreen = on
+	ebiten.SetFullscreen(on)
+}
+
+// updateFullscreen toggles fullscreen on F11, whatever screen is up.
+func (g *Game) updateFullscreen() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF11) {
+		g.setFullscreen(!g.settings.Fullscreen)
+		g.storeSettings()
+	}
+}
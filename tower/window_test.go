@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestLayoutFollowsWindow(t *testing.T) {
+	tempConfig(t)
+	g := newGameSeeded(1, modes[0], Modifiers{})
+	small := g.layout()
+	for _, size := range [][2]int{{1920, 1080}, {480, 640}, {1000, 400}} {
+		w, h := g.Layout(size[0], size[1])
+		if w != size[0] || h != size[1] {
+			t.Fatalf("Layout(%d, %d) = %d, %d", size[0], size[1], w, h)
+		}
+		l := g.layout()
+		if l.tile != float32(int(l.tile)) {
+			t.Errorf("%dx%d: tile %v isn't whole pixels", w, h, l.tile)
+		}
+		if l.originX < margin || l.originY < margin || l.originY+l.boardPxH > float32(h) || l.panelX+rightPanelW > float32(w) {
+			t.Errorf("%dx%d: board and panel don't fit: %+v", w, h, l)
+		}
+		left, right := l.originX, float32(w)-(l.panelX+rightPanelW)
+		if d := left - right; d > 2*margin || d < -2*margin {
+			t.Errorf("%dx%d: board and panel off centre, %v left and %v right", w, h, left, right)
+		}
+	}
+	g.Layout(1920, 1080)
+	if g.layout().tile <= small.tile {
+		t.Error("a bigger window didn't make a bigger board")
+	}
+}